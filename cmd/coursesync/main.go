@@ -0,0 +1,87 @@
+// coursesync 从 Markdown 目录同步课程内容
+//
+// 用法：
+//
+//	go run ./cmd/coursesync -course 12 -teacher 3 -branch 1 -dir ./course-src
+//	go run ./cmd/coursesync -course 12 -teacher 3 -branch 1 -dir ./course-src -apply
+//
+// 默认只打印同步计划；加 -apply 时先打印计划再执行
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"online-learning-platform/internal/config"
+	"online-learning-platform/internal/database"
+	"online-learning-platform/internal/logger"
+	ossclient "online-learning-platform/internal/oss"
+	"online-learning-platform/internal/service"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "配置文件路径")
+	courseID := flag.Uint("course", 0, "课程ID")
+	teacherID := flag.Uint("teacher", 0, "教师在分支节点上的用户ID")
+	branchID := flag.Uint("branch", 0, "教师所在分支ID")
+	dir := flag.String("dir", ".", "Markdown 课程目录")
+	apply := flag.Bool("apply", false, "执行同步（默认只打印计划）")
+	prune := flag.Bool("prune", false, "删除目录中已不存在的章节、课时和任务")
+	flag.Parse()
+
+	if *courseID == 0 || *teacherID == 0 || *branchID == 0 {
+		fmt.Fprintln(os.Stderr, "-course, -teacher and -branch are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		logger.Fatalf("Failed to load config: %v", err)
+	}
+	logger.InitLogger(cfg.App.LogLevel)
+
+	if err := database.InitCentralDB(cfg.Database.Central); err != nil {
+		logger.Fatalf("Failed to initialize central database: %v", err)
+	}
+	defer database.CloseCentralDB()
+
+	// 校验教师身份需要查询分支节点上的用户
+	if err := database.InitBranchDBs(cfg.Branches); err != nil {
+		logger.Fatalf("Failed to initialize branch databases: %v", err)
+	}
+	defer database.CloseBranchDBs()
+
+	if err := ossclient.InitOSSClient(cfg.OSS); err != nil {
+		logger.Fatalf("Failed to initialize OSS client: %v", err)
+	}
+
+	courseService := service.NewCourseService()
+	fsys := os.DirFS(*dir)
+	opts := service.MarkdownSyncOptions{Prune: *prune}
+
+	plan, err := courseService.SyncCourseFromMarkdown(uint(*courseID), uint(*teacherID), uint(*branchID), fsys, opts)
+	if err != nil {
+		logger.Fatalf("Failed to plan sync: %v", err)
+	}
+	fmt.Print(plan)
+
+	if !*apply {
+		if len(plan.Changes) > 0 || len(plan.Uploads) > 0 {
+			fmt.Println("dry run, re-run with -apply to apply these changes")
+		}
+		return
+	}
+	if len(plan.Changes) == 0 && len(plan.Uploads) == 0 {
+		fmt.Println("nothing to apply")
+		return
+	}
+
+	opts.Apply = true
+	applied, err := courseService.SyncCourseFromMarkdown(uint(*courseID), uint(*teacherID), uint(*branchID), fsys, opts)
+	if err != nil {
+		logger.Fatalf("Failed to apply sync: %v", err)
+	}
+	fmt.Printf("applied %d changes, uploaded %d objects\n", len(applied.Changes), len(applied.Uploads))
+}
@@ -0,0 +1,40 @@
+// searchindex 重建中央服务器的全文检索索引
+//
+// 用法：
+//
+//	go run ./cmd/searchindex
+//
+// 课程内容变更时会自动更新索引；首次部署 search_documents 表或调整切分规则后需要执行一次
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"online-learning-platform/internal/config"
+	"online-learning-platform/internal/database"
+	"online-learning-platform/internal/logger"
+	"online-learning-platform/internal/service"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "配置文件路径")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		logger.Fatalf("Failed to load config: %v", err)
+	}
+	logger.InitLogger(cfg.App.LogLevel)
+
+	if err := database.InitCentralDB(cfg.Database.Central); err != nil {
+		logger.Fatalf("Failed to initialize central database: %v", err)
+	}
+	defer database.CloseCentralDB()
+
+	indexed, err := service.NewSearchService().RebuildIndex()
+	if err != nil {
+		logger.Fatalf("Failed to rebuild search index: %v", err)
+	}
+	fmt.Printf("indexed %d documents\n", indexed)
+}
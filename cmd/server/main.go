@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -14,6 +15,7 @@ import (
 	"online-learning-platform/internal/database"
 	"online-learning-platform/internal/logger"
 	ossclient "online-learning-platform/internal/oss"
+	"online-learning-platform/internal/service"
 	"online-learning-platform/pkg/utils"
 )
 
@@ -44,16 +46,63 @@ func main() {
 	}
 	logger.Infof("Branch databases connected: %d branches", len(cfg.Branches))
 
+	// 在开始处理请求之前校正课程报名人数，失败时保留原有计数
+	if err := service.RecountEnrollments(); err != nil {
+		logger.Warnf("Failed to recount enrollments: %v", err)
+	}
+
 	// 初始化OSS客户端
 	if err := ossclient.InitOSSClient(cfg.OSS); err != nil {
 		logger.Fatalf("Failed to initialize OSS client: %v", err)
 	}
 	logger.Info("OSS client initialized")
 
+	// 初始化上传文件扫描器
+	if err := service.InitUploadScanner(cfg.Upload.Scanner); err != nil {
+		logger.Fatalf("Failed to initialize upload scanner: %v", err)
+	}
+
 	// 初始化JWT
 	utils.InitJWT(cfg.JWT.Secret)
 	logger.Info("JWT initialized")
 
+	// 启动定时发布任务
+	publishInterval, err := time.ParseDuration(cfg.Publish.Interval)
+	if err != nil || publishInterval <= 0 {
+		publishInterval = time.Minute
+	}
+	stopScheduler := make(chan struct{})
+	go service.NewPublishService().Start(publishInterval, stopScheduler)
+	logger.Infof("Publish scheduler started, interval %s", publishInterval)
+
+	// 启动播放心跳批量写入，关闭时等待剩余心跳写入完成
+	flushInterval, err := time.ParseDuration(cfg.Playback.FlushInterval)
+	if err != nil || flushInterval <= 0 {
+		flushInterval = 10 * time.Second
+	}
+	playbackDone := make(chan struct{})
+	go func() {
+		service.NewPlaybackService().Start(flushInterval, stopScheduler)
+		close(playbackDone)
+	}()
+	logger.Infof("Playback flusher started, interval %s", flushInterval)
+
+	// 启动视频处理任务，关闭时中止正在进行的转码，课时重新回到待处理状态
+	videoDone := make(chan struct{})
+	if cfg.Video.Enabled {
+		videoInterval, err := time.ParseDuration(cfg.Video.Interval)
+		if err != nil || videoInterval <= 0 {
+			videoInterval = 30 * time.Second
+		}
+		go func() {
+			service.NewVideoService(cfg.Video).Start(videoInterval, stopScheduler)
+			close(videoDone)
+		}()
+		logger.Infof("Video processor started, interval %s", videoInterval)
+	} else {
+		close(videoDone)
+	}
+
 	// 设置Gin模式
 	if cfg.App.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -103,6 +152,9 @@ func main() {
 	logger.Info("Shutting down server...")
 
 	// 清理资源
+	close(stopScheduler)
+	<-playbackDone
+	<-videoDone
 	if err := database.CloseCentralDB(); err != nil {
 		logger.Errorf("Failed to close central database: %v", err)
 	}
@@ -0,0 +1,66 @@
+// storagegc 清理对象存储中没有被任何数据库引用的对象
+//
+// 用法：
+//
+//	go run ./cmd/storagegc
+//	go run ./cmd/storagegc -grace 168h -apply
+//
+// 默认只打印报告；加 -apply 时删除最后修改时间早于宽限期的孤立对象。需要能连接中央服务器和所有分支节点
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"online-learning-platform/internal/config"
+	"online-learning-platform/internal/database"
+	"online-learning-platform/internal/logger"
+	ossclient "online-learning-platform/internal/oss"
+	"online-learning-platform/internal/service"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "配置文件路径")
+	grace := flag.Duration("grace", 72*time.Hour, "宽限期，只删除最后修改时间早于该时长的对象")
+	apply := flag.Bool("apply", false, "删除孤立对象（默认只打印报告）")
+	flag.Parse()
+
+	// 宽限期不能短于上传位置的有效期，否则会删除仍在上传中的对象
+	if *grace < 24*time.Hour {
+		fmt.Fprintln(os.Stderr, "-grace must be at least 24h")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		logger.Fatalf("Failed to load config: %v", err)
+	}
+	logger.InitLogger(cfg.App.LogLevel)
+
+	if err := database.InitCentralDB(cfg.Database.Central); err != nil {
+		logger.Fatalf("Failed to initialize central database: %v", err)
+	}
+	defer database.CloseCentralDB()
+
+	// 作业文件的引用分散在各分支节点，任何一个节点不可用时都不能判断对象是否孤立
+	if err := database.InitBranchDBs(cfg.Branches); err != nil {
+		logger.Fatalf("Failed to initialize branch databases: %v", err)
+	}
+	defer database.CloseBranchDBs()
+
+	if err := ossclient.InitOSSClient(cfg.OSS); err != nil {
+		logger.Fatalf("Failed to initialize OSS client: %v", err)
+	}
+
+	report, err := service.NewStorageGCService().Run(*grace, *apply)
+	if err != nil {
+		logger.Fatalf("Storage gc failed: %v", err)
+	}
+	fmt.Print(report)
+	if !*apply && len(report.Orphans) > 0 {
+		fmt.Println("dry run, re-run with -apply to delete these objects")
+	}
+}
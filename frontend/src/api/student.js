@@ -45,6 +45,11 @@ export const getProgress = (id) => {
   return request.get(`/student/courses/${id}/progress`)
 }
 
+// 获取课时内容的临时访问地址（需已报名）
+export const getLessonContent = (lessonId) => {
+  return request.get(`/student/lessons/${lessonId}/content`)
+}
+
 // 获取已报名课程列表
 export const getEnrolledCourses = (params) => {
   return request.get('/student/courses/enrolled', { params })
@@ -55,13 +60,12 @@ export const getTask = (id) => {
   return request.get(`/student/tasks/${id}`)
 }
 
-// 提交作业（支持文件上传）
+// 提交作业（文件先通过 uploadAnswerFile 直传，再传入 upload_id）
 export const submitAnswer = (taskId, formData) => {
   return request.post(`/student/tasks/${taskId}/answers`, formData, {
     headers: {
       'Content-Type': 'multipart/form-data'
-    },
-    timeout: 60000 // 文件上传设置为60秒超时
+    }
   })
 }
 
@@ -35,8 +35,7 @@ export const createLesson = (courseId, chapterId, formData) => {
   return request.post(`/teacher/courses/${courseId}/chapters/${chapterId}/lessons`, formData, {
     headers: {
       'Content-Type': 'multipart/form-data'
-    },
-    timeout: 60000 // 文件上传设置为60秒超时
+    }
   })
 }
 
@@ -0,0 +1,78 @@
+import request from './request'
+
+// 浏览器无法识别类型时按扩展名补充
+const extensionTypes = {
+  md: 'text/markdown',
+  txt: 'text/plain',
+  pdf: 'application/pdf',
+  mp4: 'video/mp4',
+  webm: 'video/webm'
+}
+
+const contentTypeOf = (file) => {
+  if (file.type) return file.type
+  const ext = file.name.split('.').pop().toLowerCase()
+  return extensionTypes[ext] || 'application/octet-stream'
+}
+
+// 计算文件的 SHA-256（十六进制），服务端确认上传时校验
+const sha256 = async (file) => {
+  const digest = await crypto.subtle.digest('SHA-256', await file.arrayBuffer())
+  return Array.from(new Uint8Array(digest)).map(b => b.toString(16).padStart(2, '0')).join('')
+}
+
+const putBlob = async (url, body, headers = {}) => {
+  const res = await fetch(url, { method: 'PUT', body, headers })
+  if (!res.ok) {
+    throw new Error(`上传失败：${res.status}`)
+  }
+}
+
+// 将文件直传到对象存储：申请上传位置 -> 上传（大文件分片）-> 确认
+// 上传中断时重新获取尚未上传的分片继续上传，返回已确认的上传记录
+const directUpload = async (role, createPath, file, onProgress, extra = {}) => {
+  let slot = await request.post(createPath, {
+    file_name: file.name,
+    content_type: contentTypeOf(file),
+    size: file.size,
+    sha256: await sha256(file),
+    ...extra
+  })
+
+  for (let attempt = 0; slot.status === 'pending'; attempt++) {
+    try {
+      if (slot.part_count === 1) {
+        await putBlob(slot.url, file, { 'Content-Type': slot.content_type })
+      } else {
+        let done = (slot.uploaded_parts || []).length
+        for (const part of slot.parts || []) {
+          const start = (part.part_number - 1) * slot.part_size
+          await putBlob(part.url, file.slice(start, start + slot.part_size))
+          onProgress?.(Math.round((++done / slot.part_count) * 100))
+        }
+      }
+      // 服务端读取整个文件校验，大文件需要较长时间
+      slot = await request.post(`/${role}/uploads/${slot.upload_id}/complete`, null, { timeout: 0 })
+    } catch (error) {
+      if (attempt >= 2 || error.response?.status === 400) throw error
+      slot = await request.get(`/${role}/uploads/${slot.upload_id}`)
+    }
+  }
+
+  if (slot.status !== 'completed') {
+    throw new Error('文件上传失败，请重新上传')
+  }
+  return slot
+}
+
+// 教师上传课时文件，lessonType 决定允许的文件类型和大小
+export const uploadLessonFile = (courseId, chapterId, lessonType, file, onProgress) => {
+  return directUpload('teacher', `/teacher/courses/${courseId}/chapters/${chapterId}/uploads`, file, onProgress, {
+    lesson_type: lessonType
+  })
+}
+
+// 学生上传作业文件
+export const uploadAnswerFile = (taskId, file, onProgress) => {
+  return directUpload('student', `/student/tasks/${taskId}/uploads`, file, onProgress)
+}
@@ -72,6 +72,11 @@ export default defineConfig(({ mode }) => {
         '/api': {
           target: 'http://localhost:8080',
           changeOrigin: true
+        },
+        // 本地存储后端的签名URL（oss.provider 为 local 时）
+        '/files': {
+          target: 'http://localhost:8080',
+          changeOrigin: true
         }
       }
     }
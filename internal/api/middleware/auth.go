@@ -60,6 +60,23 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware 可选认证：携带有效token时与 AuthMiddleware 一样存储用户信息，否则按未登录继续处理
+// 用于不需要认证、但登录后返回个人信息的接口
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if claims, err := utils.ParseToken(parts[1]); err == nil {
+				c.Set("user_id", claims.UserID)
+				c.Set("username", claims.Username)
+				c.Set("role", claims.Role)
+				c.Set("branch_id", claims.BranchID)
+			}
+		}
+		c.Next()
+	}
+}
+
 // RequireRole 要求特定角色的中间件
 func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
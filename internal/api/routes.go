@@ -1,11 +1,14 @@
 package api
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 
 	"online-learning-platform/internal/api/middleware"
 	"online-learning-platform/internal/api/student"
 	"online-learning-platform/internal/api/teacher"
+	"online-learning-platform/internal/oss"
 )
 
 // SetupRoutes 设置所有路由
@@ -14,9 +17,25 @@ func SetupRoutes(r *gin.Engine) {
 	studentAuthHandler := student.NewAuthHandler()
 	studentCourseHandler := student.NewCourseHandler()
 	studentTaskHandler := student.NewTaskHandler()
+	studentLearningHandler := student.NewLearningHandler()
+	studentPrerequisiteHandler := student.NewPrerequisiteHandler()
+	studentCatalogHandler := student.NewCatalogHandler()
+	studentSearchHandler := student.NewSearchHandler()
+	studentPlaybackHandler := student.NewPlaybackHandler()
+	studentUploadHandler := student.NewUploadHandler()
+	studentQuizHandler := student.NewQuizHandler()
 	teacherAuthHandler := teacher.NewAuthHandler()
 	teacherCourseHandler := teacher.NewCourseHandler()
 	teacherTaskHandler := teacher.NewTaskHandler()
+	teacherRevisionHandler := teacher.NewRevisionHandler()
+	teacherPrerequisiteHandler := teacher.NewPrerequisiteHandler()
+	teacherStaffHandler := teacher.NewStaffHandler()
+	teacherCatalogHandler := teacher.NewCatalogHandler()
+	teacherUploadHandler := teacher.NewUploadHandler()
+	teacherAnswerHandler := teacher.NewAnswerHandler()
+	teacherQuizHandler := teacher.NewQuizHandler()
+	teacherExtensionHandler := teacher.NewExtensionHandler()
+	teacherRubricHandler := teacher.NewRubricHandler()
 
 	// 学生端API
 	studentAPI := r.Group("/api/v1/student")
@@ -33,14 +52,43 @@ func SetupRoutes(r *gin.Engine) {
 
 		// 课程相关（不需要认证）
 		studentAPI.GET("/courses", studentCourseHandler.ListCourses)
+		studentAPI.GET("/courses/catalog", studentCatalogHandler.SearchCatalog)
+		studentAPI.GET("/categories", studentCatalogHandler.ListCategories)
+		studentAPI.GET("/search", studentSearchHandler.Search)
 		studentAPI.GET("/courses/:id", studentCourseHandler.GetCourse)
-		studentAPI.GET("/courses/:id/tasks", studentTaskHandler.ListTasksByCourse)
+		studentAPI.GET("/courses/:id/tasks", middleware.OptionalAuthMiddleware(), studentTaskHandler.ListTasksByCourse)
 		studentAPI.GET("/tasks/:id", studentTaskHandler.GetTask)
 
 		// 需要认证的接口
 		studentAPI.Use(middleware.AuthMiddleware())
 		{
 			studentAPI.GET("/profile", studentAuthHandler.GetProfile)
+			studentAPI.GET("/courses/enrolled", studentCourseHandler.ListEnrolledCourses)
+			studentAPI.GET("/courses/:id/prerequisites", studentPrerequisiteHandler.CheckPrerequisites)
+			studentAPI.POST("/courses/:id/enroll", studentLearningHandler.Enroll)
+			studentAPI.DELETE("/courses/:id/enroll", studentLearningHandler.Drop)
+			studentAPI.GET("/courses/:id/progress", studentLearningHandler.GetProgress)
+			studentAPI.GET("/lessons/:id/content", studentLearningHandler.GetLessonContent)
+			studentAPI.GET("/lessons/:id/hls/*name", studentLearningHandler.GetLessonPlaylist)
+			studentAPI.POST("/lessons/:id/complete", studentLearningHandler.CompleteLesson)
+			studentAPI.POST("/lessons/:id/heartbeat", studentPlaybackHandler.Heartbeat)
+			studentAPI.GET("/lessons/:id/playback", studentPlaybackHandler.GetPlayback)
+
+			// 作业提交（文件先直传对象存储，确认后通过 upload_id 提交）
+			studentAPI.POST("/tasks/:id/uploads", studentUploadHandler.CreateUpload)
+			studentAPI.GET("/uploads/:id", studentUploadHandler.GetUpload)
+			studentAPI.POST("/uploads/:id/complete", studentUploadHandler.CompleteUpload)
+			studentAPI.DELETE("/uploads/:id", studentUploadHandler.AbortUpload)
+			studentAPI.POST("/tasks/:id/answers", studentTaskHandler.SubmitAnswer)
+			studentAPI.GET("/tasks/:id/answers", studentTaskHandler.GetMyAnswer)
+			studentAPI.GET("/tasks/:id/deadline", studentTaskHandler.GetMyDeadline)
+			studentAPI.GET("/tasks/:id/attempts", studentTaskHandler.ListMyAttempts)
+			studentAPI.GET("/tasks/:id/rubric", studentTaskHandler.GetRubric)
+
+			// 测验（逐题作答，自动评分）
+			studentAPI.GET("/tasks/:id/quiz", studentQuizHandler.GetQuiz)
+			studentAPI.POST("/tasks/:id/quiz", studentQuizHandler.SubmitQuiz)
+			studentAPI.GET("/tasks/:id/quiz/result", studentQuizHandler.GetQuizResult)
 		}
 	}
 
@@ -55,23 +103,97 @@ func SetupRoutes(r *gin.Engine) {
 
 		// 需要认证的接口
 		teacherAPI.Use(middleware.AuthMiddleware())
-		teacherAPI.Use(middleware.RequireRole("teacher"))
+		teacherAPI.Use(middleware.RequireRole("teacher", "admin"))
 		{
 			// 个人信息
 			teacherAPI.GET("/profile", teacherAuthHandler.GetProfile)
 
 			// 课程管理
 			teacherAPI.POST("/courses", teacherCourseHandler.CreateCourse)
+			teacherAPI.POST("/courses/import", teacherCourseHandler.ImportCourse)
 			teacherAPI.GET("/courses", teacherCourseHandler.ListCourses)
 			teacherAPI.GET("/courses/:id", teacherCourseHandler.GetCourse)
+			teacherAPI.PUT("/courses/:id", teacherCourseHandler.UpdateCourse)
+			teacherAPI.DELETE("/courses/:id", teacherCourseHandler.DeleteCourse)
+			teacherAPI.POST("/courses/:id/archive", teacherCourseHandler.ArchiveCourse)
+			teacherAPI.POST("/courses/:id/clone", teacherCourseHandler.CloneCourse)
+			teacherAPI.GET("/courses/:id/export", teacherCourseHandler.ExportCourse)
+			teacherAPI.POST("/courses/:id/sync", teacherCourseHandler.SyncCourse)
+			teacherAPI.POST("/courses/:id/publish", teacherCourseHandler.PublishCourse)
+			teacherAPI.POST("/courses/:id/unpublish", teacherCourseHandler.UnpublishCourse)
 			teacherAPI.POST("/courses/:id/chapters", teacherCourseHandler.CreateChapter)
+			teacherAPI.PUT("/courses/:id/chapters/order", teacherCourseHandler.ReorderChapters)
+			teacherAPI.PUT("/courses/:id/chapters/:chapter_id", teacherCourseHandler.UpdateChapter)
+			teacherAPI.DELETE("/courses/:id/chapters/:chapter_id", teacherCourseHandler.DeleteChapter)
+			teacherAPI.POST("/courses/:id/chapters/:chapter_id/publish", teacherCourseHandler.PublishChapter)
+			teacherAPI.POST("/courses/:id/chapters/:chapter_id/unpublish", teacherCourseHandler.UnpublishChapter)
+			teacherAPI.POST("/courses/:id/chapters/:chapter_id/uploads", teacherUploadHandler.CreateUpload)
+			teacherAPI.GET("/uploads/:id", teacherUploadHandler.GetUpload)
+			teacherAPI.POST("/uploads/:id/complete", teacherUploadHandler.CompleteUpload)
+			teacherAPI.DELETE("/uploads/:id", teacherUploadHandler.AbortUpload)
 			teacherAPI.POST("/courses/:id/chapters/:chapter_id/lessons", teacherCourseHandler.CreateLesson)
+			teacherAPI.PUT("/courses/:id/chapters/:chapter_id/lessons/order", teacherCourseHandler.ReorderLessons)
+			teacherAPI.PUT("/courses/:id/chapters/:chapter_id/lessons/:lesson_id", teacherCourseHandler.UpdateLesson)
+			teacherAPI.DELETE("/courses/:id/chapters/:chapter_id/lessons/:lesson_id", teacherCourseHandler.DeleteLesson)
+			teacherAPI.POST("/courses/:id/chapters/:chapter_id/lessons/:lesson_id/publish", teacherCourseHandler.PublishLesson)
+			teacherAPI.POST("/courses/:id/chapters/:chapter_id/lessons/:lesson_id/unpublish", teacherCourseHandler.UnpublishLesson)
+
+			// 先修课程
+			teacherAPI.GET("/courses/:id/prerequisites", teacherPrerequisiteHandler.ListPrerequisites)
+			teacherAPI.PUT("/courses/:id/prerequisites", teacherPrerequisiteHandler.SetPrerequisites)
+			teacherAPI.GET("/courses/:id/prerequisite-overrides", teacherPrerequisiteHandler.ListOverrides)
+			teacherAPI.POST("/courses/:id/prerequisite-overrides", teacherPrerequisiteHandler.GrantOverride)
+			teacherAPI.DELETE("/courses/:id/prerequisite-overrides/:override_id", teacherPrerequisiteHandler.RevokeOverride)
+
+			// 课程目录与分类
+			teacherAPI.GET("/catalog", teacherCatalogHandler.SearchCatalog)
+			teacherAPI.POST("/categories", teacherCatalogHandler.CreateCategory)
+			teacherAPI.PUT("/categories/:id", teacherCatalogHandler.UpdateCategory)
+			teacherAPI.DELETE("/categories/:id", teacherCatalogHandler.DeleteCategory)
+
+			// 教学团队
+			teacherAPI.GET("/courses/:id/staff", teacherStaffHandler.ListStaff)
+			teacherAPI.POST("/courses/:id/staff", teacherStaffHandler.AddStaff)
+			teacherAPI.PUT("/courses/:id/staff/:staff_id", teacherStaffHandler.UpdateStaff)
+			teacherAPI.DELETE("/courses/:id/staff/:staff_id", teacherStaffHandler.RemoveStaff)
 
 			// 任务管理
 			teacherAPI.POST("/lessons/:id/tasks", teacherTaskHandler.CreateTask)
 			teacherAPI.GET("/tasks/:id", teacherTaskHandler.GetTask)
+			teacherAPI.PUT("/tasks/:id", teacherTaskHandler.UpdateTask)
+			teacherAPI.DELETE("/tasks/:id", teacherTaskHandler.DeleteTask)
 			teacherAPI.GET("/courses/:id/tasks", teacherTaskHandler.ListTasksByCourse)
+			teacherAPI.GET("/tasks/:id/questions", teacherQuizHandler.ListQuestions)
+			teacherAPI.POST("/tasks/:id/questions", teacherQuizHandler.CreateQuestion)
+			teacherAPI.PUT("/tasks/:id/questions/:question_id", teacherQuizHandler.UpdateQuestion)
+			teacherAPI.DELETE("/tasks/:id/questions/:question_id", teacherQuizHandler.DeleteQuestion)
+			teacherAPI.GET("/tasks/:id/questions/export", teacherQuizHandler.ExportQuestions)
+			teacherAPI.POST("/tasks/:id/questions/import", teacherQuizHandler.ImportQuestions)
+
+			// 作业批改
+			teacherAPI.GET("/tasks/:id/answers", teacherAnswerHandler.ListAnswers)
+			teacherAPI.GET("/tasks/:id/extensions", teacherExtensionHandler.ListExtensions)
+			teacherAPI.POST("/tasks/:id/extensions", teacherExtensionHandler.GrantExtension)
+			teacherAPI.DELETE("/tasks/:id/extensions/:extension_id", teacherExtensionHandler.RevokeExtension)
+			teacherAPI.GET("/tasks/:id/rubric", teacherRubricHandler.GetRubric)
+			teacherAPI.PUT("/tasks/:id/rubric", teacherRubricHandler.SetRubric)
+			teacherAPI.PUT("/answers/:id/grade", teacherAnswerHandler.GradeAnswer)
+			teacherAPI.GET("/answers/:id/attempts", teacherAnswerHandler.ListAttempts)
+			teacherAPI.GET("/answers/:id/compare", teacherAnswerHandler.CompareAttempts)
+			teacherAPI.GET("/answers/:id/responses", teacherQuizHandler.ListResponses)
+			teacherAPI.PUT("/answers/:id/responses/:response_id", teacherQuizHandler.OverrideResponse)
+
+			// 修订历史
+			teacherAPI.GET("/courses/:id/revisions", teacherRevisionHandler.ListCourseRevisions)
+			teacherAPI.GET("/revisions/:type/:id", teacherRevisionHandler.ListRevisions)
+			teacherAPI.GET("/revisions/:type/:id/diff", teacherRevisionHandler.DiffRevisions)
+			teacherAPI.GET("/revisions/:type/:id/:version", teacherRevisionHandler.GetRevision)
+			teacherAPI.POST("/revisions/:type/:id/:version/restore", teacherRevisionHandler.RestoreRevision)
 		}
 	}
-}
 
+	// 本地存储的签名URL（只在 oss.provider 为 local 时启用，由签名参数鉴权）
+	if h, ok := oss.LocalHandler(); ok {
+		r.Any("/files/*key", gin.WrapH(http.StripPrefix("/files/", h)))
+	}
+}
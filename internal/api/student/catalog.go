@@ -0,0 +1,89 @@
+package student
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// CatalogHandler 学生课程目录处理器
+type CatalogHandler struct {
+	catalogService *service.CatalogService
+}
+
+// NewCatalogHandler 创建课程目录处理器
+func NewCatalogHandler() *CatalogHandler {
+	return &CatalogHandler{
+		catalogService: service.NewCatalogService(),
+	}
+}
+
+// SearchCatalog 课程目录
+// @Summary 课程目录
+// @Description 按分类、标签、开课日期和教师筛选已发布的进行中课程，支持按最新或报名人数排序，返回分面统计
+// @Tags 学生课程
+// @Produce json
+// @Param category_id query int false "分类ID"
+// @Param tag query []string false "标签，可重复，课程需要包含所有标签" collectionFormat(multi)
+// @Param instructor_id query int false "教师ID"
+// @Param start_from query string false "开课日期下限"
+// @Param start_to query string false "开课日期上限"
+// @Param sort query string false "排序：newest（默认）、popular"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} service.CatalogResult
+// @Router /api/v1/student/courses/catalog [get]
+func (h *CatalogHandler) SearchCatalog(c *gin.Context) {
+	var query service.CatalogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// 学生目录只展示已发布且进行中的课程
+	query.PublishedOnly = true
+	query.Status = service.CourseStatusActive
+
+	result, err := h.catalogService.SearchCatalog(&query)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListCategories 获取课程分类列表
+// @Summary 获取课程分类列表
+// @Tags 学生课程
+// @Produce json
+// @Success 200 {array} models.Categories
+// @Router /api/v1/student/categories [get]
+func (h *CatalogHandler) ListCategories(c *gin.Context) {
+	categories, err := h.catalogService.ListCategories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
@@ -43,7 +43,11 @@ func (h *CourseHandler) ListCourses(c *gin.Context) {
 		pageSize = 10
 	}
 
-	courses, total, err := h.courseService.ListCourses(nil, nil, page, pageSize)
+	// 学生目录只展示已发布且进行中的课程，草稿和已归档课程不再出现
+	courses, total, err := h.courseService.ListCourses(service.CourseListFilter{
+		Status:        service.CourseStatusActive,
+		PublishedOnly: true,
+	}, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    errors.ErrCodeInternal,
@@ -79,7 +83,7 @@ func (h *CourseHandler) GetCourse(c *gin.Context) {
 		return
 	}
 
-	course, err := h.courseService.GetCourse(uint(courseID), true)
+	course, err := h.courseService.GetCourse(uint(courseID), true, true)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			c.JSON(appErr.HTTPStatus(), gin.H{
@@ -3,6 +3,7 @@ package student
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -61,16 +62,15 @@ func (h *LearningHandler) Enroll(c *gin.Context) {
 	c.JSON(http.StatusOK, learning)
 }
 
-// UpdateProgress 更新学习进度
-// @Summary 更新学习进度
+// Drop 退课
+// @Summary 学生退课
+// @Description 退出课程或候补，释放的名额按报名先后自动递补给候补学生
 // @Tags 学生学习
 // @Security BearerAuth
-// @Accept json
 // @Param id path int true "课程ID"
-// @Param request body service.UpdateProgressRequest true "学习进度"
-// @Success 200 {object} models.Learning
-// @Router /api/v1/student/courses/{id}/progress [put]
-func (h *LearningHandler) UpdateProgress(c *gin.Context) {
+// @Success 200 {object} map[string]bool
+// @Router /api/v1/student/courses/{id}/enroll [delete]
+func (h *LearningHandler) Drop(c *gin.Context) {
 	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -80,19 +80,133 @@ func (h *LearningHandler) UpdateProgress(c *gin.Context) {
 		return
 	}
 
-	var req service.UpdateProgressRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	if err := h.learningService.DropCourse(userID.(uint), branchID.(uint), uint(courseID)); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deleted": true})
+}
+
+// CompleteLesson 标记课时已学完
+// @Summary 标记课时已学完
+// @Description 记录课时完成情况并重新计算课程进度，进度百分比由已完成课时和已提交任务计算，全部完成时课程自动变为 completed
+// @Tags 学生学习
+// @Security BearerAuth
+// @Param id path int true "课时ID"
+// @Success 200 {object} service.CourseProgress
+// @Router /api/v1/student/lessons/{id}/complete [post]
+func (h *LearningHandler) CompleteLesson(c *gin.Context) {
+	lessonID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid lesson id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	progress, err := h.learningService.CompleteLesson(userID.(uint), branchID.(uint), uint(lessonID))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// GetLessonContent 获取课时内容
+// @Summary 获取课时内容
+// @Description 已报名的学生获取课时视频或文档的签名URL（有效期一小时，过期后重新获取）；Markdown 课时同时返回正文
+// @Tags 学生学习
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "课时ID"
+// @Success 200 {object} service.LessonContent
+// @Router /api/v1/student/lessons/{id}/content [get]
+func (h *LearningHandler) GetLessonContent(c *gin.Context) {
+	lessonID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid lesson id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	content, err := h.learningService.GetLessonContent(userID.(uint), branchID.(uint), uint(lessonID))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
 			"message": err.Error(),
 		})
 		return
 	}
 
+	c.JSON(http.StatusOK, content)
+}
+
+// GetLessonPlaylist 获取课时视频的 HLS 播放列表
+// @Summary 获取 HLS 播放列表
+// @Description 视频处理完成后，已报名的学生通过该接口获取主播放列表（master.m3u8）和各码率的播放列表（如 720p/index.m3u8），分片地址为签名URL
+// @Tags 学生学习
+// @Security BearerAuth
+// @Produce application/vnd.apple.mpegurl
+// @Param id path int true "课时ID"
+// @Param name path string true "播放列表，master.m3u8 或 {档位}/index.m3u8"
+// @Success 200 {string} string "播放列表"
+// @Router /api/v1/student/lessons/{id}/hls/{name} [get]
+func (h *LearningHandler) GetLessonPlaylist(c *gin.Context) {
+	lessonID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid lesson id",
+		})
+		return
+	}
+
 	userID, _ := c.Get("user_id")
 	branchID, _ := c.Get("branch_id")
 
-	learning, err := h.learningService.UpdateProgress(userID.(uint), branchID.(uint), uint(courseID), &req)
+	name := strings.TrimPrefix(c.Param("name"), "/")
+	playlist, err := h.learningService.GetLessonPlaylist(userID.(uint), branchID.(uint), uint(lessonID), name)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			c.JSON(appErr.HTTPStatus(), gin.H{
@@ -108,16 +222,18 @@ func (h *LearningHandler) UpdateProgress(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, learning)
+	// 播放列表中的签名URL会过期，不允许缓存
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/vnd.apple.mpegurl", []byte(playlist))
 }
 
 // GetProgress 学生查询自己进度
 // @Summary 获取学习进度
-// @Description 获取学生学习进度，如果未报名则返回 enrolled: false
+// @Description 获取学生学习进度（按已完成课时和已提交任务重新计算），如果未报名则返回 enrolled: false
 // @Tags 学生学习
 // @Security BearerAuth
 // @Param id path int true "课程ID"
-// @Success 200 {object} models.Learning "已报名时返回学习进度"
+// @Success 200 {object} service.CourseProgress "已报名时返回学习进度"
 // @Success 200 {object} map[string]bool "未报名时返回 {\"enrolled\": false}"
 // @Router /api/v1/student/courses/{id}/progress [get]
 func (h *LearningHandler) GetProgress(c *gin.Context) {
@@ -133,7 +249,7 @@ func (h *LearningHandler) GetProgress(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	branchID, _ := c.Get("branch_id")
 
-	learning, err := h.learningService.GetStudentProgress(userID.(uint), branchID.(uint), uint(courseID))
+	progress, err := h.learningService.GetStudentProgress(userID.(uint), branchID.(uint), uint(courseID))
 	if err != nil {
 		// 如果是"未报名"错误，返回正常响应表示未报名
 		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == errors.ErrCodeNotEnrolled {
@@ -158,5 +274,5 @@ func (h *LearningHandler) GetProgress(c *gin.Context) {
 	}
 
 	// 已报名，返回学习进度
-	c.JSON(http.StatusOK, learning)
+	c.JSON(http.StatusOK, progress)
 }
@@ -0,0 +1,111 @@
+package student
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// PlaybackHandler 视频播放进度处理器
+type PlaybackHandler struct {
+	playbackService *service.PlaybackService
+}
+
+// NewPlaybackHandler 创建视频播放进度处理器
+func NewPlaybackHandler() *PlaybackHandler {
+	return &PlaybackHandler{
+		playbackService: service.NewPlaybackService(),
+	}
+}
+
+// Heartbeat 上报播放心跳
+// @Summary 上报播放心跳
+// @Description 播放器每隔几秒上报距上次心跳播放过的区间（start、end，单位秒，单次不超过 60 秒），区间按服务端记录的视频时长截断
+// @Description 已观看区间去重累计，观看比例达到配置的阈值（默认 90%）时课时自动完成，视频尚未处理出时长时不会自动完成；心跳先缓冲再批量写入
+// @Tags 学生学习
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "课时ID"
+// @Param request body service.HeartbeatRequest true "播放区间"
+// @Success 200 {object} service.PlaybackState
+// @Router /api/v1/student/lessons/{id}/heartbeat [post]
+func (h *PlaybackHandler) Heartbeat(c *gin.Context) {
+	lessonID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid lesson id",
+		})
+		return
+	}
+
+	var req service.HeartbeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	state, err := h.playbackService.Heartbeat(userID.(uint), branchID.(uint), uint(lessonID), &req)
+	if err != nil {
+		respondPlaybackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// GetPlayback 获取视频观看进度
+// @Summary 获取视频观看进度
+// @Description 返回续播位置、已观看时长和是否已完成
+// @Tags 学生学习
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "课时ID"
+// @Success 200 {object} service.PlaybackState
+// @Router /api/v1/student/lessons/{id}/playback [get]
+func (h *PlaybackHandler) GetPlayback(c *gin.Context) {
+	lessonID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid lesson id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	state, err := h.playbackService.GetPlayback(userID.(uint), branchID.(uint), uint(lessonID))
+	if err != nil {
+		respondPlaybackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+func respondPlaybackError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.HTTPStatus(), gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    errors.ErrCodeInternal,
+		"message": err.Error(),
+	})
+}
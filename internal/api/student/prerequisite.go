@@ -0,0 +1,64 @@
+package student
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// PrerequisiteHandler 先修课程处理器
+type PrerequisiteHandler struct {
+	prerequisiteService *service.PrerequisiteService
+}
+
+// NewPrerequisiteHandler 创建先修课程处理器
+func NewPrerequisiteHandler() *PrerequisiteHandler {
+	return &PrerequisiteHandler{
+		prerequisiteService: service.NewPrerequisiteService(),
+	}
+}
+
+// CheckPrerequisites 检查先修课程
+// @Summary 检查先修课程
+// @Description 返回课程的每一门先修课程及当前学生的完成情况，met 为 true 时可以报名
+// @Tags 学生学习
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Success 200 {object} service.PrerequisiteCheck
+// @Router /api/v1/student/courses/{id}/prerequisites [get]
+func (h *PrerequisiteHandler) CheckPrerequisites(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	check, err := h.prerequisiteService.CheckPrerequisites(userID.(uint), branchID.(uint), uint(courseID))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
@@ -0,0 +1,144 @@
+package student
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// QuizHandler 学生测验处理器
+type QuizHandler struct {
+	quizService *service.QuizService
+}
+
+// NewQuizHandler 创建测验处理器
+func NewQuizHandler() *QuizHandler {
+	return &QuizHandler{
+		quizService: service.NewQuizService(),
+	}
+}
+
+// GetQuiz 获取测验题目
+// @Summary 获取测验题目
+// @Description 获取测验任务的题目（不包含标准答案）
+// @Tags 学生任务
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Success 200 {array} service.QuestionInfo
+// @Router /api/v1/student/tasks/{id}/quiz [get]
+func (h *QuizHandler) GetQuiz(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	questions, err := h.quizService.GetQuiz(uint(taskID))
+	if err != nil {
+		respondQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, questions)
+}
+
+// SubmitQuiz 提交测验
+// @Summary 提交测验
+// @Description 逐题提交测验作答，自动评分后返回得分；简答题未匹配标准答案时等待教师批改。重新提交覆盖上一次的作答
+// @Tags 学生任务
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Param request body service.SubmitQuizRequest true "逐题作答"
+// @Success 200 {object} service.QuizResult
+// @Router /api/v1/student/tasks/{id}/quiz [post]
+func (h *QuizHandler) SubmitQuiz(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	var req service.SubmitQuizRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	result, err := h.quizService.SubmitQuiz(userID.(uint), branchID.(uint), uint(taskID), &req)
+	if err != nil {
+		respondQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetQuizResult 查看测验得分
+// @Summary 查看测验得分
+// @Description 获取自己在测验任务下的逐题作答和得分，如果未提交则返回 {"submitted": false}
+// @Tags 学生任务
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Success 200 {object} service.QuizResult
+// @Router /api/v1/student/tasks/{id}/quiz/result [get]
+func (h *QuizHandler) GetQuizResult(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	result, err := h.quizService.GetMyQuizResult(userID.(uint), branchID.(uint), uint(taskID))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == errors.ErrCodeAnswerNotFound {
+			c.JSON(http.StatusOK, gin.H{
+				"submitted": false,
+			})
+			return
+		}
+		respondQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func respondQuizError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.HTTPStatus(), gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    errors.ErrCodeInternal,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,64 @@
+package student
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// SearchHandler 全文检索处理器
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+// NewSearchHandler 创建全文检索处理器
+func NewSearchHandler() *SearchHandler {
+	return &SearchHandler{
+		searchService: service.NewSearchService(),
+	}
+}
+
+// Search 全文检索
+// @Summary 全文检索
+// @Description 在已发布课程的课程名称和简介、章节和课时标题、任务说明中检索，支持中英文，按相关度排序并返回高亮摘要（匹配内容用 <mark></mark> 标出）
+// @Description 查询语法与 websearch_to_tsquery 相同：空格表示同时包含，"短语" 表示短语匹配，OR 表示或，-词 表示排除
+// @Tags 学生课程
+// @Produce json
+// @Param q query string true "检索词"
+// @Param type query string false "结果类型：course、chapter、lesson、task"
+// @Param course_id query int false "只检索某门课程"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} service.SearchResult
+// @Router /api/v1/student/search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	var query service.SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.searchService.Search(&query)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,7 +1,6 @@
 package student
 
 import (
-	"io"
 	"net/http"
 	"strconv"
 
@@ -48,7 +47,7 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.taskService.GetTask(uint(taskID))
+	task, err := h.taskService.GetTask(uint(taskID), true)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			c.JSON(appErr.HTTPStatus(), gin.H{
@@ -69,7 +68,7 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 
 // ListTasksByCourse 获取课程的所有任务
 // @Summary 获取课程的所有任务
-// @Description 获取指定课程的所有任务列表
+// @Description 获取指定课程已发布课时下的任务列表；登录后截止时间合并当前学生的延期（extended 为 true）
 // @Tags 学生任务
 // @Accept json
 // @Produce json
@@ -86,7 +85,14 @@ func (h *TaskHandler) ListTasksByCourse(c *gin.Context) {
 		return
 	}
 
-	tasks, err := h.taskService.ListTasksByCourse(uint(courseID))
+	// 不需要认证，登录时按当前学生合并延期
+	var userID, branchID uint
+	if id, ok := c.Get("user_id"); ok {
+		userID = id.(uint)
+		branchID = c.MustGet("branch_id").(uint)
+	}
+
+	tasks, err := h.taskService.ListStudentTasks(uint(courseID), userID, branchID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    errors.ErrCodeInternal,
@@ -100,7 +106,7 @@ func (h *TaskHandler) ListTasksByCourse(c *gin.Context) {
 
 // SubmitAnswer 学生提交作业
 // @Summary 学生提交作业
-// @Description 学生上传作业文本或图片
+// @Description 学生提交作业文本，或关联通过上传接口直传并确认的文件；每次提交保存为一次新的尝试，达到任务的最大提交次数后不再接受提交
 // @Tags 学生任务
 // @Accept multipart/form-data
 // @Produce json
@@ -108,8 +114,8 @@ func (h *TaskHandler) ListTasksByCourse(c *gin.Context) {
 // @Param id path int true "任务ID"
 // @Param answer_content formData string false "作业文本内容"
 // @Param type formData string false "作业类型(text/image_url)，默认为text"
-// @Param file formData file false "作业图片文件"
-// @Success 200 {object} models.Answers
+// @Param upload_id formData int false "已确认的作业文件上传ID"
+// @Success 200 {object} service.AnswerDetail
 // @Router /api/v1/student/tasks/{id}/answers [post]
 func (h *TaskHandler) SubmitAnswer(c *gin.Context) {
 	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -129,31 +135,19 @@ func (h *TaskHandler) SubmitAnswer(c *gin.Context) {
 		Type:          c.PostForm("type"),
 	}
 
-	var fileBytes []byte
-	var fileName string
-	if fileHeader, err := c.FormFile("file"); err == nil && fileHeader != nil {
-		f, err := fileHeader.Open()
+	if uploadIDStr := c.PostForm("upload_id"); uploadIDStr != "" {
+		uploadID, err := strconv.ParseUint(uploadIDStr, 10, 32)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code":    errors.ErrCodeInvalidParam,
-				"message": "failed to open uploaded file",
-			})
-			return
-		}
-		defer f.Close()
-
-		fileBytes, err = io.ReadAll(f)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code":    errors.ErrCodeInternal,
-				"message": "failed to read uploaded file",
+				"message": "invalid upload id",
 			})
 			return
 		}
-		fileName = fileHeader.Filename
+		req.UploadID = uint(uploadID)
 	}
 
-	answer, err := h.answerService.SubmitAnswer(userID.(uint), branchID.(uint), uint(taskID), req, fileBytes, fileName)
+	answer, err := h.answerService.SubmitAnswer(userID.(uint), branchID.(uint), uint(taskID), req)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			c.JSON(appErr.HTTPStatus(), gin.H{
@@ -174,13 +168,13 @@ func (h *TaskHandler) SubmitAnswer(c *gin.Context) {
 
 // GetMyAnswer 学生查询自己的作业
 // @Summary 学生查询作业
-// @Description 获取当前学生在指定任务下的作业，如果未提交则返回 null
+// @Description 获取当前学生在指定任务下计入成绩的一次提交，包含评分项得分、评语和行内批注，如果未提交则返回 {"submitted": false}
 // @Tags 学生任务
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "任务ID"
-// @Success 200 {object} models.Answers "已提交时返回作业信息"
+// @Success 200 {object} service.AnswerDetail "已提交时返回作业信息"
 // @Success 200 {object} map[string]interface{} "未提交时返回 {\"submitted\": false}"
 // @Router /api/v1/student/tasks/{id}/answers [get]
 func (h *TaskHandler) GetMyAnswer(c *gin.Context) {
@@ -222,3 +216,123 @@ func (h *TaskHandler) GetMyAnswer(c *gin.Context) {
 
 	c.JSON(http.StatusOK, answer)
 }
+
+// GetMyDeadline 学生查看自己的截止时间
+// @Summary 学生查看截止时间
+// @Description 获取当前学生在任务上的截止时间（已合并教师设置的延期），以及现在提交是否迟交或已不再接受提交
+// @Tags 学生任务
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Success 200 {object} service.TaskDeadline
+// @Router /api/v1/student/tasks/{id}/deadline [get]
+func (h *TaskHandler) GetMyDeadline(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	deadline, err := h.taskService.GetMyDeadline(userID.(uint), branchID.(uint), uint(taskID))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, deadline)
+}
+
+// ListMyAttempts 学生查看自己的提交记录
+// @Summary 学生查看提交记录
+// @Description 获取当前学生在任务上的全部提交、剩余提交次数和计分策略，is_counted 标记计入成绩的一次
+// @Tags 学生任务
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Success 200 {object} service.AttemptHistory
+// @Router /api/v1/student/tasks/{id}/attempts [get]
+func (h *TaskHandler) ListMyAttempts(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	history, err := h.answerService.ListMyAttempts(userID.(uint), branchID.(uint), uint(taskID))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetRubric 学生查看任务的评分标准
+// @Summary 学生查看评分标准
+// @Description 获取任务的评分项和各等级的分数，任务没有评分标准时 criteria 为空
+// @Tags 学生任务
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Success 200 {object} service.RubricInfo
+// @Router /api/v1/student/tasks/{id}/rubric [get]
+func (h *TaskHandler) GetRubric(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	rubric, err := h.taskService.GetTaskRubric(uint(taskID))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, rubric)
+}
@@ -0,0 +1,158 @@
+package student
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// UploadHandler 作业文件直传处理器
+type UploadHandler struct {
+	uploadService *service.UploadService
+}
+
+// NewUploadHandler 创建作业文件直传处理器
+func NewUploadHandler() *UploadHandler {
+	return &UploadHandler{
+		uploadService: service.NewUploadService(),
+	}
+}
+
+// CreateUpload 申请作业文件的上传位置
+// @Summary 申请作业文件上传
+// @Description 声明文件名、类型、大小和 SHA-256，返回直传对象存储的签名地址；大文件按 part_size 分片上传
+// @Description 上传完成后调用确认接口校验文件，再将 upload_id 传给提交作业接口
+// @Tags 学生任务
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "任务ID"
+// @Param request body service.CreateUploadRequest true "文件信息"
+// @Success 200 {object} service.UploadSlot
+// @Router /api/v1/student/tasks/{id}/uploads [post]
+func (h *UploadHandler) CreateUpload(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	var req service.CreateUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	slot, err := h.uploadService.CreateAnswerUpload(uint(taskID), userID.(uint), branchID.(uint), &req)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, slot)
+}
+
+// GetUpload 查询上传进度
+// @Summary 查询作业文件上传
+// @Description 返回已上传的分片和尚未上传分片的新签名地址，用于断点续传
+// @Tags 学生任务
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "上传ID"
+// @Success 200 {object} service.UploadSlot
+// @Router /api/v1/student/uploads/{id} [get]
+func (h *UploadHandler) GetUpload(c *gin.Context) {
+	h.handleUpload(c, h.uploadService.GetUpload)
+}
+
+// CompleteUpload 确认上传完成
+// @Summary 确认作业文件上传
+// @Description 合并分片并校验文件大小、类型和 SHA-256，校验失败时文件被删除，需要重新申请
+// @Tags 学生任务
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "上传ID"
+// @Success 200 {object} service.UploadSlot
+// @Router /api/v1/student/uploads/{id}/complete [post]
+func (h *UploadHandler) CompleteUpload(c *gin.Context) {
+	h.handleUpload(c, h.uploadService.CompleteUpload)
+}
+
+// AbortUpload 取消上传
+// @Summary 取消作业文件上传
+// @Description 删除已上传的分片或文件，已提交的作业文件不能取消
+// @Tags 学生任务
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "上传ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/student/uploads/{id} [delete]
+func (h *UploadHandler) AbortUpload(c *gin.Context) {
+	uploadID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid upload id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	if err := h.uploadService.AbortUpload(service.UploadPurposeAnswer, uint(uploadID), userID.(uint), branchID.(uint)); err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "上传已取消"})
+}
+
+func (h *UploadHandler) handleUpload(c *gin.Context, fn func(purpose string, uploadID, userID, branchID uint) (*service.UploadSlot, error)) {
+	uploadID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid upload id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	slot, err := fn(service.UploadPurposeAnswer, uint(uploadID), userID.(uint), branchID.(uint))
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, slot)
+}
+
+func respondUploadError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.HTTPStatus(), gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    errors.ErrCodeInternal,
+		"message": err.Error(),
+	})
+}
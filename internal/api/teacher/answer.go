@@ -0,0 +1,219 @@
+package teacher
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// AnswerHandler 教师作业批改处理器
+type AnswerHandler struct {
+	answerService *service.AnswerService
+}
+
+// NewAnswerHandler 创建作业批改处理器
+func NewAnswerHandler() *AnswerHandler {
+	return &AnswerHandler{
+		answerService: service.NewAnswerService(),
+	}
+}
+
+// ListAnswers 查看任务的所有作业
+// @Summary 查看作业
+// @Description 教学团队查看任务下所有分支的作业
+// @Tags 教师任务管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Success 200 {array} service.AnswerWithStudentInfo
+// @Router /api/v1/teacher/tasks/{id}/answers [get]
+func (h *AnswerHandler) ListAnswers(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	answers, err := h.answerService.ListAnswersForTask(instructorID.(uint), branchID.(uint), uint(taskID))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, answers)
+}
+
+// GradeAnswer 教师评分
+// @Summary 教师评分
+// @Description 根据 answer_id 和作业所在的 branch_id 评分（测验作业请逐题调整得分）。分数不能超出任务满分；任务设置了评分标准时逐项评分，总分为各评分项得分之和。可以附带评语，文本作业可以添加行内批注
+// @Tags 教师任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "作业ID"
+// @Param request body service.GradeAnswerRequest true "评分信息"
+// @Success 200 {object} service.AnswerDetail
+// @Router /api/v1/teacher/answers/{id}/grade [put]
+func (h *AnswerHandler) GradeAnswer(c *gin.Context) {
+	answerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid answer id",
+		})
+		return
+	}
+
+	var req service.GradeAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	answer, err := h.answerService.GradeAnswer(instructorID.(uint), branchID.(uint), uint(answerID), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, answer)
+}
+
+// ListAttempts 查看学生的全部提交
+// @Summary 查看提交记录
+// @Description 教学团队查看该作业所属学生在同一任务上的全部提交，is_counted 标记计入成绩的一次
+// @Tags 教师任务管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "作业ID"
+// @Param branch_id query int true "作业所在的分支ID"
+// @Success 200 {object} service.AttemptHistory
+// @Router /api/v1/teacher/answers/{id}/attempts [get]
+func (h *AnswerHandler) ListAttempts(c *gin.Context) {
+	answerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid answer id",
+		})
+		return
+	}
+	answerBranchID, err := strconv.ParseUint(c.Query("branch_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid branch id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	history, err := h.answerService.ListAttempts(instructorID.(uint), branchID.(uint), uint(answerID), uint(answerBranchID))
+	if err != nil {
+		respondAnswerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// CompareAttempts 对比学生的两次提交
+// @Summary 对比两次提交
+// @Description 对比同一学生在同一任务上的两次提交，按提交顺序列出字段变化；测验作业同时列出作答或得分不同的题目
+// @Tags 教师任务管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "作业ID"
+// @Param with query int true "要对比的另一次提交的作业ID"
+// @Param branch_id query int true "作业所在的分支ID"
+// @Success 200 {object} service.AttemptComparison
+// @Router /api/v1/teacher/answers/{id}/compare [get]
+func (h *AnswerHandler) CompareAttempts(c *gin.Context) {
+	answerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid answer id",
+		})
+		return
+	}
+	otherAnswerID, err := strconv.ParseUint(c.Query("with"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid answer id to compare with",
+		})
+		return
+	}
+	answerBranchID, err := strconv.ParseUint(c.Query("branch_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid branch id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	comparison, err := h.answerService.CompareAttempts(instructorID.(uint), branchID.(uint), uint(answerID), uint(otherAnswerID), uint(answerBranchID))
+	if err != nil {
+		respondAnswerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+func respondAnswerError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.HTTPStatus(), gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    errors.ErrCodeInternal,
+		"message": err.Error(),
+	})
+}
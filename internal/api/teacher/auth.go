@@ -57,8 +57,8 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// 验证是否为教师角色
-	if resp.Role != "teacher" {
+	// 验证是否为教师角色（管理员也从教师端登录）
+	if resp.Role != "teacher" && resp.Role != service.UserRoleAdmin {
 		c.JSON(http.StatusForbidden, gin.H{
 			"code":    errors.ErrCodeForbidden,
 			"message": "Only teachers can login here",
@@ -89,7 +89,8 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	userInfo, err := h.userService.GetUserInfo(userID.(uint))
+	branchID, _ := c.Get("branch_id")
+	userInfo, err := h.userService.GetUserInfo(userID.(uint), branchID.(uint))
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			c.JSON(appErr.HTTPStatus(), gin.H{
@@ -0,0 +1,115 @@
+package teacher
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/cartridge"
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/logger"
+)
+
+// ExportCourse 导出课程包
+// @Summary 导出课程包
+// @Description 将课程的章节、课时、任务及OSS中的课时内容导出为 IMS Common Cartridge (.imscc) 包
+// @Tags 教师课程管理
+// @Produce application/zip
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Success 200 {file} file
+// @Router /api/v1/teacher/courses/{id}/export [get]
+func (h *CourseHandler) ExportCourse(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	pkg, err := h.courseService.ExportCourseCartridge(uint(courseID), instructorID.(uint), branchID.(uint))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=course_%d.imscc", courseID))
+	c.Status(http.StatusOK)
+
+	// 响应已经开始写出，出错时只能中断并记录日志
+	if err := cartridge.Write(c.Writer, pkg); err != nil {
+		logger.Errorf("export course %d: %v", courseID, err)
+		c.Abort()
+	}
+}
+
+// ImportCourse 导入课程包
+// @Summary 导入课程包
+// @Description 从 IMS Common Cartridge 包创建新课程（草稿），返回导入统计和未能导入的条目
+// @Tags 教师课程管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "课程包 (.imscc / .zip)"
+// @Param course_title formData string false "课程标题，默认使用包内标题"
+// @Success 200 {object} service.CartridgeImportResult
+// @Router /api/v1/teacher/courses/import [post]
+func (h *CourseHandler) ImportCourse(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "file is required",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "failed to read uploaded file",
+		})
+		return
+	}
+	defer file.Close()
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	result, err := h.courseService.ImportCourseCartridge(instructorID.(uint), branchID.(uint), file, fileHeader.Size, c.PostForm("course_title"))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,176 @@
+package teacher
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// CatalogHandler 课程目录与分类管理处理器
+type CatalogHandler struct {
+	catalogService *service.CatalogService
+}
+
+// NewCatalogHandler 创建课程目录处理器
+func NewCatalogHandler() *CatalogHandler {
+	return &CatalogHandler{
+		catalogService: service.NewCatalogService(),
+	}
+}
+
+// SearchCatalog 课程目录
+// @Summary 课程目录
+// @Description 按分类、标签、状态、开课日期和教师筛选已发布课程（包括已归档课程），支持按最新或报名人数排序，返回分面统计
+// @Tags 教师课程管理
+// @Produce json
+// @Security BearerAuth
+// @Param category_id query int false "分类ID"
+// @Param tag query []string false "标签，可重复，课程需要包含所有标签" collectionFormat(multi)
+// @Param status query string false "课程状态：active、archived"
+// @Param instructor_id query int false "教师ID"
+// @Param start_from query string false "开课日期下限"
+// @Param start_to query string false "开课日期上限"
+// @Param sort query string false "排序：newest（默认）、popular"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} service.CatalogResult
+// @Router /api/v1/teacher/catalog [get]
+func (h *CatalogHandler) SearchCatalog(c *gin.Context) {
+	var query service.CatalogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+	query.PublishedOnly = true
+
+	result, err := h.catalogService.SearchCatalog(&query)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateCategory 创建课程分类
+// @Summary 创建课程分类
+// @Description 仅管理员
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.CategoryRequest true "分类信息"
+// @Success 200 {object} models.Categories
+// @Router /api/v1/teacher/categories [post]
+func (h *CatalogHandler) CreateCategory(c *gin.Context) {
+	var req service.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	role, _ := c.Get("role")
+
+	category, err := h.catalogService.CreateCategory(role.(string), &req)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// UpdateCategory 修改课程分类
+// @Summary 修改课程分类
+// @Description 仅管理员
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "分类ID"
+// @Param request body service.CategoryRequest true "分类信息"
+// @Success 200 {object} models.Categories
+// @Router /api/v1/teacher/categories/{id} [put]
+func (h *CatalogHandler) UpdateCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid category id",
+		})
+		return
+	}
+
+	var req service.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	role, _ := c.Get("role")
+
+	category, err := h.catalogService.UpdateCategory(uint(categoryID), role.(string), &req)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory 删除课程分类
+// @Summary 删除课程分类
+// @Description 仅管理员，原属该分类的课程变为未分类
+// @Tags 教师课程管理
+// @Security BearerAuth
+// @Param id path int true "分类ID"
+// @Success 200 {object} map[string]bool
+// @Router /api/v1/teacher/categories/{id} [delete]
+func (h *CatalogHandler) DeleteCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid category id",
+		})
+		return
+	}
+
+	role, _ := c.Get("role")
+
+	if err := h.catalogService.DeleteCategory(uint(categoryID), role.(string)); err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": true,
+	})
+}
+
+func respondCatalogError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.HTTPStatus(), gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    errors.ErrCodeInternal,
+		"message": err.Error(),
+	})
+}
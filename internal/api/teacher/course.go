@@ -45,7 +45,8 @@ func (h *CourseHandler) CreateCourse(c *gin.Context) {
 		return
 	}
 
-	course, err := h.courseService.CreateCourse(instructorID.(uint), &req)
+	branchID, _ := c.Get("branch_id")
+	course, err := h.courseService.CreateCourse(instructorID.(uint), branchID.(uint), &req)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			c.JSON(appErr.HTTPStatus(), gin.H{
@@ -88,9 +89,16 @@ func (h *CourseHandler) ListCourses(c *gin.Context) {
 		pageSize = 10
 	}
 
-	instructorIDUint := instructorID.(uint)
-	courses, total, err := h.courseService.ListCourses(&instructorIDUint, page, pageSize)
+	branchID, _ := c.Get("branch_id")
+	courses, total, err := h.courseService.ListInstructorCourses(instructorID.(uint), branchID.(uint), page, pageSize)
 	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    errors.ErrCodeInternal,
 			"message": err.Error(),
@@ -108,7 +116,7 @@ func (h *CourseHandler) ListCourses(c *gin.Context) {
 
 // GetCourse 获取课程详情
 // @Summary 获取课程详情
-// @Description 获取课程详细信息，包含章节和课程
+// @Description 获取课程详细信息，包含章节和课程；教学团队成员和管理员得到的课时内容地址为有效期一小时的签名URL
 // @Tags 教师课程管理
 // @Accept json
 // @Produce json
@@ -126,7 +134,12 @@ func (h *CourseHandler) GetCourse(c *gin.Context) {
 		return
 	}
 
-	course, err := h.courseService.GetCourse(uint(courseID), true)
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+	role, _ := c.Get("role")
+
+	// 教师预览：返回包含草稿在内的全部内容，教学团队成员可以获得课时内容的签名URL
+	course, err := h.courseService.GetCourseForTeacher(uint(courseID), userID.(uint), branchID.(uint), role.(string))
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			c.JSON(appErr.HTTPStatus(), gin.H{
@@ -177,7 +190,8 @@ func (h *CourseHandler) CreateChapter(c *gin.Context) {
 		return
 	}
 
-	chapter, err := h.courseService.CreateChapter(uint(courseID), instructorID.(uint), &req)
+	branchID, _ := c.Get("branch_id")
+	chapter, err := h.courseService.CreateChapter(uint(courseID), instructorID.(uint), branchID.(uint), &req)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			c.JSON(appErr.HTTPStatus(), gin.H{
@@ -196,9 +210,9 @@ func (h *CourseHandler) CreateChapter(c *gin.Context) {
 	c.JSON(http.StatusOK, chapter)
 }
 
-// CreateLesson 创建课程（上传视频）
+// CreateLesson 创建课程（关联上传的视频）
 // @Summary 创建课程
-// @Description 为章节创建新课程，可以上传视频文件
+// @Description 为章节创建新课程，视频文件先通过上传接口直传并确认，再传入 upload_id
 // @Tags 教师课程管理
 // @Accept multipart/form-data
 // @Produce json
@@ -208,7 +222,8 @@ func (h *CourseHandler) CreateChapter(c *gin.Context) {
 // @Param lesson_title formData string true "课程标题"
 // @Param lesson_type formData string false "课程类型" default(video)
 // @Param lesson_order formData int false "课程顺序"
-// @Param video_file formData file false "视频文件"
+// @Param content_url formData string false "外部内容链接"
+// @Param upload_id formData int false "已确认的课时文件上传ID"
 // @Success 200 {object} models.Lessons
 // @Router /api/v1/teacher/courses/:id/chapters/:chapter_id/lessons [post]
 func (h *CourseHandler) CreateLesson(c *gin.Context) {
@@ -231,11 +246,13 @@ func (h *CourseHandler) CreateLesson(c *gin.Context) {
 	}
 
 	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
 
 	// 从form-data获取数据
 	req := service.CreateLessonRequest{
 		LessonTitle: c.PostForm("lesson_title"),
 		LessonType:  c.PostForm("lesson_type"),
+		ContentURL:  c.PostForm("content_url"),
 	}
 
 	if orderStr := c.PostForm("lesson_order"); orderStr != "" {
@@ -252,21 +269,400 @@ func (h *CourseHandler) CreateLesson(c *gin.Context) {
 		return
 	}
 
-	// 处理文件上传
-	var videoFile []byte
-	var fileName string
-	file, err := c.FormFile("video_file")
-	if err == nil && file != nil {
-		f, err := file.Open()
-		if err == nil {
-			defer f.Close()
-			videoFile = make([]byte, file.Size)
-			f.Read(videoFile)
-			fileName = file.Filename
+	uploadID, ok := parseUploadID(c)
+	if !ok {
+		return
+	}
+	req.UploadID = uploadID
+
+	lesson, err := h.courseService.CreateLesson(uint(courseID), uint(chapterID), instructorID.(uint), branchID.(uint), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, lesson)
+}
+
+// UpdateCourse 更新课程
+// @Summary 更新课程
+// @Description 教师更新课程信息，未传入的字段保持不变
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param request body service.UpdateCourseRequest true "课程信息"
+// @Success 200 {object} models.Courses
+// @Router /api/v1/teacher/courses/{id} [put]
+func (h *CourseHandler) UpdateCourse(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	var req service.UpdateCourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	course, err := h.courseService.UpdateCourse(uint(courseID), instructorID.(uint), branchID.(uint), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
 	}
 
-	lesson, err := h.courseService.CreateLesson(uint(courseID), uint(chapterID), instructorID.(uint), &req, videoFile, fileName)
+	c.JSON(http.StatusOK, course)
+}
+
+// ArchiveCourse 归档课程
+// @Summary 归档课程
+// @Description 将课程状态改为 archived，学生目录中不再展示，已有学习记录保留
+// @Tags 教师课程管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Success 200 {object} models.Courses
+// @Router /api/v1/teacher/courses/{id}/archive [post]
+func (h *CourseHandler) ArchiveCourse(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	course, err := h.courseService.ArchiveCourse(uint(courseID), instructorID.(uint), branchID.(uint))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// CloneCourse 复制课程
+// @Summary 复制课程
+// @Description 将课程连同章节、课时、任务复制为新课程（草稿），可指定新学期开始日期，并选择复制或共享OSS内容
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param request body service.CloneCourseRequest false "复制选项"
+// @Success 200 {object} service.CourseInfo
+// @Router /api/v1/teacher/courses/{id}/clone [post]
+func (h *CourseHandler) CloneCourse(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	var req service.CloneCourseRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    errors.ErrCodeInvalidParam,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	course, err := h.courseService.CloneCourse(uint(courseID), instructorID.(uint), branchID.(uint), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// DeleteCourse 删除课程
+// @Summary 删除课程
+// @Description 软删除课程及其所有章节、课时和任务
+// @Tags 教师课程管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/teacher/courses/{id} [delete]
+func (h *CourseHandler) DeleteCourse(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	if err := h.courseService.DeleteCourse(uint(courseID), instructorID.(uint), branchID.(uint)); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": true,
+	})
+}
+
+// UpdateChapter 更新章节
+// @Summary 更新章节
+// @Description 更新章节标题和描述
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param chapter_id path int true "章节ID"
+// @Param request body service.UpdateChapterRequest true "章节信息"
+// @Success 200 {object} models.Chapters
+// @Router /api/v1/teacher/courses/{id}/chapters/{chapter_id} [put]
+func (h *CourseHandler) UpdateChapter(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	chapterID, err := strconv.ParseUint(c.Param("chapter_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid chapter id",
+		})
+		return
+	}
+
+	var req service.UpdateChapterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	chapter, err := h.courseService.UpdateChapter(uint(courseID), uint(chapterID), instructorID.(uint), branchID.(uint), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, chapter)
+}
+
+// DeleteChapter 删除章节
+// @Summary 删除章节
+// @Description 软删除章节及其所有课时和任务
+// @Tags 教师课程管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param chapter_id path int true "章节ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/teacher/courses/{id}/chapters/{chapter_id} [delete]
+func (h *CourseHandler) DeleteChapter(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	chapterID, err := strconv.ParseUint(c.Param("chapter_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid chapter id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	if err := h.courseService.DeleteChapter(uint(courseID), uint(chapterID), instructorID.(uint), branchID.(uint)); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": true,
+	})
+}
+
+// UpdateLesson 更新课时
+// @Summary 更新课时
+// @Description 更新课时信息，传入 upload_id 时用新上传的文件替换原内容
+// @Tags 教师课程管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param chapter_id path int true "章节ID"
+// @Param lesson_id path int true "课时ID"
+// @Param lesson_title formData string false "课时标题"
+// @Param lesson_type formData string false "课时类型"
+// @Param content_url formData string false "内容链接"
+// @Param upload_id formData int false "已确认的课时文件上传ID"
+// @Success 200 {object} models.Lessons
+// @Router /api/v1/teacher/courses/{id}/chapters/{chapter_id}/lessons/{lesson_id} [put]
+func (h *CourseHandler) UpdateLesson(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	chapterID, err := strconv.ParseUint(c.Param("chapter_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid chapter id",
+		})
+		return
+	}
+
+	lessonID, err := strconv.ParseUint(c.Param("lesson_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid lesson id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	// 从form-data获取数据，未传入的字段保持不变
+	var req service.UpdateLessonRequest
+	if title, ok := c.GetPostForm("lesson_title"); ok {
+		req.LessonTitle = &title
+	}
+	if lessonType, ok := c.GetPostForm("lesson_type"); ok {
+		req.LessonType = &lessonType
+	}
+	if contentURL, ok := c.GetPostForm("content_url"); ok {
+		req.ContentURL = &contentURL
+	}
+
+	uploadID, ok := parseUploadID(c)
+	if !ok {
+		return
+	}
+	req.UploadID = uploadID
+
+	lesson, err := h.courseService.UpdateLesson(uint(courseID), uint(chapterID), uint(lessonID), instructorID.(uint), branchID.(uint), &req)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			c.JSON(appErr.HTTPStatus(), gin.H{
@@ -285,3 +681,178 @@ func (h *CourseHandler) CreateLesson(c *gin.Context) {
 	c.JSON(http.StatusOK, lesson)
 }
 
+// DeleteLesson 删除课时
+// @Summary 删除课时
+// @Description 软删除课时及其所有任务
+// @Tags 教师课程管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param chapter_id path int true "章节ID"
+// @Param lesson_id path int true "课时ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/teacher/courses/{id}/chapters/{chapter_id}/lessons/{lesson_id} [delete]
+func (h *CourseHandler) DeleteLesson(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	chapterID, err := strconv.ParseUint(c.Param("chapter_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid chapter id",
+		})
+		return
+	}
+
+	lessonID, err := strconv.ParseUint(c.Param("lesson_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid lesson id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	if err := h.courseService.DeleteLesson(uint(courseID), uint(chapterID), uint(lessonID), instructorID.(uint), branchID.(uint)); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": true,
+	})
+}
+
+// ReorderChapters 调整章节顺序
+// @Summary 调整章节顺序
+// @Description 按新顺序提交课程的章节ID，未列出的章节按原顺序排在后面，顺序号会被规范化为连续的 1..n
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param request body service.ReorderChaptersRequest true "章节顺序"
+// @Success 200 {object} service.CourseInfo
+// @Router /api/v1/teacher/courses/{id}/chapters/order [put]
+func (h *CourseHandler) ReorderChapters(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	var req service.ReorderChaptersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	course, err := h.courseService.ReorderChapters(uint(courseID), instructorID.(uint), branchID.(uint), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// ReorderLessons 调整课时顺序
+// @Summary 调整课时顺序
+// @Description 按新顺序提交章节的课时ID，可包含同一课程其他章节的课时以将其移入该章节
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param chapter_id path int true "章节ID"
+// @Param request body service.ReorderLessonsRequest true "课时顺序"
+// @Success 200 {object} service.CourseInfo
+// @Router /api/v1/teacher/courses/{id}/chapters/{chapter_id}/lessons/order [put]
+func (h *CourseHandler) ReorderLessons(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	chapterID, err := strconv.ParseUint(c.Param("chapter_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid chapter id",
+		})
+		return
+	}
+
+	var req service.ReorderLessonsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	course, err := h.courseService.ReorderLessons(uint(courseID), uint(chapterID), instructorID.(uint), branchID.(uint), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
@@ -0,0 +1,148 @@
+package teacher
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// ExtensionHandler 作业延期管理处理器
+type ExtensionHandler struct {
+	taskService *service.TaskService
+}
+
+// NewExtensionHandler 创建作业延期处理器
+func NewExtensionHandler() *ExtensionHandler {
+	return &ExtensionHandler{
+		taskService: service.NewTaskService(),
+	}
+}
+
+// ListExtensions 获取任务的作业延期
+// @Summary 获取作业延期列表
+// @Tags 教师任务管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Success 200 {array} models.TaskExtensions
+// @Router /api/v1/teacher/tasks/{id}/extensions [get]
+func (h *ExtensionHandler) ListExtensions(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	extensions, err := h.taskService.ListExtensions(uint(taskID), userID.(uint), branchID.(uint))
+	if err != nil {
+		respondExtensionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, extensions)
+}
+
+// GrantExtension 为学生延长截止时间
+// @Summary 为学生延长截止时间
+// @Description 课程教师为指定分支的学生设置新的截止时间或最终截止时间，重复设置时覆盖原延期，已提交的作业不受影响
+// @Tags 教师任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Param request body service.GrantExtensionRequest true "延期信息"
+// @Success 200 {object} models.TaskExtensions
+// @Router /api/v1/teacher/tasks/{id}/extensions [post]
+func (h *ExtensionHandler) GrantExtension(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	var req service.GrantExtensionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	extension, err := h.taskService.GrantExtension(uint(taskID), userID.(uint), branchID.(uint), &req)
+	if err != nil {
+		respondExtensionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, extension)
+}
+
+// RevokeExtension 撤销作业延期
+// @Summary 撤销作业延期
+// @Tags 教师任务管理
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Param extension_id path int true "延期ID"
+// @Success 200 {object} map[string]bool
+// @Router /api/v1/teacher/tasks/{id}/extensions/{extension_id} [delete]
+func (h *ExtensionHandler) RevokeExtension(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+	extensionID, err := strconv.ParseUint(c.Param("extension_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid extension id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	if err := h.taskService.RevokeExtension(uint(taskID), uint(extensionID), userID.(uint), branchID.(uint)); err != nil {
+		respondExtensionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": true,
+	})
+}
+
+func respondExtensionError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.HTTPStatus(), gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    errors.ErrCodeInternal,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,89 @@
+package teacher
+
+import (
+	"archive/zip"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// SyncCourse 从 Markdown 目录同步课程内容
+// @Summary 从 Markdown 目录同步课程内容
+// @Description 上传 Markdown 课程目录的 zip 包，按 slug 创建或更新章节、课时和任务；默认只返回同步计划，apply=true 时执行
+// @Tags 教师课程管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param file formData file true "Markdown 课程目录 (.zip)"
+// @Param apply formData bool false "是否执行同步，默认只生成计划"
+// @Param prune formData bool false "是否删除目录中已不存在的内容"
+// @Success 200 {object} service.SyncPlan
+// @Router /api/v1/teacher/courses/{id}/sync [post]
+func (h *CourseHandler) SyncCourse(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "file is required",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "failed to read uploaded file",
+		})
+		return
+	}
+	defer file.Close()
+
+	archive, err := zip.NewReader(file, fileHeader.Size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidPackage,
+			"message": "file is not a valid zip archive",
+		})
+		return
+	}
+
+	opts := service.MarkdownSyncOptions{}
+	opts.Apply, _ = strconv.ParseBool(c.PostForm("apply"))
+	opts.Prune, _ = strconv.ParseBool(c.PostForm("prune"))
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	plan, err := h.courseService.SyncCourseFromMarkdown(uint(courseID), instructorID.(uint), branchID.(uint), archive, opts)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
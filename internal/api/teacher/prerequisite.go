@@ -0,0 +1,223 @@
+package teacher
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// PrerequisiteHandler 先修课程与豁免管理处理器
+type PrerequisiteHandler struct {
+	prerequisiteService *service.PrerequisiteService
+}
+
+// NewPrerequisiteHandler 创建先修课程处理器
+func NewPrerequisiteHandler() *PrerequisiteHandler {
+	return &PrerequisiteHandler{
+		prerequisiteService: service.NewPrerequisiteService(),
+	}
+}
+
+// ListPrerequisites 获取课程的先修课程
+// @Summary 获取课程的先修课程
+// @Tags 教师课程管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Success 200 {array} service.PrerequisiteInfo
+// @Router /api/v1/teacher/courses/{id}/prerequisites [get]
+func (h *PrerequisiteHandler) ListPrerequisites(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	prerequisites, err := h.prerequisiteService.ListPrerequisites(uint(courseID), instructorID.(uint), branchID.(uint))
+	if err != nil {
+		respondPrerequisiteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prerequisites)
+}
+
+// SetPrerequisites 设置课程的先修课程
+// @Summary 设置课程的先修课程
+// @Description 用请求中的列表覆盖课程的全部先修课程，min_status 默认为 completed，min_score 为作业得分率下限
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param request body service.SetPrerequisitesRequest true "先修课程列表"
+// @Success 200 {array} service.PrerequisiteInfo
+// @Router /api/v1/teacher/courses/{id}/prerequisites [put]
+func (h *PrerequisiteHandler) SetPrerequisites(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	var req service.SetPrerequisitesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	prerequisites, err := h.prerequisiteService.SetPrerequisites(uint(courseID), instructorID.(uint), branchID.(uint), &req)
+	if err != nil {
+		respondPrerequisiteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prerequisites)
+}
+
+// ListOverrides 获取先修豁免列表
+// @Summary 获取先修豁免列表
+// @Tags 教师课程管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Success 200 {array} models.PrerequisiteOverrides
+// @Router /api/v1/teacher/courses/{id}/prerequisite-overrides [get]
+func (h *PrerequisiteHandler) ListOverrides(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+	role, _ := c.Get("role")
+
+	overrides, err := h.prerequisiteService.ListOverrides(uint(courseID), userID.(uint), branchID.(uint), role.(string))
+	if err != nil {
+		respondPrerequisiteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, overrides)
+}
+
+// GrantOverride 豁免学生的先修要求
+// @Summary 豁免学生的先修要求
+// @Description 课程教师或管理员为指定分支的学生豁免先修要求，豁免后学生可以直接报名
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param request body service.GrantOverrideRequest true "学生信息"
+// @Success 200 {object} models.PrerequisiteOverrides
+// @Router /api/v1/teacher/courses/{id}/prerequisite-overrides [post]
+func (h *PrerequisiteHandler) GrantOverride(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	var req service.GrantOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+	role, _ := c.Get("role")
+
+	override, err := h.prerequisiteService.GrantOverride(uint(courseID), userID.(uint), branchID.(uint), role.(string), &req)
+	if err != nil {
+		respondPrerequisiteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, override)
+}
+
+// RevokeOverride 撤销先修豁免
+// @Summary 撤销先修豁免
+// @Tags 教师课程管理
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param override_id path int true "豁免ID"
+// @Success 200 {object} map[string]bool
+// @Router /api/v1/teacher/courses/{id}/prerequisite-overrides/{override_id} [delete]
+func (h *PrerequisiteHandler) RevokeOverride(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+	overrideID, err := strconv.ParseUint(c.Param("override_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid override id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+	role, _ := c.Get("role")
+
+	if err := h.prerequisiteService.RevokeOverride(uint(courseID), uint(overrideID), userID.(uint), branchID.(uint), role.(string)); err != nil {
+		respondPrerequisiteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": true,
+	})
+}
+
+func respondPrerequisiteError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.HTTPStatus(), gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    errors.ErrCodeInternal,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,356 @@
+package teacher
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// PublishCourse 发布课程
+// @Summary 发布课程
+// @Description 立即发布课程，或传入 publish_at 设置定时发布；学生只能看到已发布的内容
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param request body service.PublishRequest false "发布时间"
+// @Success 200 {object} service.CourseInfo
+// @Router /api/v1/teacher/courses/{id}/publish [post]
+func (h *CourseHandler) PublishCourse(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	var req service.PublishRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    errors.ErrCodeInvalidParam,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	course, err := h.courseService.PublishCourse(uint(courseID), instructorID.(uint), branchID.(uint), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// UnpublishCourse 撤回课程
+// @Summary 撤回课程
+// @Description 将课程撤回为草稿，并清除定时发布时间
+// @Tags 教师课程管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Success 200 {object} service.CourseInfo
+// @Router /api/v1/teacher/courses/{id}/unpublish [post]
+func (h *CourseHandler) UnpublishCourse(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	course, err := h.courseService.UnpublishCourse(uint(courseID), instructorID.(uint), branchID.(uint))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// PublishChapter 发布章节
+// @Summary 发布章节
+// @Description 立即发布章节，或传入 publish_at 设置定时发布；学生只能看到已发布的内容
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param chapter_id path int true "章节ID"
+// @Param request body service.PublishRequest false "发布时间"
+// @Success 200 {object} service.CourseInfo
+// @Router /api/v1/teacher/courses/{id}/chapters/{chapter_id}/publish [post]
+func (h *CourseHandler) PublishChapter(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	chapterID, err := strconv.ParseUint(c.Param("chapter_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid chapter id",
+		})
+		return
+	}
+
+	var req service.PublishRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    errors.ErrCodeInvalidParam,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	course, err := h.courseService.PublishChapter(uint(courseID), uint(chapterID), instructorID.(uint), branchID.(uint), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// UnpublishChapter 撤回章节
+// @Summary 撤回章节
+// @Description 将章节撤回为草稿，并清除定时发布时间
+// @Tags 教师课程管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param chapter_id path int true "章节ID"
+// @Success 200 {object} service.CourseInfo
+// @Router /api/v1/teacher/courses/{id}/chapters/{chapter_id}/unpublish [post]
+func (h *CourseHandler) UnpublishChapter(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	chapterID, err := strconv.ParseUint(c.Param("chapter_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid chapter id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	course, err := h.courseService.UnpublishChapter(uint(courseID), uint(chapterID), instructorID.(uint), branchID.(uint))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// PublishLesson 发布课时
+// @Summary 发布课时
+// @Description 立即发布课时，或传入 publish_at 设置定时发布；学生只能看到已发布的内容
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param chapter_id path int true "章节ID"
+// @Param lesson_id path int true "课时ID"
+// @Param request body service.PublishRequest false "发布时间"
+// @Success 200 {object} service.CourseInfo
+// @Router /api/v1/teacher/courses/{id}/chapters/{chapter_id}/lessons/{lesson_id}/publish [post]
+func (h *CourseHandler) PublishLesson(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	chapterID, err := strconv.ParseUint(c.Param("chapter_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid chapter id",
+		})
+		return
+	}
+
+	lessonID, err := strconv.ParseUint(c.Param("lesson_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid lesson id",
+		})
+		return
+	}
+
+	var req service.PublishRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    errors.ErrCodeInvalidParam,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	course, err := h.courseService.PublishLesson(uint(courseID), uint(chapterID), uint(lessonID), instructorID.(uint), branchID.(uint), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// UnpublishLesson 撤回课时
+// @Summary 撤回课时
+// @Description 将课时撤回为草稿，并清除定时发布时间
+// @Tags 教师课程管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param chapter_id path int true "章节ID"
+// @Param lesson_id path int true "课时ID"
+// @Success 200 {object} service.CourseInfo
+// @Router /api/v1/teacher/courses/{id}/chapters/{chapter_id}/lessons/{lesson_id}/unpublish [post]
+func (h *CourseHandler) UnpublishLesson(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	chapterID, err := strconv.ParseUint(c.Param("chapter_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid chapter id",
+		})
+		return
+	}
+
+	lessonID, err := strconv.ParseUint(c.Param("lesson_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid lesson id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	course, err := h.courseService.UnpublishLesson(uint(courseID), uint(chapterID), uint(lessonID), instructorID.(uint), branchID.(uint))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
@@ -0,0 +1,383 @@
+package teacher
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/logger"
+	"online-learning-platform/internal/qti"
+	"online-learning-platform/internal/service"
+)
+
+// QuizHandler 教师测验题库和逐题批改处理器
+type QuizHandler struct {
+	quizService *service.QuizService
+}
+
+// NewQuizHandler 创建测验处理器
+func NewQuizHandler() *QuizHandler {
+	return &QuizHandler{
+		quizService: service.NewQuizService(),
+	}
+}
+
+// ListQuestions 获取测验题目
+// @Summary 获取测验题目
+// @Description 教学团队查看测验任务的题目，包含标准答案
+// @Tags 教师任务管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Success 200 {array} service.QuestionInfo
+// @Router /api/v1/teacher/tasks/{id}/questions [get]
+func (h *QuizHandler) ListQuestions(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	questions, err := h.quizService.ListQuestions(uint(taskID), userID.(uint), branchID.(uint))
+	if err != nil {
+		respondQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, questions)
+}
+
+// CreateQuestion 添加测验题目
+// @Summary 添加测验题目
+// @Description 为测验任务添加单选、多选、判断、简答或数值题；多选题按选对和选错的选项数给部分分
+// @Tags 教师任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Param request body service.QuestionRequest true "题目信息"
+// @Success 200 {object} service.QuestionInfo
+// @Router /api/v1/teacher/tasks/{id}/questions [post]
+func (h *QuizHandler) CreateQuestion(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	var req service.QuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	question, err := h.quizService.CreateQuestion(uint(taskID), userID.(uint), branchID.(uint), &req)
+	if err != nil {
+		respondQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+// UpdateQuestion 更新测验题目
+// @Summary 更新测验题目
+// @Description 更新题目内容、标准答案和分值，已提交的作业保留提交时的得分
+// @Tags 教师任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Param question_id path int true "题目ID"
+// @Param request body service.QuestionRequest true "题目信息"
+// @Success 200 {object} service.QuestionInfo
+// @Router /api/v1/teacher/tasks/{id}/questions/{question_id} [put]
+func (h *QuizHandler) UpdateQuestion(c *gin.Context) {
+	taskID, questionID, ok := parseQuestionParams(c)
+	if !ok {
+		return
+	}
+
+	var req service.QuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	question, err := h.quizService.UpdateQuestion(taskID, questionID, userID.(uint), branchID.(uint), &req)
+	if err != nil {
+		respondQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+// DeleteQuestion 删除测验题目
+// @Summary 删除测验题目
+// @Description 删除题目，已提交的作答仍显示该题目
+// @Tags 教师任务管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Param question_id path int true "题目ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/teacher/tasks/{id}/questions/{question_id} [delete]
+func (h *QuizHandler) DeleteQuestion(c *gin.Context) {
+	taskID, questionID, ok := parseQuestionParams(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	if err := h.quizService.DeleteQuestion(taskID, questionID, userID.(uint), branchID.(uint)); err != nil {
+		respondQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": true,
+	})
+}
+
+// ExportQuestions 导出测验题库
+// @Summary 导出测验题库
+// @Description 将测验任务的题目导出为 IMS QTI 2.1 内容包（zip），单选、多选、判断题为 choiceInteraction，简答和数值题为 textEntryInteraction
+// @Tags 教师任务管理
+// @Produce application/zip
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Success 200 {file} file
+// @Router /api/v1/teacher/tasks/{id}/questions/export [get]
+func (h *QuizHandler) ExportQuestions(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	bank, err := h.quizService.ExportQuestionsQTI(uint(taskID), userID.(uint), branchID.(uint))
+	if err != nil {
+		respondQuizError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=task_%d_qti.zip", taskID))
+	c.Status(http.StatusOK)
+
+	// 响应已经开始写出，出错时只能中断并记录日志
+	if err := qti.Write(c.Writer, bank); err != nil {
+		logger.Errorf("export questions of task %d: %v", taskID, err)
+		c.Abort()
+	}
+}
+
+// ImportQuestions 导入测验题库
+// @Summary 导入测验题库
+// @Description 从 IMS QTI 2.1 内容包（zip）或单个 assessmentItem 文件导入题目，追加到已有题目之后；返回未能导入的题目及原因
+// @Tags 教师任务管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Param file formData file true "QTI 内容包 (.zip) 或题目文件 (.xml)"
+// @Success 200 {object} service.QTIImportResult
+// @Router /api/v1/teacher/tasks/{id}/questions/import [post]
+func (h *QuizHandler) ImportQuestions(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "file is required",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "failed to read uploaded file",
+		})
+		return
+	}
+	defer file.Close()
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	result, err := h.quizService.ImportQuestionsQTI(uint(taskID), userID.(uint), branchID.(uint), file, fileHeader.Size)
+	if err != nil {
+		respondQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListResponses 查看测验作业的逐题作答
+// @Summary 查看测验作答
+// @Description 教学团队查看一份测验作业的逐题作答、得分和标准答案
+// @Tags 教师任务管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "作业ID"
+// @Param branch_id query int true "作业所在的分支ID"
+// @Success 200 {object} service.QuizResult
+// @Router /api/v1/teacher/answers/{id}/responses [get]
+func (h *QuizHandler) ListResponses(c *gin.Context) {
+	answerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid answer id",
+		})
+		return
+	}
+	answerBranchID, err := strconv.ParseUint(c.Query("branch_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid branch id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	result, err := h.quizService.ListResponses(userID.(uint), branchID.(uint), uint(answerID), uint(answerBranchID))
+	if err != nil {
+		respondQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// OverrideResponse 调整一道题目的得分
+// @Summary 调整题目得分
+// @Description 教学团队批改简答题或调整自动评分结果，points 为 null 时恢复自动评分；作业分数随之重新计算
+// @Tags 教师任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "作业ID"
+// @Param response_id path int true "作答ID"
+// @Param request body service.OverrideResponseRequest true "得分"
+// @Success 200 {object} service.QuizResult
+// @Router /api/v1/teacher/answers/{id}/responses/{response_id} [put]
+func (h *QuizHandler) OverrideResponse(c *gin.Context) {
+	answerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid answer id",
+		})
+		return
+	}
+	responseID, err := strconv.ParseUint(c.Param("response_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid response id",
+		})
+		return
+	}
+
+	var req service.OverrideResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	result, err := h.quizService.OverrideResponse(userID.(uint), branchID.(uint), uint(answerID), uint(responseID), &req)
+	if err != nil {
+		respondQuizError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func parseQuestionParams(c *gin.Context) (uint, uint, bool) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return 0, 0, false
+	}
+	questionID, err := strconv.ParseUint(c.Param("question_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid question id",
+		})
+		return 0, 0, false
+	}
+	return uint(taskID), uint(questionID), true
+}
+
+func respondQuizError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.HTTPStatus(), gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    errors.ErrCodeInternal,
+		"message": err.Error(),
+	})
+}
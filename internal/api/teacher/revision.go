@@ -0,0 +1,221 @@
+package teacher
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// RevisionHandler 教师内容修订历史处理器
+type RevisionHandler struct {
+	revisionService *service.RevisionService
+}
+
+// NewRevisionHandler 创建修订历史处理器
+func NewRevisionHandler() *RevisionHandler {
+	return &RevisionHandler{
+		revisionService: service.NewRevisionService(),
+	}
+}
+
+// ListCourseRevisions 获取课程修订历史
+// @Summary 获取课程修订历史
+// @Description 获取课程及其章节、课时、任务的全部修订记录（包括已删除的内容）
+// @Tags 教师修订历史
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Success 200 {array} service.RevisionInfo
+// @Router /api/v1/teacher/courses/{id}/revisions [get]
+func (h *RevisionHandler) ListCourseRevisions(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	revisions, err := h.revisionService.ListCourseRevisions(uint(courseID), instructorID.(uint), branchID.(uint))
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// ListRevisions 获取内容修订列表
+// @Summary 获取内容修订列表
+// @Description 获取课程、章节、课时或任务的修订列表，按版本号倒序
+// @Tags 教师修订历史
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "内容类型 (course, chapter, lesson, task)"
+// @Param id path int true "内容ID"
+// @Success 200 {array} service.RevisionInfo
+// @Router /api/v1/teacher/revisions/{type}/{id} [get]
+func (h *RevisionHandler) ListRevisions(c *gin.Context) {
+	entityID, ok := parseRevisionEntityID(c)
+	if !ok {
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	revisions, err := h.revisionService.ListRevisions(c.Param("type"), entityID, instructorID.(uint), branchID.(uint))
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetRevision 获取指定修订版本
+// @Summary 获取指定修订版本
+// @Description 获取某个版本的完整快照
+// @Tags 教师修订历史
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "内容类型 (course, chapter, lesson, task)"
+// @Param id path int true "内容ID"
+// @Param version path int true "版本号"
+// @Success 200 {object} service.RevisionInfo
+// @Router /api/v1/teacher/revisions/{type}/{id}/{version} [get]
+func (h *RevisionHandler) GetRevision(c *gin.Context) {
+	entityID, ok := parseRevisionEntityID(c)
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid version",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	revision, err := h.revisionService.GetRevision(c.Param("type"), entityID, version, instructorID.(uint), branchID.(uint))
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions 对比两个修订版本
+// @Summary 对比两个修订版本
+// @Description 返回两个版本之间发生变化的字段
+// @Tags 教师修订历史
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "内容类型 (course, chapter, lesson, task)"
+// @Param id path int true "内容ID"
+// @Param from query int true "起始版本号"
+// @Param to query int true "目标版本号"
+// @Success 200 {object} service.RevisionDiff
+// @Router /api/v1/teacher/revisions/{type}/{id}/diff [get]
+func (h *RevisionHandler) DiffRevisions(c *gin.Context) {
+	entityID, ok := parseRevisionEntityID(c)
+	if !ok {
+		return
+	}
+	fromVersion, errFrom := strconv.Atoi(c.Query("from"))
+	toVersion, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "from and to versions are required",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	diff, err := h.revisionService.DiffRevisions(c.Param("type"), entityID, fromVersion, toVersion, instructorID.(uint), branchID.(uint))
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RestoreRevision 恢复到指定修订版本
+// @Summary 恢复到指定修订版本
+// @Description 将内容恢复为指定版本的快照，已删除的内容会重新启用
+// @Tags 教师修订历史
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "内容类型 (course, chapter, lesson, task)"
+// @Param id path int true "内容ID"
+// @Param version path int true "版本号"
+// @Success 200 {object} service.RevisionInfo
+// @Router /api/v1/teacher/revisions/{type}/{id}/{version}/restore [post]
+func (h *RevisionHandler) RestoreRevision(c *gin.Context) {
+	entityID, ok := parseRevisionEntityID(c)
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid version",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	revision, err := h.revisionService.RestoreRevision(c.Param("type"), entityID, version, instructorID.(uint), branchID.(uint))
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+func parseRevisionEntityID(c *gin.Context) (uint, bool) {
+	entityID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid entity id",
+		})
+		return 0, false
+	}
+	return uint(entityID), true
+}
+
+func respondRevisionError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.HTTPStatus(), gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    errors.ErrCodeInternal,
+		"message": err.Error(),
+	})
+}
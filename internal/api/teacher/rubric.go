@@ -0,0 +1,109 @@
+package teacher
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// RubricHandler 评分标准管理处理器
+type RubricHandler struct {
+	taskService *service.TaskService
+}
+
+// NewRubricHandler 创建评分标准处理器
+func NewRubricHandler() *RubricHandler {
+	return &RubricHandler{
+		taskService: service.NewTaskService(),
+	}
+}
+
+// GetRubric 获取任务的评分标准
+// @Summary 获取评分标准
+// @Tags 教师任务管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Success 200 {object} service.RubricInfo
+// @Router /api/v1/teacher/tasks/{id}/rubric [get]
+func (h *RubricHandler) GetRubric(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	rubric, err := h.taskService.GetRubric(uint(taskID), userID.(uint), branchID.(uint))
+	if err != nil {
+		respondRubricError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rubric)
+}
+
+// SetRubric 设置任务的评分标准
+// @Summary 设置评分标准
+// @Description 课程教师设置任务的评分项和等级，各评分项满分之和必须等于任务满分；criteria 为空时清除评分标准。已有的评分保留评分时的评分项，测验任务不能设置评分标准
+// @Tags 教师任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Param request body service.SetRubricRequest true "评分标准"
+// @Success 200 {object} service.RubricInfo
+// @Router /api/v1/teacher/tasks/{id}/rubric [put]
+func (h *RubricHandler) SetRubric(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	var req service.SetRubricRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	rubric, err := h.taskService.SetRubric(uint(taskID), userID.(uint), branchID.(uint), &req)
+	if err != nil {
+		respondRubricError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rubric)
+}
+
+func respondRubricError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.HTTPStatus(), gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    errors.ErrCodeInternal,
+		"message": err.Error(),
+	})
+}
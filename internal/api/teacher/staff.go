@@ -0,0 +1,204 @@
+package teacher
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// StaffHandler 课程教学团队管理处理器
+type StaffHandler struct {
+	staffService *service.CourseStaffService
+}
+
+// NewStaffHandler 创建教学团队处理器
+func NewStaffHandler() *StaffHandler {
+	return &StaffHandler{
+		staffService: service.NewCourseStaffService(),
+	}
+}
+
+// ListStaff 获取课程教学团队
+// @Summary 获取课程教学团队
+// @Description 列出课程的主讲教师、协同教师和助教，团队成员均可查看
+// @Tags 教师课程管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Success 200 {array} service.CourseStaffInfo
+// @Router /api/v1/teacher/courses/{id}/staff [get]
+func (h *StaffHandler) ListStaff(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+	role, _ := c.Get("role")
+
+	staff, err := h.staffService.ListStaff(uint(courseID), userID.(uint), branchID.(uint), role.(string))
+	if err != nil {
+		respondStaffError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, staff)
+}
+
+// AddStaff 添加协同教师或助教
+// @Summary 添加协同教师或助教
+// @Description 主讲教师或管理员将其他教师加入课程教学团队（co_instructor 可编辑课程，ta 只能批改作业），已在团队中时修改角色
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param request body service.AddCourseStaffRequest true "成员信息"
+// @Success 200 {object} service.CourseStaffInfo
+// @Router /api/v1/teacher/courses/{id}/staff [post]
+func (h *StaffHandler) AddStaff(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	var req service.AddCourseStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+	role, _ := c.Get("role")
+
+	staff, err := h.staffService.AddStaff(uint(courseID), userID.(uint), branchID.(uint), role.(string), &req)
+	if err != nil {
+		respondStaffError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, staff)
+}
+
+// UpdateStaff 修改教学团队成员角色
+// @Summary 修改教学团队成员角色
+// @Tags 教师课程管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param staff_id path int true "成员ID"
+// @Param request body service.UpdateCourseStaffRequest true "角色"
+// @Success 200 {object} service.CourseStaffInfo
+// @Router /api/v1/teacher/courses/{id}/staff/{staff_id} [put]
+func (h *StaffHandler) UpdateStaff(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+	staffID, err := strconv.ParseUint(c.Param("staff_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid staff id",
+		})
+		return
+	}
+
+	var req service.UpdateCourseStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+	role, _ := c.Get("role")
+
+	staff, err := h.staffService.UpdateStaff(uint(courseID), uint(staffID), userID.(uint), branchID.(uint), role.(string), &req)
+	if err != nil {
+		respondStaffError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, staff)
+}
+
+// RemoveStaff 移出教学团队成员
+// @Summary 移出教学团队成员
+// @Description 主讲教师不能被移出
+// @Tags 教师课程管理
+// @Security BearerAuth
+// @Param id path int true "课程ID"
+// @Param staff_id path int true "成员ID"
+// @Success 200 {object} map[string]bool
+// @Router /api/v1/teacher/courses/{id}/staff/{staff_id} [delete]
+func (h *StaffHandler) RemoveStaff(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+	staffID, err := strconv.ParseUint(c.Param("staff_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid staff id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+	role, _ := c.Get("role")
+
+	if err := h.staffService.RemoveStaff(uint(courseID), uint(staffID), userID.(uint), branchID.(uint), role.(string)); err != nil {
+		respondStaffError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": true,
+	})
+}
+
+func respondStaffError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.HTTPStatus(), gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    errors.ErrCodeInternal,
+		"message": err.Error(),
+	})
+}
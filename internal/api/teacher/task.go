@@ -54,7 +54,8 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.taskService.CreateTask(uint(lessonID), instructorID.(uint), &req)
+	branchID, _ := c.Get("branch_id")
+	task, err := h.taskService.CreateTask(uint(lessonID), instructorID.(uint), branchID.(uint), &req)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			c.JSON(appErr.HTTPStatus(), gin.H{
@@ -93,7 +94,7 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 
-	task, err := h.taskService.GetTask(uint(taskID))
+	task, err := h.taskService.GetTask(uint(taskID), false)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			c.JSON(appErr.HTTPStatus(), gin.H{
@@ -132,7 +133,7 @@ func (h *TaskHandler) ListTasksByCourse(c *gin.Context) {
 		return
 	}
 
-	tasks, err := h.taskService.ListTasksByCourse(uint(courseID))
+	tasks, err := h.taskService.ListTasksByCourse(uint(courseID), false)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    errors.ErrCodeInternal,
@@ -144,3 +145,96 @@ func (h *TaskHandler) ListTasksByCourse(c *gin.Context) {
 	c.JSON(http.StatusOK, tasks)
 }
 
+// UpdateTask 更新任务
+// @Summary 更新任务
+// @Description 更新任务信息，未传入的字段保持不变
+// @Tags 教师任务管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Param request body service.UpdateTaskRequest true "任务信息"
+// @Success 200 {object} models.Tasks
+// @Router /api/v1/teacher/tasks/{id} [put]
+func (h *TaskHandler) UpdateTask(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	var req service.UpdateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	task, err := h.taskService.UpdateTask(uint(taskID), instructorID.(uint), branchID.(uint), &req)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// DeleteTask 删除任务
+// @Summary 删除任务
+// @Description 软删除任务，已提交的作业保留
+// @Tags 教师任务管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "任务ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/teacher/tasks/{id} [delete]
+func (h *TaskHandler) DeleteTask(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid task id",
+		})
+		return
+	}
+
+	instructorID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	if err := h.taskService.DeleteTask(uint(taskID), instructorID.(uint), branchID.(uint)); err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.HTTPStatus(), gin.H{
+				"code":    appErr.Code,
+				"message": appErr.Message,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    errors.ErrCodeInternal,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": true,
+	})
+}
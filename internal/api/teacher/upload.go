@@ -0,0 +1,185 @@
+package teacher
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"online-learning-platform/internal/errors"
+	"online-learning-platform/internal/service"
+)
+
+// UploadHandler 课时文件直传处理器
+type UploadHandler struct {
+	uploadService *service.UploadService
+}
+
+// NewUploadHandler 创建课时文件直传处理器
+func NewUploadHandler() *UploadHandler {
+	return &UploadHandler{
+		uploadService: service.NewUploadService(),
+	}
+}
+
+// CreateUpload 申请课时文件的上传位置
+// @Summary 申请课时文件上传
+// @Description 声明文件名、类型、大小和 SHA-256，返回直传对象存储的签名地址；大文件按 part_size 分片上传
+// @Description 上传完成后调用确认接口校验文件，再将 upload_id 传给创建或更新课时接口
+// @Tags 教师课程管理
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "课程ID"
+// @Param chapter_id path int true "章节ID"
+// @Param request body service.CreateUploadRequest true "文件信息"
+// @Success 200 {object} service.UploadSlot
+// @Router /api/v1/teacher/courses/{id}/chapters/{chapter_id}/uploads [post]
+func (h *UploadHandler) CreateUpload(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid course id",
+		})
+		return
+	}
+
+	chapterID, err := strconv.ParseUint(c.Param("chapter_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid chapter id",
+		})
+		return
+	}
+
+	var req service.CreateUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	slot, err := h.uploadService.CreateLessonUpload(uint(courseID), uint(chapterID), userID.(uint), branchID.(uint), &req)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, slot)
+}
+
+// GetUpload 查询上传进度
+// @Summary 查询课时文件上传
+// @Description 返回已上传的分片和尚未上传分片的新签名地址，用于断点续传
+// @Tags 教师课程管理
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "上传ID"
+// @Success 200 {object} service.UploadSlot
+// @Router /api/v1/teacher/uploads/{id} [get]
+func (h *UploadHandler) GetUpload(c *gin.Context) {
+	h.handleUpload(c, h.uploadService.GetUpload)
+}
+
+// CompleteUpload 确认上传完成
+// @Summary 确认课时文件上传
+// @Description 合并分片并校验文件大小、类型和 SHA-256，校验失败时文件被删除，需要重新申请
+// @Tags 教师课程管理
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "上传ID"
+// @Success 200 {object} service.UploadSlot
+// @Router /api/v1/teacher/uploads/{id}/complete [post]
+func (h *UploadHandler) CompleteUpload(c *gin.Context) {
+	h.handleUpload(c, h.uploadService.CompleteUpload)
+}
+
+// AbortUpload 取消上传
+// @Summary 取消课时文件上传
+// @Description 删除已上传的分片或文件，已关联到课时的文件不能取消
+// @Tags 教师课程管理
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "上传ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/teacher/uploads/{id} [delete]
+func (h *UploadHandler) AbortUpload(c *gin.Context) {
+	uploadID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid upload id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	if err := h.uploadService.AbortUpload(service.UploadPurposeLesson, uint(uploadID), userID.(uint), branchID.(uint)); err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "上传已取消"})
+}
+
+func (h *UploadHandler) handleUpload(c *gin.Context, fn func(purpose string, uploadID, userID, branchID uint) (*service.UploadSlot, error)) {
+	uploadID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid upload id",
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	branchID, _ := c.Get("branch_id")
+
+	slot, err := fn(service.UploadPurposeLesson, uint(uploadID), userID.(uint), branchID.(uint))
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, slot)
+}
+
+// parseUploadID 读取表单中可选的 upload_id，格式错误时返回 400 并返回 false
+func parseUploadID(c *gin.Context) (uint, bool) {
+	uploadIDStr := c.PostForm("upload_id")
+	if uploadIDStr == "" {
+		return 0, true
+	}
+	uploadID, err := strconv.ParseUint(uploadIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    errors.ErrCodeInvalidParam,
+			"message": "invalid upload id",
+		})
+		return 0, false
+	}
+	return uint(uploadID), true
+}
+
+func respondUploadError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.HTTPStatus(), gin.H{
+			"code":    appErr.Code,
+			"message": appErr.Message,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    errors.ErrCodeInternal,
+		"message": err.Error(),
+	})
+}
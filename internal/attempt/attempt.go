@@ -0,0 +1,71 @@
+// Package attempt 选择学生多次提交中计入成绩的一次
+//
+// 每次提交都保存为一次尝试，按任务设置的策略选出其中一次计入成绩：
+// 最近一次、得分最高的一次或第一次。
+package attempt
+
+// 计入成绩的策略
+const (
+	PolicyLatest  = "latest"  // 最近一次提交
+	PolicyHighest = "highest" // 已批改的提交中得分最高的一次，都未批改时取最近一次
+	PolicyFirst   = "first"   // 第一次提交
+)
+
+// Attempt 一次提交
+type Attempt struct {
+	Number int // 第几次提交，从 1 开始
+	Score  int
+	Graded bool
+}
+
+// ValidPolicy 策略是否有效，空字符串视为 PolicyLatest
+func ValidPolicy(policy string) bool {
+	switch policy {
+	case "", PolicyLatest, PolicyHighest, PolicyFirst:
+		return true
+	}
+	return false
+}
+
+// Counted 返回计入成绩的提交次序号，没有提交时返回 0
+// 得分相同时取较早的一次，之后的提交不会覆盖已有的最高分
+func Counted(policy string, attempts []Attempt) int {
+	if len(attempts) == 0 {
+		return 0
+	}
+	first, latest := attempts[0], attempts[0]
+	var best *Attempt
+	for i := range attempts {
+		a := &attempts[i]
+		if a.Number < first.Number {
+			first = *a
+		}
+		if a.Number > latest.Number {
+			latest = *a
+		}
+		if a.Graded && (best == nil || a.Score > best.Score || (a.Score == best.Score && a.Number < best.Number)) {
+			best = a
+		}
+	}
+
+	switch policy {
+	case PolicyFirst:
+		return first.Number
+	case PolicyHighest:
+		if best != nil {
+			return best.Number
+		}
+	}
+	return latest.Number
+}
+
+// Remaining 剩余的提交次数，maxAttempts 为 0 表示不限制，此时返回 -1
+func Remaining(maxAttempts, used int) int {
+	if maxAttempts <= 0 {
+		return -1
+	}
+	if used >= maxAttempts {
+		return 0
+	}
+	return maxAttempts - used
+}
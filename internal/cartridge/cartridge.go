@@ -0,0 +1,159 @@
+// Package cartridge 实现 IMS Common Cartridge 1.3 课程包的读写
+// 课程结构映射：课程 -> 顶层文件夹（章节）-> 文件夹（课时）-> 课时内容 + 作业（任务）
+package cartridge
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+)
+
+// 资源类型
+const (
+	ResourceWebContent = "webcontent"
+	ResourceWebLink    = "imswl_xmlv1p3"
+	ResourceAssignment = "assignment_xmlv1p0"
+)
+
+// 命名空间
+const (
+	manifestNamespace   = "http://www.imsglobal.org/xsd/imsccv1p3/imscp_v1p1"
+	lomNamespace        = "http://ltsc.ieee.org/xsd/imsccv1p3/LOM/manifest"
+	webLinkNamespace    = "http://www.imsglobal.org/xsd/imsccv1p3/imswl_v1p3"
+	assignmentNamespace = "http://www.imsglobal.org/xsd/imscc_extensions/assignment"
+)
+
+// 课时和任务类型（与 models.Lessons.LessonType、models.Tasks.TaskType 取值一致）
+const (
+	LessonTypeVideo = "video"
+	LessonTypeText  = "text"
+
+	TaskTypeEssay  = "essay"
+	TaskTypeUpload = "upload"
+)
+
+// Course 课程包中的课程
+type Course struct {
+	Identifier  string
+	Title       string
+	Description string
+	Chapters    []Chapter
+}
+
+// Chapter 章节
+type Chapter struct {
+	Title   string
+	Lessons []Lesson
+}
+
+// Lesson 课时；内容为包内文件（File）或外部链接（URL），两者都为空时为纯标题课时
+type Lesson struct {
+	Title string
+	Type  string
+	URL   string
+	File  *File
+	Tasks []Task
+}
+
+// File 包内文件，Open 在写入或读取时才被调用，避免一次性加载大文件
+type File struct {
+	Name string
+	Open func() (io.ReadCloser, error)
+}
+
+// Task 作业
+type Task struct {
+	Title       string
+	Description string
+	TaskType    string
+	MaxScore    int
+}
+
+// Issue 导入时无法支持或被部分导入的条目
+type Issue struct {
+	Identifier   string `json:"identifier"`
+	Title        string `json:"title"`
+	ResourceType string `json:"resource_type"`
+	Reason       string `json:"reason"`
+}
+
+// manifest imsmanifest.xml 结构（读取时忽略命名空间前缀，按本地名匹配）
+type manifest struct {
+	XMLName       xml.Name       `xml:"manifest"`
+	Identifier    string         `xml:"identifier,attr"`
+	Title         string         `xml:"metadata>lom>general>title>string"`
+	Description   string         `xml:"metadata>lom>general>description>string"`
+	Organizations []organization `xml:"organizations>organization"`
+	Resources     []resource     `xml:"resources>resource"`
+}
+
+type organization struct {
+	Identifier string `xml:"identifier,attr"`
+	Structure  string `xml:"structure,attr"`
+	Title      string `xml:"title,omitempty"`
+	Items      []item `xml:"item"`
+}
+
+type item struct {
+	Identifier    string `xml:"identifier,attr"`
+	IdentifierRef string `xml:"identifierref,attr,omitempty"`
+	Title         string `xml:"title,omitempty"`
+	Items         []item `xml:"item"`
+}
+
+type resource struct {
+	Identifier string         `xml:"identifier,attr"`
+	Type       string         `xml:"type,attr"`
+	Href       string         `xml:"href,attr,omitempty"`
+	Files      []resourceFile `xml:"file"`
+}
+
+type resourceFile struct {
+	Href string `xml:"href,attr"`
+}
+
+// webLink 外部链接资源
+type webLink struct {
+	XMLName xml.Name `xml:"webLink"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Title   string   `xml:"title"`
+	URL     struct {
+		Href string `xml:"href,attr"`
+	} `xml:"url"`
+}
+
+// assignment 作业资源（CC 1.3 assignment 扩展）
+type assignment struct {
+	XMLName    xml.Name `xml:"assignment"`
+	Xmlns      string   `xml:"xmlns,attr,omitempty"`
+	Identifier string   `xml:"identifier,attr"`
+	Title      string   `xml:"title"`
+	Text       struct {
+		TextType string `xml:"texttype,attr"`
+		Value    string `xml:",chardata"`
+	} `xml:"text"`
+	Gradable struct {
+		PointsPossible float64 `xml:"points_possible,attr"`
+		Value          bool    `xml:",chardata"`
+	} `xml:"gradable"`
+	SubmissionFormats []struct {
+		Type string `xml:"type,attr"`
+	} `xml:"submission_formats>format"`
+}
+
+// videoExtensions 按扩展名识别视频课时
+var videoExtensions = map[string]bool{
+	".mp4": true, ".m4v": true, ".mov": true, ".webm": true, ".mkv": true, ".avi": true, ".m3u8": true,
+}
+
+// lessonTypeForName 根据文件名或链接推断课时类型
+func lessonTypeForName(name string) string {
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name = name[:i]
+	}
+	if videoExtensions[strings.ToLower(path.Ext(name))] {
+		return LessonTypeVideo
+	}
+	return LessonTypeText
+}
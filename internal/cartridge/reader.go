@@ -0,0 +1,293 @@
+package cartridge
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strings"
+)
+
+// ErrInvalidPackage 不是有效的 Common Cartridge 包
+var ErrInvalidPackage = errors.New("invalid common cartridge package")
+
+// Read 解析 Common Cartridge 包，返回课程结构和无法导入的条目
+// 章节对应组织结构中的顶层文件夹；直接挂在根节点下的内容归入一个默认章节
+func Read(r io.ReaderAt, size int64) (*Course, []Issue, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPackage, err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[path.Clean(f.Name)] = f
+	}
+
+	manifestFile, ok := files["imsmanifest.xml"]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: imsmanifest.xml not found", ErrInvalidPackage)
+	}
+
+	var m manifest
+	if err := decodeZipXML(manifestFile, &m); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPackage, err)
+	}
+
+	p := &packageReader{
+		files:     files,
+		resources: make(map[string]resource, len(m.Resources)),
+	}
+	for _, res := range m.Resources {
+		p.resources[res.Identifier] = res
+	}
+
+	course := &Course{
+		Identifier:  m.Identifier,
+		Title:       strings.TrimSpace(m.Title),
+		Description: strings.TrimSpace(m.Description),
+	}
+
+	if len(m.Organizations) == 0 {
+		return course, p.issues, nil
+	}
+	org := m.Organizations[0]
+	if course.Title == "" {
+		course.Title = strings.TrimSpace(org.Title)
+	}
+
+	// rooted-hierarchy 下只有一个根节点，真正的内容在根节点的子节点中
+	items := org.Items
+	if len(items) == 1 && items[0].IdentifierRef == "" && (len(items[0].Items) > 0 || org.Structure == "rooted-hierarchy") {
+		if course.Title == "" {
+			course.Title = strings.TrimSpace(items[0].Title)
+		}
+		items = items[0].Items
+	}
+
+	var loose Chapter
+	for _, it := range items {
+		if it.IdentifierRef == "" && len(it.Items) > 0 {
+			chapter := Chapter{Title: strings.TrimSpace(it.Title)}
+			for _, child := range it.Items {
+				p.addToChapter(&chapter, child)
+			}
+			course.Chapters = append(course.Chapters, chapter)
+			continue
+		}
+		p.addToChapter(&loose, it)
+	}
+	if len(loose.Lessons) > 0 {
+		loose.Title = "课程内容"
+		course.Chapters = append([]Chapter{loose}, course.Chapters...)
+	}
+
+	return course, p.issues, nil
+}
+
+type packageReader struct {
+	files     map[string]*zip.File
+	resources map[string]resource
+	issues    []Issue
+}
+
+// addToChapter 把章节下的一个条目转换为课时或任务
+func (p *packageReader) addToChapter(chapter *Chapter, it item) {
+	title := strings.TrimSpace(it.Title)
+
+	// 文件夹：课时内容 + 作业
+	if it.IdentifierRef == "" && len(it.Items) > 0 {
+		lesson := Lesson{Title: title, Type: LessonTypeText}
+		for _, child := range it.Items {
+			if len(child.Items) > 0 {
+				p.addIssue(child, "", "nested folders below a lesson are not supported")
+				continue
+			}
+			p.addToLesson(&lesson, child)
+		}
+		chapter.Lessons = append(chapter.Lessons, lesson)
+		return
+	}
+
+	// 纯标题条目
+	if it.IdentifierRef == "" {
+		chapter.Lessons = append(chapter.Lessons, Lesson{Title: title, Type: LessonTypeText})
+		return
+	}
+
+	res, ok := p.resources[it.IdentifierRef]
+	if !ok {
+		p.addIssue(it, "", "referenced resource not found in manifest")
+		return
+	}
+
+	// 章节下直接出现的作业归入上一个课时，没有上一个课时时单独建一个文本课时
+	if isAssignment(res.Type) {
+		if len(chapter.Lessons) == 0 {
+			chapter.Lessons = append(chapter.Lessons, Lesson{Title: title, Type: LessonTypeText})
+		}
+		p.addToLesson(&chapter.Lessons[len(chapter.Lessons)-1], it)
+		return
+	}
+
+	lesson := Lesson{Title: title, Type: LessonTypeText}
+	if p.addToLesson(&lesson, it) {
+		chapter.Lessons = append(chapter.Lessons, lesson)
+	}
+}
+
+// addToLesson 将叶子条目作为课时内容或作业加入课时，条目不受支持时返回 false
+func (p *packageReader) addToLesson(lesson *Lesson, it item) bool {
+	if it.IdentifierRef == "" {
+		return true
+	}
+
+	res, ok := p.resources[it.IdentifierRef]
+	if !ok {
+		p.addIssue(it, "", "referenced resource not found in manifest")
+		return false
+	}
+
+	switch {
+	case isAssignment(res.Type):
+		task, err := p.readAssignment(res)
+		if err != nil {
+			p.addIssue(it, res.Type, err.Error())
+			return false
+		}
+		if task.Title == "" {
+			task.Title = strings.TrimSpace(it.Title)
+		}
+		lesson.Tasks = append(lesson.Tasks, task)
+		return true
+
+	case lesson.File != nil || lesson.URL != "":
+		p.addIssue(it, res.Type, "lesson already has content, extra content item skipped")
+		return false
+
+	case res.Type == ResourceWebContent:
+		href := res.Href
+		if href == "" && len(res.Files) > 0 {
+			href = res.Files[0].Href
+		}
+		f, ok := p.files[path.Clean(href)]
+		if href == "" || !ok {
+			p.addIssue(it, res.Type, "content file not found in package")
+			return false
+		}
+		if len(res.Files) > 1 {
+			p.addIssue(it, res.Type, fmt.Sprintf("only the main file %s is imported, %d dependent files skipped", href, len(res.Files)-1))
+		}
+		lesson.File = &File{Name: path.Base(href), Open: f.Open}
+		lesson.Type = lessonTypeForName(href)
+		return true
+
+	case strings.HasPrefix(res.Type, "imswl_xmlv"):
+		url, err := p.readWebLink(res)
+		if err != nil {
+			p.addIssue(it, res.Type, err.Error())
+			return false
+		}
+		lesson.URL = url
+		lesson.Type = lessonTypeForName(url)
+		return true
+	}
+
+	p.addIssue(it, res.Type, unsupportedReason(res.Type))
+	return false
+}
+
+func (p *packageReader) readAssignment(res resource) (Task, error) {
+	var a assignment
+	if err := p.decodeResource(res, &a); err != nil {
+		return Task{}, err
+	}
+
+	task := Task{
+		Title:       strings.TrimSpace(a.Title),
+		Description: strings.TrimSpace(a.Text.Value),
+		TaskType:    TaskTypeEssay,
+		MaxScore:    int(math.Round(a.Gradable.PointsPossible)),
+	}
+	if task.MaxScore <= 0 {
+		task.MaxScore = 100
+	}
+
+	// 只接受文件提交的作业对应上传类型任务
+	onlyFiles := len(a.SubmissionFormats) > 0
+	for _, format := range a.SubmissionFormats {
+		if format.Type != "file" {
+			onlyFiles = false
+		}
+	}
+	if onlyFiles {
+		task.TaskType = TaskTypeUpload
+	}
+
+	return task, nil
+}
+
+func (p *packageReader) readWebLink(res resource) (string, error) {
+	var link webLink
+	if err := p.decodeResource(res, &link); err != nil {
+		return "", err
+	}
+	if link.URL.Href == "" {
+		return "", errors.New("web link has no url")
+	}
+	return link.URL.Href, nil
+}
+
+// decodeResource 解析资源的第一个文件
+func (p *packageReader) decodeResource(res resource, v interface{}) error {
+	href := res.Href
+	if href == "" && len(res.Files) > 0 {
+		href = res.Files[0].Href
+	}
+	f, ok := p.files[path.Clean(href)]
+	if href == "" || !ok {
+		return errors.New("resource file not found in package")
+	}
+	if err := decodeZipXML(f, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", href, err)
+	}
+	return nil
+}
+
+func (p *packageReader) addIssue(it item, resourceType, reason string) {
+	p.issues = append(p.issues, Issue{
+		Identifier:   it.Identifier,
+		Title:        strings.TrimSpace(it.Title),
+		ResourceType: resourceType,
+		Reason:       reason,
+	})
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+func isAssignment(resourceType string) bool {
+	return strings.HasPrefix(resourceType, "assignment_xmlv")
+}
+
+// unsupportedReason 不受支持资源类型的说明
+func unsupportedReason(resourceType string) string {
+	switch {
+	case strings.HasPrefix(resourceType, "imsqti_xmlv"), strings.HasPrefix(resourceType, "imscc_xmlv1p3/assessment"):
+		return "quizzes and question banks are not supported"
+	case strings.HasPrefix(resourceType, "imsdt_xmlv"):
+		return "discussion topics are not supported"
+	case strings.HasPrefix(resourceType, "imsbasiclti_xmlv"):
+		return "LTI links are not supported"
+	}
+	return "unsupported resource type"
+}
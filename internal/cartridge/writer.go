@@ -0,0 +1,227 @@
+package cartridge
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// 输出 manifest 时使用带前缀的 LOM 元素
+type manifestOut struct {
+	XMLName       xml.Name       `xml:"manifest"`
+	Identifier    string         `xml:"identifier,attr"`
+	Xmlns         string         `xml:"xmlns,attr"`
+	XmlnsLom      string         `xml:"xmlns:lomimscc,attr"`
+	Metadata      metadataOut    `xml:"metadata"`
+	Organizations []organization `xml:"organizations>organization"`
+	Resources     []resource     `xml:"resources>resource"`
+}
+
+type metadataOut struct {
+	Schema        string `xml:"schema"`
+	SchemaVersion string `xml:"schemaversion"`
+	Title         string `xml:"lomimscc:lom>lomimscc:general>lomimscc:title>lomimscc:string"`
+	Description   string `xml:"lomimscc:lom>lomimscc:general>lomimscc:description>lomimscc:string,omitempty"`
+}
+
+// packageEntry 需要写入 zip 的文件
+type packageEntry struct {
+	name string
+	data []byte
+	file *File
+}
+
+// Write 将课程写为 Common Cartridge 包
+func Write(w io.Writer, course *Course) error {
+	identifier := course.Identifier
+	if identifier == "" {
+		identifier = "course"
+	}
+
+	out := manifestOut{
+		Identifier: identifier,
+		Xmlns:      manifestNamespace,
+		XmlnsLom:   lomNamespace,
+		Metadata: metadataOut{
+			Schema:        "IMS Common Cartridge",
+			SchemaVersion: "1.3.0",
+			Title:         course.Title,
+			Description:   course.Description,
+		},
+	}
+
+	var entries []packageEntry
+	root := item{Identifier: "root"}
+
+	for ci, chapter := range course.Chapters {
+		chapterItem := item{
+			Identifier: fmt.Sprintf("chapter_%d", ci+1),
+			Title:      chapter.Title,
+		}
+
+		for li, lesson := range chapter.Lessons {
+			lessonKey := fmt.Sprintf("%d_%d", ci+1, li+1)
+			lessonItem := item{
+				Identifier: "lesson_" + lessonKey,
+				Title:      lesson.Title,
+			}
+
+			switch {
+			case lesson.File != nil:
+				href := fmt.Sprintf("lessons/%s/%s", lessonKey, safeFileName(lesson.File.Name))
+				out.Resources = append(out.Resources, resource{
+					Identifier: "res_lesson_" + lessonKey,
+					Type:       ResourceWebContent,
+					Href:       href,
+					Files:      []resourceFile{{Href: href}},
+				})
+				entries = append(entries, packageEntry{name: href, file: lesson.File})
+				lessonItem.Items = append(lessonItem.Items, item{
+					Identifier:    "lesson_" + lessonKey + "_content",
+					IdentifierRef: "res_lesson_" + lessonKey,
+					Title:         lesson.Title,
+				})
+			case lesson.URL != "":
+				href := fmt.Sprintf("lessons/%s/weblink.xml", lessonKey)
+				link := webLink{Xmlns: webLinkNamespace, Title: lesson.Title}
+				link.URL.Href = lesson.URL
+				data, err := marshalXML(link)
+				if err != nil {
+					return err
+				}
+				out.Resources = append(out.Resources, resource{
+					Identifier: "res_lesson_" + lessonKey,
+					Type:       ResourceWebLink,
+					Files:      []resourceFile{{Href: href}},
+				})
+				entries = append(entries, packageEntry{name: href, data: data})
+				lessonItem.Items = append(lessonItem.Items, item{
+					Identifier:    "lesson_" + lessonKey + "_content",
+					IdentifierRef: "res_lesson_" + lessonKey,
+					Title:         lesson.Title,
+				})
+			}
+
+			for ti, task := range lesson.Tasks {
+				taskKey := fmt.Sprintf("%s_%d", lessonKey, ti+1)
+				href := fmt.Sprintf("tasks/%s/assignment.xml", taskKey)
+				data, err := marshalXML(toAssignment("task_"+taskKey, task))
+				if err != nil {
+					return err
+				}
+				out.Resources = append(out.Resources, resource{
+					Identifier: "res_task_" + taskKey,
+					Type:       ResourceAssignment,
+					Files:      []resourceFile{{Href: href}},
+				})
+				entries = append(entries, packageEntry{name: href, data: data})
+				lessonItem.Items = append(lessonItem.Items, item{
+					Identifier:    "task_" + taskKey,
+					IdentifierRef: "res_task_" + taskKey,
+					Title:         task.Title,
+				})
+			}
+
+			chapterItem.Items = append(chapterItem.Items, lessonItem)
+		}
+
+		root.Items = append(root.Items, chapterItem)
+	}
+
+	out.Organizations = []organization{{
+		Identifier: "organization",
+		Structure:  "rooted-hierarchy",
+		Items:      []item{root},
+	}}
+
+	manifestData, err := marshalXML(out)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	if err := writeEntry(zw, packageEntry{name: "imsmanifest.xml", data: manifestData}); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := writeEntry(zw, entry); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// toAssignment 将任务转换为作业资源；quiz 等没有对应格式的任务按文本作业导出
+func toAssignment(identifier string, task Task) assignment {
+	a := assignment{Xmlns: assignmentNamespace, Identifier: identifier, Title: task.Title}
+	a.Text.TextType = "text/plain"
+	a.Text.Value = task.Description
+	a.Gradable.PointsPossible = float64(task.MaxScore)
+	a.Gradable.Value = true
+
+	format := "text"
+	if task.TaskType == TaskTypeUpload {
+		format = "file"
+	}
+	a.SubmissionFormats = append(a.SubmissionFormats, struct {
+		Type string `xml:"type,attr"`
+	}{Type: format})
+	return a
+}
+
+func writeEntry(zw *zip.Writer, entry packageEntry) error {
+	// 视频等媒体文件本身已压缩，直接存储
+	method := zip.Store
+	if entry.file == nil || !isMediaFile(entry.name) {
+		method = zip.Deflate
+	}
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: entry.name, Method: method})
+	if err != nil {
+		return fmt.Errorf("failed to create %s in package: %w", entry.name, err)
+	}
+
+	if entry.file == nil {
+		_, err = w.Write(entry.data)
+		return err
+	}
+
+	rc, err := entry.file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", entry.file.Name, err)
+	}
+	defer rc.Close()
+
+	if _, err := io.Copy(w, rc); err != nil {
+		return fmt.Errorf("failed to write %s to package: %w", entry.name, err)
+	}
+	return nil
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode xml: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func isMediaFile(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".mp4", ".m4v", ".mov", ".webm", ".mkv", ".avi", ".mp3", ".m4a", ".jpg", ".jpeg", ".png", ".gif", ".zip", ".pdf":
+		return true
+	}
+	return false
+}
+
+// safeFileName 去掉目录部分，避免包内路径穿越
+func safeFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == ".." || name == "" {
+		return "content"
+	}
+	return name
+}
@@ -12,6 +12,10 @@ type Config struct {
 	Branches []BranchConfig `mapstructure:"branches"`
 	OSS      OSSConfig      `mapstructure:"oss"`
 	Sync     SyncConfig     `mapstructure:"sync"`
+	Publish  PublishConfig  `mapstructure:"publish"`
+	Playback PlaybackConfig `mapstructure:"playback"`
+	Upload   UploadConfig   `mapstructure:"upload"`
+	Video    VideoConfig    `mapstructure:"video"`
 }
 
 // AppConfig 应用配置
@@ -55,11 +59,17 @@ type BranchConfig struct {
 
 // OSSConfig OSS配置
 type OSSConfig struct {
+	// Provider 存储后端：aliyun（默认）、s3（S3 兼容，如 MinIO）、local（本地目录）
+	Provider        string `mapstructure:"provider"`
 	Endpoint        string `mapstructure:"endpoint"`
 	AccessKeyID     string `mapstructure:"access_key_id"`
 	AccessKeySecret string `mapstructure:"access_key_secret"`
 	BucketName      string `mapstructure:"bucket_name"`
 	Region          string `mapstructure:"region"`
+	// LocalDir local 后端保存对象的目录
+	LocalDir string `mapstructure:"local_dir"`
+	// PublicURL local 后端对外的访问地址，签名URL指向 {public_url}/files/{key}
+	PublicURL string `mapstructure:"public_url"`
 }
 
 // SyncConfig 同步配置
@@ -81,6 +91,50 @@ type ConsolidationConfig struct {
 	Schedule string `mapstructure:"schedule"`
 }
 
+// PublishConfig 定时发布配置
+type PublishConfig struct {
+	Interval string `mapstructure:"interval"` // 检查到期发布内容的间隔，例如 1m
+}
+
+// PlaybackConfig 视频播放进度配置
+type PlaybackConfig struct {
+	CompletionThreshold float64 `mapstructure:"completion_threshold"` // 观看比例达到该值时课时自动完成，例如 0.9
+	FlushInterval       string  `mapstructure:"flush_interval"`       // 心跳缓冲写入分支数据库的间隔，例如 10s
+	MaxBuffered         int     `mapstructure:"max_buffered"`         // 缓冲的播放记录达到该数量时立即写入
+}
+
+// UploadConfig 文件上传配置，未配置的项使用内置默认值
+type UploadConfig struct {
+	Lesson  map[string]UploadLimitConfig `mapstructure:"lesson"` // 按课时类型（video、text）限制课时文件
+	Answer  UploadLimitConfig            `mapstructure:"answer"` // 作业文件的限制，任务可以在此范围内单独收紧
+	Asset   UploadLimitConfig            `mapstructure:"asset"`  // Markdown 同步时正文引用的资源文件的限制
+	Scanner ScannerConfig                `mapstructure:"scanner"`
+}
+
+// UploadLimitConfig 允许的文件类型和大小上限
+type UploadLimitConfig struct {
+	AllowedTypes []string `mapstructure:"allowed_types"` // 例如 image/png、application/pdf
+	MaxSize      int64    `mapstructure:"max_size"`      // 字节
+}
+
+// ScannerConfig 恶意文件扫描配置
+type ScannerConfig struct {
+	Type    string `mapstructure:"type"`     // none（默认，不扫描）、clamav
+	Address string `mapstructure:"address"`  // clamd 地址，例如 tcp://127.0.0.1:3310 或 unix:///var/run/clamav/clamd.ctl
+	Timeout string `mapstructure:"timeout"`  // 单个文件的扫描超时，例如 1m
+	MaxSize int64  `mapstructure:"max_size"` // 超过该大小的文件不扫描（字节），应不超过 clamd 的 StreamMaxLength，默认 25MB
+}
+
+// VideoConfig 课时视频处理配置
+type VideoConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`      // 是否在本实例运行视频处理任务，需要安装 ffmpeg
+	FFmpegPath  string `mapstructure:"ffmpeg_path"`  // 默认从 PATH 查找 ffmpeg
+	FFprobePath string `mapstructure:"ffprobe_path"` // 默认从 PATH 查找 ffprobe
+	Interval    string `mapstructure:"interval"`     // 检查待处理视频的间隔，例如 30s
+	Workers     int    `mapstructure:"workers"`      // 同时处理的视频数量，默认 1
+	TempDir     string `mapstructure:"temp_dir"`     // 下载和转码使用的临时目录，默认系统临时目录
+}
+
 var globalConfig *Config
 
 // LoadConfig 加载配置
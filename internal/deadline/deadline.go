@@ -0,0 +1,126 @@
+// Package deadline 计算作业的截止时间、迟交天数和迟交扣分
+//
+// 任务有两个时间点：截止时间（due）之后提交记为迟交，最终截止时间（cut-off）之后按迟交策略
+// 拒绝提交或计 0 分。学生的延期只覆盖其设置了的时间点，未设置的沿用任务的时间。
+package deadline
+
+import (
+	"errors"
+	"time"
+)
+
+// 迟交策略
+const (
+	PolicyNone            = ""                  // 迟交不扣分，最终截止后不再接受提交
+	PolicyPercentPerDay   = "percent_per_day"   // 每迟交一天（不足一天按一天）扣除分数的固定百分比，最终截止后不再接受提交
+	PolicyZeroAfterCutoff = "zero_after_cutoff" // 最终截止前迟交不扣分，之后仍可提交但计 0 分
+)
+
+// Day 迟交天数的计算单位
+const Day = 24 * time.Hour
+
+var (
+	// ErrCutoffBeforeDue 最终截止时间早于截止时间
+	ErrCutoffBeforeDue = errors.New("cut-off is before the due time")
+	// ErrInvalidPolicy 未知的迟交策略或扣分百分比超出 0-100
+	ErrInvalidPolicy = errors.New("invalid late policy")
+)
+
+// Window 截止时间，字段为 nil 表示未设置
+type Window struct {
+	DueAt    *time.Time
+	CutoffAt *time.Time
+}
+
+// Validate 同时设置时最终截止时间不能早于截止时间
+func (w Window) Validate() error {
+	if w.DueAt != nil && w.CutoffAt != nil && w.CutoffAt.Before(*w.DueAt) {
+		return ErrCutoffBeforeDue
+	}
+	return nil
+}
+
+// Extend 用延期中设置了的时间点覆盖任务的时间
+func (w Window) Extend(extension Window) Window {
+	if extension.DueAt != nil {
+		w.DueAt = extension.DueAt
+	}
+	if extension.CutoffAt != nil {
+		w.CutoffAt = extension.CutoffAt
+	}
+	return w
+}
+
+// Status 一次提交相对截止时间的状态
+type Status struct {
+	Late        bool // 晚于截止时间
+	Days        int  // 迟交天数，不足一天按一天
+	AfterCutoff bool // 晚于最终截止时间
+}
+
+// Evaluate 计算在 at 时刻提交的迟交状态
+// 只设置了最终截止时间时，最终截止之后的提交也记为迟交
+func (w Window) Evaluate(at time.Time) Status {
+	var s Status
+	if w.CutoffAt != nil && at.After(*w.CutoffAt) {
+		s.AfterCutoff = true
+	}
+	due := w.DueAt
+	if due == nil {
+		due = w.CutoffAt
+	}
+	if due != nil && at.After(*due) {
+		s.Late = true
+		s.Days = int((at.Sub(*due) + Day - 1) / Day)
+	}
+	return s
+}
+
+// Policy 迟交扣分策略
+type Policy struct {
+	Type          string
+	PercentPerDay int // PolicyPercentPerDay 时每天扣除的百分比
+}
+
+// Validate 校验策略类型和百分比
+func (p Policy) Validate() error {
+	switch p.Type {
+	case PolicyNone, PolicyZeroAfterCutoff:
+		return nil
+	case PolicyPercentPerDay:
+		if p.PercentPerDay <= 0 || p.PercentPerDay > 100 {
+			return ErrInvalidPolicy
+		}
+		return nil
+	}
+	return ErrInvalidPolicy
+}
+
+// Accepts 是否接受该状态的提交：只有 PolicyZeroAfterCutoff 在最终截止后仍接受提交
+func (p Policy) Accepts(s Status) bool {
+	return !s.AfterCutoff || p.Type == PolicyZeroAfterCutoff
+}
+
+// Apply 对原始得分扣除迟交分数，返回最终得分和扣除的分数
+// 按天扣分时按原始得分的百分比扣除（四舍五入），最多扣到 0 分
+func (p Policy) Apply(raw int, s Status) (int, int) {
+	if raw <= 0 {
+		return raw, 0
+	}
+	penalty := 0
+	switch p.Type {
+	case PolicyPercentPerDay:
+		if s.Late {
+			percent := p.PercentPerDay * s.Days
+			if percent > 100 {
+				percent = 100
+			}
+			penalty = (raw*percent + 50) / 100
+		}
+	case PolicyZeroAfterCutoff:
+		if s.AfterCutoff {
+			penalty = raw
+		}
+	}
+	return raw - penalty, penalty
+}
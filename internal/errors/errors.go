@@ -28,16 +28,31 @@ const (
 	ErrCodeLessonNotFound     ErrorCode = 3003 // 课程不存在
 	ErrCodeTaskNotFound       ErrorCode = 3004 // 任务不存在
 	ErrCodeNotCourseInstructor ErrorCode = 3005 // 不是课程教师
+	ErrCodeCourseArchived     ErrorCode = 3006 // 课程已归档
+	ErrCodeRevisionNotFound   ErrorCode = 3007 // 修订版本不存在
+	ErrCodeInvalidPackage     ErrorCode = 3008 // 课程包格式无效
+	ErrCodeCourseEnded        ErrorCode = 3009 // 课程已结束
+	ErrCodeCategoryNotFound   ErrorCode = 3010 // 课程分类不存在
+	ErrCodeCategoryExists     ErrorCode = 3011 // 课程分类已存在
 
 	// 学习相关错误码
 	ErrCodeNotEnrolled        ErrorCode = 4001 // 未报名课程
 	ErrCodeAlreadyEnrolled    ErrorCode = 4002 // 已报名课程
 	ErrCodeCannotComment      ErrorCode = 4003 // 不能评论（未报名）
+	ErrCodePrerequisitesNotMet ErrorCode = 4004 // 未满足先修课程要求
+	ErrCodeEnrollmentClosed   ErrorCode = 4005 // 不在报名时间内
 
 	// 作业相关错误码
 	ErrCodeAnswerNotFound     ErrorCode = 5001 // 作业不存在
 	ErrCodeAnswerAlreadyGraded ErrorCode = 5002 // 作业已评分
 	ErrCodeInvalidScore       ErrorCode = 5003 // 分数无效
+	ErrCodeSubmissionClosed   ErrorCode = 5004 // 已过最终截止时间
+	ErrCodeAttemptsExhausted  ErrorCode = 5005 // 提交次数已用完
+
+	// 上传相关错误码
+	ErrCodeUploadNotFound     ErrorCode = 7001 // 上传记录不存在
+	ErrCodeUploadIncomplete   ErrorCode = 7002 // 上传未完成
+	ErrCodeUploadRejected     ErrorCode = 7003 // 上传文件校验失败
 
 	// 数据库相关错误码
 	ErrCodeDatabaseError      ErrorCode = 6001 // 数据库错误
@@ -67,17 +82,21 @@ func (e *AppError) Unwrap() error {
 // HTTPStatus 返回HTTP状态码
 func (e *AppError) HTTPStatus() int {
 	switch e.Code {
-	case ErrCodeInvalidParam:
+	case ErrCodeInvalidParam, ErrCodeInvalidPackage, ErrCodeUploadRejected, ErrCodeInvalidScore:
 		return http.StatusBadRequest
 	case ErrCodeNotFound, ErrCodeUserNotFound, ErrCodeCourseNotFound,
 		ErrCodeChapterNotFound, ErrCodeLessonNotFound, ErrCodeTaskNotFound,
-		ErrCodeAnswerNotFound:
+		ErrCodeAnswerNotFound, ErrCodeRevisionNotFound, ErrCodeCategoryNotFound,
+		ErrCodeUploadNotFound:
 		return http.StatusNotFound
 	case ErrCodeUnauthorized:
 		return http.StatusUnauthorized
-	case ErrCodeForbidden, ErrCodeNotCourseInstructor, ErrCodeCannotComment:
+	case ErrCodeForbidden, ErrCodeNotCourseInstructor, ErrCodeCannotComment,
+		ErrCodeCourseArchived, ErrCodePrerequisitesNotMet, ErrCodeCourseEnded,
+		ErrCodeEnrollmentClosed, ErrCodeSubmissionClosed, ErrCodeAttemptsExhausted:
 		return http.StatusForbidden
-	case ErrCodeUserAlreadyExists, ErrCodeAlreadyEnrolled:
+	case ErrCodeUserAlreadyExists, ErrCodeAlreadyEnrolled, ErrCodeCategoryExists,
+		ErrCodeUploadIncomplete:
 		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
@@ -119,14 +138,27 @@ var (
 	ErrLessonNotFound      = NewAppError(ErrCodeLessonNotFound, "课程不存在")
 	ErrTaskNotFound        = NewAppError(ErrCodeTaskNotFound, "任务不存在")
 	ErrNotCourseInstructor = NewAppError(ErrCodeNotCourseInstructor, "不是课程教师")
+	ErrCourseArchived      = NewAppError(ErrCodeCourseArchived, "课程已归档")
+	ErrRevisionNotFound    = NewAppError(ErrCodeRevisionNotFound, "修订版本不存在")
+	ErrInvalidPackage      = NewAppError(ErrCodeInvalidPackage, "课程包格式无效")
+	ErrCourseEnded         = NewAppError(ErrCodeCourseEnded, "课程已结束，只能查看")
+	ErrCategoryNotFound    = NewAppError(ErrCodeCategoryNotFound, "课程分类不存在")
+	ErrCategoryExists      = NewAppError(ErrCodeCategoryExists, "课程分类已存在")
 
 	ErrNotEnrolled   = NewAppError(ErrCodeNotEnrolled, "未报名课程")
 	ErrAlreadyEnrolled = NewAppError(ErrCodeAlreadyEnrolled, "已报名课程")
 	ErrCannotComment = NewAppError(ErrCodeCannotComment, "不能评论，请先报名课程")
+	ErrPrerequisitesNotMet = NewAppError(ErrCodePrerequisitesNotMet, "未满足先修课程要求")
+	ErrEnrollmentClosed    = NewAppError(ErrCodeEnrollmentClosed, "不在报名时间内")
 
 	ErrAnswerNotFound     = NewAppError(ErrCodeAnswerNotFound, "作业不存在")
 	ErrAnswerAlreadyGraded = NewAppError(ErrCodeAnswerAlreadyGraded, "作业已评分")
 	ErrInvalidScore       = NewAppError(ErrCodeInvalidScore, "分数无效")
+	ErrSubmissionClosed   = NewAppError(ErrCodeSubmissionClosed, "已过最终截止时间，不再接受提交")
+	ErrAttemptsExhausted  = NewAppError(ErrCodeAttemptsExhausted, "已达到最大提交次数")
+
+	ErrUploadNotFound   = NewAppError(ErrCodeUploadNotFound, "上传记录不存在")
+	ErrUploadIncomplete = NewAppError(ErrCodeUploadIncomplete, "文件尚未上传完成")
 
 	ErrDatabaseError  = NewAppError(ErrCodeDatabaseError, "数据库错误")
 	ErrBranchNotFound = NewAppError(ErrCodeBranchNotFound, "分支不存在")
@@ -0,0 +1,457 @@
+// Package mdsync 解析以 Markdown 编写的课程目录
+//
+// 目录结构示例：
+//
+//	01-intro/
+//	  _chapter.md          # 可选，front-matter 中的 title/description/slug 作用于章节
+//	  01-welcome.md
+//	  02-variables.md
+//	  images/diagram.png   # Markdown 中以相对路径引用的资源
+//	02-types/
+//	  ...
+//
+// 章节和课时按文件名的数字前缀排序，slug 默认取去掉前缀和扩展名后的名称，
+// 可以在 front-matter 中用 slug 显式指定以便改名后仍对应同一条记录。
+package mdsync
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// 课时类型
+const (
+	LessonTypeVideo = "video"
+	LessonTypeText  = "text"
+)
+
+// Course 课程目录；FS 为课程根目录，Path、Assets 等路径均相对于它
+type Course struct {
+	FS       fs.FS
+	Chapters []Chapter
+}
+
+// Chapter 章节目录
+type Chapter struct {
+	Slug        string
+	Title       string
+	Description string
+	Path        string
+	Lessons     []Lesson
+}
+
+// Lesson 课时文件
+// Video 不为空时课时内容为视频：VideoFile 为 true 表示目录内的文件路径，否则为外部链接
+type Lesson struct {
+	Slug      string
+	Title     string
+	Type      string
+	Path      string
+	Body      string
+	Video     string
+	VideoFile bool
+	Assets    []string // Body 中引用的目录内资源（相对目录根的路径）
+	Tasks     []Task
+}
+
+// Task front-matter 中声明的作业
+type Task struct {
+	Slug        string
+	Title       string
+	Description string
+	TaskType    string
+	MaxScore    int
+}
+
+type frontMatter struct {
+	Slug        string `yaml:"slug"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Type        string `yaml:"type"`
+	Video       string `yaml:"video"`
+	Tasks       []struct {
+		Slug        string `yaml:"slug"`
+		Title       string `yaml:"title"`
+		Description string `yaml:"description"`
+		Type        string `yaml:"type"`
+		MaxScore    int    `yaml:"max_score"`
+	} `yaml:"tasks"`
+}
+
+// chapterMetaFile 章节元数据文件名
+const chapterMetaFile = "_chapter.md"
+
+var (
+	orderPrefix = regexp.MustCompile(`^(\d+)[-_. ]+`)
+	linkPattern = regexp.MustCompile(`\]\(([^)\s]+)(\s+"[^"]*")?\)`)
+	headingLine = regexp.MustCompile(`(?m)^#\s+(.+)$`)
+	slugInvalid = regexp.MustCompile(`[^\p{L}\p{N}_-]+`)
+)
+
+// Parse 解析课程目录
+// 如果根目录下只有一个包裹目录（例如解压后的 zip），会自动进入该目录
+func Parse(fsys fs.FS) (*Course, error) {
+	root, err := findRoot(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if fsys, err = fs.Sub(fsys, root); err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	course := &Course{FS: fsys}
+	chapterSlugs := make(map[string]string)
+	lessonSlugs := make(map[string]string)
+
+	for _, entry := range sortEntries(entries) {
+		if !entry.IsDir() || isIgnored(entry.Name()) {
+			continue
+		}
+
+		chapter, err := parseChapter(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if chapter == nil {
+			continue
+		}
+
+		if other, ok := chapterSlugs[chapter.Slug]; ok {
+			return nil, fmt.Errorf("duplicate chapter slug %q in %s and %s", chapter.Slug, other, chapter.Path)
+		}
+		chapterSlugs[chapter.Slug] = chapter.Path
+
+		for _, lesson := range chapter.Lessons {
+			if other, ok := lessonSlugs[lesson.Slug]; ok {
+				return nil, fmt.Errorf("duplicate lesson slug %q in %s and %s", lesson.Slug, other, lesson.Path)
+			}
+			lessonSlugs[lesson.Slug] = lesson.Path
+		}
+
+		course.Chapters = append(course.Chapters, *chapter)
+	}
+
+	return course, nil
+}
+
+// RewriteLinks 将 Markdown 中指向目录内资源的相对链接替换为 urls 中对应的地址
+// urls 的键为资源相对目录根的路径，lessonPath 为课时文件自身的路径
+func RewriteLinks(body, lessonPath string, urls map[string]string) string {
+	dir := path.Dir(lessonPath)
+	return linkPattern.ReplaceAllStringFunc(body, func(match string) string {
+		sub := linkPattern.FindStringSubmatch(match)
+		target, ok := resolveRelative(dir, sub[1])
+		if !ok {
+			return match
+		}
+		url, ok := urls[target]
+		if !ok {
+			return match
+		}
+		return "](" + url + sub[2] + ")"
+	})
+}
+
+func parseChapter(fsys fs.FS, dir string) (*Chapter, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	name := path.Base(dir)
+	chapter := &Chapter{
+		Slug:  slugify(stripOrderPrefix(name)),
+		Title: titleFromName(name),
+		Path:  dir,
+	}
+
+	data, err := fs.ReadFile(fsys, path.Join(dir, chapterMetaFile))
+	hasMeta := err == nil
+	if hasMeta {
+		meta, _, err := splitFrontMatter(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path.Join(chapter.Path, chapterMetaFile), err)
+		}
+		if meta.Slug != "" {
+			chapter.Slug = slugify(meta.Slug)
+		}
+		if meta.Title != "" {
+			chapter.Title = meta.Title
+		}
+		chapter.Description = meta.Description
+	}
+
+	for _, entry := range sortEntries(entries) {
+		if entry.IsDir() || isIgnored(entry.Name()) || !strings.EqualFold(path.Ext(entry.Name()), ".md") {
+			continue
+		}
+
+		lesson, err := parseLesson(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		taskSlugs := make(map[string]bool, len(lesson.Tasks))
+		for _, task := range lesson.Tasks {
+			if taskSlugs[task.Slug] {
+				return nil, fmt.Errorf("%s: duplicate task slug %q", lesson.Path, task.Slug)
+			}
+			taskSlugs[task.Slug] = true
+		}
+		chapter.Lessons = append(chapter.Lessons, *lesson)
+	}
+
+	// 只有资源文件的目录（例如 images/）不是章节
+	if len(chapter.Lessons) == 0 && !hasMeta {
+		return nil, nil
+	}
+
+	return chapter, nil
+}
+
+func parseLesson(fsys fs.FS, file string) (*Lesson, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	meta, body, err := splitFrontMatter(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	name := strings.TrimSuffix(path.Base(file), path.Ext(file))
+	lesson := &Lesson{
+		Slug:  slugify(stripOrderPrefix(name)),
+		Title: meta.Title,
+		Type:  meta.Type,
+		Path:  file,
+		Body:  body,
+		Video: meta.Video,
+	}
+	if meta.Slug != "" {
+		lesson.Slug = slugify(meta.Slug)
+	}
+	if lesson.Slug == "" {
+		return nil, fmt.Errorf("%s: cannot derive slug from file name", file)
+	}
+	if lesson.Title == "" {
+		if m := headingLine.FindStringSubmatch(body); m != nil {
+			lesson.Title = strings.TrimSpace(m[1])
+		} else {
+			lesson.Title = titleFromName(name)
+		}
+	}
+
+	dir := path.Dir(file)
+	if lesson.Video != "" {
+		if target, ok := resolveRelative(dir, lesson.Video); ok {
+			if _, err := fs.Stat(fsys, target); err != nil {
+				return nil, fmt.Errorf("%s: video file %s not found", file, lesson.Video)
+			}
+			lesson.Video = target
+			lesson.VideoFile = true
+		}
+	}
+	if lesson.Type == "" {
+		lesson.Type = LessonTypeText
+		if lesson.Video != "" {
+			lesson.Type = LessonTypeVideo
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, m := range linkPattern.FindAllStringSubmatch(body, -1) {
+		target, ok := resolveRelative(dir, m[1])
+		if !ok || seen[target] || strings.EqualFold(path.Ext(target), ".md") {
+			continue
+		}
+		if info, err := fs.Stat(fsys, target); err != nil || info.IsDir() {
+			continue
+		}
+		seen[target] = true
+		lesson.Assets = append(lesson.Assets, target)
+	}
+
+	for i, t := range meta.Tasks {
+		task := Task{
+			Slug:        slugify(t.Slug),
+			Title:       t.Title,
+			Description: strings.TrimSpace(t.Description),
+			TaskType:    t.Type,
+			MaxScore:    t.MaxScore,
+		}
+		if task.Slug == "" {
+			task.Slug = fmt.Sprintf("task-%d", i+1)
+		}
+		if task.Title == "" {
+			return nil, fmt.Errorf("%s: task %q has no title", file, task.Slug)
+		}
+		if task.TaskType == "" {
+			task.TaskType = "essay"
+		}
+		if task.MaxScore <= 0 {
+			task.MaxScore = 100
+		}
+		lesson.Tasks = append(lesson.Tasks, task)
+	}
+
+	return lesson, nil
+}
+
+// splitFrontMatter 拆分 --- 包围的 YAML front-matter 和正文
+func splitFrontMatter(data []byte) (frontMatter, string, error) {
+	var meta frontMatter
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	if !strings.HasPrefix(text, "---\n") {
+		return meta, text, nil
+	}
+	rest := text[len("---\n"):]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return meta, "", fmt.Errorf("unterminated front-matter")
+	}
+	if err := yaml.Unmarshal([]byte(rest[:end]), &meta); err != nil {
+		return meta, "", fmt.Errorf("invalid front-matter: %w", err)
+	}
+
+	body := rest[end+len("\n---"):]
+	if i := strings.IndexByte(body, '\n'); i >= 0 {
+		body = body[i+1:]
+	} else {
+		body = ""
+	}
+	return meta, body, nil
+}
+
+// findRoot 跳过只包含一个目录的包裹层
+func findRoot(fsys fs.FS) (string, error) {
+	root := "."
+	for {
+		entries, err := fs.ReadDir(fsys, root)
+		if err != nil {
+			return "", err
+		}
+
+		var dirs []fs.DirEntry
+		hasMarkdown := false
+		for _, entry := range entries {
+			if isIgnored(entry.Name()) {
+				continue
+			}
+			if entry.IsDir() {
+				dirs = append(dirs, entry)
+			} else if strings.EqualFold(path.Ext(entry.Name()), ".md") {
+				hasMarkdown = true
+			}
+		}
+		if len(dirs) != 1 || hasMarkdown {
+			return root, nil
+		}
+
+		// 唯一的子目录本身包含章节目录时才视为包裹层
+		child := path.Join(root, dirs[0].Name())
+		children, err := fs.ReadDir(fsys, child)
+		if err != nil {
+			return "", err
+		}
+		wrapper := false
+		for _, entry := range children {
+			if entry.IsDir() && !isIgnored(entry.Name()) && containsMarkdown(fsys, path.Join(child, entry.Name())) {
+				wrapper = true
+				break
+			}
+		}
+		if !wrapper {
+			return root, nil
+		}
+		root = child
+	}
+}
+
+func containsMarkdown(fsys fs.FS, dir string) bool {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(path.Ext(entry.Name()), ".md") {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveRelative 解析相对链接，返回相对目录根的路径；绝对地址、锚点和越出目录根的路径返回 false
+func resolveRelative(dir, ref string) (string, bool) {
+	if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "/") || strings.Contains(ref, "://") || strings.HasPrefix(ref, "mailto:") {
+		return "", false
+	}
+	if i := strings.IndexAny(ref, "?#"); i >= 0 {
+		ref = ref[:i]
+	}
+	target := path.Clean(path.Join(dir, ref))
+	if target == ".." || strings.HasPrefix(target, "../") {
+		return "", false
+	}
+	return target, true
+}
+
+// sortEntries 按数字前缀排序，没有前缀的按名称排在后面
+func sortEntries(entries []fs.DirEntry) []fs.DirEntry {
+	sorted := append([]fs.DirEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		oi, okI := orderOf(sorted[i].Name())
+		oj, okJ := orderOf(sorted[j].Name())
+		if okI != okJ {
+			return okI
+		}
+		if okI && oi != oj {
+			return oi < oj
+		}
+		return sorted[i].Name() < sorted[j].Name()
+	})
+	return sorted
+}
+
+func orderOf(name string) (int, bool) {
+	m := orderPrefix.FindStringSubmatch(name)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	return n, err == nil
+}
+
+func stripOrderPrefix(name string) string {
+	return orderPrefix.ReplaceAllString(name, "")
+}
+
+func titleFromName(name string) string {
+	name = stripOrderPrefix(strings.TrimSuffix(name, path.Ext(name)))
+	return strings.TrimSpace(strings.NewReplacer("-", " ", "_", " ").Replace(name))
+}
+
+func slugify(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = slugInvalid.ReplaceAllString(s, "-")
+	return strings.Trim(s, "-")
+}
+
+// isIgnored 隐藏文件、以下划线开头的文件（包括 _chapter.md）和 README.md 不作为章节或课时
+func isIgnored(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || strings.EqualFold(name, "README.md")
+}
@@ -7,9 +7,13 @@ import (
 )
 
 // Answers 答案表（分支节点）
+// 学生每次提交保存为一条记录，同一任务的多次提交中只有一条 is_counted 为 true
 type Answers struct {
 	AnswerID      uint           `gorm:"primaryKey;column:answer_id" json:"answer_id"`
 	TaskID        uint           `gorm:"column:task_id;not null;index" json:"task_id"`
+	Attempt       int            `gorm:"column:attempt;default:1" json:"attempt"`  // 第几次提交，从 1 开始
+	IsCounted     bool           `gorm:"column:is_counted" json:"is_counted"`      // 是否为计入成绩的提交
+	TaskRevision  int            `gorm:"column:task_revision;default:0" json:"task_revision"` // 提交时任务的修订版本号
 	BranchID      uint           `gorm:"column:branch_id;not null;index" json:"branch_id"`
 	UserID        uint           `gorm:"column:user_id;not null;index" json:"user_id"`
 	GradedBy      *uint          `gorm:"column:graded_by;index" json:"graded_by"` // 批改老师的ID
@@ -17,6 +21,14 @@ type Answers struct {
 	Type          string         `gorm:"column:type;default:'text'" json:"type"` // text, image_url
 	Score         int            `gorm:"column:score;default:0" json:"score"`
 	IsGraded      bool           `gorm:"column:is_graded;default:false" json:"is_graded"`
+	RubricScores  string         `gorm:"column:rubric_scores;type:jsonb;default:'[]'" json:"rubric_scores"`     // 按评分标准的逐项得分，结构见 internal/rubric
+	Feedback      string         `gorm:"column:feedback;type:text" json:"feedback"`                             // 教师的文字评语
+	InlineComments string        `gorm:"column:inline_comments;type:jsonb;default:'[]'" json:"inline_comments"` // 针对作业文本片段的行内批注
+	RawScore      int            `gorm:"column:raw_score;default:0" json:"raw_score"`       // 扣除迟交分数前的得分
+	LatePenalty   int            `gorm:"column:late_penalty;default:0" json:"late_penalty"` // 迟交扣除的分数
+	IsLate        bool           `gorm:"column:is_late;default:false" json:"is_late"`       // 提交时是否已过截止时间（含延期）
+	LateDays      int            `gorm:"column:late_days;default:0" json:"late_days"`       // 迟交天数，不足一天按一天
+	AfterCutoff   bool           `gorm:"column:after_cutoff;default:false" json:"after_cutoff"` // 提交时是否已过最终截止时间
 	SubmittedAt   time.Time      `gorm:"column:submitted_at" json:"submitted_at"`
 	CreatedAt     time.Time      `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt     time.Time      `gorm:"column:updated_at" json:"updated_at"`
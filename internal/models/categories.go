@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// Categories 课程分类表（中央服务器）
+type Categories struct {
+	CategoryID   uint      `gorm:"primaryKey;column:category_id" json:"category_id"`
+	CategoryName string    `gorm:"column:category_name;not null;uniqueIndex" json:"category_name"`
+	Description  string    `gorm:"column:description;type:text" json:"description"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Categories) TableName() string {
+	return "categories"
+}
+
+// CourseTags 课程标签表（中央服务器），标签为教师自由填写的短文本
+type CourseTags struct {
+	CourseID uint   `gorm:"primaryKey;column:course_id" json:"course_id"`
+	Tag      string `gorm:"primaryKey;column:tag;index" json:"tag"`
+}
+
+// TableName 指定表名
+func (CourseTags) TableName() string {
+	return "course_tags"
+}
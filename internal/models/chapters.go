@@ -13,6 +13,10 @@ type Chapters struct {
 	ChapterTitle string         `gorm:"column:chapter_title;not null" json:"chapter_title"`
 	ChapterOrder int            `gorm:"column:chapter_order;not null" json:"chapter_order"`
 	Description  string         `gorm:"column:description;type:text" json:"description"`
+	Slug         string         `gorm:"column:slug" json:"slug,omitempty"` // Markdown 同步使用的稳定标识，课程内唯一
+	PublishStatus string        `gorm:"column:publish_status;default:'draft'" json:"publish_status"` // draft, published
+	PublishAt    *time.Time     `gorm:"column:publish_at" json:"publish_at"` // 定时发布时间
+	Revision     int            `gorm:"column:revision;default:0" json:"revision"` // 当前修订版本号
 	CreatedAt    time.Time      `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt    time.Time      `gorm:"column:updated_at" json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at;index" json:"-"`
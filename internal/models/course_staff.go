@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// CourseStaff 课程教学团队表（中央服务器）
+// 每门课程有一名主讲教师（与 courses.instructor_id 一致），可以另外添加协同教师和助教
+type CourseStaff struct {
+	StaffID      uint      `gorm:"primaryKey;column:staff_id" json:"staff_id"`
+	CourseID     uint      `gorm:"column:course_id;not null;uniqueIndex:idx_course_staff_member" json:"course_id"`
+	InstructorID uint      `gorm:"column:instructor_id;not null;uniqueIndex:idx_course_staff_member;index" json:"instructor_id"`
+	Role         string    `gorm:"column:role;not null" json:"role"` // instructor, co_instructor, ta
+	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	// 关联关系
+	Instructor *Instructors `gorm:"foreignKey:InstructorID" json:"instructor,omitempty"`
+}
+
+// TableName 指定表名
+func (CourseStaff) TableName() string {
+	return "course_staff"
+}
@@ -12,9 +12,17 @@ type Courses struct {
 	CourseTitle string         `gorm:"column:course_title;not null" json:"course_title"`
 	Description string         `gorm:"column:description;type:text" json:"description"`
 	InstructorID uint           `gorm:"column:instructor_id;not null;index" json:"instructor_id"`
+	CategoryID  *uint          `gorm:"column:category_id;index" json:"category_id"` // 课程分类，可以为空
 	StartDate   *time.Time     `gorm:"column:start_date" json:"start_date"`
-	EndDate     *time.Time     `gorm:"column:end_date" json:"end_date"`
+	EndDate     *time.Time     `gorm:"column:end_date" json:"end_date"` // 结束后课程对学生只读
+	EnrollmentStartAt *time.Time `gorm:"column:enrollment_start_at" json:"enrollment_start_at"` // 报名开始时间，为空时不限制
+	EnrollmentEndAt   *time.Time `gorm:"column:enrollment_end_at" json:"enrollment_end_at"`     // 报名截止时间，为空时不限制
+	Capacity    int            `gorm:"column:capacity;default:0" json:"capacity"` // 所有分支合计的报名人数上限，0 表示不限
+	EnrollmentCount int64      `gorm:"column:enrollment_count;->" json:"enrollment_count"` // 所有分支合计的报名人数（不含候补），只在中央服务器维护
 	Status      string         `gorm:"column:status;default:'active'" json:"status"` // active, archived
+	PublishStatus string       `gorm:"column:publish_status;default:'draft'" json:"publish_status"` // draft, published
+	PublishAt   *time.Time     `gorm:"column:publish_at" json:"publish_at"` // 定时发布时间
+	Revision    int            `gorm:"column:revision;default:0" json:"revision"` // 当前修订版本号
 	CreatedAt   time.Time      `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"column:updated_at" json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at;index" json:"-"`
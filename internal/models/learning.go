@@ -11,7 +11,7 @@ type Learning struct {
 	LearningID        uint           `gorm:"primaryKey;column:learning_id" json:"learning_id"`
 	UserID            uint           `gorm:"column:user_id;not null;index" json:"user_id"`
 	CourseID          uint           `gorm:"column:course_id;not null;index" json:"course_id"`
-	Status            string         `gorm:"column:status;default:'enrolled'" json:"status"` // enrolled, in_progress, completed
+	Status            string         `gorm:"column:status;default:'enrolled'" json:"status"` // waitlisted, enrolled, in_progress, completed
 	ProgressPercentage int           `gorm:"column:progress_percentage;default:0" json:"progress_percentage"`
 	CompletedAt       *time.Time     `gorm:"column:completed_at" json:"completed_at"`
 	CreatedAt         time.Time      `gorm:"column:created_at" json:"created_at"`
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// LessonProgress 课时完成记录表（分支节点）
+// 课程进度百分比由已完成课时和已提交任务计算，不再由学生直接填写
+type LessonProgress struct {
+	LessonProgressID uint      `gorm:"primaryKey;column:lesson_progress_id" json:"lesson_progress_id"`
+	UserID           uint      `gorm:"column:user_id;not null;uniqueIndex:idx_lesson_progress_user_lesson" json:"user_id"`
+	CourseID         uint      `gorm:"column:course_id;not null;index" json:"course_id"`
+	LessonID         uint      `gorm:"column:lesson_id;not null;uniqueIndex:idx_lesson_progress_user_lesson" json:"lesson_id"`
+	CompletedAt      time.Time `gorm:"column:completed_at" json:"completed_at"`
+	CreatedAt        time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (LessonProgress) TableName() string {
+	return "lesson_progress"
+}
@@ -15,6 +15,16 @@ type Lessons struct {
 	ContentURL  string         `gorm:"column:content_url" json:"content_url"` // OSS视频链接
 	LessonType  string         `gorm:"column:lesson_type;default:'video'" json:"lesson_type"` // video, text, quiz
 	LessonOrder int            `gorm:"column:lesson_order;not null" json:"lesson_order"`
+	Slug        string         `gorm:"column:slug" json:"slug,omitempty"` // Markdown 同步使用的稳定标识，课程内唯一
+	PublishStatus string       `gorm:"column:publish_status;default:'draft'" json:"publish_status"` // draft, published
+	PublishAt   *time.Time     `gorm:"column:publish_at" json:"publish_at"` // 定时发布时间
+	Revision    int            `gorm:"column:revision;default:0" json:"revision"` // 当前修订版本号
+	ProcessingStatus    string     `gorm:"column:processing_status;default:''" json:"processing_status"` // 视频处理状态：pending, processing, ready, failed；非本Bucket视频为空
+	ProcessingError     string     `gorm:"column:processing_error" json:"processing_error,omitempty"`
+	ProcessingStartedAt *time.Time `gorm:"column:processing_started_at" json:"processing_started_at,omitempty"`
+	Duration            int        `gorm:"column:duration;default:0" json:"duration"` // 视频时长（秒）
+	ThumbnailURL        string     `gorm:"column:thumbnail_url" json:"thumbnail_url"`
+	HLSURL              string     `gorm:"column:hls_url" json:"hls_url"` // HLS 主播放列表
 	CreatedAt   time.Time      `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"column:updated_at" json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at;index" json:"-"`
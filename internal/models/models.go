@@ -12,4 +12,16 @@ package models
 // - Answers: 答案表（分支节点）
 // - Comments: 评论表（分支节点）
 // - Learning: 学习进度表（分支节点）
-
+// - LessonProgress: 课时完成记录表（分支节点）
+// - VideoProgress: 视频观看进度表（分支节点）
+// - Revisions: 内容修订历史表（中央服务器）
+// - CoursePrerequisites: 先修课程表（中央服务器）
+// - PrerequisiteOverrides: 先修课程豁免表（中央服务器）
+// - CourseStaff: 课程教学团队表（中央服务器）
+// - Categories: 课程分类表（中央服务器）
+// - CourseTags: 课程标签表（中央服务器）
+// - SearchDocuments: 全文检索文档表（中央服务器）
+// - Uploads: 直传上传记录表（课时文件在中央服务器，作业文件在分支节点）
+// - QuizQuestions: 测验题目表（中央服务器）
+// - QuizResponses: 测验逐题作答表（分支节点）
+// - TaskExtensions: 作业延期表（中央服务器）
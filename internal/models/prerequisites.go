@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// CoursePrerequisites 先修课程表（中央服务器）
+// 学生报名课程前，需要在所在分支的学习记录中满足每一门先修课程的要求
+type CoursePrerequisites struct {
+	PrerequisiteID       uint      `gorm:"primaryKey;column:prerequisite_id" json:"prerequisite_id"`
+	CourseID             uint      `gorm:"column:course_id;not null;uniqueIndex:idx_course_prerequisites_pair" json:"course_id"`
+	PrerequisiteCourseID uint      `gorm:"column:prerequisite_course_id;not null;uniqueIndex:idx_course_prerequisites_pair;index" json:"prerequisite_course_id"`
+	MinStatus            string    `gorm:"column:min_status;not null;default:'completed'" json:"min_status"` // enrolled, in_progress, completed
+	MinScore             *int      `gorm:"column:min_score" json:"min_score"`                                // 先修课程作业得分率下限（百分比），为空时不要求
+	CreatedAt            time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt            time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CoursePrerequisites) TableName() string {
+	return "course_prerequisites"
+}
+
+// PrerequisiteOverrides 先修课程豁免表（中央服务器）
+// 教师或管理员为指定学生豁免某门课程的先修要求
+type PrerequisiteOverrides struct {
+	OverrideID        uint      `gorm:"primaryKey;column:override_id" json:"override_id"`
+	CourseID          uint      `gorm:"column:course_id;not null;uniqueIndex:idx_prerequisite_overrides_student" json:"course_id"`
+	BranchID          uint      `gorm:"column:branch_id;not null;uniqueIndex:idx_prerequisite_overrides_student" json:"branch_id"`
+	UserID            uint      `gorm:"column:user_id;not null;uniqueIndex:idx_prerequisite_overrides_student" json:"user_id"`
+	Reason            string    `gorm:"column:reason;type:text" json:"reason"`
+	GrantedByUserID   uint      `gorm:"column:granted_by_user_id" json:"granted_by_user_id"`
+	GrantedByBranchID uint      `gorm:"column:granted_by_branch_id" json:"granted_by_branch_id"`
+	CreatedAt         time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+// TableName 指定表名
+func (PrerequisiteOverrides) TableName() string {
+	return "prerequisite_overrides"
+}
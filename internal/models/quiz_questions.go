@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// QuizQuestions 测验题目表（中央服务器）
+// 选项和标准答案以 JSON 保存，结构见 internal/quiz
+type QuizQuestions struct {
+	QuestionID   uint           `gorm:"primaryKey;column:question_id" json:"question_id"`
+	TaskID       uint           `gorm:"column:task_id;not null;index" json:"task_id"`
+	Position     int            `gorm:"column:position;not null" json:"position"`
+	QuestionType string         `gorm:"column:question_type;size:30;not null" json:"question_type"` // single_choice, multiple_choice, true_false, short_answer, numeric
+	Prompt       string         `gorm:"column:prompt;type:text;not null" json:"prompt"`
+	Options      string         `gorm:"column:options;type:jsonb;default:'[]'" json:"options"`
+	AnswerKey    string         `gorm:"column:answer_key;type:jsonb;default:'{}'" json:"answer_key"`
+	Points       float64        `gorm:"column:points;default:1" json:"points"`
+	CreatedAt    time.Time      `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt    time.Time      `gorm:"column:updated_at" json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at;index" json:"-"`
+}
+
+// TableName 指定表名
+func (QuizQuestions) TableName() string {
+	return "quiz_questions"
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// QuizResponses 测验的逐题作答（分支节点），属于一份 quiz 类型的作业
+// 得分以 override_points（教师调整）优先，否则为自动评分的 auto_points
+type QuizResponses struct {
+	ResponseID     uint      `gorm:"primaryKey;column:response_id" json:"response_id"`
+	AnswerID       uint      `gorm:"column:answer_id;not null;index" json:"answer_id"`
+	TaskID         uint      `gorm:"column:task_id;not null" json:"task_id"`
+	QuestionID     uint      `gorm:"column:question_id;not null" json:"question_id"`
+	BranchID       uint      `gorm:"column:branch_id;not null" json:"branch_id"`
+	UserID         uint      `gorm:"column:user_id;not null" json:"user_id"`
+	Response       string    `gorm:"column:response;type:jsonb;not null" json:"response"`
+	MaxPoints      float64   `gorm:"column:max_points" json:"max_points"` // 提交时题目的分值
+	AutoPoints     float64   `gorm:"column:auto_points" json:"auto_points"`
+	OverridePoints *float64  `gorm:"column:override_points" json:"override_points"`
+	IsCorrect      bool      `gorm:"column:is_correct;default:false" json:"is_correct"`
+	NeedsReview    bool      `gorm:"column:needs_review;default:false" json:"needs_review"` // 简答题未匹配标准答案，等待人工批改
+	GradedBy       *uint     `gorm:"column:graded_by" json:"graded_by"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (QuizResponses) TableName() string {
+	return "quiz_responses"
+}
+
+// Points 题目的最终得分
+func (r *QuizResponses) Points() float64 {
+	if r.OverridePoints != nil {
+		return *r.OverridePoints
+	}
+	return r.AutoPoints
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Revisions 内容修订历史表（中央服务器）
+// 每次创建、修改、删除或恢复课程/章节/课时/任务时保存一份完整快照
+type Revisions struct {
+	RevisionID     uint      `gorm:"primaryKey;column:revision_id" json:"revision_id"`
+	EntityType     string    `gorm:"column:entity_type;not null;uniqueIndex:idx_revisions_entity_version" json:"entity_type"` // course, chapter, lesson, task
+	EntityID       uint      `gorm:"column:entity_id;not null;uniqueIndex:idx_revisions_entity_version" json:"entity_id"`
+	Version        int       `gorm:"column:version;not null;uniqueIndex:idx_revisions_entity_version" json:"version"`
+	CourseID       uint      `gorm:"column:course_id;not null;index" json:"course_id"`
+	Action         string    `gorm:"column:action;not null" json:"action"` // create, update, delete, restore
+	Snapshot       string    `gorm:"column:snapshot;type:jsonb;not null" json:"snapshot"`
+	AuthorUserID   uint      `gorm:"column:author_user_id" json:"author_user_id"`
+	AuthorBranchID uint      `gorm:"column:author_branch_id" json:"author_branch_id"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+// TableName 指定表名
+func (Revisions) TableName() string {
+	return "revisions"
+}
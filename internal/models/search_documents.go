@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// SearchDocuments 全文检索文档表（中央服务器）
+// 每个课程、章节、课时和任务对应一行，search_vector 为 tsvector 列（GIN 索引），由服务在写入内容时维护
+type SearchDocuments struct {
+	DocumentID uint      `gorm:"primaryKey;column:document_id" json:"document_id"`
+	EntityType string    `gorm:"column:entity_type;not null;uniqueIndex:idx_search_documents_entity" json:"entity_type"` // course, chapter, lesson, task
+	EntityID   uint      `gorm:"column:entity_id;not null;uniqueIndex:idx_search_documents_entity" json:"entity_id"`
+	CourseID   uint      `gorm:"column:course_id;not null;index" json:"course_id"`
+	Title      string    `gorm:"column:title" json:"title"`
+	Body       string    `gorm:"column:body;type:text" json:"body"`
+	UpdatedAt  time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SearchDocuments) TableName() string {
+	return "search_documents"
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// TaskExtensions 作业延期表（中央服务器）
+// 教师为指定学生延长任务的截止时间，未设置的时间点沿用任务的设置
+type TaskExtensions struct {
+	ExtensionID       uint       `gorm:"primaryKey;column:extension_id" json:"extension_id"`
+	TaskID            uint       `gorm:"column:task_id;not null;uniqueIndex:idx_task_extensions_student" json:"task_id"`
+	BranchID          uint       `gorm:"column:branch_id;not null;uniqueIndex:idx_task_extensions_student" json:"branch_id"`
+	UserID            uint       `gorm:"column:user_id;not null;uniqueIndex:idx_task_extensions_student" json:"user_id"`
+	DueAt             *time.Time `gorm:"column:due_at" json:"due_at"`
+	CutoffAt          *time.Time `gorm:"column:cutoff_at" json:"cutoff_at"`
+	Reason            string     `gorm:"column:reason;type:text" json:"reason"`
+	GrantedByUserID   uint       `gorm:"column:granted_by_user_id" json:"granted_by_user_id"`
+	GrantedByBranchID uint       `gorm:"column:granted_by_branch_id" json:"granted_by_branch_id"`
+	CreatedAt         time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (TaskExtensions) TableName() string {
+	return "task_extensions"
+}
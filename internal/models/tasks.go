@@ -14,6 +14,17 @@ type Tasks struct {
 	Description string         `gorm:"column:description;type:text" json:"description"`
 	TaskType    string         `gorm:"column:task_type;default:'essay'" json:"task_type"` // essay, quiz, upload
 	MaxScore    int            `gorm:"column:max_score;default:100" json:"max_score"`
+	AllowedTypes string        `gorm:"column:allowed_types;default:''" json:"allowed_types"` // 作业文件允许的类型（逗号分隔），为空时使用全局配置
+	MaxFileSize  int64         `gorm:"column:max_file_size;default:0" json:"max_file_size"`  // 作业文件的大小上限（字节），为 0 时使用全局配置
+	DueAt              *time.Time `gorm:"column:due_at" json:"due_at"`                                     // 截止时间，之后提交记为迟交
+	CutoffAt           *time.Time `gorm:"column:cutoff_at" json:"cutoff_at"`                               // 最终截止时间，之后按迟交策略拒绝提交或计 0 分
+	LatePolicy         string     `gorm:"column:late_policy;default:''" json:"late_policy"`                   // 迟交策略：空（不扣分）, percent_per_day, zero_after_cutoff
+	LatePenaltyPercent int        `gorm:"column:late_penalty_percent;default:0" json:"late_penalty_percent"` // percent_per_day 时每迟交一天扣除的百分比
+	Rubric             string     `gorm:"column:rubric;type:jsonb;default:'[]'" json:"rubric"`                // 评分标准（评分项和等级），结构见 internal/rubric
+	MaxAttempts        int        `gorm:"column:max_attempts;default:0" json:"max_attempts"`                   // 最大提交次数，为 0 时不限制
+	AttemptPolicy      string     `gorm:"column:attempt_policy;default:'latest'" json:"attempt_policy"`       // 计入成绩的提交：latest, highest, first
+	Slug        string         `gorm:"column:slug" json:"slug,omitempty"` // Markdown 同步使用的稳定标识，课时内唯一
+	Revision    int            `gorm:"column:revision;default:0" json:"revision"` // 当前修订版本号
 	CreatedAt   time.Time      `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"column:updated_at" json:"updated_at"`
 	DeletedAt   gorm.DeletedAt  `gorm:"column:deleted_at;index" json:"-"`
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// Uploads 直传对象存储的上传记录
+// 课时文件的上传记录在中央服务器，作业文件的上传记录在学生所在的分支节点
+// 状态流转：pending（等待上传）-> completed（已校验）-> attached（已关联到课时或作业）；校验失败为 rejected，取消为 aborted
+type Uploads struct {
+	UploadID        uint       `gorm:"primaryKey;column:upload_id" json:"upload_id"`
+	Purpose         string     `gorm:"column:purpose;size:20;not null" json:"purpose"` // lesson, answer
+	UserID          uint       `gorm:"column:user_id;not null" json:"user_id"`
+	BranchID        uint       `gorm:"column:branch_id;not null" json:"branch_id"`
+	CourseID        uint       `gorm:"column:course_id" json:"course_id"`
+	ChapterID       uint       `gorm:"column:chapter_id" json:"chapter_id"`
+	TaskID          uint       `gorm:"column:task_id" json:"task_id"`
+	ObjectKey       string     `gorm:"column:object_key;size:500;not null" json:"-"`
+	FileName        string     `gorm:"column:file_name;size:255" json:"file_name"`
+	ContentType     string     `gorm:"column:content_type;size:100" json:"content_type"`
+	Size            int64      `gorm:"column:size" json:"size"`
+	SHA256          string     `gorm:"column:sha256;size:64" json:"sha256"`
+	PartSize        int64      `gorm:"column:part_size" json:"part_size"`
+	PartCount       int        `gorm:"column:part_count" json:"part_count"`
+	StorageUploadID string     `gorm:"column:storage_upload_id;size:255" json:"-"` // 对象存储的分片上传ID，单次上传时为空
+	Status          string     `gorm:"column:status;size:20;default:pending" json:"status"`
+	ExpiresAt       time.Time  `gorm:"column:expires_at" json:"expires_at"`
+	CompletedAt     *time.Time `gorm:"column:completed_at" json:"completed_at"`
+	CreatedAt       time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Uploads) TableName() string {
+	return "uploads"
+}
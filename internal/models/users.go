@@ -15,7 +15,7 @@ type Users struct {
 	PasswordHash string        `gorm:"column:password_hash;not null" json:"-"`
 	FirstName   string         `gorm:"column:first_name" json:"first_name"`
 	LastName    string         `gorm:"column:last_name" json:"last_name"`
-	Role        string         `gorm:"column:role;not null;default:'student'" json:"role"` // student, teacher
+	Role        string         `gorm:"column:role;not null;default:'student'" json:"role"` // student, teacher, admin
 	Status      string         `gorm:"column:status;default:'active'" json:"status"`
 	CreatedAt   time.Time      `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"column:updated_at" json:"updated_at"`
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// VideoProgress 视频观看进度表（分支节点）
+// Intervals 为合并后的已观看区间（JSON 数组，单位秒），重复观看的部分只计一次
+type VideoProgress struct {
+	VideoProgressID uint       `gorm:"primaryKey;column:video_progress_id" json:"video_progress_id"`
+	UserID          uint       `gorm:"column:user_id;not null;uniqueIndex:idx_video_progress_user_lesson" json:"user_id"`
+	CourseID        uint       `gorm:"column:course_id;not null;index" json:"course_id"`
+	LessonID        uint       `gorm:"column:lesson_id;not null;uniqueIndex:idx_video_progress_user_lesson" json:"lesson_id"`
+	Position        float64    `gorm:"column:position;default:0" json:"position"` // 上次播放位置，用于续播
+	Duration        float64    `gorm:"column:duration;default:0" json:"duration"` // 视频时长
+	WatchedSeconds  float64    `gorm:"column:watched_seconds;default:0" json:"watched_seconds"`
+	Intervals       string     `gorm:"column:intervals;type:text" json:"-"`
+	CompletedAt     *time.Time `gorm:"column:completed_at" json:"completed_at"` // 观看比例达到要求的时间
+	CreatedAt       time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (VideoProgress) TableName() string {
+	return "video_progress"
+}
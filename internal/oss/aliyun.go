@@ -0,0 +1,180 @@
+package oss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+
+	"online-learning-platform/internal/config"
+)
+
+// aliyunStorage 阿里云 OSS
+type aliyunStorage struct {
+	client     *oss.Client
+	bucket     *oss.Bucket
+	bucketName string
+}
+
+func newAliyunStorage(cfg config.OSSConfig) (*aliyunStorage, error) {
+	if cfg.Endpoint == "" || cfg.AccessKeyID == "" || cfg.AccessKeySecret == "" || cfg.BucketName == "" {
+		return nil, fmt.Errorf("invalid OSS config, please check endpoint/accessKey/bucket")
+	}
+
+	client, err := oss.New(cfg.Endpoint, cfg.AccessKeyID, cfg.AccessKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OSS client: %w", err)
+	}
+
+	bucket, err := client.Bucket(cfg.BucketName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bucket %s: %w", cfg.BucketName, err)
+	}
+
+	return &aliyunStorage{
+		client:     client,
+		bucket:     bucket,
+		bucketName: cfg.BucketName,
+	}, nil
+}
+
+func (s *aliyunStorage) Put(ctx context.Context, objectKey string, reader io.Reader, size int64) error {
+	return s.bucket.PutObject(objectKey, reader, oss.ObjectACL(oss.ACLPrivate), oss.WithContext(ctx))
+}
+
+func (s *aliyunStorage) Get(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	body, err := s.bucket.GetObject(objectKey, oss.WithContext(ctx))
+	if err != nil {
+		return nil, aliyunError(err)
+	}
+	return body, nil
+}
+
+func (s *aliyunStorage) Delete(ctx context.Context, objectKey string) error {
+	return s.bucket.DeleteObject(objectKey, oss.WithContext(ctx))
+}
+
+func (s *aliyunStorage) Copy(ctx context.Context, srcKey, destKey string) error {
+	_, err := s.bucket.CopyObject(srcKey, destKey, oss.ObjectACL(oss.ACLPrivate), oss.WithContext(ctx))
+	return aliyunError(err)
+}
+
+func (s *aliyunStorage) Stat(ctx context.Context, objectKey string) (*ObjectInfo, error) {
+	header, err := s.bucket.GetObjectDetailedMeta(objectKey, oss.WithContext(ctx))
+	if err != nil {
+		return nil, aliyunError(err)
+	}
+	return objectInfoFromHeader(objectKey, header), nil
+}
+
+func (s *aliyunStorage) SignURL(objectKey, method string, expire time.Duration) (string, error) {
+	return s.bucket.SignURL(objectKey, oss.HTTPMethod(method), int64(expire.Seconds()))
+}
+
+func (s *aliyunStorage) ObjectURL(objectKey string) string {
+	return fmt.Sprintf("https://%s.%s/%s", s.bucketName, s.client.Config.Endpoint, objectKey)
+}
+
+func (s *aliyunStorage) List(ctx context.Context, prefix, marker string, maxKeys int) ([]ObjectInfo, string, error) {
+	result, err := s.bucket.ListObjects(oss.Prefix(prefix), oss.Marker(marker), oss.MaxKeys(maxKeys), oss.WithContext(ctx))
+	if err != nil {
+		return nil, "", err
+	}
+	objects := make([]ObjectInfo, 0, len(result.Objects))
+	for _, o := range result.Objects {
+		objects = append(objects, ObjectInfo{Key: o.Key, Size: o.Size, ETag: o.ETag, LastModified: o.LastModified})
+	}
+	if !result.IsTruncated || len(objects) == 0 {
+		return objects, "", nil
+	}
+	next := result.NextMarker
+	if next == "" {
+		next = objects[len(objects)-1].Key
+	}
+	return objects, next, nil
+}
+
+func (s *aliyunStorage) SignPutURL(objectKey, contentType string, expire time.Duration) (string, error) {
+	// OSS 的 URL 签名包含 Content-Type，上传时必须带上相同的请求头
+	return s.bucket.SignURL(objectKey, oss.HTTPPut, int64(expire.Seconds()), oss.ContentType(contentType))
+}
+
+func (s *aliyunStorage) InitMultipart(ctx context.Context, objectKey, contentType string) (string, error) {
+	imur, err := s.bucket.InitiateMultipartUpload(objectKey,
+		oss.ObjectACL(oss.ACLPrivate), oss.ContentType(contentType), oss.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	return imur.UploadID, nil
+}
+
+func (s *aliyunStorage) SignPartURL(objectKey, uploadID string, partNumber int, expire time.Duration) (string, error) {
+	return s.bucket.SignURL(objectKey, oss.HTTPPut, int64(expire.Seconds()),
+		oss.AddParam("partNumber", strconv.Itoa(partNumber)), oss.AddParam("uploadId", uploadID))
+}
+
+func (s *aliyunStorage) ListParts(ctx context.Context, objectKey, uploadID string) ([]Part, error) {
+	result, err := s.bucket.ListUploadedParts(s.multipart(objectKey, uploadID), oss.WithContext(ctx))
+	if err != nil {
+		return nil, aliyunError(err)
+	}
+	parts := make([]Part, 0, len(result.UploadedParts))
+	for _, p := range result.UploadedParts {
+		parts = append(parts, Part{PartNumber: p.PartNumber, Size: int64(p.Size), ETag: p.ETag})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+func (s *aliyunStorage) CompleteMultipart(ctx context.Context, objectKey, uploadID string, parts []Part) error {
+	uploadParts := make([]oss.UploadPart, 0, len(parts))
+	for _, p := range parts {
+		uploadParts = append(uploadParts, oss.UploadPart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	_, err := s.bucket.CompleteMultipartUpload(s.multipart(objectKey, uploadID), uploadParts, oss.WithContext(ctx))
+	return aliyunError(err)
+}
+
+func (s *aliyunStorage) AbortMultipart(ctx context.Context, objectKey, uploadID string) error {
+	err := aliyunError(s.bucket.AbortMultipartUpload(s.multipart(objectKey, uploadID), oss.WithContext(ctx)))
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *aliyunStorage) multipart(objectKey, uploadID string) oss.InitiateMultipartUploadResult {
+	return oss.InitiateMultipartUploadResult{Bucket: s.bucketName, Key: objectKey, UploadID: uploadID}
+}
+
+// aliyunError 将 404 转换为 ErrObjectNotFound
+func aliyunError(err error) error {
+	var serviceErr oss.ServiceError
+	if errors.As(err, &serviceErr) && serviceErr.StatusCode == http.StatusNotFound {
+		return ErrObjectNotFound
+	}
+	return err
+}
+
+// objectInfoFromHeader 从 HEAD 响应头读取对象元信息（OSS 和 S3 的响应头相同）
+func objectInfoFromHeader(objectKey string, header http.Header) *ObjectInfo {
+	info := &ObjectInfo{
+		Key:         objectKey,
+		Size:        -1,
+		ContentType: header.Get("Content-Type"),
+		ETag:        header.Get("ETag"),
+	}
+	if size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
+		info.Size = size
+	}
+	if modified, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		info.LastModified = modified
+	}
+	return info
+}
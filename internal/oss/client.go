@@ -2,87 +2,144 @@ package oss
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"os"
+	"strings"
 	"time"
 
-	"github.com/aliyun/aliyun-oss-go-sdk/oss"
-
 	"online-learning-platform/internal/config"
 )
 
-// Client 封装OSS客户端
-type Client struct {
-	client     *oss.Client
-	bucket     *oss.Bucket
-	bucketName string
+// 存储后端，由 config.OSSConfig.Provider 选择
+const (
+	ProviderAliyun = "aliyun" // 阿里云 OSS（默认）
+	ProviderS3     = "s3"     // S3 兼容存储，例如 MinIO
+	ProviderLocal  = "local"  // 本地目录，签名URL由应用自身提供下载
+)
+
+// listPageSize 列举对象时每页的数量（OSS 和 S3 的上限）
+const listPageSize = 1000
+
+// ErrObjectNotFound 对象不存在
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo 对象元信息
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
 }
 
-var defaultClient *Client
+// Part 已上传的分片
+type Part struct {
+	PartNumber int
+	Size       int64
+	ETag       string
+}
 
-// InitOSSClient 初始化OSS客户端
-func InitOSSClient(cfg config.OSSConfig) error {
-	if cfg.Endpoint == "" || cfg.AccessKeyID == "" || cfg.AccessKeySecret == "" || cfg.BucketName == "" {
-		return fmt.Errorf("invalid OSS config, please check endpoint/accessKey/bucket")
-	}
+// Storage 对象存储接口，所有对象均为私有，通过签名URL访问
+type Storage interface {
+	// Put 上传对象，size 未知时传 -1
+	Put(ctx context.Context, objectKey string, reader io.Reader, size int64) error
+	// Get 读取对象内容，调用方负责关闭返回的数据流
+	Get(ctx context.Context, objectKey string) (io.ReadCloser, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, objectKey string) error
+	// Copy 在同一存储内复制对象
+	Copy(ctx context.Context, srcKey, destKey string) error
+	// Stat 返回对象元信息，对象不存在时返回 ErrObjectNotFound
+	Stat(ctx context.Context, objectKey string) (*ObjectInfo, error)
+	// SignURL 生成指定 HTTP 方法的签名URL
+	SignURL(objectKey, method string, expire time.Duration) (string, error)
+	// ObjectURL 返回对象的地址（不带签名，保存在数据库中用于定位对象）
+	ObjectURL(objectKey string) string
+	// List 按Key顺序列出前缀下 marker 之后的对象，最多 maxKeys 个；next 为空表示已经列完
+	List(ctx context.Context, prefix, marker string, maxKeys int) (objects []ObjectInfo, next string, err error)
 
-	client, err := oss.New(cfg.Endpoint, cfg.AccessKeyID, cfg.AccessKeySecret)
-	if err != nil {
-		return fmt.Errorf("failed to create OSS client: %w", err)
-	}
+	// SignPutURL 生成直传对象的签名PUT地址，客户端上传时需带上相同的 Content-Type 请求头
+	SignPutURL(objectKey, contentType string, expire time.Duration) (string, error)
+	// InitMultipart 初始化分片上传，返回上传ID
+	InitMultipart(ctx context.Context, objectKey, contentType string) (string, error)
+	// SignPartURL 生成上传指定分片的签名PUT地址，上传分片时不带 Content-Type 请求头
+	SignPartURL(objectKey, uploadID string, partNumber int, expire time.Duration) (string, error)
+	// ListParts 列出已上传的分片（按分片号排序），用于断点续传和合并
+	ListParts(ctx context.Context, objectKey, uploadID string) ([]Part, error)
+	// CompleteMultipart 按分片号顺序合并分片
+	CompleteMultipart(ctx context.Context, objectKey, uploadID string, parts []Part) error
+	// AbortMultipart 取消分片上传并清理已上传的分片
+	AbortMultipart(ctx context.Context, objectKey, uploadID string) error
+}
+
+var defaultStorage Storage
 
-	bucket, err := client.Bucket(cfg.BucketName)
+// InitOSSClient 按配置初始化对象存储
+func InitOSSClient(cfg config.OSSConfig) error {
+	var (
+		storage Storage
+		err     error
+	)
+	switch cfg.Provider {
+	case "", ProviderAliyun:
+		storage, err = newAliyunStorage(cfg)
+	case ProviderS3:
+		storage, err = newS3Storage(cfg)
+	case ProviderLocal:
+		storage, err = newLocalStorage(cfg)
+	default:
+		err = fmt.Errorf("unknown OSS provider %q", cfg.Provider)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to get bucket %s: %w", cfg.BucketName, err)
+		return err
 	}
 
-	defaultClient = &Client{
-		client:     client,
-		bucket:     bucket,
-		bucketName: cfg.BucketName,
-	}
+	defaultStorage = storage
 	return nil
 }
 
-// GetClient 获取默认客户端
-func GetClient() (*Client, error) {
-	if defaultClient == nil {
+// GetStorage 获取当前的存储后端
+func GetStorage() (Storage, error) {
+	if defaultStorage == nil {
 		return nil, fmt.Errorf("OSS client not initialized")
 	}
-	return defaultClient, nil
+	return defaultStorage, nil
 }
 
-// UploadReader 上传数据流
-func UploadReader(ctx context.Context, objectKey string, reader io.Reader, options ...oss.Option) (string, error) {
-	client, err := GetClient()
+// UploadReader 上传数据流，返回对象地址；对象为私有读写，需要通过签名URL访问
+func UploadReader(ctx context.Context, objectKey string, reader io.Reader) (string, error) {
+	storage, err := GetStorage()
 	if err != nil {
 		return "", err
 	}
 
-	if err := client.bucket.PutObject(objectKey, reader, options...); err != nil {
+	if err := storage.Put(ctx, objectKey, reader, readerSize(reader)); err != nil {
 		return "", fmt.Errorf("failed to upload object %s: %w", objectKey, err)
 	}
-
-	return fmt.Sprintf("https://%s.%s/%s", client.bucketName, client.client.Config.Endpoint, objectKey), nil
+	return storage.ObjectURL(objectKey), nil
 }
 
-// UploadFile 上传本地文件
-func UploadFile(ctx context.Context, objectKey, filePath string, options ...oss.Option) (string, error) {
-	client, err := GetClient()
+// UploadFile 上传本地文件，返回对象地址
+func UploadFile(ctx context.Context, objectKey, filePath string) (string, error) {
+	f, err := os.Open(filePath)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to open file %s: %w", filePath, err)
 	}
+	defer f.Close()
 
-	if err := client.bucket.PutObjectFromFile(objectKey, filePath, options...); err != nil {
-		return "", fmt.Errorf("failed to upload file %s to object %s: %w", filePath, objectKey, err)
+	url, err := UploadReader(ctx, objectKey, f)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload file %s: %w", filePath, err)
 	}
-
-	return fmt.Sprintf("https://%s.%s/%s", client.bucketName, client.client.Config.Endpoint, objectKey), nil
+	return url, nil
 }
 
-// GenerateSignedURL 生成签名URL
-func GenerateSignedURL(objectKey string, expire time.Duration, httpMethod oss.HTTPMethod, options ...oss.Option) (string, error) {
-	client, err := GetClient()
+// GenerateSignedURL 生成签名URL，expire 不大于 0 时有效期为 15 分钟
+func GenerateSignedURL(objectKey string, expire time.Duration, httpMethod string) (string, error) {
+	storage, err := GetStorage()
 	if err != nil {
 		return "", err
 	}
@@ -91,23 +148,186 @@ func GenerateSignedURL(objectKey string, expire time.Duration, httpMethod oss.HT
 		expire = 15 * time.Minute
 	}
 
-	signedURL, err := client.bucket.SignURL(objectKey, httpMethod, int64(expire.Seconds()), options...)
+	signedURL, err := storage.SignURL(objectKey, httpMethod, expire)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign url for object %s: %w", objectKey, err)
 	}
-
 	return signedURL, nil
 }
 
+// SignObjectURL 为本存储的对象地址生成GET签名URL，不是本存储的地址（外部链接）时原样返回
+func SignObjectURL(objectURL string, expire time.Duration) (string, error) {
+	objectKey, ok := ObjectKeyFromURL(objectURL)
+	if !ok {
+		return objectURL, nil
+	}
+	return GenerateSignedURL(objectKey, expire, http.MethodGet)
+}
+
+// SignEmbeddedURLs 将文本中 scope 前缀下的本存储对象地址替换为GET签名URL
+// 其他本存储地址原样保留（私有对象无法直接访问），外部链接不受影响
+func SignEmbeddedURLs(text, scope string, expire time.Duration) (string, error) {
+	prefix, err := ObjectURL("")
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(text, prefix)
+		if i < 0 {
+			b.WriteString(text)
+			break
+		}
+		end := i + len(prefix)
+		for end < len(text) && !strings.ContainsRune(" \t\r\n)\"'<>", rune(text[end])) {
+			end++
+		}
+		signed := text[i:end]
+		if key, ok := ObjectKeyFromURL(signed); ok && strings.HasPrefix(key, scope) {
+			if signed, err = GenerateSignedURL(key, expire, http.MethodGet); err != nil {
+				return "", err
+			}
+		}
+		b.WriteString(text[:i])
+		b.WriteString(signed)
+		text = text[end:]
+	}
+	return b.String(), nil
+}
+
+// GetObject 读取对象内容，调用方负责关闭返回的数据流
+func GetObject(objectKey string) (io.ReadCloser, error) {
+	storage, err := GetStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := storage.Get(context.Background(), objectKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", objectKey, err)
+	}
+	return body, nil
+}
+
 // DeleteObject 删除对象
 func DeleteObject(objectKey string) error {
-	client, err := GetClient()
+	storage, err := GetStorage()
 	if err != nil {
 		return err
 	}
 
-	if err := client.bucket.DeleteObject(objectKey); err != nil {
+	if err := storage.Delete(context.Background(), objectKey); err != nil {
 		return fmt.Errorf("failed to delete object %s: %w", objectKey, err)
 	}
 	return nil
 }
+
+// CopyObject 在同一个存储内复制对象，返回新对象的地址
+func CopyObject(srcKey, destKey string) (string, error) {
+	storage, err := GetStorage()
+	if err != nil {
+		return "", err
+	}
+
+	if err := storage.Copy(context.Background(), srcKey, destKey); err != nil {
+		return "", fmt.Errorf("failed to copy object %s to %s: %w", srcKey, destKey, err)
+	}
+	return storage.ObjectURL(destKey), nil
+}
+
+// StatObject 获取对象元信息，对象不存在时返回 ErrObjectNotFound
+func StatObject(objectKey string) (*ObjectInfo, error) {
+	storage, err := GetStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := storage.Stat(context.Background(), objectKey)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to stat object %s: %w", objectKey, err)
+	}
+	return info, nil
+}
+
+// ObjectExists 判断对象是否存在
+func ObjectExists(objectKey string) (bool, error) {
+	_, err := StatObject(objectKey)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, ErrObjectNotFound) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check object %s: %w", objectKey, err)
+}
+
+// ListObjects 分页列出前缀下的全部对象，fn 返回错误时停止
+func ListObjects(prefix string, fn func(ObjectInfo) error) error {
+	storage, err := GetStorage()
+	if err != nil {
+		return err
+	}
+
+	marker := ""
+	for {
+		objects, next, err := storage.List(context.Background(), prefix, marker, listPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to list objects under %s: %w", prefix, err)
+		}
+		for _, object := range objects {
+			if err := fn(object); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		marker = next
+	}
+}
+
+// ObjectURL 返回对象的地址（不检查对象是否存在）
+func ObjectURL(objectKey string) (string, error) {
+	storage, err := GetStorage()
+	if err != nil {
+		return "", err
+	}
+	return storage.ObjectURL(objectKey), nil
+}
+
+// ObjectKeyFromURL 从本存储的对象地址中解析对象Key，不是本存储的地址时返回false
+func ObjectKeyFromURL(objectURL string) (string, bool) {
+	storage, err := GetStorage()
+	if err != nil {
+		return "", false
+	}
+
+	prefix := storage.ObjectURL("")
+	if !strings.HasPrefix(objectURL, prefix) || len(objectURL) == len(prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(objectURL, prefix), true
+}
+
+// readerSize 返回已知长度的数据流剩余字节数，未知时返回 -1
+func readerSize(reader io.Reader) int64 {
+	switch r := reader.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	}
+	return -1
+}
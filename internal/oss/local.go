@@ -0,0 +1,447 @@
+package oss
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"online-learning-platform/internal/config"
+)
+
+const (
+	// localFilesPrefix local 后端对象的访问路径前缀，由 LocalHandler 处理
+	localFilesPrefix = "/files/"
+	// localUploadsDir 分片上传的临时目录，不能作为对象Key使用
+	localUploadsDir = ".uploads"
+)
+
+// localStorage 本地目录存储，用于开发环境和没有对象存储的部署
+// 对象地址为 {public_url}/files/{key}，访问时必须携带 expires 和 signature 参数
+type localStorage struct {
+	root      string
+	publicURL string
+	secret    []byte
+}
+
+func newLocalStorage(cfg config.OSSConfig) (*localStorage, error) {
+	if cfg.LocalDir == "" || cfg.PublicURL == "" {
+		return nil, fmt.Errorf("invalid OSS config, local provider requires local_dir and public_url")
+	}
+	if cfg.AccessKeySecret == "" {
+		return nil, fmt.Errorf("invalid OSS config, local provider requires access_key_secret for signing")
+	}
+
+	root, err := filepath.Abs(cfg.LocalDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local_dir %s: %w", cfg.LocalDir, err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create local_dir %s: %w", root, err)
+	}
+
+	return &localStorage{
+		root:      root,
+		publicURL: strings.TrimRight(cfg.PublicURL, "/"),
+		secret:    []byte(cfg.AccessKeySecret),
+	}, nil
+}
+
+func (s *localStorage) Put(ctx context.Context, objectKey string, reader io.Reader, size int64) error {
+	dest, err := s.path(objectKey)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，读取方不会看到写了一半的对象
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, reader); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+func (s *localStorage) Get(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	p, err := s.path(objectKey)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, localError(err)
+	}
+	return f, nil
+}
+
+func (s *localStorage) Delete(ctx context.Context, objectKey string) error {
+	p, err := s.path(objectKey)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *localStorage) Copy(ctx context.Context, srcKey, destKey string) error {
+	src, err := s.Get(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return s.Put(ctx, destKey, src, -1)
+}
+
+func (s *localStorage) Stat(ctx context.Context, objectKey string) (*ObjectInfo, error) {
+	p, err := s.path(objectKey)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, localError(err)
+	}
+	if fi.IsDir() {
+		return nil, ErrObjectNotFound
+	}
+	return &ObjectInfo{
+		Key:          objectKey,
+		Size:         fi.Size(),
+		ContentType:  mime.TypeByExtension(path.Ext(objectKey)),
+		ETag:         fmt.Sprintf("\"%x-%x\"", fi.ModTime().UnixNano(), fi.Size()),
+		LastModified: fi.ModTime(),
+	}, nil
+}
+
+func (s *localStorage) SignURL(objectKey, method string, expire time.Duration) (string, error) {
+	if _, err := s.path(objectKey); err != nil {
+		return "", err
+	}
+	return s.signedURL(method, objectKey, url.Values{}, expire), nil
+}
+
+func (s *localStorage) SignPutURL(objectKey, contentType string, expire time.Duration) (string, error) {
+	return s.SignURL(objectKey, http.MethodPut, expire)
+}
+
+func (s *localStorage) InitMultipart(ctx context.Context, objectKey, contentType string) (string, error) {
+	if _, err := s.path(objectKey); err != nil {
+		return "", err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	uploadID := hex.EncodeToString(id)
+	if err := os.MkdirAll(s.partsDir(uploadID), 0o755); err != nil {
+		return "", err
+	}
+	return uploadID, nil
+}
+
+func (s *localStorage) SignPartURL(objectKey, uploadID string, partNumber int, expire time.Duration) (string, error) {
+	if _, err := s.path(objectKey); err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	query.Set("uploadId", uploadID)
+	query.Set("partNumber", strconv.Itoa(partNumber))
+	return s.signedURL(http.MethodPut, objectKey, query, expire), nil
+}
+
+func (s *localStorage) ListParts(ctx context.Context, objectKey, uploadID string) ([]Part, error) {
+	if !validUploadID(uploadID) {
+		return nil, ErrObjectNotFound
+	}
+	entries, err := os.ReadDir(s.partsDir(uploadID))
+	if err != nil {
+		return nil, localError(err)
+	}
+
+	var parts []Part
+	for _, entry := range entries {
+		n, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue // 写入中的临时文件
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, Part{
+			PartNumber: n,
+			Size:       fi.Size(),
+			ETag:       fmt.Sprintf("\"%x-%x\"", fi.ModTime().UnixNano(), fi.Size()),
+		})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+func (s *localStorage) CompleteMultipart(ctx context.Context, objectKey, uploadID string, parts []Part) error {
+	if !validUploadID(uploadID) {
+		return ErrObjectNotFound
+	}
+	dir := s.partsDir(uploadID)
+
+	readers := make([]io.Reader, 0, len(parts))
+	for _, p := range parts {
+		f, err := os.Open(filepath.Join(dir, strconv.Itoa(p.PartNumber)))
+		if err != nil {
+			return localError(err)
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+	if err := s.Put(ctx, objectKey, io.MultiReader(readers...), -1); err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+func (s *localStorage) AbortMultipart(ctx context.Context, objectKey, uploadID string) error {
+	if !validUploadID(uploadID) {
+		return nil
+	}
+	return os.RemoveAll(s.partsDir(uploadID))
+}
+
+func (s *localStorage) List(ctx context.Context, prefix, marker string, maxKeys int) ([]ObjectInfo, string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// 跳过分片目录和写入中的临时文件
+		if strings.HasPrefix(d.Name(), ".") && p != s.root {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) && key > marker {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Strings(keys)
+
+	next := ""
+	if len(keys) > maxKeys {
+		keys = keys[:maxKeys]
+		next = keys[maxKeys-1]
+	}
+	objects := make([]ObjectInfo, 0, len(keys))
+	for _, key := range keys {
+		info, err := s.Stat(ctx, key)
+		if err != nil {
+			if errors.Is(err, ErrObjectNotFound) {
+				continue
+			}
+			return nil, "", err
+		}
+		objects = append(objects, *info)
+	}
+	return objects, next, nil
+}
+
+func (s *localStorage) ObjectURL(objectKey string) string {
+	return s.publicURL + localFilesPrefix + objectKey
+}
+
+// signedURL 生成带 expires 和 signature 参数的地址，query 中的其他参数一并签名
+func (s *localStorage) signedURL(method, objectKey string, query url.Values, expire time.Duration) string {
+	expires := time.Now().Add(expire).Unix()
+	query.Set("signature", s.signature(method, localResource(objectKey, query), expires))
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	return s.ObjectURL(objectKey) + "?" + query.Encode()
+}
+
+// partsDir 分片上传的临时目录
+func (s *localStorage) partsDir(uploadID string) string {
+	return filepath.Join(s.root, localUploadsDir, uploadID)
+}
+
+// writePart 保存一个分片
+func (s *localStorage) writePart(uploadID, partNumber string, reader io.Reader) error {
+	n, err := strconv.Atoi(partNumber)
+	if err != nil || n < 1 || !validUploadID(uploadID) {
+		return fmt.Errorf("invalid part %s of upload %s", partNumber, uploadID)
+	}
+	dir := s.partsDir(uploadID)
+	if _, err := os.Stat(dir); err != nil {
+		return localError(err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".part-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, reader); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, strconv.Itoa(n)))
+}
+
+// path 返回对象在本地目录中的路径，拒绝跳出根目录的Key
+func (s *localStorage) path(objectKey string) (string, error) {
+	if objectKey == "" || strings.HasPrefix(objectKey, "/") || strings.Contains(objectKey, "\\") {
+		return "", fmt.Errorf("invalid object key %q", objectKey)
+	}
+	cleaned := path.Clean(objectKey)
+	if cleaned != objectKey || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") ||
+		strings.SplitN(cleaned, "/", 2)[0] == localUploadsDir {
+		return "", fmt.Errorf("invalid object key %q", objectKey)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
+
+// signature 对方法、资源和过期时间做 HMAC-SHA256
+func (s *localStorage) signature(method, resource string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d", method, resource, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify 校验签名和有效期，HEAD 请求使用 GET 的签名
+func (s *localStorage) verify(method, objectKey string, query url.Values) bool {
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	expected := s.signature(method, localResource(objectKey, query), expires)
+	return hmac.Equal([]byte(expected), []byte(query.Get("signature")))
+}
+
+// ServeHTTP 处理签名URL的下载和上传，请求路径为对象Key（已去掉 /files/ 前缀）
+func (s *localStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	objectKey := r.URL.Path
+	if !s.verify(r.Method, objectKey, r.URL.Query()) {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		p, err := s.path(objectKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil || fi.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		// 按扩展名确定类型，不根据内容猜测，避免上传的文件被当作网页打开
+		contentType := mime.TypeByExtension(path.Ext(objectKey))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "private")
+		http.ServeContent(w, r, path.Base(objectKey), fi.ModTime(), f)
+	case http.MethodPut:
+		put := func() error { return s.Put(r.Context(), objectKey, r.Body, r.ContentLength) }
+		if uploadID := r.URL.Query().Get("uploadId"); uploadID != "" {
+			put = func() error { return s.writePart(uploadID, r.URL.Query().Get("partNumber"), r.Body) }
+		}
+		if err := put(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// LocalHandler 使用 local 后端时返回处理签名URL的 http.Handler，挂载在 /files/ 下并去掉该前缀
+func LocalHandler() (http.Handler, bool) {
+	s, ok := defaultStorage.(*localStorage)
+	if !ok {
+		return nil, false
+	}
+	return s, true
+}
+
+// localResource 参与签名的资源：对象Key，分片上传时附加上传ID和分片号
+func localResource(objectKey string, query url.Values) string {
+	if uploadID := query.Get("uploadId"); uploadID != "" {
+		return objectKey + "?partNumber=" + query.Get("partNumber") + "&uploadId=" + uploadID
+	}
+	return objectKey
+}
+
+// validUploadID 上传ID由 InitMultipart 生成，只包含十六进制字符
+func validUploadID(uploadID string) bool {
+	if len(uploadID) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(uploadID)
+	return err == nil
+}
+
+// localError 将文件不存在转换为 ErrObjectNotFound
+func localError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrObjectNotFound
+	}
+	return err
+}
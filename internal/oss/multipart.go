@@ -0,0 +1,89 @@
+package oss
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SignPutURL 生成客户端直传对象的签名PUT地址
+func SignPutURL(objectKey, contentType string, expire time.Duration) (string, error) {
+	storage, err := GetStorage()
+	if err != nil {
+		return "", err
+	}
+
+	signedURL, err := storage.SignPutURL(objectKey, contentType, expire)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign upload url for object %s: %w", objectKey, err)
+	}
+	return signedURL, nil
+}
+
+// InitMultipart 初始化分片上传，返回上传ID
+func InitMultipart(objectKey, contentType string) (string, error) {
+	storage, err := GetStorage()
+	if err != nil {
+		return "", err
+	}
+
+	uploadID, err := storage.InitMultipart(context.Background(), objectKey, contentType)
+	if err != nil {
+		return "", fmt.Errorf("failed to init multipart upload for object %s: %w", objectKey, err)
+	}
+	return uploadID, nil
+}
+
+// SignPartURL 生成上传分片的签名PUT地址
+func SignPartURL(objectKey, uploadID string, partNumber int, expire time.Duration) (string, error) {
+	storage, err := GetStorage()
+	if err != nil {
+		return "", err
+	}
+
+	signedURL, err := storage.SignPartURL(objectKey, uploadID, partNumber, expire)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign part %d url for object %s: %w", partNumber, objectKey, err)
+	}
+	return signedURL, nil
+}
+
+// ListParts 列出已上传的分片
+func ListParts(objectKey, uploadID string) ([]Part, error) {
+	storage, err := GetStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := storage.ListParts(context.Background(), objectKey, uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list parts of object %s: %w", objectKey, err)
+	}
+	return parts, nil
+}
+
+// CompleteMultipart 合并分片
+func CompleteMultipart(objectKey, uploadID string, parts []Part) error {
+	storage, err := GetStorage()
+	if err != nil {
+		return err
+	}
+
+	if err := storage.CompleteMultipart(context.Background(), objectKey, uploadID, parts); err != nil {
+		return fmt.Errorf("failed to complete multipart upload for object %s: %w", objectKey, err)
+	}
+	return nil
+}
+
+// AbortMultipart 取消分片上传
+func AbortMultipart(objectKey, uploadID string) error {
+	storage, err := GetStorage()
+	if err != nil {
+		return err
+	}
+
+	if err := storage.AbortMultipart(context.Background(), objectKey, uploadID); err != nil {
+		return fmt.Errorf("failed to abort multipart upload for object %s: %w", objectKey, err)
+	}
+	return nil
+}
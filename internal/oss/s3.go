@@ -0,0 +1,331 @@
+package oss
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"online-learning-platform/internal/config"
+	"online-learning-platform/internal/oss/sigv4"
+)
+
+// s3DefaultRegion MinIO 等自建存储通常不区分区域
+const s3DefaultRegion = "us-east-1"
+
+// s3Storage S3 兼容存储（MinIO 等），使用路径风格地址 {endpoint}/{bucket}/{key}
+type s3Storage struct {
+	endpoint   *url.URL
+	bucketName string
+	region     string
+	creds      sigv4.Credentials
+	httpClient *http.Client
+}
+
+func newS3Storage(cfg config.OSSConfig) (*s3Storage, error) {
+	if cfg.Endpoint == "" || cfg.AccessKeyID == "" || cfg.AccessKeySecret == "" || cfg.BucketName == "" {
+		return nil, fmt.Errorf("invalid OSS config, please check endpoint/accessKey/bucket")
+	}
+
+	endpoint := cfg.Endpoint
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %s", cfg.Endpoint)
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = s3DefaultRegion
+	}
+
+	return &s3Storage{
+		endpoint:   u,
+		bucketName: cfg.BucketName,
+		region:     region,
+		creds: sigv4.Credentials{
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.AccessKeySecret,
+		},
+		httpClient: &http.Client{},
+	}, nil
+}
+
+func (s *s3Storage) Put(ctx context.Context, objectKey string, reader io.Reader, size int64) error {
+	// S3 的 PUT 需要 Content-Length，长度未知时先写入临时文件
+	if size < 0 {
+		tmp, err := os.CreateTemp("", "s3-upload-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temp file: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+
+		if size, err = io.Copy(tmp, reader); err != nil {
+			return fmt.Errorf("failed to buffer upload: %w", err)
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to buffer upload: %w", err)
+		}
+		reader = tmp
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, objectKey, nil, reader, size, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *s3Storage) Get(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, objectKey, nil, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *s3Storage) Delete(ctx context.Context, objectKey string) error {
+	resp, err := s.do(ctx, http.MethodDelete, objectKey, nil, nil, 0, nil)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *s3Storage) Copy(ctx context.Context, srcKey, destKey string) error {
+	header := http.Header{}
+	header.Set("X-Amz-Copy-Source", sigv4.EscapePath("/"+s.bucketName+"/"+srcKey))
+	resp, err := s.do(ctx, http.MethodPut, destKey, nil, nil, 0, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// 复制失败时 S3 也可能返回 200，错误信息在响应体中
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read copy response: %w", err)
+	}
+	if strings.Contains(string(body), "<Error>") {
+		return fmt.Errorf("copy object failed: %s", string(body))
+	}
+	return nil
+}
+
+func (s *s3Storage) Stat(ctx context.Context, objectKey string) (*ObjectInfo, error) {
+	resp, err := s.do(ctx, http.MethodHead, objectKey, nil, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return objectInfoFromHeader(objectKey, resp.Header), nil
+}
+
+func (s *s3Storage) SignURL(objectKey, method string, expire time.Duration) (string, error) {
+	return sigv4.PresignURL(method, s.objectLocation(objectKey), s.creds, s.region, "s3", time.Now(), expire), nil
+}
+
+func (s *s3Storage) ObjectURL(objectKey string) string {
+	return s.endpoint.String() + "/" + s.bucketName + "/" + objectKey
+}
+
+func (s *s3Storage) List(ctx context.Context, prefix, marker string, maxKeys int) ([]ObjectInfo, string, error) {
+	query := url.Values{
+		"prefix":   {prefix},
+		"max-keys": {strconv.Itoa(maxKeys)},
+	}
+	if marker != "" {
+		query.Set("marker", marker)
+	}
+	resp, err := s.do(ctx, http.MethodGet, "", query, nil, 0, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		IsTruncated bool   `xml:"IsTruncated"`
+		NextMarker  string `xml:"NextMarker"`
+		Contents    []struct {
+			Key          string    `xml:"Key"`
+			Size         int64     `xml:"Size"`
+			ETag         string    `xml:"ETag"`
+			LastModified time.Time `xml:"LastModified"`
+		} `xml:"Contents"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, "", fmt.Errorf("failed to decode object list: %w", err)
+	}
+	objects := make([]ObjectInfo, 0, len(result.Contents))
+	for _, o := range result.Contents {
+		objects = append(objects, ObjectInfo{Key: o.Key, Size: o.Size, ETag: o.ETag, LastModified: o.LastModified})
+	}
+	if !result.IsTruncated || len(objects) == 0 {
+		return objects, "", nil
+	}
+	// 不指定 delimiter 时 S3 不返回 NextMarker，从最后一个Key继续
+	next := result.NextMarker
+	if next == "" {
+		next = objects[len(objects)-1].Key
+	}
+	return objects, next, nil
+}
+
+func (s *s3Storage) SignPutURL(objectKey, contentType string, expire time.Duration) (string, error) {
+	return s.SignURL(objectKey, http.MethodPut, expire)
+}
+
+func (s *s3Storage) InitMultipart(ctx context.Context, objectKey, contentType string) (string, error) {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	resp, err := s.do(ctx, http.MethodPost, objectKey, url.Values{"uploads": {""}}, nil, 0, header)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode multipart upload: %w", err)
+	}
+	return result.UploadID, nil
+}
+
+func (s *s3Storage) SignPartURL(objectKey, uploadID string, partNumber int, expire time.Duration) (string, error) {
+	u := s.objectLocation(objectKey)
+	u.RawQuery = url.Values{
+		"partNumber": {strconv.Itoa(partNumber)},
+		"uploadId":   {uploadID},
+	}.Encode()
+	return sigv4.PresignURL(http.MethodPut, u, s.creds, s.region, "s3", time.Now(), expire), nil
+}
+
+func (s *s3Storage) ListParts(ctx context.Context, objectKey, uploadID string) ([]Part, error) {
+	resp, err := s.do(ctx, http.MethodGet, objectKey, url.Values{"uploadId": {uploadID}}, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Parts []struct {
+			PartNumber int    `xml:"PartNumber"`
+			Size       int64  `xml:"Size"`
+			ETag       string `xml:"ETag"`
+		} `xml:"Part"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode parts: %w", err)
+	}
+	parts := make([]Part, 0, len(result.Parts))
+	for _, p := range result.Parts {
+		parts = append(parts, Part{PartNumber: p.PartNumber, Size: p.Size, ETag: p.ETag})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+func (s *s3Storage) CompleteMultipart(ctx context.Context, objectKey, uploadID string, parts []Part) error {
+	type completePart struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	body := struct {
+		XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+		Parts   []completePart `xml:"Part"`
+	}{}
+	for _, p := range parts {
+		body.Parts = append(body.Parts, completePart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	data, err := xml.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodPost, objectKey, url.Values{"uploadId": {uploadID}}, bytes.NewReader(data), int64(len(data)), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// 合并失败时 S3 也可能返回 200，错误信息在响应体中
+	result, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read complete response: %w", err)
+	}
+	if strings.Contains(string(result), "<Error>") {
+		return fmt.Errorf("complete multipart upload failed: %s", string(result))
+	}
+	return nil
+}
+
+func (s *s3Storage) AbortMultipart(ctx context.Context, objectKey, uploadID string) error {
+	resp, err := s.do(ctx, http.MethodDelete, objectKey, url.Values{"uploadId": {uploadID}}, nil, 0, nil)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// objectLocation 返回对象的请求地址
+func (s *s3Storage) objectLocation(objectKey string) *url.URL {
+	u := *s.endpoint
+	u.Path = u.Path + "/" + s.bucketName + "/" + objectKey
+	u.RawPath = ""
+	return &u
+}
+
+// do 发送签名请求，非 2xx 响应转换为错误，404 返回 ErrObjectNotFound
+func (s *s3Storage) do(ctx context.Context, method, objectKey string, query url.Values, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	u := s.objectLocation(objectKey)
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	sigv4.SignRequest(req, sigv4.UnsignedPayload, s.creds, s.region, "s3", time.Now())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("s3 %s %s: status %d: %s", method, objectKey, resp.StatusCode, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,226 @@
+// Package playback 记录视频观看区间，并在内存中缓冲播放心跳
+//
+// 播放器每隔几秒上报一次心跳，直接写库会让每个观看中的学生每分钟产生多次写入。
+// 心跳先合并到 Buffer 中，由服务定时或在缓冲条目过多时批量写入分支数据库。
+package playback
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MergeGap 两段观看区间之间的空隙不超过该秒数时视为连续（心跳上报时间存在误差）
+const MergeGap = 1.0
+
+// HeartbeatTolerance 心跳区间长度允许超出两次心跳实际间隔的秒数（网络延迟和计时误差）
+const HeartbeatTolerance = 2.0
+
+// Interval 一段已观看的区间，单位为秒
+type Interval struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// Merge 按开始时间排序并合并重叠或相邻的区间，返回新的切片
+func Merge(intervals []Interval) []Interval {
+	if len(intervals) == 0 {
+		return []Interval{}
+	}
+	sorted := make([]Interval, len(intervals))
+	copy(sorted, intervals)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	merged := []Interval{sorted[0]}
+	for _, iv := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if iv.Start <= last.End+MergeGap {
+			if iv.End > last.End {
+				last.End = iv.End
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// Watched 计算区间覆盖的总秒数，重复观看的部分只计一次
+func Watched(intervals []Interval) float64 {
+	total := 0.0
+	for _, iv := range Merge(intervals) {
+		total += iv.End - iv.Start
+	}
+	return total
+}
+
+// Clamp 将区间限制在 [0, duration] 内，区间为空时返回 false
+func Clamp(iv Interval, duration float64) (Interval, bool) {
+	if iv.Start < 0 {
+		iv.Start = 0
+	}
+	if iv.End > duration {
+		iv.End = duration
+	}
+	return iv, iv.End > iv.Start
+}
+
+// ClampAll 将全部区间限制在 [0, duration] 内，去掉截断后为空的区间并合并
+func ClampAll(intervals []Interval, duration float64) []Interval {
+	clamped := make([]Interval, 0, len(intervals))
+	for _, iv := range intervals {
+		if iv, ok := Clamp(iv, duration); ok {
+			clamped = append(clamped, iv)
+		}
+	}
+	return Merge(clamped)
+}
+
+// Key 标识一个学生在某个课时上的播放记录
+type Key struct {
+	BranchID uint
+	UserID   uint
+	LessonID uint
+}
+
+// Entry 缓冲中的播放记录
+// Saved 为载入时已写入数据库的区间，Pending 为尚未写入的心跳区间
+type Entry struct {
+	Key
+	CourseID    uint
+	Saved       []Interval
+	Pending     []Interval
+	Position    float64
+	Duration    float64 // 服务端记录的视频时长，为 0 时未知
+	CompletedAt *time.Time
+	UpdatedAt   time.Time // 上次心跳（或载入的记录上次写入）的时间
+}
+
+// Intervals 返回已保存和未写入区间合并后的结果
+func (e *Entry) Intervals() []Interval {
+	all := make([]Interval, 0, len(e.Saved)+len(e.Pending))
+	all = append(all, e.Saved...)
+	all = append(all, e.Pending...)
+	return Merge(all)
+}
+
+func (e *Entry) clone() Entry {
+	c := *e
+	c.Saved = append([]Interval(nil), e.Saved...)
+	c.Pending = append([]Interval(nil), e.Pending...)
+	return c
+}
+
+// Buffer 并发安全的心跳缓冲
+type Buffer struct {
+	mu      sync.Mutex
+	entries map[Key]*Entry
+}
+
+// NewBuffer 创建心跳缓冲
+func NewBuffer() *Buffer {
+	return &Buffer{entries: make(map[Key]*Entry)}
+}
+
+// Get 返回缓冲中的播放记录副本
+func (b *Buffer) Get(key Key) (Entry, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.entries[key]
+	if !ok {
+		return Entry{}, false
+	}
+	return e.clone(), true
+}
+
+// Seed 放入从数据库载入的播放记录，已存在时保留缓冲中的记录
+func (b *Buffer) Seed(e Entry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.entries[e.Key]; ok {
+		return
+	}
+	seeded := e.clone()
+	b.entries[e.Key] = &seeded
+}
+
+// Record 记录一次心跳，返回更新后的记录副本；缓冲中没有该记录时返回 false，需要先 Seed
+// 区间长度不超过距 UpdatedAt 的实际时间（加上 HeartbeatTolerance），连续快速上报不能加快观看进度；
+// 区间和播放位置限制在 Seed 时给出的视频时长内，时长未知（为 0）时不截断
+func (b *Buffer) Record(key Key, iv Interval, position float64, at time.Time) (Entry, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.entries[key]
+	if !ok {
+		return Entry{}, false
+	}
+	if !e.UpdatedAt.IsZero() {
+		elapsed := at.Sub(e.UpdatedAt).Seconds()
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		if limit := elapsed + HeartbeatTolerance; iv.End-iv.Start > limit {
+			iv.End = iv.Start + limit
+		}
+	}
+	if e.Duration > 0 {
+		iv, ok = Clamp(iv, e.Duration)
+		if position > e.Duration {
+			position = e.Duration
+		}
+	} else {
+		ok = iv.End > iv.Start
+	}
+	if ok {
+		e.Pending = Merge(append(e.Pending, iv))
+	}
+	e.Position = position
+	e.UpdatedAt = at
+	return e.clone(), true
+}
+
+// Restore 写入失败时把记录放回缓冲，与期间新到的心跳合并
+func (b *Buffer) Restore(e Entry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current, ok := b.entries[e.Key]
+	if !ok {
+		restored := e.clone()
+		b.entries[e.Key] = &restored
+		return
+	}
+	current.Pending = Merge(append(current.Pending, e.Pending...))
+}
+
+// Len 返回缓冲中的记录数
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
+
+// Drain 取出全部记录并清空缓冲
+func (b *Buffer) Drain() []Entry {
+	b.mu.Lock()
+	entries := b.entries
+	b.entries = make(map[Key]*Entry)
+	b.mu.Unlock()
+
+	drained := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		drained = append(drained, *e)
+	}
+	sort.Slice(drained, func(i, j int) bool {
+		a, c := drained[i].Key, drained[j].Key
+		if a.BranchID != c.BranchID {
+			return a.BranchID < c.BranchID
+		}
+		if a.UserID != c.UserID {
+			return a.UserID < c.UserID
+		}
+		return a.LessonID < c.LessonID
+	})
+	return drained
+}
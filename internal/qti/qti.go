@@ -0,0 +1,128 @@
+// Package qti 实现 IMS QTI 2.1 题库的读写
+// 题目类型映射：单选、多选、判断 -> choiceInteraction；简答 -> textEntryInteraction（string）；
+// 数值 -> textEntryInteraction（float/integer）。题库打包为带 imsmanifest.xml 的内容包
+package qti
+
+import (
+	"encoding/xml"
+
+	"online-learning-platform/internal/quiz"
+)
+
+// 资源类型
+const (
+	ResourceItem = "imsqti_item_xmlv2p1"
+	ResourceTest = "imsqti_test_xmlv2p1"
+)
+
+// 命名空间
+const (
+	itemNamespace     = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	manifestNamespace = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	xsiNamespace      = "http://www.w3.org/2001/XMLSchema-instance"
+	itemSchema        = "http://www.imsglobal.org/xsd/imsqti_v2p1 http://www.imsglobal.org/xsd/qti/qtiv2p1/imsqti_v2p1.xsd"
+)
+
+// 响应处理模板
+const (
+	templateMatchCorrect = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+	templateMapResponse  = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/map_response"
+)
+
+// 题目的响应和分值变量名
+const (
+	responseIdentifier = "RESPONSE"
+	scoreIdentifier    = "SCORE"
+	maxScoreIdentifier = "MAXSCORE"
+)
+
+// Bank 题库
+type Bank struct {
+	Identifier string
+	Title      string
+	Items      []Item
+}
+
+// Item 一道题目，Question 中的 Type、Options、Key 和 Points 与题库中的题目一致
+type Item struct {
+	Identifier string
+	Title      string
+	Prompt     string
+	Question   quiz.Question
+}
+
+// Issue 导入时无法支持或被部分导入的题目
+type Issue struct {
+	Identifier string `json:"identifier"`
+	Title      string `json:"title"`
+	Reason     string `json:"reason"`
+}
+
+// manifest imsmanifest.xml 结构（读取时忽略命名空间前缀，按本地名匹配）
+type manifest struct {
+	XMLName    xml.Name   `xml:"manifest"`
+	Identifier string     `xml:"identifier,attr"`
+	Xmlns      string     `xml:"xmlns,attr,omitempty"`
+	Metadata   *metadata  `xml:"metadata"`
+	Resources  []resource `xml:"resources>resource"`
+}
+
+type metadata struct {
+	Schema        string `xml:"schema"`
+	SchemaVersion string `xml:"schemaversion"`
+}
+
+type resource struct {
+	Identifier string         `xml:"identifier,attr"`
+	Type       string         `xml:"type,attr"`
+	Href       string         `xml:"href,attr,omitempty"`
+	Files      []resourceFile `xml:"file"`
+}
+
+type resourceFile struct {
+	Href string `xml:"href,attr"`
+}
+
+// assessmentItem 读取时使用的题目结构，题干和响应处理按原始 XML 保留后再解析
+type assessmentItem struct {
+	XMLName              xml.Name              `xml:"assessmentItem"`
+	Identifier           string                `xml:"identifier,attr"`
+	Title                string                `xml:"title,attr"`
+	ResponseDeclarations []responseDeclaration `xml:"responseDeclaration"`
+	OutcomeDeclarations  []outcomeDeclaration  `xml:"outcomeDeclaration"`
+	ItemBody             *struct {
+		Inner []byte `xml:",innerxml"`
+	} `xml:"itemBody"`
+	ResponseProcessing *struct {
+		Template string `xml:"template,attr"`
+		Inner    []byte `xml:",innerxml"`
+	} `xml:"responseProcessing"`
+}
+
+type responseDeclaration struct {
+	Identifier      string   `xml:"identifier,attr"`
+	Cardinality     string   `xml:"cardinality,attr"`
+	BaseType        string   `xml:"baseType,attr"`
+	CorrectResponse []string `xml:"correctResponse>value"`
+	Mapping         *mapping `xml:"mapping"`
+}
+
+type mapping struct {
+	LowerBound   *float64   `xml:"lowerBound,attr"`
+	UpperBound   *float64   `xml:"upperBound,attr"`
+	DefaultValue float64    `xml:"defaultValue,attr"`
+	Entries      []mapEntry `xml:"mapEntry"`
+}
+
+type mapEntry struct {
+	MapKey        string  `xml:"mapKey,attr"`
+	MappedValue   float64 `xml:"mappedValue,attr"`
+	CaseSensitive *bool   `xml:"caseSensitive,attr"`
+}
+
+type outcomeDeclaration struct {
+	Identifier   string   `xml:"identifier,attr"`
+	Cardinality  string   `xml:"cardinality,attr"`
+	BaseType     string   `xml:"baseType,attr"`
+	DefaultValue []string `xml:"defaultValue>value"`
+}
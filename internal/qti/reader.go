@@ -0,0 +1,448 @@
+package qti
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"online-learning-platform/internal/quiz"
+)
+
+// ErrInvalidPackage 不是有效的 QTI 内容包或题目文件
+var ErrInvalidPackage = errors.New("invalid qti package")
+
+// maxItemSize 单个题目文件的大小上限
+const maxItemSize = 4 << 20
+
+// Read 解析 QTI 2.1 内容包（zip）或单个 assessmentItem 文件，返回可导入的题目和无法导入的题目
+func Read(r io.ReaderAt, size int64) (*Bank, []Issue, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		// 不是 zip 时按单个题目文件解析
+		if size > maxItemSize {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPackage, err)
+		}
+		data, readErr := io.ReadAll(io.NewSectionReader(r, 0, size))
+		if readErr != nil {
+			return nil, nil, readErr
+		}
+		if !isAssessmentItem(data) {
+			return nil, nil, fmt.Errorf("%w: not a zip package or assessmentItem", ErrInvalidPackage)
+		}
+		bank := &Bank{}
+		var issues []Issue
+		addItem(bank, &issues, "", data)
+		return bank, issues, nil
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[path.Clean(f.Name)] = f
+	}
+	manifestFile, ok := files["imsmanifest.xml"]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: imsmanifest.xml not found", ErrInvalidPackage)
+	}
+	var m manifest
+	if err := decodeZipXML(manifestFile, &m); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPackage, err)
+	}
+
+	bank := &Bank{Identifier: m.Identifier}
+	var issues []Issue
+	for _, res := range m.Resources {
+		switch {
+		case strings.HasPrefix(res.Type, "imsqti_item_xmlv2p"):
+			href := res.Href
+			if href == "" && len(res.Files) > 0 {
+				href = res.Files[0].Href
+			}
+			f, ok := files[path.Clean(href)]
+			if href == "" || !ok {
+				issues = append(issues, Issue{Identifier: res.Identifier, Reason: "item file not found in package"})
+				continue
+			}
+			data, err := readZipFile(f)
+			if err != nil {
+				issues = append(issues, Issue{Identifier: res.Identifier, Reason: err.Error()})
+				continue
+			}
+			addItem(bank, &issues, res.Identifier, data)
+		case strings.HasPrefix(res.Type, "imsqti_xmlv1p"):
+			issues = append(issues, Issue{Identifier: res.Identifier, Reason: "QTI 1.x items are not supported"})
+		}
+		// 测试（assessmentTest）只引用题目，题目本身作为单独的资源导入；图片等其他资源忽略
+	}
+	return bank, issues, nil
+}
+
+// addItem 解析一道题目，不支持时记录原因
+func addItem(bank *Bank, issues *[]Issue, identifier string, data []byte) {
+	it, err := parseItem(data)
+	if err != nil {
+		issue := Issue{Identifier: identifier, Reason: err.Error()}
+		if it != nil {
+			issue.Identifier, issue.Title = it.Identifier, it.Title
+		}
+		*issues = append(*issues, issue)
+		return
+	}
+	bank.Items = append(bank.Items, *it)
+}
+
+// parseItem 将 assessmentItem 转换为题目；返回错误时 Item 中只有标识符和标题
+func parseItem(data []byte) (*Item, error) {
+	var ai assessmentItem
+	if err := newDecoder(bytes.NewReader(data)).Decode(&ai); err != nil {
+		return nil, fmt.Errorf("failed to parse item: %v", err)
+	}
+	it := &Item{Identifier: ai.Identifier, Title: strings.TrimSpace(ai.Title)}
+
+	var body itemBody
+	if ai.ItemBody != nil {
+		if err := body.parse(ai.ItemBody.Inner); err != nil {
+			return it, fmt.Errorf("failed to parse item body: %v", err)
+		}
+	}
+	switch {
+	case len(body.interactions) == 0:
+		return it, errors.New("item has no interaction")
+	case len(body.interactions) > 1:
+		return it, errors.New("items with more than one interaction are not supported")
+	}
+	in := body.interactions[0]
+	if in.name != "choiceInteraction" && in.name != "textEntryInteraction" {
+		return it, fmt.Errorf("%s is not supported", in.name)
+	}
+
+	var rd *responseDeclaration
+	for i := range ai.ResponseDeclarations {
+		if ai.ResponseDeclarations[i].Identifier == in.responseIdentifier {
+			rd = &ai.ResponseDeclarations[i]
+		}
+	}
+	if rd == nil {
+		return it, fmt.Errorf("response declaration %q not found", in.responseIdentifier)
+	}
+
+	it.Prompt = body.prompt()
+	if it.Title == "" {
+		it.Title = titleFromPrompt(it.Prompt)
+	}
+	if it.Prompt == "" {
+		it.Prompt = it.Title
+	}
+
+	q := &it.Question
+	q.Points = itemPoints(&ai, rd)
+
+	var rpInner []byte
+	if ai.ResponseProcessing != nil && ai.ResponseProcessing.Template == "" {
+		rpInner = ai.ResponseProcessing.Inner
+	}
+
+	if in.name == "choiceInteraction" {
+		if rd.Cardinality == "ordered" {
+			return it, errors.New("ordered choice responses are not supported")
+		}
+		q.Options = in.choices
+		q.Key.Choices = correctValues(rd)
+		q.Type = quiz.TypeMultipleChoice
+		if rd.Cardinality == "single" || (rd.Cardinality == "" && in.maxChoices == 1) {
+			q.Type = quiz.TypeSingleChoice
+		}
+		if q.Type == quiz.TypeSingleChoice && isTrueFalse(q.Options) {
+			q.Type = quiz.TypeTrueFalse
+			q.Options = nil
+			for i, c := range q.Key.Choices {
+				q.Key.Choices[i] = strings.ToLower(c)
+			}
+		}
+	} else {
+		switch rd.BaseType {
+		case "float", "integer":
+			q.Type = quiz.TypeNumeric
+			if len(rd.CorrectResponse) == 0 {
+				return it, errors.New("numeric item has no correct response")
+			}
+			n, err := strconv.ParseFloat(strings.TrimSpace(rd.CorrectResponse[0]), 64)
+			if err != nil {
+				return it, fmt.Errorf("invalid numeric correct response %q", rd.CorrectResponse[0])
+			}
+			q.Key.Number = &n
+			if q.Key.Tolerance, err = toleranceFromProcessing(rpInner, n); err != nil {
+				return it, err
+			}
+		case "string", "":
+			q.Type = quiz.TypeShortAnswer
+			q.Key.Texts = correctValues(rd)
+			q.Key.CaseSensitive = len(q.Key.Texts) > 0 && isCaseSensitive(rd)
+		default:
+			return it, fmt.Errorf("text entry with base type %q is not supported", rd.BaseType)
+		}
+	}
+
+	if err := quiz.Validate(*q); err != nil {
+		return it, err
+	}
+	return it, nil
+}
+
+// itemPoints 题目分值：优先使用 MAXSCORE，其次是映射的上限，默认 1 分
+func itemPoints(ai *assessmentItem, rd *responseDeclaration) float64 {
+	for _, od := range ai.OutcomeDeclarations {
+		if od.Identifier == maxScoreIdentifier && len(od.DefaultValue) > 0 {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(od.DefaultValue[0]), 64); err == nil && v > 0 {
+				return quiz.RoundPoints(v)
+			}
+		}
+	}
+	if rd.Mapping != nil && rd.Mapping.UpperBound != nil && *rd.Mapping.UpperBound > 0 {
+		return quiz.RoundPoints(*rd.Mapping.UpperBound)
+	}
+	return 1
+}
+
+// correctValues 标准答案；没有 correctResponse 时使用映射中得分为正的取值
+func correctValues(rd *responseDeclaration) []string {
+	var values []string
+	seen := make(map[string]bool)
+	add := func(v string) {
+		v = strings.TrimSpace(v)
+		if v != "" && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	for _, v := range rd.CorrectResponse {
+		add(v)
+	}
+	if rd.Mapping != nil && (len(values) == 0 || rd.BaseType == "string") {
+		for _, e := range rd.Mapping.Entries {
+			if e.MappedValue > 0 {
+				add(e.MapKey)
+			}
+		}
+	}
+	return values
+}
+
+// isCaseSensitive QTI 中 mapEntry 默认区分大小写，只有全部显式声明不区分时才不区分
+func isCaseSensitive(rd *responseDeclaration) bool {
+	if rd.Mapping == nil || len(rd.Mapping.Entries) == 0 {
+		return true
+	}
+	for _, e := range rd.Mapping.Entries {
+		if e.CaseSensitive == nil || *e.CaseSensitive {
+			return true
+		}
+	}
+	return false
+}
+
+// isTrueFalse 两个选项的标识符为 true 和 false 时按判断题导入
+func isTrueFalse(options []quiz.Option) bool {
+	if len(options) != 2 {
+		return false
+	}
+	a, b := strings.ToLower(options[0].ID), strings.ToLower(options[1].ID)
+	return (a == quiz.ChoiceTrue && b == quiz.ChoiceFalse) || (a == quiz.ChoiceFalse && b == quiz.ChoiceTrue)
+}
+
+// toleranceFromProcessing 从自定义响应处理的 equal 表达式中读取数值题的误差
+func toleranceFromProcessing(inner []byte, answer float64) (float64, error) {
+	if len(inner) == 0 {
+		return 0, nil
+	}
+	d := newDecoder(bytes.NewReader(inner))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse response processing: %v", err)
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "equal" {
+			continue
+		}
+		mode, tolerance := attr(se, "toleranceMode"), strings.Fields(attr(se, "tolerance"))
+		if mode == "" || mode == "exact" || len(tolerance) == 0 {
+			return 0, nil
+		}
+		// 上下误差不同时取较小的一个
+		t, err := strconv.ParseFloat(tolerance[0], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid tolerance %q", attr(se, "tolerance"))
+		}
+		if len(tolerance) > 1 {
+			if t2, err := strconv.ParseFloat(tolerance[1], 64); err == nil && t2 < t {
+				t = t2
+			}
+		}
+		switch mode {
+		case "absolute":
+			return t, nil
+		case "relative":
+			return math.Abs(answer) * t / 100, nil
+		}
+		return 0, fmt.Errorf("tolerance mode %q is not supported", mode)
+	}
+}
+
+// interaction 题干中的一个交互
+type interaction struct {
+	name               string
+	responseIdentifier string
+	maxChoices         int
+	choices            []quiz.Option
+}
+
+// itemBody 从题干 XML 中提取交互、选项和题干文本
+type itemBody struct {
+	interactions []interaction
+	text         strings.Builder
+}
+
+// skippedBodyElements 不属于题干文本的元素
+var skippedBodyElements = map[string]bool{
+	"feedbackBlock": true, "feedbackInline": true, "modalFeedback": true, "rubricBlock": true, "templateBlock": true,
+}
+
+// blockBodyElements 块级元素，前后换行
+var blockBodyElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "prompt": true, "blockquote": true, "pre": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "tr": true,
+}
+
+func (b *itemBody) parse(inner []byte) error {
+	d := newDecoder(bytes.NewReader(inner))
+	var current *interaction
+	var choice *quiz.Option
+	var choiceText strings.Builder
+	skip := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			switch {
+			case skip > 0 || skippedBodyElements[name]:
+				skip++
+			case strings.HasSuffix(name, "Interaction"):
+				in := interaction{name: name, responseIdentifier: attr(t, "responseIdentifier")}
+				in.maxChoices, _ = strconv.Atoi(attr(t, "maxChoices"))
+				b.interactions = append(b.interactions, in)
+				current = &b.interactions[len(b.interactions)-1]
+				b.text.WriteString(" ")
+			case current != nil && name == "simpleChoice":
+				choice = &quiz.Option{ID: attr(t, "identifier")}
+				choiceText.Reset()
+			case blockBodyElements[name]:
+				b.text.WriteString("\n")
+			}
+		case xml.EndElement:
+			name := t.Name.Local
+			switch {
+			case skip > 0:
+				skip--
+			case current != nil && name == current.name:
+				current = nil
+			case choice != nil && name == "simpleChoice":
+				choice.Text = strings.Join(strings.Fields(choiceText.String()), " ")
+				current.choices = append(current.choices, *choice)
+				choice = nil
+			case blockBodyElements[name]:
+				b.text.WriteString("\n")
+			}
+		case xml.CharData:
+			switch {
+			case skip > 0:
+			case choice != nil:
+				choiceText.Write(t)
+			case current == nil || current.name == "choiceInteraction":
+				// 交互内只保留 prompt 的文本（选项文本已单独记录）
+				b.text.Write(t)
+			}
+		}
+	}
+}
+
+// prompt 题干文本：合并行内空白，去掉空行
+func (b *itemBody) prompt() string {
+	var lines []string
+	for _, line := range strings.Split(b.text.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func attr(se xml.StartElement, name string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// newDecoder 题目中常带有 HTML 实体和未闭合的 HTML 标签，按宽松模式解析
+func newDecoder(r io.Reader) *xml.Decoder {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	return d
+}
+
+// isAssessmentItem 判断文件的根元素是否为 assessmentItem
+func isAssessmentItem(data []byte) bool {
+	d := newDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return false
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se.Name.Local == "assessmentItem"
+		}
+	}
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > maxItemSize {
+		return nil, fmt.Errorf("item file %s is too large", f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, maxItemSize))
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return newDecoder(rc).Decode(v)
+}
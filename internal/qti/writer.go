@@ -0,0 +1,304 @@
+package qti
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"online-learning-platform/internal/quiz"
+)
+
+// maxTitleLength 由题干生成的题目标题长度（字符数）
+const maxTitleLength = 60
+
+// identifierPattern QTI 标识符（NCName 的常用子集）
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// 输出 manifest 时声明命名空间
+type manifestOut struct {
+	XMLName    xml.Name   `xml:"manifest"`
+	Identifier string     `xml:"identifier,attr"`
+	Xmlns      string     `xml:"xmlns,attr"`
+	Metadata   metadata   `xml:"metadata"`
+	Resources  []resource `xml:"resources>resource"`
+}
+
+type itemOut struct {
+	XMLName             xml.Name              `xml:"assessmentItem"`
+	Xmlns               string                `xml:"xmlns,attr"`
+	XmlnsXsi            string                `xml:"xmlns:xsi,attr"`
+	SchemaLocation      string                `xml:"xsi:schemaLocation,attr"`
+	Identifier          string                `xml:"identifier,attr"`
+	Title               string                `xml:"title,attr"`
+	Adaptive            bool                  `xml:"adaptive,attr"`
+	TimeDependent       bool                  `xml:"timeDependent,attr"`
+	ResponseDeclaration responseDeclaration   `xml:"responseDeclaration"`
+	OutcomeDeclarations []outcomeDeclaration  `xml:"outcomeDeclaration"`
+	ItemBody            itemBodyOut           `xml:"itemBody"`
+	ResponseProcessing  responseProcessingOut `xml:"responseProcessing"`
+}
+
+type itemBodyOut struct {
+	Paragraphs []paragraphOut        `xml:"p"`
+	Choice     *choiceInteractionOut `xml:"choiceInteraction"`
+}
+
+type paragraphOut struct {
+	Text      string        `xml:",chardata"`
+	TextEntry *textEntryOut `xml:"textEntryInteraction"`
+}
+
+type textEntryOut struct {
+	ResponseIdentifier string `xml:"responseIdentifier,attr"`
+	ExpectedLength     int    `xml:"expectedLength,attr"`
+}
+
+type choiceInteractionOut struct {
+	ResponseIdentifier string            `xml:"responseIdentifier,attr"`
+	Shuffle            bool              `xml:"shuffle,attr"`
+	MaxChoices         int               `xml:"maxChoices,attr"`
+	Prompt             string            `xml:"prompt,omitempty"`
+	Choices            []simpleChoiceOut `xml:"simpleChoice"`
+}
+
+type simpleChoiceOut struct {
+	Identifier string `xml:"identifier,attr"`
+	Text       string `xml:",chardata"`
+}
+
+type responseProcessingOut struct {
+	Template string `xml:"template,attr,omitempty"`
+	Inner    string `xml:",innerxml"`
+}
+
+// Write 将题库写为 QTI 2.1 内容包，每道题目一个 assessmentItem 文件
+func Write(w io.Writer, bank *Bank) error {
+	identifier := bank.Identifier
+	if !identifierPattern.MatchString(identifier) {
+		identifier = "question_bank"
+	}
+	out := manifestOut{
+		Identifier: identifier,
+		Xmlns:      manifestNamespace,
+		Metadata:   metadata{Schema: "QTIv2.1 Package", SchemaVersion: "1.0.0"},
+	}
+
+	type entry struct {
+		name string
+		data []byte
+	}
+	var entries []entry
+	used := make(map[string]bool, len(bank.Items))
+	for i, it := range bank.Items {
+		id := it.Identifier
+		if !identifierPattern.MatchString(id) || used[id] {
+			id = fmt.Sprintf("item_%d", i+1)
+		}
+		used[id] = true
+		it.Identifier = id
+
+		data, err := marshalItem(it)
+		if err != nil {
+			return err
+		}
+		href := "items/" + id + ".xml"
+		out.Resources = append(out.Resources, resource{
+			Identifier: id,
+			Type:       ResourceItem,
+			Href:       href,
+			Files:      []resourceFile{{Href: href}},
+		})
+		entries = append(entries, entry{name: href, data: data})
+	}
+
+	manifestData, err := marshalXML(out)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	for _, e := range append([]entry{{name: "imsmanifest.xml", data: manifestData}}, entries...) {
+		f, err := zw.Create(e.name)
+		if err != nil {
+			return fmt.Errorf("failed to create %s in package: %w", e.name, err)
+		}
+		if _, err := f.Write(e.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// marshalItem 将题目转换为 assessmentItem
+func marshalItem(it Item) ([]byte, error) {
+	q := it.Question
+	out := itemOut{
+		Xmlns:          itemNamespace,
+		XmlnsXsi:       xsiNamespace,
+		SchemaLocation: itemSchema,
+		Identifier:     it.Identifier,
+		Title:          it.Title,
+		ResponseDeclaration: responseDeclaration{
+			Identifier:  responseIdentifier,
+			Cardinality: "single",
+			BaseType:    "identifier",
+		},
+		OutcomeDeclarations: []outcomeDeclaration{
+			{Identifier: scoreIdentifier, Cardinality: "single", BaseType: "float", DefaultValue: []string{"0"}},
+			{Identifier: maxScoreIdentifier, Cardinality: "single", BaseType: "float", DefaultValue: []string{formatFloat(q.Points)}},
+		},
+		ResponseProcessing: responseProcessingOut{Template: templateMatchCorrect},
+	}
+	if out.Title == "" {
+		out.Title = titleFromPrompt(it.Prompt)
+	}
+	rd := &out.ResponseDeclaration
+
+	switch q.Type {
+	case quiz.TypeSingleChoice, quiz.TypeMultipleChoice, quiz.TypeTrueFalse:
+		options := q.Options
+		if q.Type == quiz.TypeTrueFalse {
+			options = []quiz.Option{{ID: quiz.ChoiceTrue, Text: "正确"}, {ID: quiz.ChoiceFalse, Text: "错误"}}
+		}
+		ids := choiceIdentifiers(options)
+		choice := &choiceInteractionOut{
+			ResponseIdentifier: responseIdentifier,
+			MaxChoices:         1,
+			Prompt:             it.Prompt,
+		}
+		for _, o := range options {
+			choice.Choices = append(choice.Choices, simpleChoiceOut{Identifier: ids[o.ID], Text: o.Text})
+		}
+		for _, c := range q.Key.Choices {
+			rd.CorrectResponse = append(rd.CorrectResponse, ids[c])
+		}
+		out.ItemBody.Choice = choice
+
+		// 多选题的部分得分：每个正确选项加一份，每个错误选项扣一份，最低 0 分
+		if q.Type == quiz.TypeMultipleChoice {
+			rd.Cardinality = "multiple"
+			choice.MaxChoices = 0
+			correct := make(map[string]bool, len(q.Key.Choices))
+			for _, c := range q.Key.Choices {
+				correct[c] = true
+			}
+			share := q.Points / float64(len(q.Key.Choices))
+			lower, upper := 0.0, q.Points
+			m := &mapping{LowerBound: &lower, UpperBound: &upper}
+			for _, o := range options {
+				value := -share
+				if correct[o.ID] {
+					value = share
+				}
+				m.Entries = append(m.Entries, mapEntry{MapKey: ids[o.ID], MappedValue: value})
+			}
+			rd.Mapping = m
+			out.ResponseProcessing.Template = templateMapResponse
+		}
+
+	case quiz.TypeShortAnswer:
+		rd.BaseType = "string"
+		out.ItemBody.Paragraphs = append(promptParagraphs(it.Prompt), paragraphOut{
+			TextEntry: &textEntryOut{ResponseIdentifier: responseIdentifier, ExpectedLength: 20},
+		})
+		if len(q.Key.Texts) == 0 {
+			// 没有标准答案，全部人工批改
+			out.ResponseProcessing.Template = ""
+			break
+		}
+		caseSensitive := q.Key.CaseSensitive
+		upper := q.Points
+		m := &mapping{UpperBound: &upper}
+		for _, t := range q.Key.Texts {
+			m.Entries = append(m.Entries, mapEntry{MapKey: t, MappedValue: q.Points, CaseSensitive: &caseSensitive})
+		}
+		rd.CorrectResponse = []string{q.Key.Texts[0]}
+		rd.Mapping = m
+		out.ResponseProcessing.Template = templateMapResponse
+
+	case quiz.TypeNumeric:
+		rd.BaseType = "float"
+		out.ItemBody.Paragraphs = append(promptParagraphs(it.Prompt), paragraphOut{
+			TextEntry: &textEntryOut{ResponseIdentifier: responseIdentifier, ExpectedLength: 10},
+		})
+		if q.Key.Number != nil {
+			rd.CorrectResponse = []string{formatFloat(*q.Key.Number)}
+		}
+		if q.Key.Tolerance > 0 {
+			out.ResponseProcessing = responseProcessingOut{Inner: toleranceProcessing(q.Key.Tolerance)}
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported question type %q", q.Type)
+	}
+
+	return marshalXML(out)
+}
+
+// toleranceProcessing 数值题允许误差时的响应处理：在误差范围内得满分
+func toleranceProcessing(tolerance float64) string {
+	t := formatFloat(tolerance)
+	return `<responseCondition><responseIf>` +
+		`<equal toleranceMode="absolute" tolerance="` + t + ` ` + t + `">` +
+		`<variable identifier="` + responseIdentifier + `"/><correct identifier="` + responseIdentifier + `"/></equal>` +
+		`<setOutcomeValue identifier="` + scoreIdentifier + `"><variable identifier="` + maxScoreIdentifier + `"/></setOutcomeValue>` +
+		`</responseIf></responseCondition>`
+}
+
+// choiceIdentifiers 选项ID不是合法的 QTI 标识符时按顺序重新编号
+func choiceIdentifiers(options []quiz.Option) map[string]string {
+	ids := make(map[string]string, len(options))
+	used := make(map[string]bool, len(options))
+	for i, o := range options {
+		id := o.ID
+		if !identifierPattern.MatchString(id) || used[id] {
+			id = fmt.Sprintf("choice_%d", i+1)
+		}
+		used[id] = true
+		ids[o.ID] = id
+	}
+	return ids
+}
+
+// promptParagraphs 题干按行拆分为段落
+func promptParagraphs(prompt string) []paragraphOut {
+	var paragraphs []paragraphOut
+	for _, line := range strings.Split(prompt, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paragraphs = append(paragraphs, paragraphOut{Text: line})
+		}
+	}
+	return paragraphs
+}
+
+// titleFromPrompt 取题干第一行作为标题
+func titleFromPrompt(prompt string) string {
+	title := strings.TrimSpace(prompt)
+	if i := strings.IndexByte(title, '\n'); i >= 0 {
+		title = strings.TrimSpace(title[:i])
+	}
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		title = string([]rune(title)[:maxTitleLength]) + "…"
+	}
+	if title == "" {
+		title = "Question"
+	}
+	return title
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode xml: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
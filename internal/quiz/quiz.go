@@ -0,0 +1,202 @@
+// Package quiz 测验题目的校验和自动评分
+//
+// 支持单选、多选、判断、简答和数值题。多选题按选对和选错的选项数给部分分；
+// 简答题与可接受的答案逐一比较，匹配不到时得 0 分并标记为待人工批改。
+package quiz
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// 题目类型
+const (
+	TypeSingleChoice   = "single_choice"
+	TypeMultipleChoice = "multiple_choice"
+	TypeTrueFalse      = "true_false"
+	TypeShortAnswer    = "short_answer"
+	TypeNumeric        = "numeric"
+)
+
+// 判断题的选项ID
+const (
+	ChoiceTrue  = "true"
+	ChoiceFalse = "false"
+)
+
+// Option 选择题的选项
+type Option struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// Key 标准答案
+type Key struct {
+	Choices       []string `json:"choices,omitempty"`        // 选择题的正确选项ID，判断题为 true 或 false
+	Texts         []string `json:"texts,omitempty"`          // 简答题可接受的答案，为空时全部人工批改
+	CaseSensitive bool     `json:"case_sensitive,omitempty"` // 简答题是否区分大小写
+	Number        *float64 `json:"number,omitempty"`         // 数值题的答案
+	Tolerance     float64  `json:"tolerance,omitempty"`      // 数值题允许的误差（绝对值）
+}
+
+// Question 一道题目
+type Question struct {
+	Type    string
+	Options []Option
+	Key     Key
+	Points  float64
+}
+
+// Response 学生对一道题目的作答
+type Response struct {
+	Choices []string `json:"choices,omitempty"`
+	Text    string   `json:"text,omitempty"`
+	Number  *float64 `json:"number,omitempty"`
+}
+
+// Result 一道题目的评分结果
+type Result struct {
+	Points      float64 `json:"points"`
+	Correct     bool    `json:"correct"`
+	NeedsReview bool    `json:"needs_review"`
+}
+
+// ValidType 判断是否为支持的题目类型
+func ValidType(t string) bool {
+	switch t {
+	case TypeSingleChoice, TypeMultipleChoice, TypeTrueFalse, TypeShortAnswer, TypeNumeric:
+		return true
+	}
+	return false
+}
+
+// Validate 校验题目的选项和标准答案
+func Validate(q Question) error {
+	if !ValidType(q.Type) {
+		return fmt.Errorf("unsupported question type %q", q.Type)
+	}
+	if q.Points <= 0 {
+		return fmt.Errorf("points must be positive")
+	}
+
+	switch q.Type {
+	case TypeSingleChoice, TypeMultipleChoice:
+		if len(q.Options) < 2 {
+			return fmt.Errorf("at least two options are required")
+		}
+		ids := make(map[string]bool, len(q.Options))
+		for _, o := range q.Options {
+			if o.ID == "" || ids[o.ID] {
+				return fmt.Errorf("option ids must be unique and non-empty")
+			}
+			ids[o.ID] = true
+		}
+		if len(q.Key.Choices) == 0 || (q.Type == TypeSingleChoice && len(q.Key.Choices) != 1) {
+			return fmt.Errorf("invalid number of correct choices")
+		}
+		for _, c := range q.Key.Choices {
+			if !ids[c] {
+				return fmt.Errorf("correct choice %q is not an option", c)
+			}
+		}
+	case TypeTrueFalse:
+		if len(q.Key.Choices) != 1 || (q.Key.Choices[0] != ChoiceTrue && q.Key.Choices[0] != ChoiceFalse) {
+			return fmt.Errorf("true/false answer must be %q or %q", ChoiceTrue, ChoiceFalse)
+		}
+	case TypeNumeric:
+		if q.Key.Number == nil {
+			return fmt.Errorf("numeric answer is required")
+		}
+		if q.Key.Tolerance < 0 {
+			return fmt.Errorf("tolerance must not be negative")
+		}
+	}
+	return nil
+}
+
+// Grade 按标准答案给一道题目评分
+func Grade(q Question, r Response) Result {
+	switch q.Type {
+	case TypeSingleChoice, TypeTrueFalse:
+		if len(r.Choices) == 1 && len(q.Key.Choices) == 1 && r.Choices[0] == q.Key.Choices[0] {
+			return Result{Points: q.Points, Correct: true}
+		}
+		return Result{}
+
+	case TypeMultipleChoice:
+		// 每个选对的选项得 1 份，选错的选项扣 1 份，最低 0 分
+		correct := make(map[string]bool, len(q.Key.Choices))
+		for _, c := range q.Key.Choices {
+			correct[c] = true
+		}
+		hits, wrong := 0, 0
+		seen := make(map[string]bool, len(r.Choices))
+		for _, c := range r.Choices {
+			if seen[c] {
+				continue
+			}
+			seen[c] = true
+			if correct[c] {
+				hits++
+			} else {
+				wrong++
+			}
+		}
+		if hits == len(correct) && wrong == 0 {
+			return Result{Points: q.Points, Correct: true}
+		}
+		share := float64(hits-wrong) / float64(len(correct))
+		if share < 0 {
+			share = 0
+		}
+		return Result{Points: RoundPoints(q.Points * share)}
+
+	case TypeShortAnswer:
+		// 没有作答时直接得 0 分，不需要人工批改
+		got := normalizeText(r.Text, q.Key.CaseSensitive)
+		if got == "" {
+			return Result{}
+		}
+		for _, t := range q.Key.Texts {
+			if got == normalizeText(t, q.Key.CaseSensitive) {
+				return Result{Points: q.Points, Correct: true}
+			}
+		}
+		// 没有参考答案或与参考答案不一致时需要人工批改
+		return Result{NeedsReview: true}
+
+	case TypeNumeric:
+		if r.Number != nil && q.Key.Number != nil &&
+			math.Abs(*r.Number-*q.Key.Number) <= q.Key.Tolerance+1e-9 {
+			return Result{Points: q.Points, Correct: true}
+		}
+		return Result{}
+	}
+	return Result{}
+}
+
+// Scale 将得分按满分换算为任务的分数（四舍五入）
+func Scale(earned, total float64, maxScore int) int {
+	if total <= 0 || earned <= 0 {
+		return 0
+	}
+	if earned > total {
+		earned = total
+	}
+	return int(math.Round(earned / total * float64(maxScore)))
+}
+
+// RoundPoints 得分保留两位小数
+func RoundPoints(points float64) float64 {
+	return math.Round(points*100) / 100
+}
+
+// normalizeText 去掉首尾空白并合并连续空白
+func normalizeText(s string, caseSensitive bool) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if !caseSensitive {
+		s = strings.ToLower(s)
+	}
+	return s
+}
@@ -0,0 +1,199 @@
+// Package rubric 评分标准的校验和按标准评分
+//
+// 评分标准由若干评分项组成，每个评分项有满分和若干等级（每个等级对应一个分数）。
+// 按标准评分时每个评分项必须且只能评一次，作业得分为各评分项得分之和。
+package rubric
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidRubric 评分标准无效
+	ErrInvalidRubric = errors.New("invalid rubric")
+	// ErrInvalidScores 评分项缺失、重复或不存在
+	ErrInvalidScores = errors.New("invalid criterion scores")
+	// ErrOutOfRange 评分项得分超出范围
+	ErrOutOfRange = errors.New("score out of range")
+	// ErrInvalidComment 行内批注的位置无效
+	ErrInvalidComment = errors.New("invalid inline comment")
+)
+
+// Level 评分项的一个等级
+type Level struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Points      int    `json:"points"`
+}
+
+// Criterion 一个评分项
+type Criterion struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	MaxPoints   int     `json:"max_points"` // 为 0 时取等级中的最高分
+	Levels      []Level `json:"levels"`
+}
+
+// ScoreInput 教师对一个评分项的评分，选择等级时不填 points 则取等级的分数
+type ScoreInput struct {
+	CriterionID string `json:"criterion_id"`
+	LevelID     string `json:"level_id"`
+	Points      *int   `json:"points"`
+	Comment     string `json:"comment"`
+}
+
+// Score 一个评分项的得分，保存评分时的评分项标题和满分，之后修改评分标准不影响已有评分
+type Score struct {
+	CriterionID string `json:"criterion_id"`
+	Title       string `json:"title"`
+	LevelID     string `json:"level_id,omitempty"`
+	LevelTitle  string `json:"level_title,omitempty"`
+	Points      int    `json:"points"`
+	MaxPoints   int    `json:"max_points"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// InlineComment 针对作业文本中一段内容的批注，Start 和 End 为字符（rune）位置，左闭右开
+type InlineComment struct {
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	Quote   string `json:"quote"` // 被批注的原文，保存时自动填写
+	Comment string `json:"comment"`
+}
+
+// Normalize 校验评分标准并补全ID和满分，返回新的切片
+// 评分项和等级的ID为空或重复时按顺序编号为 criterion_N、level_N
+func Normalize(criteria []Criterion) ([]Criterion, error) {
+	result := make([]Criterion, 0, len(criteria))
+	usedCriteria := make(map[string]bool, len(criteria))
+	for i, c := range criteria {
+		c.Title = strings.TrimSpace(c.Title)
+		if c.Title == "" {
+			return nil, fmt.Errorf("%w: criterion %d has no title", ErrInvalidRubric, i+1)
+		}
+		if c.ID == "" || usedCriteria[c.ID] {
+			c.ID = fmt.Sprintf("criterion_%d", i+1)
+		}
+		usedCriteria[c.ID] = true
+
+		levels := make([]Level, 0, len(c.Levels))
+		usedLevels := make(map[string]bool, len(c.Levels))
+		highest := 0
+		for j, l := range c.Levels {
+			l.Title = strings.TrimSpace(l.Title)
+			if l.Title == "" {
+				return nil, fmt.Errorf("%w: level %d of %q has no title", ErrInvalidRubric, j+1, c.Title)
+			}
+			if l.Points < 0 {
+				return nil, fmt.Errorf("%w: level %q of %q has negative points", ErrInvalidRubric, l.Title, c.Title)
+			}
+			if l.ID == "" || usedLevels[l.ID] {
+				l.ID = fmt.Sprintf("level_%d", j+1)
+			}
+			usedLevels[l.ID] = true
+			if l.Points > highest {
+				highest = l.Points
+			}
+			levels = append(levels, l)
+		}
+		c.Levels = levels
+
+		if c.MaxPoints == 0 {
+			c.MaxPoints = highest
+		}
+		if c.MaxPoints <= 0 {
+			return nil, fmt.Errorf("%w: criterion %q has no points", ErrInvalidRubric, c.Title)
+		}
+		if highest > c.MaxPoints {
+			return nil, fmt.Errorf("%w: levels of %q exceed its max points", ErrInvalidRubric, c.Title)
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// MaxTotal 评分标准的满分
+func MaxTotal(criteria []Criterion) int {
+	total := 0
+	for _, c := range criteria {
+		total += c.MaxPoints
+	}
+	return total
+}
+
+// Grade 按评分标准计算每个评分项的得分和总分
+func Grade(criteria []Criterion, inputs []ScoreInput) ([]Score, int, error) {
+	byID := make(map[string]ScoreInput, len(inputs))
+	for _, in := range inputs {
+		if _, ok := byID[in.CriterionID]; ok {
+			return nil, 0, fmt.Errorf("%w: criterion %q scored more than once", ErrInvalidScores, in.CriterionID)
+		}
+		byID[in.CriterionID] = in
+	}
+
+	scores := make([]Score, 0, len(criteria))
+	total := 0
+	for _, c := range criteria {
+		in, ok := byID[c.ID]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: criterion %q is not scored", ErrInvalidScores, c.Title)
+		}
+		delete(byID, c.ID)
+
+		score := Score{CriterionID: c.ID, Title: c.Title, MaxPoints: c.MaxPoints, Comment: strings.TrimSpace(in.Comment)}
+		if in.LevelID != "" {
+			level, ok := findLevel(c, in.LevelID)
+			if !ok {
+				return nil, 0, fmt.Errorf("%w: level %q not found in %q", ErrInvalidScores, in.LevelID, c.Title)
+			}
+			score.LevelID = level.ID
+			score.LevelTitle = level.Title
+			score.Points = level.Points
+		}
+		if in.Points != nil {
+			score.Points = *in.Points
+		} else if in.LevelID == "" {
+			return nil, 0, fmt.Errorf("%w: criterion %q has neither level nor points", ErrInvalidScores, c.Title)
+		}
+		if score.Points < 0 || score.Points > c.MaxPoints {
+			return nil, 0, fmt.Errorf("%w: %q should be between 0 and %d", ErrOutOfRange, c.Title, c.MaxPoints)
+		}
+		total += score.Points
+		scores = append(scores, score)
+	}
+	for id := range byID {
+		return nil, 0, fmt.Errorf("%w: criterion %q not found", ErrInvalidScores, id)
+	}
+	return scores, total, nil
+}
+
+// AnchorComments 校验行内批注的位置并填写被批注的原文，返回新的切片
+func AnchorComments(content string, comments []InlineComment) ([]InlineComment, error) {
+	runes := []rune(content)
+	result := make([]InlineComment, 0, len(comments))
+	for _, c := range comments {
+		c.Comment = strings.TrimSpace(c.Comment)
+		if c.Comment == "" {
+			return nil, fmt.Errorf("%w: empty comment", ErrInvalidComment)
+		}
+		if c.Start < 0 || c.End <= c.Start || c.End > len(runes) {
+			return nil, fmt.Errorf("%w: range [%d, %d) outside of %d characters", ErrInvalidComment, c.Start, c.End, len(runes))
+		}
+		c.Quote = string(runes[c.Start:c.End])
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+func findLevel(c Criterion, id string) (Level, bool) {
+	for _, l := range c.Levels {
+		if l.ID == id {
+			return l, true
+		}
+	}
+	return Level{}, false
+}
@@ -0,0 +1,229 @@
+// Package search 为 Postgres 全文检索准备文本：中文切分和结果摘要高亮
+//
+// Postgres 自带的解析器会把连续的汉字当作一个词，无法检索词中的部分内容。
+// 建索引前把中日韩文字切成单字和相邻二字组，查询时切成二字组，
+// 英文部分原样交给 english 配置做词干提取，因此不依赖 zhparser 等扩展。
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// isCJK 是否为需要切分的中日韩文字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// SegmentDocument 切分待索引的文本：中日韩文字输出单字和二字组，其他内容保持不变
+func SegmentDocument(text string) string {
+	return segment(text, true)
+}
+
+// SegmentQuery 切分查询文本：中日韩文字输出二字组（只有一个字时输出单字），
+// 其他内容保持不变，websearch_to_tsquery 的引号、OR 和减号语法仍然可用
+func SegmentQuery(text string) string {
+	return segment(text, false)
+}
+
+func segment(text string, withUnigrams bool) string {
+	var b strings.Builder
+	var run []rune
+	var prev rune // 中日韩片段前的最后一个字符
+
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		var tokens []string
+		if withUnigrams || len(run) == 1 {
+			for _, r := range run {
+				tokens = append(tokens, string(r))
+			}
+		}
+		for i := 0; i+1 < len(run); i++ {
+			tokens = append(tokens, string(run[i:i+2]))
+		}
+		joined := strings.Join(tokens, " ")
+		if !withUnigrams && prev == '-' && len(tokens) > 1 {
+			// 排除词切分后需要作为短语整体排除
+			joined = `"` + joined + `"`
+		} else {
+			b.WriteByte(' ')
+		}
+		b.WriteString(joined)
+		b.WriteByte(' ')
+		run = run[:0]
+	}
+
+	for _, r := range text {
+		if isCJK(r) {
+			run = append(run, r)
+			continue
+		}
+		flush()
+		b.WriteRune(r)
+		prev = r
+	}
+	flush()
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Terms 提取用于高亮的查询词：英文按词拆分并去掉常见词尾，中日韩文字按连续片段
+// 以减号开头的排除词和 OR 关键字不参与高亮
+func Terms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(term string) {
+		if term != "" && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	for _, field := range strings.Fields(query) {
+		if strings.HasPrefix(field, "-") || field == "OR" {
+			continue
+		}
+		var word, run []rune
+		flushWord := func() {
+			if len(word) > 0 {
+				add(stem(string(word)))
+				word = word[:0]
+			}
+		}
+		flushRun := func() {
+			if len(run) > 0 {
+				add(string(run))
+				run = run[:0]
+			}
+		}
+		for _, r := range strings.ToLower(field) {
+			switch {
+			case isCJK(r):
+				flushWord()
+				run = append(run, r)
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				flushRun()
+				word = append(word, r)
+			default:
+				flushWord()
+				flushRun()
+			}
+		}
+		flushWord()
+		flushRun()
+	}
+	return terms
+}
+
+// stem 去掉常见英文词尾，用于在原文中按前缀匹配同一词的不同形式
+func stem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+// Snippet 从原文中截取包含查询词的片段（最多 maxRunes 个字符），
+// 匹配内容用 <mark></mark> 包裹，其余文本做 HTML 转义；没有匹配时返回开头部分
+func Snippet(text string, terms []string, maxRunes int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	type span struct{ start, end int }
+	var matches []span
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) == 0 {
+			continue
+		}
+		cjk := isCJK(t[0])
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if !hasPrefixAt(lower, i, t) {
+				continue
+			}
+			end := i + len(t)
+			if !cjk {
+				// 英文按词首匹配，并高亮到词尾
+				if i > 0 && isWordRune(lower[i-1]) {
+					continue
+				}
+				for end < len(lower) && isWordRune(lower[end]) {
+					end++
+				}
+			}
+			matches = append(matches, span{i, end})
+			i = end - 1
+		}
+	}
+
+	start := 0
+	if len(matches) > 0 {
+		first := matches[0].start
+		for _, m := range matches {
+			if m.start < first {
+				first = m.start
+			}
+		}
+		start = first - maxRunes/4
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + maxRunes
+	if end > len(runes) {
+		end = len(runes)
+		if start = end - maxRunes; start < 0 {
+			start = 0
+		}
+	}
+
+	marked := make([]bool, len(runes))
+	for _, m := range matches {
+		for i := m.start; i < m.end; i++ {
+			marked[i] = true
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; i++ {
+		if marked[i] && (i == start || !marked[i-1]) {
+			b.WriteString("<mark>")
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		if marked[i] && (i == end-1 || !marked[i+1]) {
+			b.WriteString("</mark>")
+		}
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func hasPrefixAt(text []rune, at int, prefix []rune) bool {
+	for j, r := range prefix {
+		if text[at+j] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
@@ -1,8 +1,6 @@
 package service
 
 import (
-	"bytes"
-	"context"
 	"fmt"
 	"time"
 
@@ -11,7 +9,7 @@ import (
 	"online-learning-platform/internal/database"
 	apperrors "online-learning-platform/internal/errors"
 	"online-learning-platform/internal/models"
-	"online-learning-platform/internal/oss"
+	"online-learning-platform/internal/rubric"
 )
 
 // AnswerService 作业提交/评分服务
@@ -25,25 +23,35 @@ func NewAnswerService() *AnswerService {
 // SubmitAnswerRequest 学生提交作业请求
 type SubmitAnswerRequest struct {
 	AnswerContent string `json:"answer_content"`
-	Type          string `json:"type"` // text, image_url
+	Type          string `json:"type"`      // text, image_url
+	UploadID      uint   `json:"upload_id"` // 已确认的作业文件上传，优先于 AnswerContent
 }
 
 // GradeAnswerRequest 教师评分请求
+// 任务设置了评分标准时逐项评分，总分为各评分项得分之和，score 可省略；否则必须填写 score
 type GradeAnswerRequest struct {
-	BranchID uint `json:"branch_id" binding:"required"` // 答案所在的分支ID
-	Score    int  `json:"score" binding:"required"`
+	BranchID       uint                   `json:"branch_id" binding:"required"` // 答案所在的分支ID
+	Score          *int                   `json:"score"`                        // 扣除迟交分数前的得分，范围 0 到任务满分
+	Criteria       []rubric.ScoreInput    `json:"criteria"`                     // 各评分项的得分
+	Feedback       string                 `json:"feedback"`                     // 评语
+	InlineComments []rubric.InlineComment `json:"inline_comments"`              // 行内批注，仅用于文本作业
 }
 
-// SubmitAnswer 学生提交作业
-func (s *AnswerService) SubmitAnswer(userID, branchID, taskID uint, req *SubmitAnswerRequest, fileBytes []byte, fileName string) (*models.Answers, error) {
-	// 校验任务是否存在
-	centralDB := database.GetCentralDB()
-	var task models.Tasks
-	if err := centralDB.Where("task_id = ?", taskID).First(&task).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, apperrors.ErrTaskNotFound
+// SubmitAnswer 学生提交作业，每次提交保存为一次新的尝试
+// 提交文件时先通过上传接口直传并确认，再传入 upload_id
+func (s *AnswerService) SubmitAnswer(userID, branchID, taskID uint, req *SubmitAnswerRequest) (*AnswerDetail, error) {
+	task, courseID, err := findSubmittableTask(userID, branchID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.TaskType == TaskTypeQuiz {
+		hasQuestions, err := hasQuizQuestions(taskID)
+		if err != nil {
+			return nil, err
+		}
+		if hasQuestions {
+			return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "测验任务请逐题作答后提交")
 		}
-		return nil, fmt.Errorf("failed to verify task: %w", err)
 	}
 
 	branchDB, err := database.GetBranchDBByBranchID(branchID)
@@ -51,111 +59,143 @@ func (s *AnswerService) SubmitAnswer(userID, branchID, taskID uint, req *SubmitA
 		return nil, err
 	}
 
-	if req.AnswerContent == "" && len(fileBytes) == 0 {
+	if req.AnswerContent == "" && req.UploadID == 0 {
 		return nil, apperrors.ErrInvalidParam
 	}
 
 	answerContent := req.AnswerContent
 	answerType := req.Type
-	objectKey := ""
-
-	// 如果上传了图片则优先使用OSS
-	if len(fileBytes) > 0 && fileName != "" {
-		objectKey = fmt.Sprintf("answers/%d/%d/%d/%d_%s", branchID, userID, taskID, time.Now().Unix(), fileName)
-		ossURL, err := oss.UploadReader(context.Background(), objectKey, bytes.NewReader(fileBytes))
-		if err != nil {
-			return nil, fmt.Errorf("failed to upload answer file to OSS: %w", err)
+	if req.UploadID == 0 {
+		if err := rejectObjectURL(answerContent); err != nil {
+			return nil, err
 		}
-		answerContent = ossURL
-		answerType = "image_url"
 	}
+	now := time.Now()
 
-	if answerType == "" {
-		answerType = "text"
+	// 截止时间按学生的延期计算，迟交状态在提交时确定
+	lateStatus, err := checkSubmissionDeadline(task, branchID, userID, now)
+	if err != nil {
+		return nil, err
 	}
 
-	now := time.Now()
-
 	var answer models.Answers
-	tx := branchDB.Where("task_id = ? AND user_id = ?", taskID, userID).First(&answer)
-	if tx.Error != nil && tx.Error != gorm.ErrRecordNotFound {
-		if objectKey != "" {
-			_ = oss.DeleteObject(objectKey)
-		}
-		return nil, fmt.Errorf("failed to check existing answer: %w", tx.Error)
-	}
-
-	if tx.Error == nil {
-		// 更新已有作业
-		answer.AnswerContent = answerContent
-		answer.Type = answerType
-		answer.SubmittedAt = now
-		answer.Score = 0
-		answer.IsGraded = false
-		answer.GradedBy = nil
-		answer.BranchID = branchID
-
-		if err := branchDB.Save(&answer).Error; err != nil {
-			if objectKey != "" {
-				_ = oss.DeleteObject(objectKey)
+	created := false
+	if err := branchDB.Transaction(func(tx *gorm.DB) error {
+		// 上传的文件优先于文本内容
+		if req.UploadID != 0 {
+			objectURL, err := attachUpload(tx, UploadPurposeAnswer, req.UploadID, userID, branchID, func(u *models.Uploads) bool {
+				return u.TaskID == taskID
+			}, taskUploadPolicy(task))
+			if err != nil {
+				return err
 			}
-			return nil, fmt.Errorf("failed to update answer: %w", err)
+			answerContent = objectURL
+			answerType = "image_url"
+		}
+		if answerType == "" {
+			answerType = "text"
 		}
-		return &answer, nil
+
+		answer = models.Answers{
+			TaskID:        taskID,
+			TaskRevision:  task.Revision,
+			BranchID:      branchID,
+			UserID:        userID,
+			AnswerContent: answerContent,
+			Type:          answerType,
+			SubmittedAt:   now,
+		}
+		setLateStatus(&answer, lateStatus)
+		var err error
+		created, err = createAttempt(tx, task, courseID, &answer)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	// 首次提交计入课程进度
+	if created {
+		refreshStudentProgress(branchDB, userID, courseID)
 	}
 
-	// 创建新作业
-	answer = models.Answers{
-		TaskID:        taskID,
-		BranchID:      branchID,
-		UserID:        userID,
-		AnswerContent: answerContent,
-		Type:          answerType,
-		SubmittedAt:   now,
+	return answerDetail(&answer)
+}
+
+// findSubmittableTask 查询学生可以提交作业的任务，返回任务和所属课程ID
+// 未发布课时下的任务对学生不可见，课程结束后不再接受提交，未报名或仍在候补的学生不能提交
+func findSubmittableTask(userID, branchID, taskID uint) (*models.Tasks, uint, error) {
+	centralDB := database.GetCentralDB()
+	var task models.Tasks
+	if err := centralDB.Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, 0, apperrors.ErrTaskNotFound
+		}
+		return nil, 0, fmt.Errorf("failed to verify task: %w", err)
 	}
 
-	if err := branchDB.Create(&answer).Error; err != nil {
-		if objectKey != "" {
-			_ = oss.DeleteObject(objectKey)
+	if err := ensureLessonVisible(task.LessonID); err != nil {
+		if err == apperrors.ErrLessonNotFound {
+			return nil, 0, apperrors.ErrTaskNotFound
 		}
-		return nil, fmt.Errorf("failed to create answer: %w", err)
+		return nil, 0, err
 	}
 
-	return &answer, nil
+	var courseID uint
+	if err := centralDB.Model(&models.Lessons{}).Select("course_id").Where("lesson_id = ?", task.LessonID).Scan(&courseID).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to verify lesson: %w", err)
+	}
+	if err := ensureCourseWritable(courseID); err != nil {
+		return nil, 0, err
+	}
+
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	if err != nil {
+		return nil, 0, err
+	}
+	learning, err := getLearning(branchDB, userID, courseID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if learning.Status == LearningStatusWaitlisted {
+		return nil, 0, apperrors.ErrNotEnrolled
+	}
+	return &task, courseID, nil
 }
 
-// GetStudentAnswer 学生查询自己的作业
-func (s *AnswerService) GetStudentAnswer(userID, branchID, taskID uint) (*models.Answers, error) {
+// GetStudentAnswer 学生查询自己计入成绩的作业，包含评分项得分、评语和行内批注
+func (s *AnswerService) GetStudentAnswer(userID, branchID, taskID uint) (*AnswerDetail, error) {
 	branchDB, err := database.GetBranchDBByBranchID(branchID)
 	if err != nil {
 		return nil, err
 	}
 
 	var answer models.Answers
-	if err := branchDB.Where("task_id = ? AND user_id = ?", taskID, userID).First(&answer).Error; err != nil {
+	if err := branchDB.Where("task_id = ? AND user_id = ? AND is_counted = ?", taskID, userID, true).First(&answer).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, apperrors.ErrAnswerNotFound
 		}
 		return nil, fmt.Errorf("failed to query answer: %w", err)
 	}
-	return &answer, nil
+	return answerDetail(&answer)
 }
 
 // AnswerWithStudentInfo 包含学生信息的作业
 type AnswerWithStudentInfo struct {
-	models.Answers
+	AnswerDetail
 	StudentFirstName string `json:"student_first_name"`
 	StudentLastName  string `json:"student_last_name"`
+	AttemptCount     int    `json:"attempt_count"` // 学生在该任务上的提交次数
 }
 
-// ListAnswersForTask 教师查看任务的所有作业（跨所有分支，因为课程是共享的）
+// ListAnswersForTask 教学团队查看任务的所有作业（跨所有分支，因为课程是共享的）
+// 每个学生只列出计入成绩的一次提交，全部提交通过 ListAttempts 查看
 func (s *AnswerService) ListAnswersForTask(instructorUserID, branchID, taskID uint) ([]AnswerWithStudentInfo, error) {
 	if _, err := ensureInstructorRecord(instructorUserID, branchID); err != nil {
 		return nil, err
 	}
 
-	// 校验任务属于该教师
-	if err := validateTaskOwner(taskID, instructorUserID, branchID); err != nil {
+	// 校验教师属于任务所属课程的教学团队
+	if err := validateTaskStaff(taskID, instructorUserID, branchID); err != nil {
 		return nil, err
 	}
 
@@ -166,16 +206,18 @@ func (s *AnswerService) ListAnswersForTask(instructorUserID, branchID, taskID ui
 	// 使用JOIN查询获取学生姓名
 	type result struct {
 		models.Answers
-		FirstName string
-		LastName  string
+		FirstName    string
+		LastName     string
+		AttemptCount int
 	}
 
 	for _, branchDB := range branchDBs {
 		var results []result
 		if err := branchDB.Table("answers").
-			Select("answers.*, users.first_name, users.last_name").
+			Select("answers.*, users.first_name, users.last_name, "+
+				"(SELECT COUNT(*) FROM answers AS a WHERE a.task_id = answers.task_id AND a.user_id = answers.user_id) AS attempt_count").
 			Joins("JOIN users ON users.user_id = answers.user_id").
-			Where("answers.task_id = ?", taskID).
+			Where("answers.task_id = ? AND answers.is_counted = ?", taskID, true).
 			Order("answers.submitted_at DESC").
 			Scan(&results).Error; err != nil {
 			// 如果某个分支查询失败，继续查询其他分支
@@ -184,10 +226,15 @@ func (s *AnswerService) ListAnswersForTask(instructorUserID, branchID, taskID ui
 
 		// 转换为AnswerWithStudentInfo
 		for _, r := range results {
+			detail, err := answerDetail(&r.Answers)
+			if err != nil {
+				return nil, err
+			}
 			allAnswers = append(allAnswers, AnswerWithStudentInfo{
-				Answers:          r.Answers,
+				AnswerDetail:     *detail,
 				StudentFirstName: r.FirstName,
 				StudentLastName:  r.LastName,
+				AttemptCount:     r.AttemptCount,
 			})
 		}
 	}
@@ -195,83 +242,124 @@ func (s *AnswerService) ListAnswersForTask(instructorUserID, branchID, taskID ui
 	return allAnswers, nil
 }
 
-// GradeAnswer 教师评分
-// answerBranchID: 答案所在的分支ID（从请求中获取，确保找到正确的答案）
-func (s *AnswerService) GradeAnswer(instructorUserID, instructorBranchID, answerID, answerBranchID uint, score int) (*models.Answers, error) {
-	if _, err := ensureInstructorRecord(instructorUserID, instructorBranchID); err != nil {
+// GradeAnswer 教学团队成员评分，分数不能超出任务满分，可以附带评语和行内批注
+// req.BranchID: 答案所在的分支ID（从请求中获取，确保找到正确的答案）
+func (s *AnswerService) GradeAnswer(instructorUserID, instructorBranchID, answerID uint, req *GradeAnswerRequest) (*AnswerDetail, error) {
+	answerBranchID := req.BranchID
+	branchDB, answer, err := findAnswerForStaff(instructorUserID, instructorBranchID, answerID, answerBranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 测验作业的分数由逐题得分计算
+	if answer.Type == AnswerTypeQuiz {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "测验作业请逐题调整得分")
+	}
+
+	var task models.Tasks
+	if err := database.GetCentralDB().Unscoped().Where("task_id = ?", answer.TaskID).First(&task).Error; err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	score, scores, err := gradeScore(&task, req)
+	if err != nil {
 		return nil, err
 	}
+	if err := applyFeedback(answer, scores, req); err != nil {
+		return nil, err
+	}
+
+	// 更新答案，迟交扣分按任务当前的迟交策略计算
+	applyLatePenalty(&task, answer, score)
+	answer.IsGraded = true
+	answer.GradedBy = gradedByOnBranch(branchDB, instructorUserID, answerBranchID)
+
+	// 按最高分计分时，评分可能改变计入成绩的提交
+	if err := branchDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(answer).Error; err != nil {
+			return fmt.Errorf("failed to grade answer: %w", err)
+		}
+		return recountAttempts(tx, &task, answer)
+	}); err != nil {
+		return nil, err
+	}
+
+	return answerDetail(answer)
+}
+
+// findAnswerForStaff 查询作业，并确认当前教师属于作业所属课程的教学团队
+// answerBranchID 为作业所在的分支：不同分支的 answer_id 可能重复，必须同时匹配分支
+func findAnswerForStaff(instructorUserID, instructorBranchID, answerID, answerBranchID uint) (*gorm.DB, *models.Answers, error) {
+	if _, err := ensureInstructorRecord(instructorUserID, instructorBranchID); err != nil {
+		return nil, nil, err
+	}
 
-	// 根据答案所在的分支ID，直接查询对应的分支数据库
-	// 这样可以避免因为不同分支中 answer_id 重复而找到错误的答案
 	branchDB, err := database.GetBranchDBByBranchID(answerBranchID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get branch database: %w", err)
+		return nil, nil, fmt.Errorf("failed to get branch database: %w", err)
 	}
 
 	var answer models.Answers
-	// 同时匹配 answer_id 和 branch_id，确保找到正确的答案
 	if err := branchDB.Where("answer_id = ? AND branch_id = ?", answerID, answerBranchID).First(&answer).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, apperrors.ErrAnswerNotFound
+			return nil, nil, apperrors.ErrAnswerNotFound
 		}
-		return nil, fmt.Errorf("failed to get answer: %w", err)
+		return nil, nil, fmt.Errorf("failed to get answer: %w", err)
 	}
 
-	// 更新答案
-	answer.Score = score
-	answer.IsGraded = true
-	
-	// 注意：graded_by 字段有外键约束，如果教师在其他分支，该分支的users表中可能没有该教师
-	// 因此，我们需要检查该分支是否有该教师，如果没有则不设置 graded_by
-	// 或者，我们可以尝试在该分支中查找或创建教师记录
-	// 但最简单的方法是：如果教师在该分支存在，则设置 graded_by；否则设置为 nil
-	var teacherUser models.Users
-	if err := branchDB.Where("user_id = ? AND branch_id = ?", instructorUserID, answerBranchID).First(&teacherUser).Error; err == nil {
-		// 教师在该分支存在，可以设置 graded_by
-		answer.GradedBy = &instructorUserID
-	} else {
-		// 教师不在该分支，不设置 graded_by（避免外键约束错误）
-		// 但我们可以通过其他方式记录评分教师（比如在注释中，或者不记录）
-		answer.GradedBy = nil
+	// 主讲、协同教师和助教都可以批改
+	if err := validateTaskStaff(answer.TaskID, instructorUserID, instructorBranchID); err != nil {
+		return nil, nil, err
 	}
+	return branchDB, &answer, nil
+}
 
-	if err := branchDB.Save(&answer).Error; err != nil {
-		return nil, fmt.Errorf("failed to grade answer: %w", err)
+// gradedByOnBranch 批改教师在作业所在分支有用户记录时返回其ID，否则返回 nil
+// graded_by 有外键约束，教师在其他分支时该分支的 users 表中没有该教师
+func gradedByOnBranch(branchDB *gorm.DB, instructorUserID, answerBranchID uint) *uint {
+	var teacherUser models.Users
+	if err := branchDB.Where("user_id = ? AND branch_id = ?", instructorUserID, answerBranchID).First(&teacherUser).Error; err != nil {
+		return nil
 	}
-
-	return &answer, nil
+	return &instructorUserID
 }
 
-// validateTaskOwner 确认任务属于当前教师
+// validateTaskOwner 确认当前教师可以编辑任务（任务所属课程的主讲或协同教师）
 func validateTaskOwner(taskID, instructorUserID, branchID uint) error {
-	instructorRecord, err := ensureInstructorRecord(instructorUserID, branchID)
+	courseID, err := getTaskCourseID(taskID)
 	if err != nil {
 		return err
 	}
+	return validateCourseOwner(courseID, instructorUserID, branchID)
+}
 
-	db := database.GetCentralDB()
-	type result struct {
-		TaskID       uint
-		InstructorID uint
+// validateTaskStaff 确认当前教师属于任务所属课程的教学团队（包括助教），可以查看和批改作业
+func validateTaskStaff(taskID, instructorUserID, branchID uint) error {
+	courseID, err := getTaskCourseID(taskID)
+	if err != nil {
+		return err
 	}
-	var res result
+	return validateCourseStaff(courseID, instructorUserID, branchID)
+}
 
-	query := `SELECT tasks.task_id, courses.instructor_id
-			  FROM tasks
-			  JOIN lessons ON lessons.lesson_id = tasks.lesson_id
-			  JOIN courses ON courses.course_id = lessons.course_id
-			  WHERE tasks.task_id = ?`
+// getTaskCourseID 查询任务所属的课程，任务或其课时已删除时返回 ErrTaskNotFound
+func getTaskCourseID(taskID uint) (uint, error) {
+	db := database.GetCentralDB()
 
-	if err := db.Raw(query, taskID).Scan(&res).Error; err != nil {
-		if err == gorm.ErrRecordNotFound || res.TaskID == 0 {
-			return apperrors.ErrTaskNotFound
+	var task models.Tasks
+	if err := db.Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, apperrors.ErrTaskNotFound
 		}
-		return fmt.Errorf("failed to verify task owner: %w", err)
+		return 0, fmt.Errorf("failed to verify task owner: %w", err)
 	}
 
-	if res.InstructorID != instructorRecord.InstructorID {
-		return apperrors.ErrNotCourseInstructor
+	var lesson models.Lessons
+	if err := db.Where("lesson_id = ?", task.LessonID).First(&lesson).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, apperrors.ErrTaskNotFound
+		}
+		return 0, fmt.Errorf("failed to verify task owner: %w", err)
 	}
-	return nil
+	return lesson.CourseID, nil
 }
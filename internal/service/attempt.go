@@ -0,0 +1,341 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"online-learning-platform/internal/attempt"
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/logger"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/pkg/utils"
+)
+
+// AttemptHistory 学生在任务上的全部提交
+type AttemptHistory struct {
+	TaskID        uint           `json:"task_id"`
+	MaxAttempts   int            `json:"max_attempts"`   // 为 0 时不限制
+	AttemptPolicy string         `json:"attempt_policy"` // latest, highest, first
+	Remaining     int            `json:"remaining"`      // 剩余提交次数，不限制时为 -1
+	Attempts      []AnswerDetail `json:"attempts"`
+}
+
+// ResponseChange 两次测验提交中一道题目的作答变化
+type ResponseChange struct {
+	QuestionID   uint            `json:"question_id"`
+	FromResponse json.RawMessage `json:"from_response"`
+	ToResponse   json.RawMessage `json:"to_response"`
+	FromPoints   float64         `json:"from_points"`
+	ToPoints     float64         `json:"to_points"`
+}
+
+// AttemptComparison 同一学生两次提交的对比
+type AttemptComparison struct {
+	TaskID    uint                `json:"task_id"`
+	UserID    uint                `json:"user_id"`
+	From      AnswerDetail        `json:"from"`
+	To        AnswerDetail        `json:"to"`
+	Changes   []utils.FieldChange `json:"changes"`
+	Responses []ResponseChange    `json:"responses,omitempty"` // 测验作业中作答或得分不同的题目
+}
+
+// attemptDiffIgnored 对比提交时忽略的字段
+var attemptDiffIgnored = []string{"answer_id", "attempt", "is_counted", "created_at", "updated_at", "branch", "user", "grader"}
+
+// ListMyAttempts 学生查看自己在任务上的全部提交
+func (s *AnswerService) ListMyAttempts(userID, branchID, taskID uint) (*AttemptHistory, error) {
+	var task models.Tasks
+	if err := database.GetCentralDB().Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if err := ensureLessonVisible(task.LessonID); err != nil {
+		if err == apperrors.ErrLessonNotFound {
+			return nil, apperrors.ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := listAttempts(branchDB, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	details, err := answerDetails(attempts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttemptHistory{
+		TaskID:        taskID,
+		MaxAttempts:   task.MaxAttempts,
+		AttemptPolicy: attemptPolicy(&task),
+		Remaining:     attempt.Remaining(task.MaxAttempts, len(attempts)),
+		Attempts:      details,
+	}, nil
+}
+
+// ListAttempts 教学团队查看学生在该作业所属任务上的全部提交
+func (s *AnswerService) ListAttempts(instructorUserID, instructorBranchID, answerID, answerBranchID uint) (*AttemptHistory, error) {
+	branchDB, answer, err := findAnswerForStaff(instructorUserID, instructorBranchID, answerID, answerBranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var task models.Tasks
+	if err := database.GetCentralDB().Unscoped().Where("task_id = ?", answer.TaskID).First(&task).Error; err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	attempts, err := listAttempts(branchDB, answer.TaskID, answer.UserID)
+	if err != nil {
+		return nil, err
+	}
+	details, err := answerDetails(attempts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttemptHistory{
+		TaskID:        answer.TaskID,
+		MaxAttempts:   task.MaxAttempts,
+		AttemptPolicy: attemptPolicy(&task),
+		Remaining:     attempt.Remaining(task.MaxAttempts, len(attempts)),
+		Attempts:      details,
+	}, nil
+}
+
+// CompareAttempts 教学团队对比同一学生的两次提交，测验作业同时逐题对比作答和得分
+func (s *AnswerService) CompareAttempts(instructorUserID, instructorBranchID, answerID, otherAnswerID, answerBranchID uint) (*AttemptComparison, error) {
+	branchDB, from, err := findAnswerForStaff(instructorUserID, instructorBranchID, answerID, answerBranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var to models.Answers
+	if err := branchDB.Where("answer_id = ? AND task_id = ? AND user_id = ?", otherAnswerID, from.TaskID, from.UserID).First(&to).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NewAppError(apperrors.ErrCodeAnswerNotFound, "要对比的作业不存在或不属于同一学生的同一任务")
+		}
+		return nil, fmt.Errorf("failed to get answer: %w", err)
+	}
+	// 按提交顺序对比
+	if to.Attempt < from.Attempt {
+		*from, to = to, *from
+	}
+
+	// 在签名前对比，避免签名参数被当作内容变化
+	fromJSON, err := json.Marshal(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode answer: %w", err)
+	}
+	toJSON, err := json.Marshal(&to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode answer: %w", err)
+	}
+	changes, err := utils.DiffJSONFields(fromJSON, toJSON, attemptDiffIgnored...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff attempts: %w", err)
+	}
+
+	comparison := &AttemptComparison{
+		TaskID:  from.TaskID,
+		UserID:  from.UserID,
+		Changes: changes,
+	}
+	if from.Type == AnswerTypeQuiz && to.Type == AnswerTypeQuiz {
+		if comparison.Responses, err = compareQuizResponses(branchDB, from.AnswerID, to.AnswerID); err != nil {
+			return nil, err
+		}
+	}
+
+	fromDetail, err := answerDetail(from)
+	if err != nil {
+		return nil, err
+	}
+	toDetail, err := answerDetail(&to)
+	if err != nil {
+		return nil, err
+	}
+	comparison.From = *fromDetail
+	comparison.To = *toDetail
+	return comparison, nil
+}
+
+// compareQuizResponses 逐题对比两次测验提交，只返回作答或得分不同的题目
+func compareQuizResponses(db *gorm.DB, fromAnswerID, toAnswerID uint) ([]ResponseChange, error) {
+	fromResponses, err := loadQuizResponses(db, fromAnswerID)
+	if err != nil {
+		return nil, err
+	}
+	toResponses, err := loadQuizResponses(db, toAnswerID)
+	if err != nil {
+		return nil, err
+	}
+
+	byQuestion := make(map[uint]*models.QuizResponses, len(fromResponses))
+	for i := range fromResponses {
+		byQuestion[fromResponses[i].QuestionID] = &fromResponses[i]
+	}
+
+	changes := make([]ResponseChange, 0)
+	for _, r := range toResponses {
+		change := ResponseChange{
+			QuestionID: r.QuestionID,
+			ToResponse: json.RawMessage(r.Response),
+			ToPoints:   r.Points(),
+		}
+		if prev, ok := byQuestion[r.QuestionID]; ok {
+			delete(byQuestion, r.QuestionID)
+			if prev.Response == r.Response && prev.Points() == r.Points() {
+				continue
+			}
+			change.FromResponse = json.RawMessage(prev.Response)
+			change.FromPoints = prev.Points()
+		}
+		changes = append(changes, change)
+	}
+	// 只在较早一次中作答的题目（之后被删除的题目）
+	for _, prev := range fromResponses {
+		if _, ok := byQuestion[prev.QuestionID]; ok {
+			changes = append(changes, ResponseChange{
+				QuestionID:   prev.QuestionID,
+				FromResponse: json.RawMessage(prev.Response),
+				FromPoints:   prev.Points(),
+			})
+		}
+	}
+	return changes, nil
+}
+
+// createAttempt 保存学生的一次提交并重新选出计入成绩的提交，返回是否为首次提交
+// 达到任务的最大提交次数时返回 ErrAttemptsExhausted
+func createAttempt(tx *gorm.DB, task *models.Tasks, courseID uint, answer *models.Answers) (bool, error) {
+	// 锁定学生的选课记录串行化同一学生的提交：首次提交时还没有可锁定的提交记录，
+	// 只锁已有提交无法阻止并发提交得到相同的次序号、绕过提交次数限制
+	var learning models.Learning
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND course_id = ?", answer.UserID, courseID).
+		First(&learning).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, apperrors.ErrNotEnrolled
+		}
+		return false, fmt.Errorf("failed to lock learning record: %w", err)
+	}
+
+	var previous []models.Answers
+	if err := tx.Where("task_id = ? AND user_id = ?", answer.TaskID, answer.UserID).
+		Find(&previous).Error; err != nil {
+		return false, fmt.Errorf("failed to check existing answers: %w", err)
+	}
+	if attempt.Remaining(task.MaxAttempts, len(previous)) == 0 {
+		return false, apperrors.NewAppError(apperrors.ErrCodeAttemptsExhausted,
+			fmt.Sprintf("%s（%d 次）", apperrors.ErrAttemptsExhausted.Message, task.MaxAttempts))
+	}
+
+	answer.Attempt = 1
+	for _, a := range previous {
+		if a.Attempt >= answer.Attempt {
+			answer.Attempt = a.Attempt + 1
+		}
+	}
+	if err := tx.Create(answer).Error; err != nil {
+		return false, fmt.Errorf("failed to create answer: %w", err)
+	}
+	if err := recountAttempts(tx, task, answer); err != nil {
+		return false, err
+	}
+	return len(previous) == 0, nil
+}
+
+// recountAttempts 按任务的计分策略重新选出学生计入成绩的提交，并同步 answer 的 is_counted
+func recountAttempts(tx *gorm.DB, task *models.Tasks, answer *models.Answers) error {
+	counted, err := updateCountedAttempt(tx, attemptPolicy(task), answer.TaskID, answer.UserID)
+	if err != nil {
+		return err
+	}
+	answer.IsCounted = answer.AnswerID == counted
+	return nil
+}
+
+// updateCountedAttempt 选出计入成绩的提交并更新 is_counted，返回其作业ID
+func updateCountedAttempt(tx *gorm.DB, policy string, taskID, userID uint) (uint, error) {
+	var attempts []models.Answers
+	if err := tx.Select("answer_id", "attempt", "score", "is_graded").
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Find(&attempts).Error; err != nil {
+		return 0, fmt.Errorf("failed to load attempts: %w", err)
+	}
+
+	candidates := make([]attempt.Attempt, 0, len(attempts))
+	for _, a := range attempts {
+		candidates = append(candidates, attempt.Attempt{Number: a.Attempt, Score: a.Score, Graded: a.IsGraded})
+	}
+	number := attempt.Counted(policy, candidates)
+
+	var counted uint
+	for _, a := range attempts {
+		if a.Attempt == number {
+			counted = a.AnswerID
+		}
+	}
+	if err := tx.Model(&models.Answers{}).
+		Where("task_id = ? AND user_id = ? AND answer_id <> ? AND is_counted = ?", taskID, userID, counted, true).
+		Update("is_counted", false).Error; err != nil {
+		return 0, fmt.Errorf("failed to update counted attempt: %w", err)
+	}
+	if err := tx.Model(&models.Answers{}).
+		Where("answer_id = ?", counted).
+		Update("is_counted", true).Error; err != nil {
+		return 0, fmt.Errorf("failed to update counted attempt: %w", err)
+	}
+	return counted, nil
+}
+
+// recountTaskAttempts 任务的计分策略变化后，在所有分支重新选出每个学生计入成绩的提交
+func recountTaskAttempts(task *models.Tasks) {
+	policy := attemptPolicy(task)
+	for bID, db := range database.GetAllBranchDBs() {
+		var userIDs []uint
+		if err := db.Model(&models.Answers{}).Where("task_id = ?", task.TaskID).
+			Distinct("user_id").Pluck("user_id", &userIDs).Error; err != nil {
+			logger.Warnf("task %d: failed to load students on branch %d: %v", task.TaskID, bID, err)
+			continue
+		}
+		for _, userID := range userIDs {
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				_, err := updateCountedAttempt(tx, policy, task.TaskID, userID)
+				return err
+			}); err != nil {
+				logger.Warnf("task %d: failed to recount attempts of user %d on branch %d: %v", task.TaskID, userID, bID, err)
+			}
+		}
+	}
+}
+
+// listAttempts 按提交顺序列出学生在任务上的全部提交
+func listAttempts(db *gorm.DB, taskID, userID uint) ([]models.Answers, error) {
+	var attempts []models.Answers
+	if err := db.Where("task_id = ? AND user_id = ?", taskID, userID).
+		Order("attempt ASC").Find(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list attempts: %w", err)
+	}
+	return attempts, nil
+}
+
+// attemptPolicy 任务的计分策略，未设置时取最近一次提交
+func attemptPolicy(task *models.Tasks) string {
+	if task.AttemptPolicy == "" {
+		return attempt.PolicyLatest
+	}
+	return task.AttemptPolicy
+}
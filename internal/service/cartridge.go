@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/cartridge"
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/logger"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/internal/oss"
+	"online-learning-platform/internal/upload"
+)
+
+// CartridgeImportResult 课程包导入结果
+type CartridgeImportResult struct {
+	Course   *CourseInfo       `json:"course"`
+	Chapters int               `json:"chapters"`
+	Lessons  int               `json:"lessons"`
+	Tasks    int               `json:"tasks"`
+	Issues   []cartridge.Issue `json:"issues"` // 未导入或部分导入的条目
+}
+
+// ExportCourseCartridge 加载课程完整结构（包括草稿）用于导出 Common Cartridge 包
+// 本Bucket中的课时内容在 cartridge.Write 写出时才从OSS下载，其他链接按外部链接导出
+func (s *CourseService) ExportCourseCartridge(courseID, instructorUserID, branchID uint) (*cartridge.Course, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	course, err := getCourseByID(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+
+	var chapters []models.Chapters
+	if err := db.Where("course_id = ?", courseID).Order("chapter_order ASC").Find(&chapters).Error; err != nil {
+		return nil, fmt.Errorf("failed to load chapters: %w", err)
+	}
+	var lessons []models.Lessons
+	if err := db.Where("course_id = ?", courseID).Order("lesson_order ASC").Find(&lessons).Error; err != nil {
+		return nil, fmt.Errorf("failed to load lessons: %w", err)
+	}
+	var tasks []models.Tasks
+	lessonIDs := db.Model(&models.Lessons{}).Select("lesson_id").Where("course_id = ?", courseID)
+	if err := db.Where("lesson_id IN (?)", lessonIDs).Order("task_id ASC").Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+
+	tasksByLesson := make(map[uint][]cartridge.Task)
+	for _, task := range tasks {
+		tasksByLesson[task.LessonID] = append(tasksByLesson[task.LessonID], cartridge.Task{
+			Title:       task.TaskTitle,
+			Description: task.Description,
+			TaskType:    task.TaskType,
+			MaxScore:    task.MaxScore,
+		})
+	}
+
+	lessonsByChapter := make(map[uint][]cartridge.Lesson)
+	for _, lesson := range lessons {
+		item := cartridge.Lesson{
+			Title: lesson.LessonTitle,
+			Type:  lesson.LessonType,
+			Tasks: tasksByLesson[lesson.LessonID],
+		}
+		if objectKey, ok := oss.ObjectKeyFromURL(lesson.ContentURL); ok {
+			item.File = &cartridge.File{
+				Name: path.Base(objectKey),
+				Open: func() (io.ReadCloser, error) { return oss.GetObject(objectKey) },
+			}
+		} else {
+			item.URL = lesson.ContentURL
+		}
+		lessonsByChapter[lesson.ChapterID] = append(lessonsByChapter[lesson.ChapterID], item)
+	}
+
+	pkg := &cartridge.Course{
+		Identifier:  fmt.Sprintf("course_%d", course.CourseID),
+		Title:       course.CourseTitle,
+		Description: course.Description,
+	}
+	for _, chapter := range chapters {
+		pkg.Chapters = append(pkg.Chapters, cartridge.Chapter{
+			Title:   chapter.ChapterTitle,
+			Lessons: lessonsByChapter[chapter.ChapterID],
+		})
+	}
+
+	return pkg, nil
+}
+
+// ImportCourseCartridge 从 Common Cartridge 包创建新课程（草稿），课时文件按上传限制检查后上传到OSS
+// courseTitle 不为空时覆盖包内的课程标题；不支持的条目跳过并在结果中列出
+func (s *CourseService) ImportCourseCartridge(instructorUserID, branchID uint, r io.ReaderAt, size int64, courseTitle string) (*CartridgeImportResult, error) {
+	instructor, err := ensureInstructorRecord(instructorUserID, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, issues, err := cartridge.Read(r, size)
+	if err != nil {
+		if errors.Is(err, cartridge.ErrInvalidPackage) {
+			return nil, apperrors.WrapError(apperrors.ErrCodeInvalidPackage, apperrors.ErrInvalidPackage.Message, err)
+		}
+		return nil, fmt.Errorf("failed to read package: %w", err)
+	}
+
+	course := models.Courses{
+		CourseTitle:   pkg.Title,
+		Description:   pkg.Description,
+		InstructorID:  instructor.InstructorID,
+		Status:        CourseStatusActive,
+		PublishStatus: PublishStatusDraft,
+	}
+	if courseTitle != "" {
+		course.CourseTitle = courseTitle
+	}
+	if course.CourseTitle == "" {
+		return nil, apperrors.ErrInvalidParam
+	}
+
+	result := &CartridgeImportResult{Issues: issues}
+	if result.Issues == nil {
+		result.Issues = []cartridge.Issue{}
+	}
+
+	// 事务失败时需要清理已上传的OSS对象
+	var uploadedKeys []string
+
+	db := database.GetCentralDB()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&course).Error; err != nil {
+			return fmt.Errorf("failed to create course: %w", err)
+		}
+		if err := addPrimaryInstructor(tx, course.CourseID, course.InstructorID); err != nil {
+			return err
+		}
+		if _, err := recordRevision(tx, &course, RevisionActionCreate, instructorUserID, branchID); err != nil {
+			return err
+		}
+
+		for ci, ch := range pkg.Chapters {
+			chapter := models.Chapters{
+				CourseID:      course.CourseID,
+				ChapterTitle:  ch.Title,
+				ChapterOrder:  ci + 1,
+				PublishStatus: PublishStatusDraft,
+			}
+			if chapter.ChapterTitle == "" {
+				chapter.ChapterTitle = fmt.Sprintf("第%d章", ci+1)
+			}
+			if err := tx.Create(&chapter).Error; err != nil {
+				return fmt.Errorf("failed to create chapter: %w", err)
+			}
+			if _, err := recordRevision(tx, &chapter, RevisionActionCreate, instructorUserID, branchID); err != nil {
+				return err
+			}
+			result.Chapters++
+
+			order := 0
+			for li, l := range ch.Lessons {
+				lesson := models.Lessons{
+					CourseID:      course.CourseID,
+					ChapterID:     chapter.ChapterID,
+					LessonTitle:   l.Title,
+					ContentURL:    l.URL,
+					LessonType:    l.Type,
+					PublishStatus: PublishStatusDraft,
+				}
+				if lesson.LessonTitle == "" {
+					lesson.LessonTitle = fmt.Sprintf("第%d节", li+1)
+				}
+				if l.File != nil {
+					// 包内文件与客户端上传的文件按相同的限制检查，未通过的课时（包括其作业）跳过
+					fileName, err := checkCartridgeFile(l.Type, l.File)
+					if err != nil {
+						reason, rejected := importRejection(err)
+						if !rejected {
+							return err
+						}
+						result.Issues = append(result.Issues, cartridge.Issue{
+							Identifier:   l.File.Name,
+							Title:        lesson.LessonTitle,
+							ResourceType: cartridge.ResourceWebContent,
+							Reason:       "课时文件未通过校验：" + reason,
+						})
+						continue
+					}
+					objectKey := fmt.Sprintf("courses/%d/chapters/%d/lessons/%s", course.CourseID, chapter.ChapterID, fileName)
+					contentURL, err := uploadCartridgeFile(objectKey, l.File)
+					if err != nil {
+						return err
+					}
+					uploadedKeys = append(uploadedKeys, objectKey)
+					lesson.ContentURL = contentURL
+				}
+				order++
+				lesson.LessonOrder = order
+				queueVideoProcessing(&lesson)
+				if err := tx.Create(&lesson).Error; err != nil {
+					return fmt.Errorf("failed to create lesson: %w", err)
+				}
+				if _, err := recordRevision(tx, &lesson, RevisionActionCreate, instructorUserID, branchID); err != nil {
+					return err
+				}
+				result.Lessons++
+
+				for _, t := range l.Tasks {
+					task := models.Tasks{
+						LessonID:    lesson.LessonID,
+						TaskTitle:   t.Title,
+						Description: t.Description,
+						TaskType:    t.TaskType,
+						MaxScore:    t.MaxScore,
+					}
+					if err := tx.Create(&task).Error; err != nil {
+						return fmt.Errorf("failed to create task: %w", err)
+					}
+					if _, err := recordRevision(tx, &task, RevisionActionCreate, instructorUserID, branchID); err != nil {
+						return err
+					}
+					result.Tasks++
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		for _, key := range uploadedKeys {
+			if delErr := oss.DeleteObject(key); delErr != nil {
+				logger.Warnf("import cartridge: failed to clean up uploaded object %s: %v", key, delErr)
+			}
+		}
+		return nil, err
+	}
+
+	result.Course, err = s.GetCourse(course.CourseID, true, false)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkCartridgeFile 按课时类型的上传限制检查包内文件，返回清理后的文件名
+func checkCartridgeFile(lessonType string, file *cartridge.File) (string, error) {
+	policy, ok := lessonUploadPolicy(lessonType)
+	if !ok {
+		return "", apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "该课时类型不支持上传文件")
+	}
+	contentType, err := checkImportedFile(policy, file.Name, file.Open)
+	if err != nil {
+		return "", err
+	}
+	return upload.SanitizeFileName(file.Name, contentType), nil
+}
+
+// uploadCartridgeFile 将课程包中的文件上传到OSS
+func uploadCartridgeFile(objectKey string, file *cartridge.File) (string, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s in package: %w", file.Name, err)
+	}
+	defer rc.Close()
+
+	contentURL, err := oss.UploadReader(context.Background(), objectKey, rc)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload lesson content: %w", err)
+	}
+	return contentURL, nil
+}
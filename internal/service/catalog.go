@@ -0,0 +1,443 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/models"
+)
+
+// 课程目录排序方式
+const (
+	CatalogSortNewest  = "newest"  // 按创建时间倒序
+	CatalogSortPopular = "popular" // 按所有分支的报名人数倒序
+)
+
+const (
+	maxTagLength     = 50
+	maxTagsPerCourse = 20
+	maxTagFacets     = 50
+)
+
+// CatalogQuery 课程目录查询条件，由查询参数绑定
+type CatalogQuery struct {
+	CategoryID    *uint    `form:"category_id"`
+	Tags          []string `form:"tag"`    // 可重复，课程需要包含所有标签
+	Status        string   `form:"status"` // 为空时不过滤
+	InstructorID  *uint    `form:"instructor_id"`
+	StartFrom     *string  `form:"start_from"` // 开课日期下限
+	StartTo       *string  `form:"start_to"`   // 开课日期上限
+	Sort          string   `form:"sort"`       // newest（默认）, popular
+	Page          int      `form:"page"`
+	PageSize      int      `form:"page_size"`
+	PublishedOnly bool     `form:"-"`
+}
+
+// CatalogCourse 目录中的课程，附带报名人数
+type CatalogCourse struct {
+	CourseInfo
+	EnrollmentCount int64 `json:"enrollment_count"`
+}
+
+// FacetCount 分面统计项
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// CatalogFacets 分面统计，每个维度按除自身以外的条件统计，标签按全部条件统计
+type CatalogFacets struct {
+	Categories  []FacetCount `json:"categories"`
+	Tags        []FacetCount `json:"tags"`
+	Statuses    []FacetCount `json:"statuses"`
+	Instructors []FacetCount `json:"instructors"`
+}
+
+// CatalogResult 课程目录查询结果
+type CatalogResult struct {
+	Courses  []CatalogCourse `json:"courses"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+	Facets   CatalogFacets   `json:"facets"`
+}
+
+// CategoryRequest 创建或修改课程分类请求
+type CategoryRequest struct {
+	CategoryName string `json:"category_name" binding:"required"`
+	Description  string `json:"description"`
+}
+
+// CatalogService 课程目录与分类服务
+type CatalogService struct{}
+
+// NewCatalogService 创建实例
+func NewCatalogService() *CatalogService {
+	return &CatalogService{}
+}
+
+// SearchCatalog 按分类、标签、状态、开课日期和教师筛选课程，返回分页结果和分面统计
+func (s *CatalogService) SearchCatalog(q *CatalogQuery) (*CatalogResult, error) {
+	if q.Sort == "" {
+		q.Sort = CatalogSortNewest
+	}
+	if q.Sort != CatalogSortNewest && q.Sort != CatalogSortPopular {
+		return nil, apperrors.ErrInvalidParam
+	}
+	if q.Status != "" && !isValidCourseStatus(q.Status) {
+		return nil, apperrors.ErrInvalidParam
+	}
+	tags, err := normalizeTags(q.Tags)
+	if err != nil {
+		return nil, err
+	}
+	q.Tags = tags
+	startFrom, err := parseCourseDate(q.StartFrom)
+	if err != nil {
+		return nil, err
+	}
+	startTo, err := parseCourseDate(q.StartTo)
+	if err != nil {
+		return nil, err
+	}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 || q.PageSize > 100 {
+		q.PageSize = 10
+	}
+	filter := catalogFilter{query: q, startFrom: startFrom, startTo: startTo}
+
+	db := database.GetCentralDB()
+	result := &CatalogResult{Page: q.Page, PageSize: q.PageSize, Courses: []CatalogCourse{}}
+
+	// 报名人数由中央服务器的 enrollment_count 记录，排序和分页都在数据库中完成
+	order := "created_at DESC"
+	if q.Sort == CatalogSortPopular {
+		order = "enrollment_count DESC, created_at DESC"
+	}
+	if err := catalogScope(db, filter, "").Count(&result.Total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count courses: %w", err)
+	}
+	var courses []models.Courses
+	if err := catalogScope(db, filter, "").Order(order).
+		Offset((q.Page - 1) * q.PageSize).Limit(q.PageSize).Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list courses: %w", err)
+	}
+
+	ids := make([]uint, 0, len(courses))
+	for _, course := range courses {
+		ids = append(ids, course.CourseID)
+	}
+	tagsByCourse, err := loadCourseTags(db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range courses {
+		info := toCourseInfo(&courses[i])
+		if courseTags, ok := tagsByCourse[courses[i].CourseID]; ok {
+			info.Tags = courseTags
+		}
+		result.Courses = append(result.Courses, CatalogCourse{
+			CourseInfo:      info,
+			EnrollmentCount: courses[i].EnrollmentCount,
+		})
+	}
+
+	if result.Facets, err = catalogFacets(db, filter); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListCategories 获取全部课程分类
+func (s *CatalogService) ListCategories() ([]models.Categories, error) {
+	var categories []models.Categories
+	if err := database.GetCentralDB().Order("category_name ASC").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	return categories, nil
+}
+
+// CreateCategory 管理员创建课程分类
+func (s *CatalogService) CreateCategory(role string, req *CategoryRequest) (*models.Categories, error) {
+	if role != UserRoleAdmin {
+		return nil, apperrors.ErrForbidden
+	}
+
+	category := models.Categories{Description: req.Description}
+	if err := setCategoryName(database.GetCentralDB(), &category, req.CategoryName); err != nil {
+		return nil, err
+	}
+	if err := database.GetCentralDB().Create(&category).Error; err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	return &category, nil
+}
+
+// UpdateCategory 管理员修改课程分类
+func (s *CatalogService) UpdateCategory(categoryID uint, role string, req *CategoryRequest) (*models.Categories, error) {
+	if role != UserRoleAdmin {
+		return nil, apperrors.ErrForbidden
+	}
+
+	db := database.GetCentralDB()
+	category, err := getCategoryByID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if err := setCategoryName(db, category, req.CategoryName); err != nil {
+		return nil, err
+	}
+	category.Description = req.Description
+	if err := db.Save(category).Error; err != nil {
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+	return category, nil
+}
+
+// DeleteCategory 管理员删除课程分类，原属该分类的课程变为未分类
+func (s *CatalogService) DeleteCategory(categoryID uint, role string) error {
+	if role != UserRoleAdmin {
+		return apperrors.ErrForbidden
+	}
+
+	if _, err := getCategoryByID(categoryID); err != nil {
+		return err
+	}
+
+	return database.GetCentralDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Courses{}).Where("category_id = ?", categoryID).
+			Update("category_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to detach courses from category: %w", err)
+		}
+		if err := tx.Delete(&models.Categories{}, categoryID).Error; err != nil {
+			return fmt.Errorf("failed to delete category: %w", err)
+		}
+		return nil
+	})
+}
+
+// catalogFilter 解析后的目录查询条件
+type catalogFilter struct {
+	query     *CatalogQuery
+	startFrom *time.Time
+	startTo   *time.Time
+}
+
+// catalogScope 构造目录查询，except 指定的条件不参与过滤（用于分面统计）
+func catalogScope(db *gorm.DB, f catalogFilter, except string) *gorm.DB {
+	q := f.query
+	query := db.Model(&models.Courses{})
+	if q.PublishedOnly {
+		query = query.Where("publish_status = ?", PublishStatusPublished)
+	}
+	if q.CategoryID != nil && except != "category" {
+		query = query.Where("category_id = ?", *q.CategoryID)
+	}
+	if q.Status != "" && except != "status" {
+		query = query.Where("status = ?", q.Status)
+	}
+	if q.InstructorID != nil && except != "instructor" {
+		query = query.Where("instructor_id = ?", *q.InstructorID)
+	}
+	if f.startFrom != nil {
+		query = query.Where("start_date >= ?", *f.startFrom)
+	}
+	if f.startTo != nil {
+		query = query.Where("start_date <= ?", *f.startTo)
+	}
+	for _, tag := range q.Tags {
+		query = query.Where("course_id IN (?)", db.Model(&models.CourseTags{}).Select("course_id").Where("tag = ?", tag))
+	}
+	return query
+}
+
+// catalogFacets 统计分类、标签、状态和教师的分面计数
+func catalogFacets(db *gorm.DB, f catalogFilter) (CatalogFacets, error) {
+	type facetRow struct {
+		Value string
+		Count int64
+	}
+	group := func(except, column string) ([]facetRow, error) {
+		var rows []facetRow
+		err := catalogScope(db, f, except).
+			Select("CAST(" + column + " AS TEXT) AS value, COUNT(*) AS count").
+			Where(column + " IS NOT NULL").
+			Group(column).Order("count DESC, value ASC").
+			Scan(&rows).Error
+		return rows, err
+	}
+
+	facets := CatalogFacets{
+		Categories:  []FacetCount{},
+		Tags:        []FacetCount{},
+		Statuses:    []FacetCount{},
+		Instructors: []FacetCount{},
+	}
+
+	categoryRows, err := group("category", "category_id")
+	if err != nil {
+		return facets, fmt.Errorf("failed to count category facets: %w", err)
+	}
+	var categories []models.Categories
+	if err := db.Find(&categories).Error; err != nil {
+		return facets, fmt.Errorf("failed to load categories: %w", err)
+	}
+	categoryNames := make(map[string]string, len(categories))
+	for _, category := range categories {
+		categoryNames[strconv.FormatUint(uint64(category.CategoryID), 10)] = category.CategoryName
+	}
+	for _, row := range categoryRows {
+		facets.Categories = append(facets.Categories, FacetCount{Value: row.Value, Label: categoryNames[row.Value], Count: row.Count})
+	}
+
+	statusRows, err := group("status", "status")
+	if err != nil {
+		return facets, fmt.Errorf("failed to count status facets: %w", err)
+	}
+	for _, row := range statusRows {
+		facets.Statuses = append(facets.Statuses, FacetCount{Value: row.Value, Label: row.Value, Count: row.Count})
+	}
+
+	instructorRows, err := group("instructor", "instructor_id")
+	if err != nil {
+		return facets, fmt.Errorf("failed to count instructor facets: %w", err)
+	}
+	instructorIDs := make([]string, 0, len(instructorRows))
+	for _, row := range instructorRows {
+		instructorIDs = append(instructorIDs, row.Value)
+	}
+	instructorNames := make(map[string]string, len(instructorRows))
+	if len(instructorIDs) > 0 {
+		var instructors []models.Instructors
+		if err := db.Where("instructor_id IN ?", instructorIDs).Find(&instructors).Error; err != nil {
+			return facets, fmt.Errorf("failed to load instructors: %w", err)
+		}
+		for _, instructor := range instructors {
+			instructorNames[strconv.FormatUint(uint64(instructor.InstructorID), 10)] = instructor.Username
+		}
+	}
+	for _, row := range instructorRows {
+		facets.Instructors = append(facets.Instructors, FacetCount{Value: row.Value, Label: instructorNames[row.Value], Count: row.Count})
+	}
+
+	var tagRows []facetRow
+	if err := db.Model(&models.CourseTags{}).
+		Select("tag AS value, COUNT(*) AS count").
+		Where("course_id IN (?)", catalogScope(db, f, "").Select("course_id")).
+		Group("tag").Order("count DESC, value ASC").Limit(maxTagFacets).
+		Scan(&tagRows).Error; err != nil {
+		return facets, fmt.Errorf("failed to count tag facets: %w", err)
+	}
+	for _, row := range tagRows {
+		facets.Tags = append(facets.Tags, FacetCount{Value: row.Value, Label: row.Value, Count: row.Count})
+	}
+
+	return facets, nil
+}
+
+// loadCourseTags 批量读取课程标签
+func loadCourseTags(db *gorm.DB, courseIDs []uint) (map[uint][]string, error) {
+	tagsByCourse := make(map[uint][]string, len(courseIDs))
+	if len(courseIDs) == 0 {
+		return tagsByCourse, nil
+	}
+	var rows []models.CourseTags
+	if err := db.Where("course_id IN ?", courseIDs).Order("tag ASC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load course tags: %w", err)
+	}
+	for _, row := range rows {
+		tagsByCourse[row.CourseID] = append(tagsByCourse[row.CourseID], row.Tag)
+	}
+	return tagsByCourse, nil
+}
+
+// replaceCourseTags 整体替换课程标签
+func replaceCourseTags(tx *gorm.DB, courseID uint, tags []string) error {
+	if err := tx.Where("course_id = ?", courseID).Delete(&models.CourseTags{}).Error; err != nil {
+		return fmt.Errorf("failed to clear course tags: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	rows := make([]models.CourseTags, 0, len(tags))
+	for _, tag := range tags {
+		rows = append(rows, models.CourseTags{CourseID: courseID, Tag: tag})
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to save course tags: %w", err)
+	}
+	return nil
+}
+
+// normalizeTags 去除空白、转为小写并去重，标签过长或数量过多时返回参数错误
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, apperrors.ErrInvalidParam
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxTagsPerCourse {
+		return nil, apperrors.ErrInvalidParam
+	}
+	return normalized, nil
+}
+
+// resolveCategoryID 校验分类存在，0 表示不设置分类
+func resolveCategoryID(categoryID *uint) (*uint, error) {
+	if categoryID == nil || *categoryID == 0 {
+		return nil, nil
+	}
+	if _, err := getCategoryByID(*categoryID); err != nil {
+		return nil, err
+	}
+	id := *categoryID
+	return &id, nil
+}
+
+func getCategoryByID(categoryID uint) (*models.Categories, error) {
+	var category models.Categories
+	if err := database.GetCentralDB().Where("category_id = ?", categoryID).First(&category).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to query category: %w", err)
+	}
+	return &category, nil
+}
+
+// setCategoryName 设置分类名称，名称不能为空也不能与其他分类重复
+func setCategoryName(db *gorm.DB, category *models.Categories, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return apperrors.ErrInvalidParam
+	}
+	var count int64
+	if err := db.Model(&models.Categories{}).
+		Where("category_name = ? AND category_id <> ?", name, category.CategoryID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check category name: %w", err)
+	}
+	if count > 0 {
+		return apperrors.ErrCategoryExists
+	}
+	category.CategoryName = name
+	return nil
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	"online-learning-platform/internal/logger"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/internal/oss"
+)
+
+// CloneCourseRequest 复制课程请求
+type CloneCourseRequest struct {
+	CourseTitle string  `json:"course_title"` // 为空时沿用原课程标题
+	StartDate   *string `json:"start_date"`   // 新学期开始日期，结束日期和报名时间按相同间隔顺延；为空时沿用原日期
+	CopyContent bool    `json:"copy_content"` // true 复制OSS内容对象，false 与原课程共享同一对象
+}
+
+// CloneCourse 将课程连同章节、课时、任务、分类和标签复制为当前教师名下的新课程
+// 新课程及其内容均为草稿状态，需要重新发布；整个复制在中央服务器的一个事务内完成
+func (s *CourseService) CloneCourse(courseID, instructorUserID, branchID uint, req *CloneCourseRequest) (*CourseInfo, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	instructor, err := ensureInstructorRecord(instructorUserID, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := getCourseByID(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := models.Courses{
+		CourseTitle:       source.CourseTitle,
+		Description:       source.Description,
+		InstructorID:      instructor.InstructorID,
+		CategoryID:        source.CategoryID,
+		StartDate:         source.StartDate,
+		EndDate:           source.EndDate,
+		EnrollmentStartAt: source.EnrollmentStartAt,
+		EnrollmentEndAt:   source.EnrollmentEndAt,
+		Capacity:          source.Capacity,
+		Status:            CourseStatusActive,
+		PublishStatus:     PublishStatusDraft,
+	}
+	if req.CourseTitle != "" {
+		clone.CourseTitle = req.CourseTitle
+	}
+
+	// 指定新的开课日期时，报名时间和作业截止时间随开课日期平移
+	var offset time.Duration
+	if req.StartDate != nil {
+		startDate, err := parseCourseDate(req.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		if startDate != nil && source.StartDate != nil {
+			offset = startDate.Sub(*source.StartDate)
+			clone.EndDate = shiftOptionalTime(source.EndDate, offset)
+			clone.EnrollmentStartAt = shiftOptionalTime(source.EnrollmentStartAt, offset)
+			clone.EnrollmentEndAt = shiftOptionalTime(source.EnrollmentEndAt, offset)
+		}
+		clone.StartDate = startDate
+	}
+
+	db := database.GetCentralDB()
+
+	var chapters []models.Chapters
+	if err := db.Where("course_id = ?", courseID).Order("chapter_order ASC").Find(&chapters).Error; err != nil {
+		return nil, fmt.Errorf("failed to load chapters: %w", err)
+	}
+	var lessons []models.Lessons
+	if err := db.Where("course_id = ?", courseID).Order("lesson_order ASC").Find(&lessons).Error; err != nil {
+		return nil, fmt.Errorf("failed to load lessons: %w", err)
+	}
+	var tasks []models.Tasks
+	lessonIDs := db.Model(&models.Lessons{}).Select("lesson_id").Where("course_id = ?", courseID)
+	if err := db.Where("lesson_id IN (?)", lessonIDs).Order("task_id ASC").Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+	tagsByCourse, err := loadCourseTags(db, []uint{courseID})
+	if err != nil {
+		return nil, err
+	}
+
+	// 事务失败时需要清理已复制的OSS对象
+	var copiedKeys []string
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&clone).Error; err != nil {
+			return fmt.Errorf("failed to create course: %w", err)
+		}
+		if err := addPrimaryInstructor(tx, clone.CourseID, clone.InstructorID); err != nil {
+			return err
+		}
+		if err := replaceCourseTags(tx, clone.CourseID, tagsByCourse[courseID]); err != nil {
+			return err
+		}
+		if _, err := recordRevision(tx, &clone, RevisionActionCreate, instructorUserID, branchID); err != nil {
+			return err
+		}
+
+		chapterIDs := make(map[uint]uint, len(chapters))
+		for _, chapter := range chapters {
+			newChapter := models.Chapters{
+				CourseID:      clone.CourseID,
+				ChapterTitle:  chapter.ChapterTitle,
+				ChapterOrder:  chapter.ChapterOrder,
+				Description:   chapter.Description,
+				Slug:          chapter.Slug,
+				PublishStatus: PublishStatusDraft,
+			}
+			if err := tx.Create(&newChapter).Error; err != nil {
+				return fmt.Errorf("failed to create chapter: %w", err)
+			}
+			if _, err := recordRevision(tx, &newChapter, RevisionActionCreate, instructorUserID, branchID); err != nil {
+				return err
+			}
+			chapterIDs[chapter.ChapterID] = newChapter.ChapterID
+		}
+
+		lessonIDs := make(map[uint]uint, len(lessons))
+		for _, lesson := range lessons {
+			newLesson := models.Lessons{
+				CourseID:      clone.CourseID,
+				ChapterID:     chapterIDs[lesson.ChapterID],
+				LessonTitle:   lesson.LessonTitle,
+				ContentURL:    lesson.ContentURL,
+				LessonType:    lesson.LessonType,
+				LessonOrder:   lesson.LessonOrder,
+				Slug:          lesson.Slug,
+				PublishStatus: PublishStatusDraft,
+			}
+			if req.CopyContent {
+				contentURL, copiedKey, err := copyLessonContent(lesson.ContentURL, newLesson.CourseID, newLesson.ChapterID)
+				if err != nil {
+					return err
+				}
+				if copiedKey != "" {
+					copiedKeys = append(copiedKeys, copiedKey)
+				}
+				newLesson.ContentURL = contentURL
+			}
+			if newLesson.ContentURL == lesson.ContentURL {
+				copyVideoProcessing(&newLesson, &lesson)
+			} else {
+				queueVideoProcessing(&newLesson)
+			}
+			if err := tx.Create(&newLesson).Error; err != nil {
+				return fmt.Errorf("failed to create lesson: %w", err)
+			}
+			if _, err := recordRevision(tx, &newLesson, RevisionActionCreate, instructorUserID, branchID); err != nil {
+				return err
+			}
+			lessonIDs[lesson.LessonID] = newLesson.LessonID
+		}
+
+		for _, task := range tasks {
+			newTask := models.Tasks{
+				LessonID:           lessonIDs[task.LessonID],
+				TaskTitle:          task.TaskTitle,
+				Description:        task.Description,
+				TaskType:           task.TaskType,
+				MaxScore:           task.MaxScore,
+				Slug:               task.Slug,
+				AllowedTypes:       task.AllowedTypes,
+				MaxFileSize:        task.MaxFileSize,
+				DueAt:              shiftOptionalTime(task.DueAt, offset),
+				CutoffAt:           shiftOptionalTime(task.CutoffAt, offset),
+				LatePolicy:         task.LatePolicy,
+				LatePenaltyPercent: task.LatePenaltyPercent,
+				MaxAttempts:        task.MaxAttempts,
+				AttemptPolicy:      task.AttemptPolicy,
+				Rubric:             task.Rubric,
+			}
+			if err := tx.Create(&newTask).Error; err != nil {
+				return fmt.Errorf("failed to create task: %w", err)
+			}
+			if _, err := recordRevision(tx, &newTask, RevisionActionCreate, instructorUserID, branchID); err != nil {
+				return err
+			}
+			if err := copyQuizQuestions(tx, task.TaskID, newTask.TaskID); err != nil {
+				return err
+			}
+		}
+
+		// 先修课程要求随课程一起复制，豁免和作业延期属于具体学期的学生，不复制
+		var prerequisites []models.CoursePrerequisites
+		if err := tx.Where("course_id = ?", courseID).Find(&prerequisites).Error; err != nil {
+			return fmt.Errorf("failed to load prerequisites: %w", err)
+		}
+		for _, p := range prerequisites {
+			newPrerequisite := models.CoursePrerequisites{
+				CourseID:             clone.CourseID,
+				PrerequisiteCourseID: p.PrerequisiteCourseID,
+				MinStatus:            p.MinStatus,
+				MinScore:             p.MinScore,
+			}
+			if err := tx.Create(&newPrerequisite).Error; err != nil {
+				return fmt.Errorf("failed to copy prerequisite: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		for _, key := range copiedKeys {
+			if delErr := oss.DeleteObject(key); delErr != nil {
+				logger.Warnf("clone course: failed to clean up copied object %s: %v", key, delErr)
+			}
+		}
+		return nil, err
+	}
+
+	return s.GetCourse(clone.CourseID, true, false)
+}
+
+// copyLessonContent 把课时内容对象复制到新课程的目录下
+// 内容不是本Bucket中的对象（外部链接或为空）时原样返回，copiedKey 为空
+func copyLessonContent(contentURL string, courseID, chapterID uint) (newURL, copiedKey string, err error) {
+	srcKey, ok := oss.ObjectKeyFromURL(contentURL)
+	if !ok {
+		return contentURL, "", nil
+	}
+
+	destKey := fmt.Sprintf("courses/%d/chapters/%d/lessons/%s", courseID, chapterID, path.Base(srcKey))
+	newURL, err = oss.CopyObject(srcKey, destKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to copy lesson content: %w", err)
+	}
+	return newURL, destKey, nil
+}
+
+// shiftOptionalTime 将可选时间平移 offset，为空时仍为空
+func shiftOptionalTime(t *time.Time, offset time.Duration) *time.Time {
+	if t == nil {
+		return nil
+	}
+	shifted := t.Add(offset)
+	return &shifted
+}
@@ -47,6 +47,11 @@ func (s *CommentService) AddComment(userID, branchID, courseID uint, req *AddCom
 		return nil, err
 	}
 
+	// 课程结束后只读
+	if err := ensureCourseWritable(courseID); err != nil {
+		return nil, err
+	}
+
 	return s.createComment(userID, branchID, courseID, req)
 }
 
@@ -56,8 +61,8 @@ func (s *CommentService) AddCommentAsTeacher(instructorUserID, branchID, courseI
 		return nil, err
 	}
 
-	// 验证教师是否是课程创建者
-	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+	// 验证教师属于课程教学团队（包括助教）
+	if err := validateCourseStaff(courseID, instructorUserID, branchID); err != nil {
 		return nil, err
 	}
 
@@ -159,7 +164,8 @@ func ensureStudentEnrolled(userID, branchID, courseID uint) error {
 	}
 
 	var learning models.Learning
-	if err := branchDB.Where("user_id = ? AND course_id = ?", userID, courseID).First(&learning).Error; err != nil {
+	// 候补中的学生尚未正式报名
+	if err := branchDB.Where("user_id = ? AND course_id = ? AND status <> ?", userID, courseID, LearningStatusWaitlisted).First(&learning).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return apperrors.ErrCannotComment
 		}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/internal/oss"
+	"online-learning-platform/internal/video"
+)
+
+// 签名URL有效期：课时内容需要覆盖一次完整的播放，作业文件只用于查看和下载
+const (
+	lessonURLExpiry = time.Hour
+	answerURLExpiry = 15 * time.Minute
+)
+
+// LessonContent 课时内容的临时访问地址
+type LessonContent struct {
+	LessonID   uint   `json:"lesson_id"`
+	LessonType string `json:"lesson_type"`
+	URL        string `json:"url"`
+	ExpiresAt  string `json:"expires_at"`
+	// Markdown 课时正文，其中引用的图片等资源已替换为签名URL
+	Markdown string `json:"markdown,omitempty"`
+	// 视频处理完成后返回时长、封面和 HLS 主播放列表地址（需要携带登录凭证请求）
+	Duration     int    `json:"duration,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	HLSURL       string `json:"hls_url,omitempty"`
+}
+
+// GetLessonContent 已报名的学生获取课时内容的签名URL，每次请求都重新校验报名状态
+func (s *LearningService) GetLessonContent(userID, branchID, lessonID uint) (*LessonContent, error) {
+	lesson, err := getAccessibleLesson(userID, branchID, lessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	if lesson.ContentURL == "" {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeNotFound, "课时没有内容")
+	}
+
+	content := &LessonContent{
+		LessonID:   lesson.LessonID,
+		LessonType: lesson.LessonType,
+		ExpiresAt:  time.Now().Add(lessonURLExpiry).Format("2006-01-02 15:04:05"),
+	}
+	content.URL, err = oss.SignObjectURL(lesson.ContentURL, lessonURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Markdown 同步生成的正文中引用了同一Bucket的资源，需要逐个签名才能显示
+	if objectKey, ok := oss.ObjectKeyFromURL(lesson.ContentURL); ok {
+		if scope, ok := markdownScope(objectKey); ok {
+			if content.Markdown, err = signedMarkdown(objectKey, scope); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// 处理完成前只返回原视频地址，播放器直接播放原文件
+	if lesson.ProcessingStatus == VideoStatusReady {
+		content.Duration = lesson.Duration
+		content.ThumbnailURL, err = oss.SignObjectURL(lesson.ThumbnailURL, lessonURLExpiry)
+		if err != nil {
+			return nil, err
+		}
+		content.HLSURL = fmt.Sprintf("/api/v1/student/lessons/%d/hls/%s", lesson.LessonID, video.MasterPlaylistName)
+	}
+
+	return content, nil
+}
+
+// GetLessonPlaylist 已报名的学生获取 HLS 播放列表，其中的分片地址替换为签名URL
+// 各档位的播放列表使用相对路径，播放器会继续通过本接口请求
+func (s *LearningService) GetLessonPlaylist(userID, branchID, lessonID uint, name string) (string, error) {
+	if !video.ValidPlaylistName(name) {
+		return "", apperrors.NewAppError(apperrors.ErrCodeNotFound, "播放列表不存在")
+	}
+	lesson, err := getAccessibleLesson(userID, branchID, lessonID)
+	if err != nil {
+		return "", err
+	}
+	masterKey, ok := oss.ObjectKeyFromURL(lesson.HLSURL)
+	if lesson.ProcessingStatus != VideoStatusReady || !ok {
+		return "", apperrors.NewAppError(apperrors.ErrCodeNotFound, "视频尚未处理完成")
+	}
+
+	playlistKey := path.Join(path.Dir(masterKey), name)
+	playlist, err := readObjectText(playlistKey)
+	if err != nil {
+		if errors.Is(err, oss.ErrObjectNotFound) {
+			return "", apperrors.NewAppError(apperrors.ErrCodeNotFound, "播放列表不存在")
+		}
+		return "", err
+	}
+
+	// 播放列表不会在播放中途刷新，分片签名需要覆盖整个视频的播放（包括暂停）
+	expiry := lessonURLExpiry + 2*time.Duration(lesson.Duration)*time.Second
+	return video.RewriteSegments(playlist, func(uri string) (string, error) {
+		return oss.GenerateSignedURL(path.Join(path.Dir(playlistKey), uri), expiry, http.MethodGet)
+	})
+}
+
+// getAccessibleLesson 查询已发布、并且学生已报名（不包括候补）的课时
+func getAccessibleLesson(userID, branchID, lessonID uint) (*models.Lessons, error) {
+	var lesson models.Lessons
+	if err := database.GetCentralDB().Where("lesson_id = ?", lessonID).First(&lesson).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrLessonNotFound
+		}
+		return nil, fmt.Errorf("failed to query lesson: %w", err)
+	}
+	if err := ensureLessonVisible(lessonID); err != nil {
+		return nil, err
+	}
+
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	if err != nil {
+		return nil, err
+	}
+	learning, err := getLearning(branchDB, userID, lesson.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if learning.Status == LearningStatusWaitlisted {
+		return nil, apperrors.ErrNotEnrolled
+	}
+	return &lesson, nil
+}
+
+// GetCourseForTeacher 教师预览课程（包含草稿）
+// 管理员和课程教学团队成员得到课时内容的签名URL，其他教师看不到课时内容地址
+func (s *CourseService) GetCourseForTeacher(courseID, userID, branchID uint, role string) (*CourseInfo, error) {
+	info, err := s.GetCourse(courseID, true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if role != UserRoleAdmin {
+		if err := validateCourseStaff(courseID, userID, branchID); err != nil {
+			if err == apperrors.ErrNotCourseInstructor {
+				hideLessonContent(info)
+				return info, nil
+			}
+			return nil, err
+		}
+	}
+
+	if err := signLessonContent(info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// hideLessonContent 去掉本Bucket中课时内容的地址，学生报名后通过 GetLessonContent 获取签名URL
+// 外部链接不受影响
+func hideLessonContent(info *CourseInfo) {
+	for i := range info.Chapters {
+		for j := range info.Chapters[i].Lessons {
+			lesson := &info.Chapters[i].Lessons[j]
+			lesson.HasContent = lesson.ContentURL != ""
+			if _, ok := oss.ObjectKeyFromURL(lesson.ContentURL); ok {
+				lesson.ContentURL = ""
+			}
+			lesson.ProcessingStatus = ""
+			lesson.ProcessingError = ""
+			lesson.ThumbnailURL = ""
+		}
+	}
+}
+
+// signLessonContent 将课时内容地址替换为签名URL
+func signLessonContent(info *CourseInfo) error {
+	for i := range info.Chapters {
+		for j := range info.Chapters[i].Lessons {
+			lesson := &info.Chapters[i].Lessons[j]
+			lesson.HasContent = lesson.ContentURL != ""
+			if lesson.ContentURL == "" {
+				continue
+			}
+			signed, err := oss.SignObjectURL(lesson.ContentURL, lessonURLExpiry)
+			if err != nil {
+				return err
+			}
+			lesson.ContentURL = signed
+			if lesson.ThumbnailURL != "" {
+				if lesson.ThumbnailURL, err = oss.SignObjectURL(lesson.ThumbnailURL, lessonURLExpiry); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// signAnswerContent 将作业文件地址替换为签名URL，只用于返回给作业所有者或课程教学团队
+func signAnswerContent(answer *models.Answers) error {
+	if answer.Type != "image_url" || answer.AnswerContent == "" {
+		return nil
+	}
+	signed, err := signScopedObjectURL(answer.AnswerContent,
+		answerObjectPrefix(answer.BranchID, answer.UserID, answer.TaskID), answerURLExpiry)
+	if err != nil {
+		return err
+	}
+	answer.AnswerContent = signed
+	return nil
+}
+
+// errObjectURLNotAllowed 直接填写本Bucket的对象地址，本Bucket中的文件只能通过上传接口关联
+var errObjectURLNotAllowed = apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "本存储中的文件请通过上传接口关联，不能直接填写地址")
+
+// rejectObjectURL 拒绝直接填写的本Bucket对象地址，外部链接不受影响
+func rejectObjectURL(contentURL string) error {
+	if _, ok := oss.ObjectKeyFromURL(contentURL); ok {
+		return errObjectURLNotAllowed
+	}
+	return nil
+}
+
+// answerObjectPrefix 学生在任务上提交的作业文件的对象Key前缀
+func answerObjectPrefix(branchID, userID, taskID uint) string {
+	return fmt.Sprintf("answers/%d/%d/%d/", branchID, userID, taskID)
+}
+
+// signScopedObjectURL 为 prefix 下的本Bucket对象生成签名URL，外部链接原样返回
+// 不在 prefix 下的本Bucket地址不是通过上传接口关联的文件，返回空字符串，避免签出其他学生的文件
+func signScopedObjectURL(objectURL, prefix string, expire time.Duration) (string, error) {
+	objectKey, ok := oss.ObjectKeyFromURL(objectURL)
+	if !ok {
+		return objectURL, nil
+	}
+	if !strings.HasPrefix(objectKey, prefix) {
+		return "", nil
+	}
+	return oss.GenerateSignedURL(objectKey, expire, http.MethodGet)
+}
+
+// markdownScope Markdown 正文对象（courses/{course_id}/markdown/）所属课程的对象Key前缀
+// 未复制内容的克隆课程仍引用原课程的正文，正文中的资源按正文所在的课程签名
+func markdownScope(objectKey string) (string, bool) {
+	parts := strings.SplitN(objectKey, "/", 4)
+	if len(parts) < 4 || parts[0] != "courses" || parts[2] != "markdown" {
+		return "", false
+	}
+	return parts[0] + "/" + parts[1] + "/", true
+}
+
+// signedMarkdown 读取 Markdown 正文，将其中 scope 前缀下的本Bucket对象地址替换为签名URL
+func signedMarkdown(objectKey, scope string) (string, error) {
+	rc, err := oss.GetObject(objectKey)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return "", fmt.Errorf("failed to read markdown %s: %w", objectKey, err)
+	}
+	return oss.SignEmbeddedURLs(string(data), scope, lessonURLExpiry)
+}
@@ -1,16 +1,16 @@
 package service
 
 import (
-	"bytes"
-	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
 	"online-learning-platform/internal/database"
 	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/logger"
 	"online-learning-platform/internal/models"
-	"online-learning-platform/internal/oss"
+	"online-learning-platform/pkg/utils"
 )
 
 // CourseService 课程管理服务
@@ -21,12 +21,23 @@ func NewCourseService() *CourseService {
 	return &CourseService{}
 }
 
+// 课程状态
+const (
+	CourseStatusActive   = "active"
+	CourseStatusArchived = "archived"
+)
+
 // CreateCourseRequest 创建课程请求
 type CreateCourseRequest struct {
 	CourseTitle string  `json:"course_title" binding:"required"`
 	Description string  `json:"description"`
 	StartDate   *string `json:"start_date"`
 	EndDate     *string `json:"end_date"`
+	EnrollmentStartAt *string `json:"enrollment_start_at"` // 报名开始时间
+	EnrollmentEndAt   *string `json:"enrollment_end_at"`   // 报名截止时间
+	Capacity    int     `json:"capacity"`            // 报名人数上限，0 表示不限
+	CategoryID  *uint   `json:"category_id"`
+	Tags        []string `json:"tags"`
 	Status      string  `json:"status"`
 }
 
@@ -42,83 +53,188 @@ type CreateLessonRequest struct {
 	LessonTitle string `json:"lesson_title" binding:"required"`
 	LessonType  string `json:"lesson_type"` // video, text, quiz
 	LessonOrder int    `json:"lesson_order"`
-	ContentURL  string `json:"content_url"` // OSS URL，如果上传文件则自动生成
+	ContentURL  string `json:"content_url"` // 外部链接；上传的文件通过 UploadID 关联
+	UploadID    uint   `json:"upload_id"`   // 已确认的课时文件上传，优先于 ContentURL
+}
+
+// UpdateCourseRequest 更新课程请求（字段为 null 表示不修改）
+type UpdateCourseRequest struct {
+	CourseTitle *string `json:"course_title"`
+	Description *string `json:"description"`
+	StartDate   *string `json:"start_date"`
+	EndDate     *string `json:"end_date"`
+	EnrollmentStartAt *string `json:"enrollment_start_at"`
+	EnrollmentEndAt   *string `json:"enrollment_end_at"`
+	Capacity    *int    `json:"capacity"` // 提高或取消上限时自动递补候补学生
+	CategoryID  *uint   `json:"category_id"` // 0 表示清除分类
+	Tags        *[]string `json:"tags"`      // 整体替换课程标签
+	Status      *string `json:"status"`   // active, archived
+}
+
+// UpdateChapterRequest 更新章节请求
+type UpdateChapterRequest struct {
+	ChapterTitle *string `json:"chapter_title"`
+	Description  *string `json:"description"`
+}
+
+// UpdateLessonRequest 更新课时请求
+type UpdateLessonRequest struct {
+	LessonTitle *string `json:"lesson_title"`
+	LessonType  *string `json:"lesson_type"`
+	ContentURL  *string `json:"content_url"`
+	UploadID    uint    `json:"upload_id"` // 已确认的课时文件上传，替换内容链接
+}
+
+// ReorderChaptersRequest 章节排序请求，按新顺序列出课程的章节ID
+type ReorderChaptersRequest struct {
+	ChapterIDs []uint `json:"chapter_ids" binding:"required"`
+}
+
+// ReorderLessonsRequest 课时排序请求，按新顺序列出章节的课时ID
+// 列表中可以包含同一课程其他章节的课时，表示将其移动到该章节
+type ReorderLessonsRequest struct {
+	LessonIDs []uint `json:"lesson_ids" binding:"required"`
 }
 
 // CourseInfo 课程信息
 type CourseInfo struct {
-	CourseID    uint            `json:"course_id"`
-	CourseTitle string          `json:"course_title"`
-	Description string          `json:"description"`
-	InstructorID uint           `json:"instructor_id"`
-	StartDate   *string         `json:"start_date"`
-	EndDate     *string         `json:"end_date"`
-	Status      string          `json:"status"`
-	Chapters    []ChapterInfo   `json:"chapters,omitempty"`
-	CreatedAt   string          `json:"created_at"`
-	UpdatedAt   string          `json:"updated_at"`
+	CourseID      uint          `json:"course_id"`
+	CourseTitle   string        `json:"course_title"`
+	Description   string        `json:"description"`
+	InstructorID  uint          `json:"instructor_id"`
+	CategoryID    *uint         `json:"category_id"`
+	Tags          []string      `json:"tags"`
+	StartDate     *string       `json:"start_date"`
+	EndDate       *string       `json:"end_date"`
+	EnrollmentStartAt *string   `json:"enrollment_start_at"`
+	EnrollmentEndAt   *string   `json:"enrollment_end_at"`
+	Capacity      int           `json:"capacity"`
+	Status        string        `json:"status"`
+	PublishStatus string        `json:"publish_status"`
+	PublishAt     *string       `json:"publish_at"`
+	Chapters      []ChapterInfo `json:"chapters,omitempty"`
+	CreatedAt     string        `json:"created_at"`
+	UpdatedAt     string        `json:"updated_at"`
 }
 
 // ChapterInfo 章节信息
 type ChapterInfo struct {
-	ChapterID    uint          `json:"chapter_id"`
-	CourseID     uint          `json:"course_id"`
-	ChapterTitle string        `json:"chapter_title"`
-	ChapterOrder int           `json:"chapter_order"`
-	Description  string        `json:"description"`
-	Lessons      []LessonInfo  `json:"lessons,omitempty"`
-	CreatedAt    string        `json:"created_at"`
-	UpdatedAt    string        `json:"updated_at"`
+	ChapterID     uint         `json:"chapter_id"`
+	CourseID      uint         `json:"course_id"`
+	ChapterTitle  string       `json:"chapter_title"`
+	ChapterOrder  int          `json:"chapter_order"`
+	Description   string       `json:"description"`
+	PublishStatus string       `json:"publish_status"`
+	PublishAt     *string      `json:"publish_at"`
+	Lessons       []LessonInfo `json:"lessons,omitempty"`
+	CreatedAt     string       `json:"created_at"`
+	UpdatedAt     string       `json:"updated_at"`
 }
 
 // LessonInfo 课程信息
 type LessonInfo struct {
-	LessonID    uint   `json:"lesson_id"`
-	CourseID    uint   `json:"course_id"`
-	ChapterID   uint   `json:"chapter_id"`
-	LessonTitle string `json:"lesson_title"`
-	ContentURL  string `json:"content_url"`
-	LessonType  string `json:"lesson_type"`
-	LessonOrder int    `json:"lesson_order"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	LessonID         uint    `json:"lesson_id"`
+	CourseID         uint    `json:"course_id"`
+	ChapterID        uint    `json:"chapter_id"`
+	LessonTitle      string  `json:"lesson_title"`
+	ContentURL       string  `json:"content_url"` // 学生视角不返回本Bucket的地址，报名后通过课时内容接口获取签名URL
+	HasContent       bool    `json:"has_content"`
+	LessonType       string  `json:"lesson_type"`
+	LessonOrder      int     `json:"lesson_order"`
+	PublishStatus    string  `json:"publish_status"`
+	PublishAt        *string `json:"publish_at"`
+	ProcessingStatus string  `json:"processing_status,omitempty"` // 视频处理状态，只返回给教学团队
+	ProcessingError  string  `json:"processing_error,omitempty"`
+	Duration         int     `json:"duration"`                // 视频时长（秒），处理完成前为 0
+	ThumbnailURL     string  `json:"thumbnail_url,omitempty"` // 视频封面的签名URL，只返回给教学团队
+	CreatedAt        string  `json:"created_at"`
+	UpdatedAt        string  `json:"updated_at"`
+}
+
+// CourseListFilter 课程列表过滤条件
+type CourseListFilter struct {
+	InstructorID      *uint
+	StaffInstructorID *uint  // 教师所在教学团队的课程（主讲、协同或助教）
+	Status            string // 为空时不过滤状态
+	PublishedOnly     bool   // 学生只能看到已发布的课程
 }
 
 // CreateCourse 教师创建课程
-func (s *CourseService) CreateCourse(instructorID uint, req *CreateCourseRequest) (*models.Courses, error) {
+func (s *CourseService) CreateCourse(instructorUserID, branchID uint, req *CreateCourseRequest) (*models.Courses, error) {
+	instructor, err := ensureInstructorRecord(instructorUserID, branchID)
+	if err != nil {
+		return nil, err
+	}
+
 	db := database.GetCentralDB()
 
 	course := models.Courses{
-		CourseTitle:  req.CourseTitle,
-		Description:  req.Description,
-		InstructorID: instructorID,
-		Status:       "active",
+		CourseTitle:   req.CourseTitle,
+		Description:   req.Description,
+		InstructorID:  instructor.InstructorID,
+		Status:        CourseStatusActive,
+		PublishStatus: PublishStatusDraft,
 	}
 
 	if req.Status != "" {
+		if !isValidCourseStatus(req.Status) {
+			return nil, apperrors.ErrInvalidParam
+		}
 		course.Status = req.Status
 	}
 
-	if err := db.Create(&course).Error; err != nil {
-		return nil, fmt.Errorf("failed to create course: %w", err)
+	if course.StartDate, err = parseCourseDate(req.StartDate); err != nil {
+		return nil, err
+	}
+	if course.EndDate, err = parseCourseDate(req.EndDate); err != nil {
+		return nil, err
+	}
+	if course.EnrollmentStartAt, err = parseCourseDate(req.EnrollmentStartAt); err != nil {
+		return nil, err
+	}
+	if course.EnrollmentEndAt, err = parseCourseDate(req.EnrollmentEndAt); err != nil {
+		return nil, err
+	}
+	if req.Capacity < 0 {
+		return nil, apperrors.ErrInvalidParam
+	}
+	course.Capacity = req.Capacity
+	if course.CategoryID, err = resolveCategoryID(req.CategoryID); err != nil {
+		return nil, err
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&course).Error; err != nil {
+			return fmt.Errorf("failed to create course: %w", err)
+		}
+		if err := addPrimaryInstructor(tx, course.CourseID, course.InstructorID); err != nil {
+			return err
+		}
+		if err := replaceCourseTags(tx, course.CourseID, tags); err != nil {
+			return err
+		}
+		_, err := recordRevision(tx, &course, RevisionActionCreate, instructorUserID, branchID)
+		return err
+	}); err != nil {
+		return nil, err
 	}
 
 	return &course, nil
 }
 
 // CreateChapter 创建章节
-func (s *CourseService) CreateChapter(courseID, instructorID uint, req *CreateChapterRequest) (*models.Chapters, error) {
-	db := database.GetCentralDB()
-
+func (s *CourseService) CreateChapter(courseID, instructorUserID, branchID uint, req *CreateChapterRequest) (*models.Chapters, error) {
 	// 验证课程是否存在且属于该教师
-	var course models.Courses
-	if err := db.Where("course_id = ? AND instructor_id = ?", courseID, instructorID).First(&course).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, apperrors.ErrNotCourseInstructor
-		}
-		return nil, fmt.Errorf("failed to verify course: %w", err)
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
 	}
 
+	db := database.GetCentralDB()
+
 	// 如果没有指定顺序，自动获取下一个顺序
 	chapterOrder := req.ChapterOrder
 	if chapterOrder == 0 {
@@ -131,50 +247,38 @@ func (s *CourseService) CreateChapter(courseID, instructorID uint, req *CreateCh
 	}
 
 	chapter := models.Chapters{
-		CourseID:     courseID,
-		ChapterTitle: req.ChapterTitle,
-		ChapterOrder: chapterOrder,
-		Description:  req.Description,
+		CourseID:      courseID,
+		ChapterTitle:  req.ChapterTitle,
+		ChapterOrder:  chapterOrder,
+		Description:   req.Description,
+		PublishStatus: PublishStatusDraft,
 	}
 
-	if err := db.Create(&chapter).Error; err != nil {
-		return nil, fmt.Errorf("failed to create chapter: %w", err)
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&chapter).Error; err != nil {
+			return fmt.Errorf("failed to create chapter: %w", err)
+		}
+		_, err := recordRevision(tx, &chapter, RevisionActionCreate, instructorUserID, branchID)
+		return err
+	}); err != nil {
+		return nil, err
 	}
 
 	return &chapter, nil
 }
 
 // CreateLesson 创建课程（上传视频到OSS）
-func (s *CourseService) CreateLesson(courseID, chapterID, instructorID uint, req *CreateLessonRequest, videoFile []byte, fileName string) (*models.Lessons, error) {
-	db := database.GetCentralDB()
-
+func (s *CourseService) CreateLesson(courseID, chapterID, instructorUserID, branchID uint, req *CreateLessonRequest) (*models.Lessons, error) {
 	// 验证课程是否存在且属于该教师
-	var course models.Courses
-	if err := db.Where("course_id = ? AND instructor_id = ?", courseID, instructorID).First(&course).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, apperrors.ErrNotCourseInstructor
-		}
-		return nil, fmt.Errorf("failed to verify course: %w", err)
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
 	}
 
-	// 验证章节是否存在
-	var chapter models.Chapters
-	if err := db.Where("chapter_id = ? AND course_id = ?", chapterID, courseID).First(&chapter).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, apperrors.ErrChapterNotFound
-		}
-		return nil, fmt.Errorf("failed to verify chapter: %w", err)
-	}
+	db := database.GetCentralDB()
 
-	// 上传视频到OSS
-	contentURL := req.ContentURL
-	if len(videoFile) > 0 && fileName != "" {
-		objectKey := fmt.Sprintf("courses/%d/chapters/%d/lessons/%s", courseID, chapterID, fileName)
-		ossURL, err := oss.UploadReader(context.Background(), objectKey, bytes.NewReader(videoFile))
-		if err != nil {
-			return nil, fmt.Errorf("failed to upload video to OSS: %w", err)
-		}
-		contentURL = ossURL
+	// 验证章节是否存在
+	if _, err := findChapter(db, courseID, chapterID); err != nil {
+		return nil, err
 	}
 
 	// 如果没有指定顺序，自动获取下一个顺序
@@ -193,24 +297,46 @@ func (s *CourseService) CreateLesson(courseID, chapterID, instructorID uint, req
 		lessonType = "video"
 	}
 
+	if req.UploadID == 0 {
+		if err := rejectObjectURL(req.ContentURL); err != nil {
+			return nil, err
+		}
+	}
+
 	lesson := models.Lessons{
-		CourseID:    courseID,
-		ChapterID:   chapterID,
-		LessonTitle: req.LessonTitle,
-		ContentURL:  contentURL,
-		LessonType:  lessonType,
-		LessonOrder: lessonOrder,
+		CourseID:      courseID,
+		ChapterID:     chapterID,
+		LessonTitle:   req.LessonTitle,
+		ContentURL:    req.ContentURL,
+		LessonType:    lessonType,
+		LessonOrder:   lessonOrder,
+		PublishStatus: PublishStatusDraft,
 	}
 
-	if err := db.Create(&lesson).Error; err != nil {
-		return nil, fmt.Errorf("failed to create lesson: %w", err)
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if req.UploadID != 0 {
+			contentURL, err := attachLessonUpload(tx, req.UploadID, courseID, chapterID, lesson.LessonType, instructorUserID, branchID)
+			if err != nil {
+				return err
+			}
+			lesson.ContentURL = contentURL
+		}
+		queueVideoProcessing(&lesson)
+		if err := tx.Create(&lesson).Error; err != nil {
+			return fmt.Errorf("failed to create lesson: %w", err)
+		}
+		_, err := recordRevision(tx, &lesson, RevisionActionCreate, instructorUserID, branchID)
+		return err
+	}); err != nil {
+		return nil, err
 	}
 
 	return &lesson, nil
 }
 
 // GetCourse 获取课程详情
-func (s *CourseService) GetCourse(courseID uint, includeDetails bool) (*CourseInfo, error) {
+// publishedOnly 为 true 时（学生视角）只返回已发布的课程、章节和课时；教师预览时传 false
+func (s *CourseService) GetCourse(courseID uint, includeDetails, publishedOnly bool) (*CourseInfo, error) {
 	db := database.GetCentralDB()
 
 	var course models.Courses
@@ -221,76 +347,100 @@ func (s *CourseService) GetCourse(courseID uint, includeDetails bool) (*CourseIn
 		return nil, fmt.Errorf("failed to get course: %w", err)
 	}
 
-	courseInfo := &CourseInfo{
-		CourseID:     course.CourseID,
-		CourseTitle:  course.CourseTitle,
-		Description:  course.Description,
-		InstructorID: course.InstructorID,
-		Status:       course.Status,
+	if publishedOnly && course.PublishStatus != PublishStatusPublished {
+		return nil, apperrors.ErrCourseNotFound
 	}
 
-	if course.StartDate != nil {
-		startDate := course.StartDate.Format("2006-01-02 15:04:05")
-		courseInfo.StartDate = &startDate
+	info := toCourseInfo(&course)
+	courseInfo := &info
+	tagsByCourse, err := loadCourseTags(db, []uint{course.CourseID})
+	if err != nil {
+		return nil, err
 	}
-	if course.EndDate != nil {
-		endDate := course.EndDate.Format("2006-01-02 15:04:05")
-		courseInfo.EndDate = &endDate
+	if tags, ok := tagsByCourse[course.CourseID]; ok {
+		courseInfo.Tags = tags
 	}
-	courseInfo.CreatedAt = course.CreatedAt.Format("2006-01-02 15:04:05")
-	courseInfo.UpdatedAt = course.UpdatedAt.Format("2006-01-02 15:04:05")
 
 	if includeDetails {
 		// 获取章节和课程
+		chapterQuery := db.Where("course_id = ?", courseID)
+		if publishedOnly {
+			chapterQuery = chapterQuery.Where("publish_status = ?", PublishStatusPublished)
+		}
 		var chapters []models.Chapters
-		db.Where("course_id = ?", courseID).Order("chapter_order ASC").Find(&chapters)
+		chapterQuery.Order("chapter_order ASC").Find(&chapters)
 
 		chapterInfos := make([]ChapterInfo, 0, len(chapters))
 		for _, ch := range chapters {
 			chapterInfo := ChapterInfo{
-				ChapterID:    ch.ChapterID,
-				CourseID:     ch.CourseID,
-				ChapterTitle: ch.ChapterTitle,
-				ChapterOrder: ch.ChapterOrder,
-				Description:  ch.Description,
-				CreatedAt:    ch.CreatedAt.Format("2006-01-02 15:04:05"),
-				UpdatedAt:    ch.UpdatedAt.Format("2006-01-02 15:04:05"),
+				ChapterID:     ch.ChapterID,
+				CourseID:      ch.CourseID,
+				ChapterTitle:  ch.ChapterTitle,
+				ChapterOrder:  ch.ChapterOrder,
+				Description:   ch.Description,
+				PublishStatus: ch.PublishStatus,
+				PublishAt:     formatOptionalTime(ch.PublishAt),
+				CreatedAt:     ch.CreatedAt.Format("2006-01-02 15:04:05"),
+				UpdatedAt:     ch.UpdatedAt.Format("2006-01-02 15:04:05"),
 			}
 
 			// 获取课程
+			lessonQuery := db.Where("chapter_id = ?", ch.ChapterID)
+			if publishedOnly {
+				lessonQuery = lessonQuery.Where("publish_status = ?", PublishStatusPublished)
+			}
 			var lessons []models.Lessons
-			db.Where("chapter_id = ?", ch.ChapterID).Order("lesson_order ASC").Find(&lessons)
+			lessonQuery.Order("lesson_order ASC").Find(&lessons)
 
 			lessonInfos := make([]LessonInfo, 0, len(lessons))
 			for _, le := range lessons {
 				lessonInfos = append(lessonInfos, LessonInfo{
-					LessonID:    le.LessonID,
-					CourseID:    le.CourseID,
-					ChapterID:   le.ChapterID,
-					LessonTitle: le.LessonTitle,
-					ContentURL:  le.ContentURL,
-					LessonType:  le.LessonType,
-					LessonOrder: le.LessonOrder,
-					CreatedAt:   le.CreatedAt.Format("2006-01-02 15:04:05"),
-					UpdatedAt:   le.UpdatedAt.Format("2006-01-02 15:04:05"),
+					LessonID:         le.LessonID,
+					CourseID:         le.CourseID,
+					ChapterID:        le.ChapterID,
+					LessonTitle:      le.LessonTitle,
+					ContentURL:       le.ContentURL,
+					LessonType:       le.LessonType,
+					LessonOrder:      le.LessonOrder,
+					PublishStatus:    le.PublishStatus,
+					PublishAt:        formatOptionalTime(le.PublishAt),
+					ProcessingStatus: le.ProcessingStatus,
+					ProcessingError:  le.ProcessingError,
+					Duration:         le.Duration,
+					ThumbnailURL:     le.ThumbnailURL,
+					CreatedAt:        le.CreatedAt.Format("2006-01-02 15:04:05"),
+					UpdatedAt:        le.UpdatedAt.Format("2006-01-02 15:04:05"),
 				})
 			}
 			chapterInfo.Lessons = lessonInfos
 			chapterInfos = append(chapterInfos, chapterInfo)
 		}
 		courseInfo.Chapters = chapterInfos
+		if publishedOnly {
+			hideLessonContent(courseInfo)
+		}
 	}
 
 	return courseInfo, nil
 }
 
 // ListCourses 获取课程列表
-func (s *CourseService) ListCourses(instructorID *uint, page, pageSize int) ([]CourseInfo, int64, error) {
+func (s *CourseService) ListCourses(filter CourseListFilter, page, pageSize int) ([]CourseInfo, int64, error) {
 	db := database.GetCentralDB()
 
 	query := db.Model(&models.Courses{})
-	if instructorID != nil {
-		query = query.Where("instructor_id = ?", *instructorID)
+	if filter.InstructorID != nil {
+		query = query.Where("instructor_id = ?", *filter.InstructorID)
+	}
+	if filter.StaffInstructorID != nil {
+		staffCourses := db.Model(&models.CourseStaff{}).Select("course_id").Where("instructor_id = ?", *filter.StaffInstructorID)
+		query = query.Where("instructor_id = ? OR course_id IN (?)", *filter.StaffInstructorID, staffCourses)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.PublishedOnly {
+		query = query.Where("publish_status = ?", PublishStatusPublished)
 	}
 
 	var total int64
@@ -302,28 +452,601 @@ func (s *CourseService) ListCourses(instructorID *uint, page, pageSize int) ([]C
 		return nil, 0, fmt.Errorf("failed to list courses: %w", err)
 	}
 
+	courseIDs := make([]uint, 0, len(courses))
+	for _, course := range courses {
+		courseIDs = append(courseIDs, course.CourseID)
+	}
+	tagsByCourse, err := loadCourseTags(db, courseIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	courseInfos := make([]CourseInfo, 0, len(courses))
 	for _, course := range courses {
-		courseInfo := CourseInfo{
-			CourseID:     course.CourseID,
-			CourseTitle:  course.CourseTitle,
-			Description:  course.Description,
-			InstructorID: course.InstructorID,
-			Status:       course.Status,
-			CreatedAt:    course.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:    course.UpdatedAt.Format("2006-01-02 15:04:05"),
-		}
-		if course.StartDate != nil {
-			startDate := course.StartDate.Format("2006-01-02 15:04:05")
-			courseInfo.StartDate = &startDate
+		info := toCourseInfo(&course)
+		if tags, ok := tagsByCourse[course.CourseID]; ok {
+			info.Tags = tags
 		}
-		if course.EndDate != nil {
-			endDate := course.EndDate.Format("2006-01-02 15:04:05")
-			courseInfo.EndDate = &endDate
+		courseInfos = append(courseInfos, info)
+	}
+
+	return courseInfos, total, nil
+}
+
+// ListInstructorCourses 获取教师参与教学的课程列表（包含已归档课程）
+func (s *CourseService) ListInstructorCourses(instructorUserID, branchID uint, page, pageSize int) ([]CourseInfo, int64, error) {
+	instructor, err := ensureInstructorRecord(instructorUserID, branchID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.ListCourses(CourseListFilter{StaffInstructorID: &instructor.InstructorID}, page, pageSize)
+}
+
+// ListEnrolledCourses 获取学生已报名的课程列表
+// 已归档的课程不会出现在公共目录中，但仍保留在学生的已报名列表里
+func (s *CourseService) ListEnrolledCourses(userID, branchID uint, page, pageSize int) ([]CourseInfo, int64, error) {
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := branchDB.Model(&models.Learning{}).Where("user_id = ?", userID)
+
+	var total int64
+	query.Count(&total)
+
+	var courseIDs []uint
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Pluck("course_id", &courseIDs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list enrolled courses: %w", err)
+	}
+
+	if len(courseIDs) == 0 {
+		return []CourseInfo{}, total, nil
+	}
+
+	var courses []models.Courses
+	if err := database.GetCentralDB().
+		Where("course_id IN ? AND publish_status = ?", courseIDs, PublishStatusPublished).
+		Find(&courses).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to query enrolled courses: %w", err)
+	}
+
+	courseMap := make(map[uint]models.Courses, len(courses))
+	for _, course := range courses {
+		courseMap[course.CourseID] = course
+	}
+
+	// 按报名时间顺序返回，已删除或撤回发布的课程直接跳过
+	courseInfos := make([]CourseInfo, 0, len(courseIDs))
+	for _, id := range courseIDs {
+		if course, ok := courseMap[id]; ok {
+			courseInfos = append(courseInfos, toCourseInfo(&course))
 		}
-		courseInfos = append(courseInfos, courseInfo)
 	}
 
 	return courseInfos, total, nil
 }
 
+// UpdateCourse 教师更新课程信息
+func (s *CourseService) UpdateCourse(courseID, instructorUserID, branchID uint, req *UpdateCourseRequest) (*models.Courses, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+
+	var course models.Courses
+	if err := db.Where("course_id = ?", courseID).First(&course).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrCourseNotFound
+		}
+		return nil, fmt.Errorf("failed to get course: %w", err)
+	}
+
+	if req.CourseTitle != nil {
+		if *req.CourseTitle == "" {
+			return nil, apperrors.ErrInvalidParam
+		}
+		course.CourseTitle = *req.CourseTitle
+	}
+	if req.Description != nil {
+		course.Description = *req.Description
+	}
+	if req.Status != nil {
+		if !isValidCourseStatus(*req.Status) {
+			return nil, apperrors.ErrInvalidParam
+		}
+		course.Status = *req.Status
+	}
+
+	var err error
+	if req.StartDate != nil {
+		if course.StartDate, err = parseCourseDate(req.StartDate); err != nil {
+			return nil, err
+		}
+	}
+	if req.EndDate != nil {
+		if course.EndDate, err = parseCourseDate(req.EndDate); err != nil {
+			return nil, err
+		}
+	}
+	if req.EnrollmentStartAt != nil {
+		if course.EnrollmentStartAt, err = parseCourseDate(req.EnrollmentStartAt); err != nil {
+			return nil, err
+		}
+	}
+	if req.EnrollmentEndAt != nil {
+		if course.EnrollmentEndAt, err = parseCourseDate(req.EnrollmentEndAt); err != nil {
+			return nil, err
+		}
+	}
+	seatsAdded := false
+	if req.Capacity != nil {
+		if *req.Capacity < 0 {
+			return nil, apperrors.ErrInvalidParam
+		}
+		seatsAdded = course.Capacity > 0 && (*req.Capacity == 0 || *req.Capacity > course.Capacity)
+		course.Capacity = *req.Capacity
+	}
+	if req.CategoryID != nil {
+		if course.CategoryID, err = resolveCategoryID(req.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+	var tags []string
+	if req.Tags != nil {
+		if tags, err = normalizeTags(*req.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&course).Error; err != nil {
+			return fmt.Errorf("failed to update course: %w", err)
+		}
+		if req.Tags != nil {
+			if err := replaceCourseTags(tx, course.CourseID, tags); err != nil {
+				return err
+			}
+		}
+		_, err := recordRevision(tx, &course, RevisionActionUpdate, instructorUserID, branchID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if seatsAdded {
+		if _, err := promoteWaitlist(courseID); err != nil {
+			logger.Warnf("course %d: failed to promote waitlist after capacity change: %v", courseID, err)
+		}
+	}
+
+	return &course, nil
+}
+
+// ArchiveCourse 归档课程：从学生目录中隐藏，保留学习记录和作业
+func (s *CourseService) ArchiveCourse(courseID, instructorUserID, branchID uint) (*models.Courses, error) {
+	status := CourseStatusArchived
+	return s.UpdateCourse(courseID, instructorUserID, branchID, &UpdateCourseRequest{Status: &status})
+}
+
+// DeleteCourse 主讲教师删除课程（软删除课程及其章节、课时、任务）
+func (s *CourseService) DeleteCourse(courseID, instructorUserID, branchID uint) error {
+	if err := validateCourseInstructor(courseID, instructorUserID, branchID); err != nil {
+		return err
+	}
+
+	db := database.GetCentralDB()
+	course, err := getCourseByID(courseID)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := recordCascadeDeleteRevisions(tx, "course_id", courseID, instructorUserID, branchID); err != nil {
+			return err
+		}
+		if _, err := recordRevision(tx, course, RevisionActionDelete, instructorUserID, branchID); err != nil {
+			return err
+		}
+
+		lessonIDs := tx.Model(&models.Lessons{}).Select("lesson_id").Where("course_id = ?", courseID)
+		if err := tx.Where("lesson_id IN (?)", lessonIDs).Delete(&models.Tasks{}).Error; err != nil {
+			return fmt.Errorf("failed to delete tasks: %w", err)
+		}
+		if err := tx.Where("course_id = ?", courseID).Delete(&models.Lessons{}).Error; err != nil {
+			return fmt.Errorf("failed to delete lessons: %w", err)
+		}
+		if err := tx.Where("course_id = ?", courseID).Delete(&models.Chapters{}).Error; err != nil {
+			return fmt.Errorf("failed to delete chapters: %w", err)
+		}
+		if err := tx.Where("course_id = ?", courseID).Delete(&models.Courses{}).Error; err != nil {
+			return fmt.Errorf("failed to delete course: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateChapter 教师更新章节
+func (s *CourseService) UpdateChapter(courseID, chapterID, instructorUserID, branchID uint, req *UpdateChapterRequest) (*models.Chapters, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+
+	chapter, err := findChapter(db, courseID, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ChapterTitle != nil {
+		if *req.ChapterTitle == "" {
+			return nil, apperrors.ErrInvalidParam
+		}
+		chapter.ChapterTitle = *req.ChapterTitle
+	}
+	if req.Description != nil {
+		chapter.Description = *req.Description
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(chapter).Error; err != nil {
+			return fmt.Errorf("failed to update chapter: %w", err)
+		}
+		_, err := recordRevision(tx, chapter, RevisionActionUpdate, instructorUserID, branchID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return chapter, nil
+}
+
+// DeleteChapter 教师删除章节（软删除章节及其课时、任务）
+func (s *CourseService) DeleteChapter(courseID, chapterID, instructorUserID, branchID uint) error {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return err
+	}
+
+	db := database.GetCentralDB()
+	if _, err := findChapter(db, courseID, chapterID); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteChapterTx(tx, chapterID, instructorUserID, branchID); err != nil {
+			return err
+		}
+		return renumberChapters(tx, courseID, nil)
+	})
+}
+
+// UpdateLesson 教师更新课时，关联新上传的文件时替换内容链接
+func (s *CourseService) UpdateLesson(courseID, chapterID, lessonID, instructorUserID, branchID uint, req *UpdateLessonRequest) (*models.Lessons, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+
+	lesson, err := findLesson(db, courseID, chapterID, lessonID)
+	if err != nil {
+		return nil, err
+	}
+
+	prevContentURL, prevLessonType := lesson.ContentURL, lesson.LessonType
+	if req.LessonTitle != nil {
+		if *req.LessonTitle == "" {
+			return nil, apperrors.ErrInvalidParam
+		}
+		lesson.LessonTitle = *req.LessonTitle
+	}
+	if req.LessonType != nil {
+		lesson.LessonType = *req.LessonType
+	}
+	if req.ContentURL != nil && *req.ContentURL != prevContentURL {
+		if req.UploadID == 0 {
+			if err := rejectObjectURL(*req.ContentURL); err != nil {
+				return nil, err
+			}
+		}
+		lesson.ContentURL = *req.ContentURL
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if req.UploadID != 0 {
+			contentURL, err := attachLessonUpload(tx, req.UploadID, courseID, chapterID, lesson.LessonType, instructorUserID, branchID)
+			if err != nil {
+				return err
+			}
+			lesson.ContentURL = contentURL
+		}
+		// 视频未更换时保留处理结果，也不覆盖处理任务同时写入的状态
+		save := tx
+		if lesson.ContentURL != prevContentURL || lesson.LessonType != prevLessonType {
+			queueVideoProcessing(lesson)
+		} else {
+			save = tx.Omit(videoProcessingColumns...)
+		}
+		if err := save.Save(lesson).Error; err != nil {
+			return fmt.Errorf("failed to update lesson: %w", err)
+		}
+		_, err := recordRevision(tx, lesson, RevisionActionUpdate, instructorUserID, branchID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return lesson, nil
+}
+
+// DeleteLesson 教师删除课时（软删除课时及其任务）
+func (s *CourseService) DeleteLesson(courseID, chapterID, lessonID, instructorUserID, branchID uint) error {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return err
+	}
+
+	db := database.GetCentralDB()
+	if _, err := findLesson(db, courseID, chapterID, lessonID); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteLessonTx(tx, lessonID, instructorUserID, branchID); err != nil {
+			return err
+		}
+		return renumberLessons(tx, chapterID, nil)
+	})
+}
+
+// ReorderChapters 批量调整课程的章节顺序
+func (s *CourseService) ReorderChapters(courseID, instructorUserID, branchID uint, req *ReorderChaptersRequest) (*CourseInfo, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		var chapterIDs []uint
+		if err := tx.Model(&models.Chapters{}).
+			Where("course_id = ? AND chapter_id IN ?", courseID, req.ChapterIDs).
+			Pluck("chapter_id", &chapterIDs).Error; err != nil {
+			return fmt.Errorf("failed to verify chapters: %w", err)
+		}
+		if len(chapterIDs) != countDistinct(req.ChapterIDs) {
+			return apperrors.ErrChapterNotFound
+		}
+		return renumberChapters(tx, courseID, req.ChapterIDs)
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.GetCourse(courseID, true, false)
+}
+
+// ReorderLessons 批量调整章节内的课时顺序，支持把其他章节的课时移入该章节
+// 课时ID保持不变，因此其下的任务和学生作业不受影响
+func (s *CourseService) ReorderLessons(courseID, chapterID, instructorUserID, branchID uint, req *ReorderLessonsRequest) (*CourseInfo, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findChapter(tx, courseID, chapterID); err != nil {
+			return err
+		}
+
+		var lessons []models.Lessons
+		if err := tx.Where("course_id = ? AND lesson_id IN ?", courseID, req.LessonIDs).Find(&lessons).Error; err != nil {
+			return fmt.Errorf("failed to verify lessons: %w", err)
+		}
+		if len(lessons) != countDistinct(req.LessonIDs) {
+			return apperrors.ErrLessonNotFound
+		}
+
+		// 记录被移出的原章节，移动后需要重新编号以消除空位
+		sourceChapters := make(map[uint]bool)
+		for _, lesson := range lessons {
+			if lesson.ChapterID != chapterID {
+				sourceChapters[lesson.ChapterID] = true
+			}
+		}
+
+		if err := tx.Model(&models.Lessons{}).
+			Where("lesson_id IN ? AND chapter_id <> ?", req.LessonIDs, chapterID).
+			Update("chapter_id", chapterID).Error; err != nil {
+			return fmt.Errorf("failed to move lessons: %w", err)
+		}
+
+		if err := renumberLessons(tx, chapterID, req.LessonIDs); err != nil {
+			return err
+		}
+		for sourceID := range sourceChapters {
+			if err := renumberLessons(tx, sourceID, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.GetCourse(courseID, true, false)
+}
+
+// deleteChapterTx 在事务中软删除章节及其课时和任务，并记录删除修订；调用方负责重新编号
+func deleteChapterTx(tx *gorm.DB, chapterID, instructorUserID, branchID uint) error {
+	// 章节本身的快照由级联记录一并保存
+	if err := recordCascadeDeleteRevisions(tx, "chapter_id", chapterID, instructorUserID, branchID); err != nil {
+		return err
+	}
+
+	lessonIDs := tx.Model(&models.Lessons{}).Select("lesson_id").Where("chapter_id = ?", chapterID)
+	if err := tx.Where("lesson_id IN (?)", lessonIDs).Delete(&models.Tasks{}).Error; err != nil {
+		return fmt.Errorf("failed to delete tasks: %w", err)
+	}
+	if err := tx.Where("chapter_id = ?", chapterID).Delete(&models.Lessons{}).Error; err != nil {
+		return fmt.Errorf("failed to delete lessons: %w", err)
+	}
+	if err := tx.Where("chapter_id = ?", chapterID).Delete(&models.Chapters{}).Error; err != nil {
+		return fmt.Errorf("failed to delete chapter: %w", err)
+	}
+	return nil
+}
+
+// deleteLessonTx 在事务中软删除课时及其任务，并记录删除修订；调用方负责重新编号
+func deleteLessonTx(tx *gorm.DB, lessonID, instructorUserID, branchID uint) error {
+	// 课时本身的快照由级联记录一并保存
+	if err := recordCascadeDeleteRevisions(tx, "lesson_id", lessonID, instructorUserID, branchID); err != nil {
+		return err
+	}
+
+	if err := tx.Where("lesson_id = ?", lessonID).Delete(&models.Tasks{}).Error; err != nil {
+		return fmt.Errorf("failed to delete tasks: %w", err)
+	}
+	if err := tx.Where("lesson_id = ?", lessonID).Delete(&models.Lessons{}).Error; err != nil {
+		return fmt.Errorf("failed to delete lesson: %w", err)
+	}
+	return nil
+}
+
+// renumberChapters 按 requested 指定的顺序（其余章节保持原有相对顺序）将章节重新编号为 1..n
+func renumberChapters(tx *gorm.DB, courseID uint, requested []uint) error {
+	var existing []uint
+	if err := tx.Model(&models.Chapters{}).
+		Where("course_id = ?", courseID).
+		Order("chapter_order ASC, chapter_id ASC").
+		Pluck("chapter_id", &existing).Error; err != nil {
+		return fmt.Errorf("failed to list chapters: %w", err)
+	}
+
+	for i, id := range utils.NormalizeOrder(requested, existing) {
+		if err := tx.Model(&models.Chapters{}).
+			Where("chapter_id = ?", id).
+			Update("chapter_order", i+1).Error; err != nil {
+			return fmt.Errorf("failed to update chapter order: %w", err)
+		}
+	}
+	return nil
+}
+
+// renumberLessons 按 requested 指定的顺序（其余课时保持原有相对顺序）将章节内课时重新编号为 1..n
+func renumberLessons(tx *gorm.DB, chapterID uint, requested []uint) error {
+	var existing []uint
+	if err := tx.Model(&models.Lessons{}).
+		Where("chapter_id = ?", chapterID).
+		Order("lesson_order ASC, lesson_id ASC").
+		Pluck("lesson_id", &existing).Error; err != nil {
+		return fmt.Errorf("failed to list lessons: %w", err)
+	}
+
+	for i, id := range utils.NormalizeOrder(requested, existing) {
+		if err := tx.Model(&models.Lessons{}).
+			Where("lesson_id = ?", id).
+			Update("lesson_order", i+1).Error; err != nil {
+			return fmt.Errorf("failed to update lesson order: %w", err)
+		}
+	}
+	return nil
+}
+
+func countDistinct(ids []uint) int {
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	return len(seen)
+}
+
+// findChapter 查询属于指定课程的章节
+func findChapter(db *gorm.DB, courseID, chapterID uint) (*models.Chapters, error) {
+	var chapter models.Chapters
+	if err := db.Where("chapter_id = ? AND course_id = ?", chapterID, courseID).First(&chapter).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrChapterNotFound
+		}
+		return nil, fmt.Errorf("failed to verify chapter: %w", err)
+	}
+	return &chapter, nil
+}
+
+// findLesson 查询属于指定课程和章节的课时
+func findLesson(db *gorm.DB, courseID, chapterID, lessonID uint) (*models.Lessons, error) {
+	var lesson models.Lessons
+	if err := db.Where("lesson_id = ? AND chapter_id = ? AND course_id = ?", lessonID, chapterID, courseID).First(&lesson).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrLessonNotFound
+		}
+		return nil, fmt.Errorf("failed to verify lesson: %w", err)
+	}
+	return &lesson, nil
+}
+
+// attachLessonUpload 将教师上传并确认的文件关联到课时，文件必须是为同一章节申请的，并且符合课时类型的限制
+func attachLessonUpload(tx *gorm.DB, uploadID, courseID, chapterID uint, lessonType string, instructorUserID, branchID uint) (string, error) {
+	policy, ok := lessonUploadPolicy(lessonType)
+	if !ok {
+		return "", apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "该课时类型不支持上传文件")
+	}
+	return attachUpload(tx, UploadPurposeLesson, uploadID, instructorUserID, branchID, func(u *models.Uploads) bool {
+		return u.CourseID == courseID && u.ChapterID == chapterID
+	}, policy)
+}
+
+// toCourseInfo 转换课程模型为返回结构（不含章节）
+func toCourseInfo(course *models.Courses) CourseInfo {
+	courseInfo := CourseInfo{
+		CourseID:      course.CourseID,
+		CourseTitle:   course.CourseTitle,
+		Description:   course.Description,
+		InstructorID:  course.InstructorID,
+		CategoryID:    course.CategoryID,
+		Tags:          []string{},
+		Status:        course.Status,
+		PublishStatus: course.PublishStatus,
+		PublishAt:     formatOptionalTime(course.PublishAt),
+		EnrollmentStartAt: formatOptionalTime(course.EnrollmentStartAt),
+		EnrollmentEndAt:   formatOptionalTime(course.EnrollmentEndAt),
+		Capacity:      course.Capacity,
+		CreatedAt:     course.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     course.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if course.StartDate != nil {
+		startDate := course.StartDate.Format("2006-01-02 15:04:05")
+		courseInfo.StartDate = &startDate
+	}
+	if course.EndDate != nil {
+		endDate := course.EndDate.Format("2006-01-02 15:04:05")
+		courseInfo.EndDate = &endDate
+	}
+	return courseInfo
+}
+
+// formatOptionalTime 格式化可选时间
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format("2006-01-02 15:04:05")
+	return &formatted
+}
+
+// parseCourseDate 解析课程日期，支持 "2006-01-02" 和 "2006-01-02 15:04:05"，空字符串表示清空
+func parseCourseDate(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, *value, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, apperrors.ErrInvalidParam
+}
+
+func isValidCourseStatus(status string) bool {
+	return status == CourseStatusActive || status == CourseStatusArchived
+}
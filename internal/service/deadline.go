@@ -0,0 +1,245 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	"online-learning-platform/internal/deadline"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/models"
+)
+
+// GrantExtensionRequest 作业延期请求，至少设置一个时间点，空字符串表示沿用任务的时间
+type GrantExtensionRequest struct {
+	BranchID uint    `json:"branch_id" binding:"required"` // 学生所在分支
+	UserID   uint    `json:"user_id" binding:"required"`
+	DueAt    *string `json:"due_at"`    // 格式 "2006-01-02 15:04:05"
+	CutoffAt *string `json:"cutoff_at"` // 格式 "2006-01-02 15:04:05"
+	Reason   string  `json:"reason"`
+}
+
+// TaskDeadline 学生在任务上的截止时间（已合并延期）
+type TaskDeadline struct {
+	TaskID             uint    `json:"task_id"`
+	DueAt              *string `json:"due_at"`
+	CutoffAt           *string `json:"cutoff_at"`
+	LatePolicy         string  `json:"late_policy"`
+	LatePenaltyPercent int     `json:"late_penalty_percent"`
+	Extended           bool    `json:"extended"` // 是否有延期
+	Late               bool    `json:"late"`     // 现在提交是否记为迟交
+	Closed             bool    `json:"closed"`   // 现在是否已不再接受提交
+}
+
+// ListExtensions 教学团队查看任务的作业延期
+func (s *TaskService) ListExtensions(taskID, instructorUserID, branchID uint) ([]models.TaskExtensions, error) {
+	if err := validateTaskStaff(taskID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	var extensions []models.TaskExtensions
+	if err := database.GetCentralDB().Where("task_id = ?", taskID).
+		Order("created_at ASC").Find(&extensions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list extensions: %w", err)
+	}
+	return extensions, nil
+}
+
+// GrantExtension 为学生延长任务的截止时间，重复授予时覆盖原延期
+// 已提交的作业不受影响，延期只作用于之后的提交
+func (s *TaskService) GrantExtension(taskID, instructorUserID, branchID uint, req *GrantExtensionRequest) (*models.TaskExtensions, error) {
+	if err := validateTaskOwner(taskID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	dueAt, err := parseCourseDate(req.DueAt)
+	if err != nil {
+		return nil, err
+	}
+	cutoffAt, err := parseCourseDate(req.CutoffAt)
+	if err != nil {
+		return nil, err
+	}
+	if dueAt == nil && cutoffAt == nil {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "请设置延期后的截止时间")
+	}
+
+	db := database.GetCentralDB()
+	var task models.Tasks
+	if err := db.Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if err := taskWindow(&task).Extend(deadline.Window{DueAt: dueAt, CutoffAt: cutoffAt}).Validate(); err != nil {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "最终截止时间不能早于截止时间")
+	}
+
+	// 学生属于所在分支，需要到分支节点确认
+	studentDB, err := database.GetBranchDBByBranchID(req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+	if err := studentDB.Where("user_id = ?", req.UserID).First(&models.Users{}).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to query student: %w", err)
+	}
+
+	var extension models.TaskExtensions
+	err = db.Where("task_id = ? AND branch_id = ? AND user_id = ?", taskID, req.BranchID, req.UserID).First(&extension).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to query extension: %w", err)
+	}
+
+	extension.TaskID = taskID
+	extension.BranchID = req.BranchID
+	extension.UserID = req.UserID
+	extension.DueAt = dueAt
+	extension.CutoffAt = cutoffAt
+	extension.Reason = req.Reason
+	extension.GrantedByUserID = instructorUserID
+	extension.GrantedByBranchID = branchID
+	if err := db.Save(&extension).Error; err != nil {
+		return nil, fmt.Errorf("failed to save extension: %w", err)
+	}
+	return &extension, nil
+}
+
+// RevokeExtension 撤销作业延期
+func (s *TaskService) RevokeExtension(taskID, extensionID, instructorUserID, branchID uint) error {
+	if err := validateTaskOwner(taskID, instructorUserID, branchID); err != nil {
+		return err
+	}
+
+	result := database.GetCentralDB().Where("extension_id = ? AND task_id = ?", extensionID, taskID).Delete(&models.TaskExtensions{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke extension: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+// GetMyDeadline 学生查看自己在任务上的截止时间
+func (s *TaskService) GetMyDeadline(userID, branchID, taskID uint) (*TaskDeadline, error) {
+	var task models.Tasks
+	if err := database.GetCentralDB().Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if err := ensureLessonVisible(task.LessonID); err != nil {
+		if err == apperrors.ErrLessonNotFound {
+			return nil, apperrors.ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	window, extended, err := studentWindow(&task, branchID, userID)
+	if err != nil {
+		return nil, err
+	}
+	status := window.Evaluate(time.Now())
+
+	return &TaskDeadline{
+		TaskID:             task.TaskID,
+		DueAt:              formatOptionalTime(window.DueAt),
+		CutoffAt:           formatOptionalTime(window.CutoffAt),
+		LatePolicy:         task.LatePolicy,
+		LatePenaltyPercent: task.LatePenaltyPercent,
+		Extended:           extended,
+		Late:               status.Late,
+		Closed:             !taskPolicy(&task).Accepts(status),
+	}, nil
+}
+
+// taskWindow 任务设置的截止时间
+func taskWindow(task *models.Tasks) deadline.Window {
+	return deadline.Window{DueAt: task.DueAt, CutoffAt: task.CutoffAt}
+}
+
+// taskPolicy 任务设置的迟交策略
+func taskPolicy(task *models.Tasks) deadline.Policy {
+	return deadline.Policy{Type: task.LatePolicy, PercentPerDay: task.LatePenaltyPercent}
+}
+
+// validateTaskDeadlines 校验任务的截止时间和迟交策略
+func validateTaskDeadlines(task *models.Tasks) error {
+	if err := taskWindow(task).Validate(); err != nil {
+		return apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "最终截止时间不能早于截止时间")
+	}
+	if task.LatePolicy != deadline.PolicyPercentPerDay {
+		task.LatePenaltyPercent = 0
+	}
+	if err := taskPolicy(task).Validate(); err != nil {
+		return apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "迟交策略无效，按天扣分时每天扣除的百分比应在 1 到 100 之间")
+	}
+	return nil
+}
+
+// studentWindow 学生的截止时间：有延期时用延期覆盖任务的时间，返回是否有延期
+func studentWindow(task *models.Tasks, branchID, userID uint) (deadline.Window, bool, error) {
+	window := taskWindow(task)
+	var extension models.TaskExtensions
+	err := database.GetCentralDB().Where("task_id = ? AND branch_id = ? AND user_id = ?", task.TaskID, branchID, userID).First(&extension).Error
+	if err == gorm.ErrRecordNotFound {
+		return window, false, nil
+	}
+	if err != nil {
+		return window, false, fmt.Errorf("failed to query extension: %w", err)
+	}
+	return extendedWindow(task, &extension), true, nil
+}
+
+// studentExtensions 一次查询学生在多个任务上的延期，按任务ID索引
+func studentExtensions(taskIDs []uint, branchID, userID uint) (map[uint]*models.TaskExtensions, error) {
+	var extensions []models.TaskExtensions
+	if err := database.GetCentralDB().Where("task_id IN ? AND branch_id = ? AND user_id = ?", taskIDs, branchID, userID).
+		Find(&extensions).Error; err != nil {
+		return nil, fmt.Errorf("failed to query extensions: %w", err)
+	}
+	byTask := make(map[uint]*models.TaskExtensions, len(extensions))
+	for i := range extensions {
+		byTask[extensions[i].TaskID] = &extensions[i]
+	}
+	return byTask, nil
+}
+
+// extendedWindow 用延期覆盖任务的截止时间
+func extendedWindow(task *models.Tasks, extension *models.TaskExtensions) deadline.Window {
+	return taskWindow(task).Extend(deadline.Window{DueAt: extension.DueAt, CutoffAt: extension.CutoffAt})
+}
+
+// checkSubmissionDeadline 计算学生在 at 时刻提交的迟交状态，按迟交策略不再接受提交时返回 ErrSubmissionClosed
+func checkSubmissionDeadline(task *models.Tasks, branchID, userID uint, at time.Time) (deadline.Status, error) {
+	window, _, err := studentWindow(task, branchID, userID)
+	if err != nil {
+		return deadline.Status{}, err
+	}
+	status := window.Evaluate(at)
+	if !taskPolicy(task).Accepts(status) {
+		return status, apperrors.ErrSubmissionClosed
+	}
+	return status, nil
+}
+
+// setLateStatus 在作业上记录提交时的迟交状态
+func setLateStatus(answer *models.Answers, status deadline.Status) {
+	answer.IsLate = status.Late
+	answer.LateDays = status.Days
+	answer.AfterCutoff = status.AfterCutoff
+}
+
+// applyLatePenalty 按任务当前的迟交策略和作业提交时的迟交状态计算最终得分
+func applyLatePenalty(task *models.Tasks, answer *models.Answers, raw int) {
+	status := deadline.Status{Late: answer.IsLate, Days: answer.LateDays, AfterCutoff: answer.AfterCutoff}
+	answer.RawScore = raw
+	answer.Score, answer.LatePenalty = taskPolicy(task).Apply(raw, status)
+}
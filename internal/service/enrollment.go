@@ -0,0 +1,279 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/logger"
+	"online-learning-platform/internal/models"
+)
+
+// LearningStatusWaitlisted 课程已满时进入候补，有空位后按报名先后自动转为 enrolled
+const LearningStatusWaitlisted = "waitlisted"
+
+// checkEnrollmentWindow 校验课程当前是否接受报名
+func checkEnrollmentWindow(course *models.Courses, now time.Time) error {
+	if err := checkCourseNotEnded(course, now); err != nil {
+		return err
+	}
+	if course.EnrollmentStartAt != nil && now.Before(*course.EnrollmentStartAt) {
+		return apperrors.ErrEnrollmentClosed
+	}
+	if course.EnrollmentEndAt != nil && now.After(*course.EnrollmentEndAt) {
+		return apperrors.ErrEnrollmentClosed
+	}
+	return nil
+}
+
+// checkCourseNotEnded 课程结束后学生只能查看，不能再报名、提交作业、评论或更新进度
+func checkCourseNotEnded(course *models.Courses, now time.Time) error {
+	if course.EndDate != nil && now.After(*course.EndDate) {
+		return apperrors.ErrCourseEnded
+	}
+	return nil
+}
+
+// ensureCourseWritable 查询课程并校验其未结束
+func ensureCourseWritable(courseID uint) error {
+	course, err := getCourseByID(courseID)
+	if err != nil {
+		return err
+	}
+	return checkCourseNotEnded(course, time.Now())
+}
+
+// createEnrollment 在分支节点创建学习记录
+// 课程设置了人数上限时，在中央服务器锁定课程行后统计所有分支的占用名额，名额已满则进入候补
+func createEnrollment(branchDB *gorm.DB, userID, courseID uint) (*models.Learning, error) {
+	learning := models.Learning{
+		UserID:             userID,
+		CourseID:           courseID,
+		Status:             LearningStatusEnrolled,
+		ProgressPercentage: 0,
+	}
+
+	err := database.GetCentralDB().Transaction(func(tx *gorm.DB) error {
+		var course models.Courses
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("course_id = ?", courseID).First(&course).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return apperrors.ErrCourseNotFound
+			}
+			return fmt.Errorf("failed to lock course: %w", err)
+		}
+
+		if course.Capacity > 0 {
+			taken, err := countOccupiedSeats(courseID)
+			if err != nil {
+				return err
+			}
+			if taken >= int64(course.Capacity) {
+				learning.Status = LearningStatusWaitlisted
+			}
+		}
+
+		if err := branchDB.Create(&learning).Error; err != nil {
+			return fmt.Errorf("failed to enroll course: %w", err)
+		}
+		if learning.Status == LearningStatusEnrolled {
+			return adjustEnrollmentCount(tx, courseID, 1)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &learning, nil
+}
+
+// countOccupiedSeats 统计课程在所有分支已占用的名额（候补不占名额）
+func countOccupiedSeats(courseID uint) (int64, error) {
+	var total int64
+	for bID, db := range database.GetAllBranchDBs() {
+		var count int64
+		if err := db.Model(&models.Learning{}).
+			Where("course_id = ? AND status <> ?", courseID, LearningStatusWaitlisted).
+			Count(&count).Error; err != nil {
+			return 0, fmt.Errorf("failed to count enrollments on branch %d: %w", bID, err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// promoteWaitlist 按报名先后将候补学生转为正式报名，直到名额用完，返回递补人数
+// 课程已结束或已归档时不再递补
+func promoteWaitlist(courseID uint) (int, error) {
+	promoted := 0
+	err := database.GetCentralDB().Transaction(func(tx *gorm.DB) error {
+		var course models.Courses
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("course_id = ?", courseID).First(&course).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return apperrors.ErrCourseNotFound
+			}
+			return fmt.Errorf("failed to lock course: %w", err)
+		}
+		if course.Status == CourseStatusArchived || checkCourseNotEnded(&course, time.Now()) != nil {
+			return nil
+		}
+
+		available := -1 // 不限人数
+		if course.Capacity > 0 {
+			taken, err := countOccupiedSeats(courseID)
+			if err != nil {
+				return err
+			}
+			available = course.Capacity - int(taken)
+		}
+
+		for available != 0 {
+			db, learning, err := nextWaitlisted(courseID)
+			if err != nil {
+				return err
+			}
+			if learning == nil {
+				return nil
+			}
+			if err := db.Model(learning).Update("status", LearningStatusEnrolled).Error; err != nil {
+				return fmt.Errorf("failed to promote waitlisted enrollment: %w", err)
+			}
+			if err := adjustEnrollmentCount(tx, courseID, 1); err != nil {
+				return err
+			}
+			logger.Infof("course %d: promoted user %d from waitlist", courseID, learning.UserID)
+			promoted++
+			if available > 0 {
+				available--
+			}
+		}
+		return nil
+	})
+	return promoted, err
+}
+
+// adjustEnrollmentCount 增减中央服务器记录的课程报名人数，课程目录按热度排序时使用
+func adjustEnrollmentCount(db *gorm.DB, courseID uint, delta int) error {
+	if err := db.Exec("UPDATE courses SET enrollment_count = GREATEST(enrollment_count + ?, 0) WHERE course_id = ?",
+		delta, courseID).Error; err != nil {
+		return fmt.Errorf("failed to update enrollment count: %w", err)
+	}
+	return nil
+}
+
+// RecountEnrollments 按所有分支的学习记录重新统计每门课程的报名人数（不含候补）
+// 服务启动时执行，校正分支写入成功但计数更新失败造成的偏差；任一分支查询失败时不修改计数
+// 其他实例可能同时在处理报名，每门课程只在计数仍为统计前读到的值时才更新，期间发生变化的课程留到下次校正
+func RecountEnrollments() error {
+	central := database.GetCentralDB()
+	var stored []struct {
+		CourseID        uint
+		EnrollmentCount int64
+	}
+	if err := central.Model(&models.Courses{}).Select("course_id, enrollment_count").Scan(&stored).Error; err != nil {
+		return fmt.Errorf("failed to load enrollment counts: %w", err)
+	}
+
+	counts := make(map[uint]int64)
+	for bID, db := range database.GetAllBranchDBs() {
+		type row struct {
+			CourseID uint
+			Count    int64
+		}
+		var rows []row
+		if err := db.Model(&models.Learning{}).
+			Select("course_id, COUNT(*) AS count").
+			Where("status <> ?", LearningStatusWaitlisted).
+			Group("course_id").
+			Scan(&rows).Error; err != nil {
+			return fmt.Errorf("failed to count enrollments on branch %d: %w", bID, err)
+		}
+		for _, r := range rows {
+			counts[r.CourseID] += r.Count
+		}
+	}
+
+	var corrected int64
+	for _, course := range stored {
+		count := counts[course.CourseID]
+		if count == course.EnrollmentCount {
+			continue
+		}
+		result := central.Exec("UPDATE courses SET enrollment_count = ? WHERE course_id = ? AND enrollment_count = ?",
+			count, course.CourseID, course.EnrollmentCount)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update enrollment count: %w", result.Error)
+		}
+		corrected += result.RowsAffected
+	}
+	if corrected > 0 {
+		logger.Infof("Enrollment counts corrected for %d courses", corrected)
+	}
+	return nil
+}
+
+// nextWaitlisted 在所有分支中查找最早进入候补的学习记录，没有候补时返回 nil
+func nextWaitlisted(courseID uint) (*gorm.DB, *models.Learning, error) {
+	var (
+		earliestDB *gorm.DB
+		earliest   *models.Learning
+	)
+	for bID, db := range database.GetAllBranchDBs() {
+		var learning models.Learning
+		err := db.Where("course_id = ? AND status = ?", courseID, LearningStatusWaitlisted).
+			Order("created_at ASC, learning_id ASC").First(&learning).Error
+		if err == gorm.ErrRecordNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to query waitlist on branch %d: %w", bID, err)
+		}
+		if earliest == nil || learning.CreatedAt.Before(earliest.CreatedAt) {
+			earliestDB, earliest = db, &learning
+		}
+	}
+	return earliestDB, earliest, nil
+}
+
+// DropCourse 学生退课（包括退出候补），释放的名额自动递补给候补学生
+func (s *LearningService) DropCourse(userID, branchID, courseID uint) error {
+	course, err := getCourseByID(courseID)
+	if err != nil {
+		return err
+	}
+	if err := checkCourseNotEnded(course, time.Now()); err != nil {
+		return err
+	}
+
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	if err != nil {
+		return err
+	}
+
+	var learning models.Learning
+	if err := branchDB.Where("user_id = ? AND course_id = ?", userID, courseID).First(&learning).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return apperrors.ErrNotEnrolled
+		}
+		return fmt.Errorf("failed to query learning record: %w", err)
+	}
+
+	if err := branchDB.Delete(&learning).Error; err != nil {
+		return fmt.Errorf("failed to drop course: %w", err)
+	}
+
+	if learning.Status != LearningStatusWaitlisted {
+		if err := adjustEnrollmentCount(database.GetCentralDB(), courseID, -1); err != nil {
+			logger.Warnf("course %d: %v", courseID, err)
+		}
+		if _, err := promoteWaitlist(courseID); err != nil {
+			logger.Warnf("course %d: failed to promote waitlist after drop: %v", courseID, err)
+		}
+	}
+	return nil
+}
@@ -64,19 +64,53 @@ func getInstructorByID(instructorID uint) (*models.Instructors, error) {
 	return &instructor, nil
 }
 
+// validateCourseOwner 校验教师可以编辑课程内容（主讲教师或协同教师）
 func validateCourseOwner(courseID, instructorUserID, branchID uint) error {
+	return checkCourseOwner(database.GetCentralDB(), courseID, instructorUserID, branchID)
+}
+
+// validateCourseStaff 校验教师属于课程教学团队（包括助教），可以查看学生作业和进度、批改作业
+func validateCourseStaff(courseID, instructorUserID, branchID uint) error {
+	return checkCourseRole(database.GetCentralDB(), courseID, instructorUserID, branchID, staffRolesAll)
+}
+
+// validateCourseInstructor 校验教师是课程的主讲教师（删除课程、管理教学团队）
+func validateCourseInstructor(courseID, instructorUserID, branchID uint) error {
+	return checkCourseRole(database.GetCentralDB(), courseID, instructorUserID, branchID, []string{StaffRoleInstructor})
+}
+
+// checkCourseOwner 在给定的查询作用域内校验编辑权限（传入 Unscoped 可校验已删除的课程）
+func checkCourseOwner(db *gorm.DB, courseID, instructorUserID, branchID uint) error {
+	return checkCourseRole(db, courseID, instructorUserID, branchID, staffRolesEditors)
+}
+
+// checkCourseRole 校验教师在课程教学团队中的角色属于 roles
+// courses.instructor_id 始终视为主讲教师，兼容尚未登记到 course_staff 的课程
+func checkCourseRole(db *gorm.DB, courseID, instructorUserID, branchID uint, roles []string) error {
 	instructorRecord, err := ensureInstructorRecord(instructorUserID, branchID)
 	if err != nil {
 		return err
 	}
 
-	db := database.GetCentralDB()
 	var course models.Courses
-	if err := db.Where("course_id = ? AND instructor_id = ?", courseID, instructorRecord.InstructorID).First(&course).Error; err != nil {
+	if err := db.Where("course_id = ?", courseID).First(&course).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return apperrors.ErrNotCourseInstructor
 		}
 		return fmt.Errorf("failed to verify course owner: %w", err)
 	}
+	if course.InstructorID == instructorRecord.InstructorID {
+		return nil
+	}
+
+	var count int64
+	if err := database.GetCentralDB().Model(&models.CourseStaff{}).
+		Where("course_id = ? AND instructor_id = ? AND role IN ?", courseID, instructorRecord.InstructorID, roles).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to verify course staff: %w", err)
+	}
+	if count == 0 {
+		return apperrors.ErrNotCourseInstructor
+	}
 	return nil
 }
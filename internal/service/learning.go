@@ -11,6 +11,13 @@ import (
 	"online-learning-platform/internal/models"
 )
 
+// 学习状态
+const (
+	LearningStatusEnrolled   = "enrolled"
+	LearningStatusInProgress = "in_progress"
+	LearningStatusCompleted  = "completed"
+)
+
 // LearningService 学习进度服务
 type LearningService struct{}
 
@@ -19,98 +26,41 @@ func NewLearningService() *LearningService {
 	return &LearningService{}
 }
 
-// EnrollCourse 学生报名课程
+// EnrollCourse 学生报名课程，课程设置了先修课程时需要满足要求或已被豁免
+// 只能在报名时间内报名；课程人数已满时进入候补（status 为 waitlisted）
 func (s *LearningService) EnrollCourse(userID, branchID, courseID uint) (*models.Learning, error) {
-	// 校验课程是否存在
-	if err := ensureCourseExists(courseID); err != nil {
-		return nil, err
-	}
-
-	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	// 校验课程是否存在，未发布的课程对学生不可见，已归档的课程不再接受报名
+	course, err := getCourseByID(courseID)
 	if err != nil {
 		return nil, err
 	}
-
-	var learning models.Learning
-	if err := branchDB.Where("user_id = ? AND course_id = ?", userID, courseID).First(&learning).Error; err == nil {
-		return nil, apperrors.ErrAlreadyEnrolled
-	} else if err != nil && err != gorm.ErrRecordNotFound {
-		return nil, fmt.Errorf("failed to query learning record: %w", err)
+	if course.PublishStatus != PublishStatusPublished {
+		return nil, apperrors.ErrCourseNotFound
 	}
-
-	learning = models.Learning{
-		UserID:             userID,
-		CourseID:           courseID,
-		Status:             "enrolled",
-		ProgressPercentage: 0,
+	if course.Status == CourseStatusArchived {
+		return nil, apperrors.ErrCourseArchived
 	}
-
-	if err := branchDB.Create(&learning).Error; err != nil {
-		return nil, fmt.Errorf("failed to enroll course: %w", err)
+	if err := checkEnrollmentWindow(course, time.Now()); err != nil {
+		return nil, err
 	}
 
-	return &learning, nil
-}
-
-// UpdateProgressRequest 更新进度请求
-type UpdateProgressRequest struct {
-	Status             string `json:"status"`
-	ProgressPercentage *int   `json:"progress_percentage"`
-}
-
-// UpdateProgress 更新学习进度
-func (s *LearningService) UpdateProgress(userID, branchID, courseID uint, req *UpdateProgressRequest) (*models.Learning, error) {
 	branchDB, err := database.GetBranchDBByBranchID(branchID)
 	if err != nil {
 		return nil, err
 	}
 
 	var learning models.Learning
-	if err := branchDB.Where("user_id = ? AND course_id = ?", userID, courseID).First(&learning).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, apperrors.ErrNotEnrolled
-		}
-		return nil, fmt.Errorf("failed to get learning progress: %w", err)
-	}
-
-	if req.ProgressPercentage != nil {
-		if *req.ProgressPercentage < 0 || *req.ProgressPercentage > 100 {
-			return nil, apperrors.ErrInvalidParam
-		}
-		learning.ProgressPercentage = *req.ProgressPercentage
-	}
-
-	if req.Status != "" {
-		learning.Status = req.Status
-		if req.Status == "completed" && learning.CompletedAt == nil {
-			now := time.Now()
-			learning.CompletedAt = &now
-		}
-	}
-
-	if err := branchDB.Save(&learning).Error; err != nil {
-		return nil, fmt.Errorf("failed to update learning progress: %w", err)
+	if err := branchDB.Where("user_id = ? AND course_id = ?", userID, courseID).First(&learning).Error; err == nil {
+		return nil, apperrors.ErrAlreadyEnrolled
+	} else if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to query learning record: %w", err)
 	}
 
-	return &learning, nil
-}
-
-// GetStudentProgress 获取学生进度
-func (s *LearningService) GetStudentProgress(userID, branchID, courseID uint) (*models.Learning, error) {
-	branchDB, err := database.GetBranchDBByBranchID(branchID)
-	if err != nil {
+	if err := ensurePrerequisitesMet(userID, branchID, courseID); err != nil {
 		return nil, err
 	}
 
-	var learning models.Learning
-	if err := branchDB.Where("user_id = ? AND course_id = ?", userID, courseID).First(&learning).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, apperrors.ErrNotEnrolled
-		}
-		return nil, fmt.Errorf("failed to query learning progress: %w", err)
-	}
-
-	return &learning, nil
+	return createEnrollment(branchDB, userID, courseID)
 }
 
 // LearningProgressView 教师视角学生学习进度
@@ -126,9 +76,9 @@ type LearningProgressView struct {
 	UpdatedAt          time.Time  `json:"updated_at"`
 }
 
-// ListCourseProgressForTeacher 教师查看课程学生进度
+// ListCourseProgressForTeacher 教学团队成员查看课程学生进度
 func (s *LearningService) ListCourseProgressForTeacher(instructorUserID, branchID, courseID uint) ([]LearningProgressView, error) {
-	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+	if err := validateCourseStaff(courseID, instructorUserID, branchID); err != nil {
 		return nil, err
 	}
 
@@ -174,13 +124,18 @@ func (s *LearningService) ListCourseProgressForTeacher(instructorUserID, branchI
 }
 
 func ensureCourseExists(courseID uint) error {
+	_, err := getCourseByID(courseID)
+	return err
+}
+
+func getCourseByID(courseID uint) (*models.Courses, error) {
 	db := database.GetCentralDB()
 	var course models.Courses
 	if err := db.Where("course_id = ?", courseID).First(&course).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return apperrors.ErrCourseNotFound
+			return nil, apperrors.ErrCourseNotFound
 		}
-		return fmt.Errorf("failed to query course: %w", err)
+		return nil, fmt.Errorf("failed to query course: %w", err)
 	}
-	return nil
+	return &course, nil
 }
@@ -0,0 +1,682 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/mdsync"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/internal/oss"
+	"online-learning-platform/internal/upload"
+)
+
+// 同步变更动作
+const (
+	SyncActionCreate = "create"
+	SyncActionUpdate = "update"
+	SyncActionDelete = "delete"
+)
+
+var syncActionSymbols = map[string]string{
+	SyncActionCreate: "+",
+	SyncActionUpdate: "~",
+	SyncActionDelete: "-",
+}
+
+// MarkdownSyncOptions Markdown 同步选项
+type MarkdownSyncOptions struct {
+	Apply bool // false 时只生成同步计划，不修改数据
+	Prune bool // 删除目录中已不存在的章节、课时和任务（只处理带 slug 的内容）
+}
+
+// SyncChange 同步计划中的一项变更
+type SyncChange struct {
+	Action     string   `json:"action"`
+	EntityType string   `json:"entity_type"`
+	Slug       string   `json:"slug"`
+	Title      string   `json:"title"`
+	Fields     []string `json:"fields,omitempty"` // 更新时发生变化的字段
+}
+
+// SyncSkip 引用的文件未通过上传校验而跳过的课时
+type SyncSkip struct {
+	Slug   string `json:"slug"`
+	Title  string `json:"title"`
+	Reason string `json:"reason"`
+}
+
+// SyncPlan 同步计划；Applied 为 true 时表示已经执行
+type SyncPlan struct {
+	CourseID  uint         `json:"course_id"`
+	Applied   bool         `json:"applied"`
+	Changes   []SyncChange `json:"changes"`
+	Uploads   []string     `json:"uploads"` // 需要上传到OSS的对象Key
+	Skipped   []SyncSkip   `json:"skipped"` // 跳过的课时，已有的课时保持不变
+	Unchanged int          `json:"unchanged"`
+}
+
+// String 以 +（新建）~（更新）-（删除）列出变更，供命令行输出
+func (p *SyncPlan) String() string {
+	counts := make(map[string]int)
+	for _, change := range p.Changes {
+		counts[change.Action]++
+	}
+
+	var b strings.Builder
+	state := "plan"
+	if p.Applied {
+		state = "applied"
+	}
+	fmt.Fprintf(&b, "course %d (%s): %d to create, %d to update, %d to delete, %d unchanged, %d objects to upload\n",
+		p.CourseID, state, counts[SyncActionCreate], counts[SyncActionUpdate], counts[SyncActionDelete], p.Unchanged, len(p.Uploads))
+
+	for _, change := range p.Changes {
+		fmt.Fprintf(&b, "%s %-7s %s %q", syncActionSymbols[change.Action], change.EntityType, change.Slug, change.Title)
+		if len(change.Fields) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(change.Fields, ", "))
+		}
+		b.WriteString("\n")
+	}
+	for _, key := range p.Uploads {
+		fmt.Fprintf(&b, "^ upload  %s\n", key)
+	}
+	for _, skip := range p.Skipped {
+		fmt.Fprintf(&b, "! skip    lesson %s %q: %s\n", skip.Slug, skip.Title, skip.Reason)
+	}
+	return b.String()
+}
+
+// SyncCourseFromMarkdown 按 Markdown 目录同步课程的章节、课时和任务
+// 已有内容按 slug 匹配，没有 slug 的内容（通过接口手工创建的）不会被修改或删除，并排在同步内容之后；
+// 资源文件和课时正文按内容哈希命名上传到OSS，重复同步同一目录不会产生任何变更
+func (s *CourseService) SyncCourseFromMarkdown(courseID, instructorUserID, branchID uint, fsys fs.FS, opts MarkdownSyncOptions) (*SyncPlan, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	source, err := mdsync.Parse(fsys)
+	if err != nil {
+		return nil, apperrors.WrapError(apperrors.ErrCodeInvalidPackage, "课程目录格式无效: "+err.Error(), err)
+	}
+
+	m := &markdownSync{
+		courseID:         courseID,
+		instructorUserID: instructorUserID,
+		branchID:         branchID,
+		source:           source,
+		plan:             &SyncPlan{CourseID: courseID, Changes: []SyncChange{}, Uploads: []string{}, Skipped: []SyncSkip{}},
+		assetURLs:        make(map[string]string),
+		uploadKeys:       make(map[string]bool),
+		rejectedKeys:     make(map[string]string),
+		lessonOrder:      make(map[*models.Chapters][]*models.Lessons),
+		renumberSource:   make(map[uint]bool),
+	}
+	if err := m.build(database.GetCentralDB(), opts.Prune); err != nil {
+		return nil, err
+	}
+
+	if !opts.Apply {
+		return m.plan, nil
+	}
+
+	// 先上传OSS对象再写数据库；对象按内容命名，事务失败时保留下来也不会被误用，留给孤儿对象清理处理
+	for _, upload := range m.uploads {
+		if err := upload.put(); err != nil {
+			return nil, err
+		}
+	}
+
+	db := database.GetCentralDB()
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		for _, op := range m.ops {
+			if err := op(tx); err != nil {
+				return err
+			}
+		}
+		return m.renumber(tx)
+	}); err != nil {
+		return nil, err
+	}
+
+	m.plan.Applied = true
+	return m.plan, nil
+}
+
+// markdownSync 一次同步的上下文：build 比较目录与数据库生成计划和待执行的操作
+type markdownSync struct {
+	courseID         uint
+	instructorUserID uint
+	branchID         uint
+	source           *mdsync.Course
+	plan             *SyncPlan
+
+	ops          []func(tx *gorm.DB) error
+	uploads      []syncUpload
+	uploadKeys   map[string]bool
+	rejectedKeys map[string]string // 未通过上传校验的对象Key -> 原因
+	assetURLs    map[string]string // 目录内资源路径 -> OSS地址
+
+	// 排序：新建的章节和课时在事务中才有ID，因此按指针记录
+	chapterOrder    []*models.Chapters
+	lessonOrder     map[*models.Chapters][]*models.Lessons
+	reorderChapters bool
+	reorderLessons  []*models.Chapters
+	renumberSource  map[uint]bool // 有课时被移出或删除的原章节
+}
+
+// syncSkipError 课时引用的文件未通过上传校验，跳过该课时
+type syncSkipError struct {
+	reason string
+}
+
+func (e *syncSkipError) Error() string {
+	return e.reason
+}
+
+type syncUpload struct {
+	key  string
+	open func() (io.ReadCloser, error)
+}
+
+func (u syncUpload) put() error {
+	rc, err := u.open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", u.key, err)
+	}
+	defer rc.Close()
+
+	if _, err := oss.UploadReader(context.Background(), u.key, rc); err != nil {
+		return fmt.Errorf("failed to upload course content: %w", err)
+	}
+	return nil
+}
+
+func (m *markdownSync) build(db *gorm.DB, prune bool) error {
+	var chapters []models.Chapters
+	if err := db.Where("course_id = ? AND slug <> ''", m.courseID).Order("chapter_id ASC").Find(&chapters).Error; err != nil {
+		return fmt.Errorf("failed to load chapters: %w", err)
+	}
+	var lessons []models.Lessons
+	if err := db.Where("course_id = ? AND slug <> ''", m.courseID).Order("lesson_id ASC").Find(&lessons).Error; err != nil {
+		return fmt.Errorf("failed to load lessons: %w", err)
+	}
+	var tasks []models.Tasks
+	lessonIDs := db.Model(&models.Lessons{}).Select("lesson_id").Where("course_id = ?", m.courseID)
+	if err := db.Where("lesson_id IN (?) AND slug <> ''", lessonIDs).Order("task_id ASC").Find(&tasks).Error; err != nil {
+		return fmt.Errorf("failed to load tasks: %w", err)
+	}
+
+	existingChapters := make(map[string]*models.Chapters)
+	for i := range chapters {
+		if _, ok := existingChapters[chapters[i].Slug]; !ok {
+			existingChapters[chapters[i].Slug] = &chapters[i]
+		}
+	}
+	existingLessons := make(map[string]*models.Lessons)
+	for i := range lessons {
+		if _, ok := existingLessons[lessons[i].Slug]; !ok {
+			existingLessons[lessons[i].Slug] = &lessons[i]
+		}
+	}
+	existingTasks := make(map[uint]map[string]*models.Tasks)
+	for i := range tasks {
+		byLesson := existingTasks[tasks[i].LessonID]
+		if byLesson == nil {
+			byLesson = make(map[string]*models.Tasks)
+			existingTasks[tasks[i].LessonID] = byLesson
+		}
+		if _, ok := byLesson[tasks[i].Slug]; !ok {
+			byLesson[tasks[i].Slug] = &tasks[i]
+		}
+	}
+
+	seenChapters := make(map[string]bool)
+	seenLessons := make(map[string]bool)
+
+	for ci, src := range m.source.Chapters {
+		seenChapters[src.Slug] = true
+		chapter := m.syncChapter(existingChapters[src.Slug], src, ci+1)
+
+		for li, srcLesson := range src.Lessons {
+			seenLessons[srcLesson.Slug] = true
+			existing := existingLessons[srcLesson.Slug]
+			lesson, err := m.syncLesson(existing, chapter, srcLesson, li+1)
+			if err != nil {
+				var skip *syncSkipError
+				if errors.As(err, &skip) {
+					m.plan.Skipped = append(m.plan.Skipped, SyncSkip{Slug: srcLesson.Slug, Title: srcLesson.Title, Reason: skip.reason})
+					continue
+				}
+				return err
+			}
+
+			var current map[string]*models.Tasks
+			if existing != nil {
+				current = existingTasks[existing.LessonID]
+			}
+			if err := m.syncTasks(current, lesson, srcLesson.Tasks, prune); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !prune {
+		return nil
+	}
+
+	// 先删课时再删章节：被删除章节中已移到其他章节的课时不受影响
+	for i := range lessons {
+		lesson := &lessons[i]
+		if seenLessons[lesson.Slug] || existingLessons[lesson.Slug] != lesson {
+			continue
+		}
+		m.addChange(SyncActionDelete, RevisionEntityLesson, lesson.Slug, lesson.LessonTitle, nil)
+		m.renumberSource[lesson.ChapterID] = true
+		m.ops = append(m.ops, func(tx *gorm.DB) error {
+			return deleteLessonTx(tx, lesson.LessonID, m.instructorUserID, m.branchID)
+		})
+	}
+	for i := range chapters {
+		chapter := &chapters[i]
+		if seenChapters[chapter.Slug] || existingChapters[chapter.Slug] != chapter {
+			continue
+		}
+		// 章节中还有手工创建（没有 slug）的课时时保留章节，避免级联删除未同步的内容
+		var manualLessons int64
+		if err := db.Model(&models.Lessons{}).Where("chapter_id = ? AND slug = ''", chapter.ChapterID).Count(&manualLessons).Error; err != nil {
+			return fmt.Errorf("failed to count lessons: %w", err)
+		}
+		if manualLessons > 0 {
+			continue
+		}
+		m.addChange(SyncActionDelete, RevisionEntityChapter, chapter.Slug, chapter.ChapterTitle, nil)
+		m.reorderChapters = true
+		delete(m.renumberSource, chapter.ChapterID)
+		m.ops = append(m.ops, func(tx *gorm.DB) error {
+			return deleteChapterTx(tx, chapter.ChapterID, m.instructorUserID, m.branchID)
+		})
+	}
+	return nil
+}
+
+func (m *markdownSync) syncChapter(existing *models.Chapters, src mdsync.Chapter, order int) *models.Chapters {
+	if existing == nil {
+		chapter := &models.Chapters{
+			CourseID:      m.courseID,
+			ChapterTitle:  src.Title,
+			ChapterOrder:  order,
+			Description:   src.Description,
+			Slug:          src.Slug,
+			PublishStatus: PublishStatusDraft,
+		}
+		m.addChange(SyncActionCreate, RevisionEntityChapter, src.Slug, src.Title, nil)
+		m.chapterOrder = append(m.chapterOrder, chapter)
+		m.reorderChapters = true
+		m.ops = append(m.ops, func(tx *gorm.DB) error {
+			if err := tx.Create(chapter).Error; err != nil {
+				return fmt.Errorf("failed to create chapter: %w", err)
+			}
+			_, err := recordRevision(tx, chapter, RevisionActionCreate, m.instructorUserID, m.branchID)
+			return err
+		})
+		return chapter
+	}
+
+	chapter := existing
+	m.chapterOrder = append(m.chapterOrder, chapter)
+
+	var fields []string
+	if chapter.ChapterTitle != src.Title {
+		chapter.ChapterTitle = src.Title
+		fields = append(fields, "chapter_title")
+	}
+	if chapter.Description != src.Description {
+		chapter.Description = src.Description
+		fields = append(fields, "description")
+	}
+	if chapter.ChapterOrder != order {
+		fields = append(fields, "chapter_order")
+		m.reorderChapters = true
+	}
+	m.addChange(SyncActionUpdate, RevisionEntityChapter, src.Slug, src.Title, fields)
+
+	// 只有顺序变化时由 renumber 处理，不需要产生修订
+	if hasContentChange(fields, "chapter_order") {
+		m.ops = append(m.ops, func(tx *gorm.DB) error {
+			if err := tx.Save(chapter).Error; err != nil {
+				return fmt.Errorf("failed to update chapter: %w", err)
+			}
+			_, err := recordRevision(tx, chapter, RevisionActionUpdate, m.instructorUserID, m.branchID)
+			return err
+		})
+	}
+	return chapter
+}
+
+func (m *markdownSync) syncLesson(existing *models.Lessons, chapter *models.Chapters, src mdsync.Lesson, order int) (*models.Lessons, error) {
+	contentURL, err := m.lessonContent(src)
+	if err != nil {
+		return nil, err
+	}
+	m.lessonOrder[chapter] = append(m.lessonOrder[chapter], existing)
+
+	if existing == nil {
+		lesson := &models.Lessons{
+			CourseID:      m.courseID,
+			LessonTitle:   src.Title,
+			ContentURL:    contentURL,
+			LessonType:    src.Type,
+			LessonOrder:   order,
+			Slug:          src.Slug,
+			PublishStatus: PublishStatusDraft,
+		}
+		queueVideoProcessing(lesson)
+		m.lessonOrder[chapter][len(m.lessonOrder[chapter])-1] = lesson
+		m.addChange(SyncActionCreate, RevisionEntityLesson, src.Slug, src.Title, nil)
+		m.markLessonReorder(chapter)
+		m.ops = append(m.ops, func(tx *gorm.DB) error {
+			lesson.ChapterID = chapter.ChapterID
+			if err := tx.Create(lesson).Error; err != nil {
+				return fmt.Errorf("failed to create lesson: %w", err)
+			}
+			_, err := recordRevision(tx, lesson, RevisionActionCreate, m.instructorUserID, m.branchID)
+			return err
+		})
+		return lesson, nil
+	}
+
+	lesson := existing
+	var fields []string
+	if lesson.LessonTitle != src.Title {
+		lesson.LessonTitle = src.Title
+		fields = append(fields, "lesson_title")
+	}
+	if lesson.LessonType != src.Type || lesson.ContentURL != contentURL {
+		if lesson.LessonType != src.Type {
+			fields = append(fields, "lesson_type")
+		}
+		if lesson.ContentURL != contentURL {
+			fields = append(fields, "content_url")
+		}
+		lesson.LessonType = src.Type
+		lesson.ContentURL = contentURL
+		queueVideoProcessing(lesson)
+	}
+	if chapter.ChapterID == 0 || lesson.ChapterID != chapter.ChapterID {
+		fields = append(fields, "chapter_id")
+		m.renumberSource[lesson.ChapterID] = true
+		m.markLessonReorder(chapter)
+	} else if lesson.LessonOrder != order {
+		fields = append(fields, "lesson_order")
+		m.markLessonReorder(chapter)
+	}
+	m.addChange(SyncActionUpdate, RevisionEntityLesson, src.Slug, src.Title, fields)
+
+	if hasContentChange(fields, "lesson_order") {
+		m.ops = append(m.ops, func(tx *gorm.DB) error {
+			lesson.ChapterID = chapter.ChapterID
+			if err := tx.Save(lesson).Error; err != nil {
+				return fmt.Errorf("failed to update lesson: %w", err)
+			}
+			_, err := recordRevision(tx, lesson, RevisionActionUpdate, m.instructorUserID, m.branchID)
+			return err
+		})
+	}
+	return lesson, nil
+}
+
+func (m *markdownSync) syncTasks(existing map[string]*models.Tasks, lesson *models.Lessons, src []mdsync.Task, prune bool) error {
+	seen := make(map[string]bool, len(src))
+	for _, t := range src {
+		seen[t.Slug] = true
+
+		task, ok := existing[t.Slug]
+		if !ok {
+			task = &models.Tasks{
+				TaskTitle:   t.Title,
+				Description: t.Description,
+				TaskType:    t.TaskType,
+				MaxScore:    t.MaxScore,
+				Slug:        t.Slug,
+			}
+			m.addChange(SyncActionCreate, RevisionEntityTask, t.Slug, t.Title, nil)
+			m.ops = append(m.ops, func(tx *gorm.DB) error {
+				task.LessonID = lesson.LessonID
+				if err := tx.Create(task).Error; err != nil {
+					return fmt.Errorf("failed to create task: %w", err)
+				}
+				_, err := recordRevision(tx, task, RevisionActionCreate, m.instructorUserID, m.branchID)
+				return err
+			})
+			continue
+		}
+
+		var fields []string
+		if task.TaskTitle != t.Title {
+			task.TaskTitle = t.Title
+			fields = append(fields, "task_title")
+		}
+		if task.Description != t.Description {
+			task.Description = t.Description
+			fields = append(fields, "description")
+		}
+		scoringChanged := false
+		if task.TaskType != t.TaskType {
+			task.TaskType = t.TaskType
+			fields = append(fields, "task_type")
+			scoringChanged = true
+		}
+		if task.MaxScore != t.MaxScore {
+			task.MaxScore = t.MaxScore
+			fields = append(fields, "max_score")
+			scoringChanged = true
+		}
+		// 已设置的评分标准需要与新的任务类型和满分一致
+		if scoringChanged {
+			if err := validateTaskRubric(task); err != nil {
+				var appErr *apperrors.AppError
+				if errors.As(err, &appErr) {
+					return apperrors.WrapError(appErr.Code, fmt.Sprintf("任务 %s: %s", t.Slug, appErr.Message), err)
+				}
+				return err
+			}
+		}
+		m.addChange(SyncActionUpdate, RevisionEntityTask, t.Slug, t.Title, fields)
+		if len(fields) > 0 {
+			m.ops = append(m.ops, func(tx *gorm.DB) error {
+				if err := tx.Save(task).Error; err != nil {
+					return fmt.Errorf("failed to update task: %w", err)
+				}
+				_, err := recordRevision(tx, task, RevisionActionUpdate, m.instructorUserID, m.branchID)
+				return err
+			})
+		}
+	}
+
+	if !prune {
+		return nil
+	}
+	// 按任务ID顺序删除，保证计划输出稳定
+	stale := make([]*models.Tasks, 0, len(existing))
+	for slug, task := range existing {
+		if !seen[slug] {
+			stale = append(stale, task)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].TaskID < stale[j].TaskID })
+	for _, task := range stale {
+		task := task
+		m.addChange(SyncActionDelete, RevisionEntityTask, task.Slug, task.TaskTitle, nil)
+		m.ops = append(m.ops, func(tx *gorm.DB) error {
+			return deleteTaskTx(tx, task, m.instructorUserID, m.branchID)
+		})
+	}
+	return nil
+}
+
+// lessonContent 计算课时的内容地址：视频课时使用视频文件或外部链接（正文不上传），
+// 文本课时把正文中的资源链接替换为OSS地址后作为 Markdown 文件上传
+func (m *markdownSync) lessonContent(src mdsync.Lesson) (string, error) {
+	if src.Video != "" {
+		if !src.VideoFile {
+			return src.Video, nil
+		}
+		policy, _ := lessonUploadPolicy(LessonTypeVideo)
+		return m.assetURL(src.Video, policy)
+	}
+
+	urls := make(map[string]string, len(src.Assets))
+	for _, asset := range src.Assets {
+		url, err := m.assetURL(asset, courseAssetUploadPolicy())
+		if err != nil {
+			return "", err
+		}
+		urls[asset] = url
+	}
+
+	body := mdsync.RewriteLinks(src.Body, src.Path, urls)
+	if strings.TrimSpace(body) == "" {
+		return "", nil
+	}
+	sum := sha256.Sum256([]byte(body))
+	objectKey := fmt.Sprintf("courses/%d/markdown/%s-%s.md", m.courseID, src.Slug, hex.EncodeToString(sum[:])[:12])
+	policy, _ := lessonUploadPolicy("text")
+	return m.addUpload(objectKey, src.Path, policy, func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(body)), nil
+	})
+}
+
+// assetURL 按内容哈希计算资源文件的对象Key，同一文件在多个课时中引用时只上传一次
+func (m *markdownSync) assetURL(file string, policy upload.Policy) (string, error) {
+	if url, ok := m.assetURLs[file]; ok {
+		return url, nil
+	}
+
+	f, err := m.source.FS.Open(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	hash := sha256.New()
+	_, err = io.Copy(hash, f)
+	f.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	objectKey := fmt.Sprintf("courses/%d/assets/%s%s", m.courseID, hex.EncodeToString(hash.Sum(nil))[:16], strings.ToLower(path.Ext(file)))
+	url, err := m.addUpload(objectKey, file, policy, func() (io.ReadCloser, error) { return m.source.FS.Open(file) })
+	if err != nil {
+		return "", err
+	}
+	m.assetURLs[file] = url
+	return url, nil
+}
+
+// addUpload 登记需要上传的对象（OSS中已存在的跳过），返回对象的访问地址
+// 新上传的文件与客户端上传一样按 policy 检查类型、大小和内容并扫描，未通过时返回 syncSkipError；
+// file 为目录内的文件路径，用于说明跳过原因
+func (m *markdownSync) addUpload(objectKey, file string, policy upload.Policy, open func() (io.ReadCloser, error)) (string, error) {
+	if reason, ok := m.rejectedKeys[objectKey]; ok {
+		return "", &syncSkipError{reason: reason}
+	}
+	url, err := oss.ObjectURL(objectKey)
+	if err != nil {
+		return "", err
+	}
+	if m.uploadKeys[objectKey] {
+		return url, nil
+	}
+
+	exists, err := oss.ObjectExists(objectKey)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		if _, err := checkImportedFile(policy, objectKey, open); err != nil {
+			reason, rejected := importRejection(err)
+			if !rejected {
+				return "", err
+			}
+			m.rejectedKeys[objectKey] = file + ": " + reason
+			return "", &syncSkipError{reason: m.rejectedKeys[objectKey]}
+		}
+		m.uploads = append(m.uploads, syncUpload{key: objectKey, open: open})
+		m.plan.Uploads = append(m.plan.Uploads, objectKey)
+	}
+	m.uploadKeys[objectKey] = true
+	return url, nil
+}
+
+// renumber 按目录顺序重新编号发生变化的章节和课时，未同步的内容排在后面
+func (m *markdownSync) renumber(tx *gorm.DB) error {
+	for _, chapter := range m.reorderLessons {
+		ids := make([]uint, 0, len(m.lessonOrder[chapter]))
+		for _, lesson := range m.lessonOrder[chapter] {
+			ids = append(ids, lesson.LessonID)
+		}
+		if err := renumberLessons(tx, chapter.ChapterID, ids); err != nil {
+			return err
+		}
+		delete(m.renumberSource, chapter.ChapterID)
+	}
+	for chapterID := range m.renumberSource {
+		if err := renumberLessons(tx, chapterID, nil); err != nil {
+			return err
+		}
+	}
+
+	if !m.reorderChapters {
+		return nil
+	}
+	ids := make([]uint, 0, len(m.chapterOrder))
+	for _, chapter := range m.chapterOrder {
+		ids = append(ids, chapter.ChapterID)
+	}
+	return renumberChapters(tx, m.courseID, ids)
+}
+
+func (m *markdownSync) markLessonReorder(chapter *models.Chapters) {
+	for _, c := range m.reorderLessons {
+		if c == chapter {
+			return
+		}
+	}
+	m.reorderLessons = append(m.reorderLessons, chapter)
+}
+
+// addChange 记录变更；没有字段变化的更新计入 Unchanged
+func (m *markdownSync) addChange(action, entityType, slug, title string, fields []string) {
+	if action == SyncActionUpdate && len(fields) == 0 {
+		m.plan.Unchanged++
+		return
+	}
+	m.plan.Changes = append(m.plan.Changes, SyncChange{
+		Action:     action,
+		EntityType: entityType,
+		Slug:       slug,
+		Title:      title,
+		Fields:     fields,
+	})
+}
+
+// hasContentChange 判断除顺序字段以外是否还有其他字段变化
+func hasContentChange(fields []string, orderField string) bool {
+	for _, field := range fields {
+		if field != orderField {
+			return true
+		}
+	}
+	return false
+}
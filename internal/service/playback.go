@@ -0,0 +1,406 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"online-learning-platform/internal/config"
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/logger"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/internal/playback"
+)
+
+// LessonTypeVideo 视频课时
+const LessonTypeVideo = "video"
+
+const (
+	defaultCompletionThreshold = 0.9
+	defaultMaxBufferedPlayback = 1000
+	// maxHeartbeatSpan 单次心跳上报区间的最大长度（秒），防止一次上报整段视频；
+	// 计入的长度还受距上次心跳的实际时间限制，见 playback.Buffer.Record
+	maxHeartbeatSpan = 60.0
+)
+
+var (
+	heartbeatBuffer = playback.NewBuffer()
+	flushRequested  = make(chan struct{}, 1)
+)
+
+// HeartbeatRequest 播放心跳，start/end 为距上次心跳播放过的区间（秒）
+// 视频时长以视频处理得到的课时时长为准，不接受客户端上报
+type HeartbeatRequest struct {
+	Start float64 `json:"start" binding:"gte=0"`
+	End   float64 `json:"end" binding:"gtefield=Start"`
+}
+
+// PlaybackState 视频观看进度
+type PlaybackState struct {
+	LessonID       uint       `json:"lesson_id"`
+	Position       float64    `json:"position"` // 续播位置
+	Duration       float64    `json:"duration"`
+	WatchedSeconds float64    `json:"watched_seconds"`
+	WatchedPercent int        `json:"watched_percent"`
+	Completed      bool       `json:"completed"`
+	CompletedAt    *time.Time `json:"completed_at"`
+}
+
+// PlaybackService 视频播放进度服务
+type PlaybackService struct{}
+
+// NewPlaybackService 创建实例
+func NewPlaybackService() *PlaybackService {
+	return &PlaybackService{}
+}
+
+// Heartbeat 记录播放心跳，心跳先写入内存缓冲，由 Start 定时批量写入分支数据库
+// 同一课时在一个写入周期内只在第一次心跳时校验课时和报名状态
+func (s *PlaybackService) Heartbeat(userID, branchID, lessonID uint, req *HeartbeatRequest) (*PlaybackState, error) {
+	if req.End-req.Start > maxHeartbeatSpan {
+		return nil, apperrors.ErrInvalidParam
+	}
+
+	key := playback.Key{BranchID: branchID, UserID: userID, LessonID: lessonID}
+	iv := playback.Interval{Start: req.Start, End: req.End}
+	now := time.Now()
+
+	entry, ok := heartbeatBuffer.Record(key, iv, req.End, now)
+	if !ok {
+		seed, err := loadPlaybackEntry(key)
+		if err != nil {
+			return nil, err
+		}
+		heartbeatBuffer.Seed(*seed)
+		entry, _ = heartbeatBuffer.Record(key, iv, req.End, now)
+	}
+
+	if heartbeatBuffer.Len() >= playbackMaxBuffered() {
+		select {
+		case flushRequested <- struct{}{}:
+		default:
+		}
+	}
+
+	return toPlaybackState(&entry), nil
+}
+
+// GetPlayback 获取视频观看进度和续播位置，包含尚未写入数据库的心跳
+func (s *PlaybackService) GetPlayback(userID, branchID, lessonID uint) (*PlaybackState, error) {
+	key := playback.Key{BranchID: branchID, UserID: userID, LessonID: lessonID}
+	if entry, ok := heartbeatBuffer.Get(key); ok {
+		return toPlaybackState(&entry), nil
+	}
+
+	if err := ensureLessonVisible(lessonID); err != nil {
+		return nil, err
+	}
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var record models.VideoProgress
+	if err := branchDB.Where("user_id = ? AND lesson_id = ?", userID, lessonID).First(&record).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &PlaybackState{LessonID: lessonID}, nil
+		}
+		return nil, fmt.Errorf("failed to query video progress: %w", err)
+	}
+
+	entry, err := videoProgressEntry(key, &record)
+	if err != nil {
+		return nil, err
+	}
+	// 旧记录中的时长可能来自客户端上报，以课时的时长为准
+	var duration int
+	if err := database.GetCentralDB().Model(&models.Lessons{}).Select("duration").
+		Where("lesson_id = ?", lessonID).Scan(&duration).Error; err != nil {
+		return nil, fmt.Errorf("failed to query lesson: %w", err)
+	}
+	entry.Duration = float64(duration)
+	return toPlaybackState(entry), nil
+}
+
+// FlushHeartbeats 将缓冲中的心跳写入分支数据库，返回写入的记录数
+// 写入失败的记录放回缓冲，下次重试
+func (s *PlaybackService) FlushHeartbeats() (int, error) {
+	entries := heartbeatBuffer.Drain()
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	// 视频可能在载入记录之后才处理完成，写入时以课时当前的时长为准
+	durations, err := lessonDurations(entries)
+	if err != nil {
+		for i := range entries {
+			heartbeatBuffer.Restore(entries[i])
+		}
+		return 0, err
+	}
+
+	flushed := 0
+	var firstErr error
+	for i := range entries {
+		entries[i].Duration = durations[entries[i].LessonID]
+		if err := flushPlaybackEntry(&entries[i]); err != nil {
+			heartbeatBuffer.Restore(entries[i])
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		flushed++
+	}
+	return flushed, firstErr
+}
+
+// Start 按固定间隔写入心跳缓冲，缓冲过大时提前写入；stop 关闭后写入剩余心跳并返回
+func (s *PlaybackService) Start(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	flush := func() {
+		count, err := s.FlushHeartbeats()
+		if err != nil {
+			logger.WithError(err).Error("Flushing playback heartbeats failed")
+		}
+		if count > 0 {
+			logger.Debugf("Playback heartbeats: %d records flushed", count)
+		}
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			flush()
+		case <-flushRequested:
+			flush()
+		case <-stop:
+			flush()
+			return
+		}
+	}
+}
+
+// loadPlaybackEntry 校验课时和报名状态，并载入已保存的观看进度
+// 视频时长取课时的 duration，视频尚未处理完成时为 0，此时只记录区间，不会自动完成
+func loadPlaybackEntry(key playback.Key) (*playback.Entry, error) {
+	lesson, err := getVideoLesson(key.LessonID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureCourseWritable(lesson.CourseID); err != nil {
+		return nil, err
+	}
+
+	branchDB, err := database.GetBranchDBByBranchID(key.BranchID)
+	if err != nil {
+		return nil, err
+	}
+	learning, err := getLearning(branchDB, key.UserID, lesson.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if learning.Status == LearningStatusWaitlisted {
+		return nil, apperrors.ErrNotEnrolled
+	}
+
+	var record models.VideoProgress
+	if err := branchDB.Where("user_id = ? AND lesson_id = ?", key.UserID, key.LessonID).First(&record).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("failed to query video progress: %w", err)
+		}
+		return &playback.Entry{Key: key, CourseID: lesson.CourseID, Duration: float64(lesson.Duration)}, nil
+	}
+
+	entry, err := videoProgressEntry(key, &record)
+	if err != nil {
+		return nil, err
+	}
+	entry.CourseID = lesson.CourseID
+	entry.Duration = float64(lesson.Duration)
+	return entry, nil
+}
+
+// flushPlaybackEntry 在事务中合并一条缓冲记录，观看比例达到要求时标记课时完成
+func flushPlaybackEntry(entry *playback.Entry) error {
+	branchDB, err := database.GetBranchDBByBranchID(entry.BranchID)
+	if err != nil {
+		return err
+	}
+
+	completed := false
+	err = branchDB.Transaction(func(tx *gorm.DB) error {
+		var record models.VideoProgress
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND lesson_id = ?", entry.UserID, entry.LessonID).
+			First(&record).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to query video progress: %w", err)
+		}
+
+		// 以数据库中的区间为准合并，多个实例或重试写入时不会丢失区间；
+		// 时长已知时截断全部区间，包括视频处理完成前按原样记录的区间
+		saved, err := decodeIntervals(record.Intervals)
+		if err != nil {
+			return err
+		}
+		intervals := playback.Merge(append(saved, entry.Pending...))
+		if entry.Duration > 0 {
+			intervals = playback.ClampAll(intervals, entry.Duration)
+			if entry.Position > entry.Duration {
+				entry.Position = entry.Duration
+			}
+		}
+		encoded, err := json.Marshal(intervals)
+		if err != nil {
+			return fmt.Errorf("failed to encode watched intervals: %w", err)
+		}
+
+		record.UserID = entry.UserID
+		record.CourseID = entry.CourseID
+		record.LessonID = entry.LessonID
+		record.Position = entry.Position
+		record.Duration = entry.Duration
+		record.WatchedSeconds = playback.Watched(intervals)
+		record.Intervals = string(encoded)
+		if record.CompletedAt == nil && reachedCompletion(record.WatchedSeconds, record.Duration) {
+			now := time.Now()
+			record.CompletedAt = &now
+			completed = true
+		}
+		if err := tx.Save(&record).Error; err != nil {
+			return fmt.Errorf("failed to save video progress: %w", err)
+		}
+
+		if completed {
+			lessonProgress := models.LessonProgress{
+				UserID:      entry.UserID,
+				CourseID:    entry.CourseID,
+				LessonID:    entry.LessonID,
+				CompletedAt: *record.CompletedAt,
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&lessonProgress).Error; err != nil {
+				return fmt.Errorf("failed to record lesson progress: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if completed {
+		refreshStudentProgress(branchDB, entry.UserID, entry.CourseID)
+	}
+	return nil
+}
+
+// lessonDurations 查询缓冲记录对应课时的当前时长
+func lessonDurations(entries []playback.Entry) (map[uint]float64, error) {
+	lessonIDs := make([]uint, 0, len(entries))
+	for _, e := range entries {
+		lessonIDs = append(lessonIDs, e.LessonID)
+	}
+	var lessons []models.Lessons
+	if err := database.GetCentralDB().Unscoped().Select("lesson_id", "duration").
+		Where("lesson_id IN ?", lessonIDs).Find(&lessons).Error; err != nil {
+		return nil, fmt.Errorf("failed to query lesson durations: %w", err)
+	}
+	durations := make(map[uint]float64, len(lessons))
+	for _, lesson := range lessons {
+		durations[lesson.LessonID] = float64(lesson.Duration)
+	}
+	return durations, nil
+}
+
+// getVideoLesson 查询学生可见的视频课时，不是视频课时时不能记录播放进度
+func getVideoLesson(lessonID uint) (*models.Lessons, error) {
+	var lesson models.Lessons
+	if err := database.GetCentralDB().Where("lesson_id = ?", lessonID).First(&lesson).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrLessonNotFound
+		}
+		return nil, fmt.Errorf("failed to query lesson: %w", err)
+	}
+	if err := ensureLessonVisible(lessonID); err != nil {
+		return nil, err
+	}
+	if !isVideoLesson(&lesson) {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "只有视频课时可以记录播放进度")
+	}
+	return &lesson, nil
+}
+
+func isVideoLesson(lesson *models.Lessons) bool {
+	return lesson.LessonType == LessonTypeVideo && lesson.ContentURL != ""
+}
+
+func videoProgressEntry(key playback.Key, record *models.VideoProgress) (*playback.Entry, error) {
+	saved, err := decodeIntervals(record.Intervals)
+	if err != nil {
+		return nil, err
+	}
+	return &playback.Entry{
+		Key:         key,
+		CourseID:    record.CourseID,
+		Saved:       saved,
+		Position:    record.Position,
+		Duration:    record.Duration,
+		CompletedAt: record.CompletedAt,
+		UpdatedAt:   record.UpdatedAt,
+	}, nil
+}
+
+func decodeIntervals(raw string) ([]playback.Interval, error) {
+	intervals := []playback.Interval{}
+	if raw == "" {
+		return intervals, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &intervals); err != nil {
+		return nil, fmt.Errorf("failed to decode watched intervals: %w", err)
+	}
+	return intervals, nil
+}
+
+func toPlaybackState(entry *playback.Entry) *PlaybackState {
+	watched := playback.Watched(entry.Intervals())
+	state := &PlaybackState{
+		LessonID:       entry.LessonID,
+		Position:       entry.Position,
+		Duration:       entry.Duration,
+		WatchedSeconds: watched,
+		CompletedAt:    entry.CompletedAt,
+	}
+	if entry.Duration > 0 {
+		state.WatchedPercent = int(watched * 100 / entry.Duration)
+	}
+	state.Completed = entry.CompletedAt != nil || reachedCompletion(watched, entry.Duration)
+	return state
+}
+
+func reachedCompletion(watched, duration float64) bool {
+	return duration > 0 && watched >= duration*playbackCompletionThreshold()
+}
+
+// playbackCompletionThreshold 完成课时需要的观看比例，未配置或超出 (0, 1] 时使用默认值
+func playbackCompletionThreshold() float64 {
+	if cfg := config.GetConfig(); cfg != nil {
+		if t := cfg.Playback.CompletionThreshold; t > 0 && t <= 1 {
+			return t
+		}
+	}
+	return defaultCompletionThreshold
+}
+
+func playbackMaxBuffered() int {
+	if cfg := config.GetConfig(); cfg != nil && cfg.Playback.MaxBuffered > 0 {
+		return cfg.Playback.MaxBuffered
+	}
+	return defaultMaxBufferedPlayback
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/models"
+)
+
+// UserRoleAdmin 管理员角色，可以管理所有课程的先修豁免
+const UserRoleAdmin = "admin"
+
+// learningStatusRank 学习状态的先后顺序，用于比较是否达到先修要求
+var learningStatusRank = map[string]int{
+	LearningStatusEnrolled:   1,
+	LearningStatusInProgress: 2,
+	LearningStatusCompleted:  3,
+}
+
+// PrerequisiteService 先修课程服务
+type PrerequisiteService struct{}
+
+// NewPrerequisiteService 创建先修课程服务
+func NewPrerequisiteService() *PrerequisiteService {
+	return &PrerequisiteService{}
+}
+
+// PrerequisiteRequest 先修课程设置
+type PrerequisiteRequest struct {
+	CourseID  uint   `json:"course_id" binding:"required"`
+	MinStatus string `json:"min_status"` // 默认 completed
+	MinScore  *int   `json:"min_score"`  // 作业得分率下限（0-100）
+}
+
+// SetPrerequisitesRequest 设置课程的全部先修课程（覆盖原有设置）
+type SetPrerequisitesRequest struct {
+	Prerequisites []PrerequisiteRequest `json:"prerequisites"`
+}
+
+// PrerequisiteInfo 先修课程信息
+type PrerequisiteInfo struct {
+	CourseID    uint   `json:"course_id"`
+	CourseTitle string `json:"course_title"`
+	MinStatus   string `json:"min_status"`
+	MinScore    *int   `json:"min_score"`
+}
+
+// PrerequisiteStatus 学生对一门先修课程的完成情况
+type PrerequisiteStatus struct {
+	PrerequisiteInfo
+	Status string `json:"status"` // 学生在先修课程中的学习状态，未报名时为空
+	Score  *int   `json:"score"`  // 作业得分率，只在设置了 MinScore 时计算
+	Met    bool   `json:"met"`
+}
+
+// PrerequisiteCheck 报名前的先修课程检查结果
+type PrerequisiteCheck struct {
+	CourseID      uint                 `json:"course_id"`
+	Met           bool                 `json:"met"`        // 可以报名（全部满足或已豁免）
+	Overridden    bool                 `json:"overridden"` // 教师或管理员已豁免
+	Prerequisites []PrerequisiteStatus `json:"prerequisites"`
+}
+
+// GrantOverrideRequest 豁免请求
+type GrantOverrideRequest struct {
+	BranchID uint   `json:"branch_id" binding:"required"`
+	UserID   uint   `json:"user_id" binding:"required"`
+	Reason   string `json:"reason"`
+}
+
+// ListPrerequisites 获取课程的先修课程（教师视角）
+func (s *PrerequisiteService) ListPrerequisites(courseID, instructorUserID, branchID uint) ([]PrerequisiteInfo, error) {
+	if err := validateCourseStaff(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+	return listPrerequisites(database.GetCentralDB(), courseID)
+}
+
+// SetPrerequisites 设置课程的先修课程，先修课程之间不能形成循环
+func (s *PrerequisiteService) SetPrerequisites(courseID, instructorUserID, branchID uint, req *SetPrerequisitesRequest) ([]PrerequisiteInfo, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+	prerequisites := make([]models.CoursePrerequisites, 0, len(req.Prerequisites))
+	seen := make(map[uint]bool, len(req.Prerequisites))
+	for _, item := range req.Prerequisites {
+		if item.CourseID == courseID || seen[item.CourseID] {
+			return nil, apperrors.ErrInvalidParam
+		}
+		seen[item.CourseID] = true
+
+		if item.MinStatus == "" {
+			item.MinStatus = LearningStatusCompleted
+		}
+		if _, ok := learningStatusRank[item.MinStatus]; !ok {
+			return nil, apperrors.ErrInvalidParam
+		}
+		if item.MinScore != nil && (*item.MinScore < 0 || *item.MinScore > 100) {
+			return nil, apperrors.ErrInvalidParam
+		}
+		if _, err := getCourseByID(item.CourseID); err != nil {
+			return nil, err
+		}
+
+		prerequisites = append(prerequisites, models.CoursePrerequisites{
+			CourseID:             courseID,
+			PrerequisiteCourseID: item.CourseID,
+			MinStatus:            item.MinStatus,
+			MinScore:             item.MinScore,
+		})
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("course_id = ?", courseID).Delete(&models.CoursePrerequisites{}).Error; err != nil {
+			return fmt.Errorf("failed to clear prerequisites: %w", err)
+		}
+		if len(prerequisites) == 0 {
+			return nil
+		}
+		if err := tx.Create(&prerequisites).Error; err != nil {
+			return fmt.Errorf("failed to save prerequisites: %w", err)
+		}
+		return checkPrerequisiteCycle(tx, courseID)
+	}); err != nil {
+		return nil, err
+	}
+
+	return listPrerequisites(db, courseID)
+}
+
+// CheckPrerequisites 检查学生是否满足课程的先修要求
+func (s *PrerequisiteService) CheckPrerequisites(userID, branchID, courseID uint) (*PrerequisiteCheck, error) {
+	return checkPrerequisites(userID, branchID, courseID)
+}
+
+// ListOverrides 获取课程的先修豁免列表
+func (s *PrerequisiteService) ListOverrides(courseID, userID, branchID uint, role string) ([]models.PrerequisiteOverrides, error) {
+	if err := authorizeCourseManager(courseID, userID, branchID, role); err != nil {
+		return nil, err
+	}
+
+	var overrides []models.PrerequisiteOverrides
+	if err := database.GetCentralDB().Where("course_id = ?", courseID).Order("override_id ASC").Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to list overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// GrantOverride 为学生豁免课程的先修要求，重复授予时更新原因
+func (s *PrerequisiteService) GrantOverride(courseID, userID, branchID uint, role string, req *GrantOverrideRequest) (*models.PrerequisiteOverrides, error) {
+	if err := authorizeCourseManager(courseID, userID, branchID, role); err != nil {
+		return nil, err
+	}
+
+	// 学生属于所在分支，需要到分支节点确认
+	studentDB, err := database.GetBranchDBByBranchID(req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+	if err := studentDB.Where("user_id = ?", req.UserID).First(&models.Users{}).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to query student: %w", err)
+	}
+
+	db := database.GetCentralDB()
+	var override models.PrerequisiteOverrides
+	err = db.Where("course_id = ? AND branch_id = ? AND user_id = ?", courseID, req.BranchID, req.UserID).First(&override).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to query override: %w", err)
+	}
+
+	override.CourseID = courseID
+	override.BranchID = req.BranchID
+	override.UserID = req.UserID
+	override.Reason = req.Reason
+	override.GrantedByUserID = userID
+	override.GrantedByBranchID = branchID
+	if err := db.Save(&override).Error; err != nil {
+		return nil, fmt.Errorf("failed to save override: %w", err)
+	}
+	return &override, nil
+}
+
+// RevokeOverride 撤销先修豁免，已经报名的学生不受影响
+func (s *PrerequisiteService) RevokeOverride(courseID, overrideID, userID, branchID uint, role string) error {
+	if err := authorizeCourseManager(courseID, userID, branchID, role); err != nil {
+		return err
+	}
+
+	result := database.GetCentralDB().Where("override_id = ? AND course_id = ?", overrideID, courseID).Delete(&models.PrerequisiteOverrides{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke override: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+// ensurePrerequisitesMet 报名前校验先修要求，未满足时返回列出未满足课程的错误
+func ensurePrerequisitesMet(userID, branchID, courseID uint) error {
+	check, err := checkPrerequisites(userID, branchID, courseID)
+	if err != nil {
+		return err
+	}
+	if check.Met {
+		return nil
+	}
+
+	var unmet []string
+	for _, p := range check.Prerequisites {
+		if p.Met {
+			continue
+		}
+		requirement := p.MinStatus
+		if p.MinScore != nil {
+			requirement = fmt.Sprintf("%s，得分率不低于%d%%", requirement, *p.MinScore)
+		}
+		unmet = append(unmet, fmt.Sprintf("《%s》（需要 %s）", p.CourseTitle, requirement))
+	}
+	return apperrors.NewAppError(apperrors.ErrCodePrerequisitesNotMet,
+		fmt.Sprintf("%s：%s", apperrors.ErrPrerequisitesNotMet.Message, strings.Join(unmet, "、")))
+}
+
+func checkPrerequisites(userID, branchID, courseID uint) (*PrerequisiteCheck, error) {
+	db := database.GetCentralDB()
+	prerequisites, err := listPrerequisites(db, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &PrerequisiteCheck{CourseID: courseID, Met: true, Prerequisites: make([]PrerequisiteStatus, 0, len(prerequisites))}
+	if len(prerequisites) == 0 {
+		return check, nil
+	}
+
+	var overrides int64
+	if err := db.Model(&models.PrerequisiteOverrides{}).
+		Where("course_id = ? AND branch_id = ? AND user_id = ?", courseID, branchID, userID).
+		Count(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to query override: %w", err)
+	}
+	check.Overridden = overrides > 0
+
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range prerequisites {
+		status := PrerequisiteStatus{PrerequisiteInfo: p}
+
+		var learning models.Learning
+		if err := branchDB.Where("user_id = ? AND course_id = ?", userID, p.CourseID).First(&learning).Error; err == nil {
+			status.Status = learning.Status
+		} else if err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("failed to query learning record: %w", err)
+		}
+		status.Met = learningStatusRank[status.Status] >= learningStatusRank[p.MinStatus]
+
+		if p.MinScore != nil && status.Status != "" {
+			score, err := courseScorePercent(branchDB, userID, p.CourseID)
+			if err != nil {
+				return nil, err
+			}
+			status.Score = &score
+			status.Met = status.Met && score >= *p.MinScore
+		}
+
+		if !status.Met {
+			check.Met = false
+		}
+		check.Prerequisites = append(check.Prerequisites, status)
+	}
+
+	if check.Overridden {
+		check.Met = true
+	}
+	return check, nil
+}
+
+// listPrerequisites 查询课程的先修课程，已删除的先修课程不再生效
+func listPrerequisites(db *gorm.DB, courseID uint) ([]PrerequisiteInfo, error) {
+	var rows []struct {
+		PrerequisiteCourseID uint
+		CourseTitle          string
+		MinStatus            string
+		MinScore             *int
+	}
+	if err := db.Table("course_prerequisites AS p").
+		Select("p.prerequisite_course_id, c.course_title, p.min_status, p.min_score").
+		Joins("JOIN courses c ON c.course_id = p.prerequisite_course_id AND c.deleted_at IS NULL").
+		Where("p.course_id = ?", courseID).
+		Order("p.prerequisite_id ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list prerequisites: %w", err)
+	}
+
+	result := make([]PrerequisiteInfo, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, PrerequisiteInfo{
+			CourseID:    row.PrerequisiteCourseID,
+			CourseTitle: row.CourseTitle,
+			MinStatus:   row.MinStatus,
+			MinScore:    row.MinScore,
+		})
+	}
+	return result, nil
+}
+
+// checkPrerequisiteCycle 从课程的先修课程出发沿先修关系查找，回到课程本身说明存在循环
+func checkPrerequisiteCycle(tx *gorm.DB, courseID uint) error {
+	visited := map[uint]bool{}
+	queue := []uint{courseID}
+	for len(queue) > 0 {
+		var next []uint
+		if err := tx.Model(&models.CoursePrerequisites{}).
+			Where("course_id IN ?", queue).
+			Pluck("prerequisite_course_id", &next).Error; err != nil {
+			return fmt.Errorf("failed to check prerequisite cycle: %w", err)
+		}
+
+		queue = queue[:0]
+		for _, id := range next {
+			if id == courseID {
+				return apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "先修课程不能形成循环")
+			}
+			if !visited[id] {
+				visited[id] = true
+				queue = append(queue, id)
+			}
+		}
+	}
+	return nil
+}
+
+// courseScorePercent 计算学生在课程作业上的得分率（每个任务取已批改的最高分）
+// 课程没有任务时视为满分
+func courseScorePercent(branchDB *gorm.DB, userID, courseID uint) (int, error) {
+	var tasks []models.Tasks
+	lessonIDs := database.GetCentralDB().Model(&models.Lessons{}).Select("lesson_id").Where("course_id = ?", courseID)
+	if err := database.GetCentralDB().Where("lesson_id IN (?)", lessonIDs).Find(&tasks).Error; err != nil {
+		return 0, fmt.Errorf("failed to load tasks: %w", err)
+	}
+
+	total := 0
+	taskIDs := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		total += task.MaxScore
+		taskIDs = append(taskIDs, task.TaskID)
+	}
+	if total == 0 {
+		return 100, nil
+	}
+
+	var earned int
+	best := branchDB.Model(&models.Answers{}).
+		Select("MAX(score) AS score").
+		Where("user_id = ? AND task_id IN ? AND is_graded = ? AND is_counted = ?", userID, taskIDs, true, true).
+		Group("task_id")
+	if err := branchDB.Table("(?) AS best", best).Select("COALESCE(SUM(score), 0)").Scan(&earned).Error; err != nil {
+		return 0, fmt.Errorf("failed to sum scores: %w", err)
+	}
+
+	return earned * 100 / total, nil
+}
+
+// authorizeCourseManager 管理员可以管理任意课程，教师需要是课程的主讲或协同教师
+func authorizeCourseManager(courseID, userID, branchID uint, role string) error {
+	if role == UserRoleAdmin {
+		_, err := getCourseByID(courseID)
+		return err
+	}
+	return validateCourseOwner(courseID, userID, branchID)
+}
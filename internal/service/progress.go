@@ -0,0 +1,197 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/logger"
+	"online-learning-platform/internal/models"
+)
+
+// CourseProgress 学生的课程进度
+// 百分比 = (已完成课时 + 已提交任务) / (课程中学生可见的课时 + 任务)，全部完成时课程自动标记为 completed
+type CourseProgress struct {
+	models.Learning
+	TotalLessons     int    `json:"total_lessons"`
+	CompletedLessons []uint `json:"completed_lessons"`
+	TotalTasks       int    `json:"total_tasks"`
+	SubmittedTasks   []uint `json:"submitted_tasks"`
+}
+
+// CompleteLesson 学生标记课时已学完，返回重新计算后的课程进度
+// 课时需要对学生可见，候补中或课程已结束时不能标记；视频课时按观看比例自动完成
+func (s *LearningService) CompleteLesson(userID, branchID, lessonID uint) (*CourseProgress, error) {
+	var lesson models.Lessons
+	if err := database.GetCentralDB().Where("lesson_id = ?", lessonID).First(&lesson).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrLessonNotFound
+		}
+		return nil, fmt.Errorf("failed to query lesson: %w", err)
+	}
+	if err := ensureLessonVisible(lessonID); err != nil {
+		return nil, err
+	}
+	// 视频课时由播放心跳判定完成，不能直接标记
+	if isVideoLesson(&lesson) {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "视频课时观看达到要求后自动完成")
+	}
+	if err := ensureCourseWritable(lesson.CourseID); err != nil {
+		return nil, err
+	}
+
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	learning, err := getLearning(branchDB, userID, lesson.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if learning.Status == LearningStatusWaitlisted {
+		return nil, apperrors.ErrNotEnrolled
+	}
+
+	// 重复标记时保留第一次完成的时间
+	record := models.LessonProgress{
+		UserID:      userID,
+		CourseID:    lesson.CourseID,
+		LessonID:    lessonID,
+		CompletedAt: time.Now(),
+	}
+	if err := branchDB.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error; err != nil {
+		return nil, fmt.Errorf("failed to record lesson progress: %w", err)
+	}
+
+	return refreshCourseProgress(branchDB, learning)
+}
+
+// GetStudentProgress 获取学生进度，返回前按当前课程内容重新计算
+func (s *LearningService) GetStudentProgress(userID, branchID, courseID uint) (*CourseProgress, error) {
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	learning, err := getLearning(branchDB, userID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if learning.Status == LearningStatusWaitlisted {
+		return &CourseProgress{Learning: *learning, CompletedLessons: []uint{}, SubmittedTasks: []uint{}}, nil
+	}
+
+	return refreshCourseProgress(branchDB, learning)
+}
+
+// refreshStudentProgress 学生提交作业后更新课程进度，未报名或候补中时不处理
+// 作业已经保存，进度更新失败只记录日志，下次查询进度时会重新计算
+func refreshStudentProgress(branchDB *gorm.DB, userID, courseID uint) {
+	learning, err := getLearning(branchDB, userID, courseID)
+	if err != nil {
+		if err != apperrors.ErrNotEnrolled {
+			logger.Warnf("course %d: failed to load learning record of user %d: %v", courseID, userID, err)
+		}
+		return
+	}
+	if learning.Status == LearningStatusWaitlisted {
+		return
+	}
+	if _, err := refreshCourseProgress(branchDB, learning); err != nil {
+		logger.Warnf("course %d: failed to refresh progress of user %d: %v", courseID, userID, err)
+	}
+}
+
+// refreshCourseProgress 按已完成课时和已提交任务重新计算进度并保存
+// 开始学习后状态变为 in_progress，全部完成时变为 completed 并记录完成时间；
+// 课程完成后再增加内容不会撤销完成状态
+func refreshCourseProgress(branchDB *gorm.DB, learning *models.Learning) (*CourseProgress, error) {
+	centralDB := database.GetCentralDB()
+
+	var lessonIDs []uint
+	if err := centralDB.Table("lessons").Scopes(visibleLessons).
+		Where("lessons.course_id = ?", learning.CourseID).
+		Pluck("lessons.lesson_id", &lessonIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load lessons: %w", err)
+	}
+
+	var taskIDs []uint
+	if len(lessonIDs) > 0 {
+		if err := centralDB.Model(&models.Tasks{}).
+			Where("lesson_id IN ?", lessonIDs).
+			Pluck("task_id", &taskIDs).Error; err != nil {
+			return nil, fmt.Errorf("failed to load tasks: %w", err)
+		}
+	}
+
+	progress := &CourseProgress{
+		TotalLessons:     len(lessonIDs),
+		CompletedLessons: []uint{},
+		TotalTasks:       len(taskIDs),
+		SubmittedTasks:   []uint{},
+	}
+	if len(lessonIDs) > 0 {
+		if err := branchDB.Model(&models.LessonProgress{}).
+			Where("user_id = ? AND lesson_id IN ?", learning.UserID, lessonIDs).
+			Order("lesson_id ASC").
+			Pluck("lesson_id", &progress.CompletedLessons).Error; err != nil {
+			return nil, fmt.Errorf("failed to load lesson progress: %w", err)
+		}
+	}
+	if len(taskIDs) > 0 {
+		if err := branchDB.Model(&models.Answers{}).
+			Where("user_id = ? AND task_id IN ?", learning.UserID, taskIDs).
+			Distinct("task_id").Order("task_id ASC").
+			Pluck("task_id", &progress.SubmittedTasks).Error; err != nil {
+			return nil, fmt.Errorf("failed to load submitted tasks: %w", err)
+		}
+	}
+
+	total := progress.TotalLessons + progress.TotalTasks
+	done := len(progress.CompletedLessons) + len(progress.SubmittedTasks)
+	percentage := 0
+	if total > 0 {
+		percentage = done * 100 / total
+	}
+
+	status := learning.Status
+	completedAt := learning.CompletedAt
+	if status != LearningStatusCompleted {
+		switch {
+		case total > 0 && done == total:
+			status = LearningStatusCompleted
+			now := time.Now()
+			completedAt = &now
+		case done > 0:
+			status = LearningStatusInProgress
+		}
+	}
+
+	if percentage != learning.ProgressPercentage || status != learning.Status {
+		learning.ProgressPercentage = percentage
+		learning.Status = status
+		learning.CompletedAt = completedAt
+		if err := branchDB.Save(learning).Error; err != nil {
+			return nil, fmt.Errorf("failed to update learning progress: %w", err)
+		}
+	}
+
+	progress.Learning = *learning
+	return progress, nil
+}
+
+func getLearning(branchDB *gorm.DB, userID, courseID uint) (*models.Learning, error) {
+	var learning models.Learning
+	if err := branchDB.Where("user_id = ? AND course_id = ?", userID, courseID).First(&learning).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrNotEnrolled
+		}
+		return nil, fmt.Errorf("failed to query learning progress: %w", err)
+	}
+	return &learning, nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/logger"
+	"online-learning-platform/internal/models"
+)
+
+// 发布状态
+const (
+	PublishStatusDraft     = "draft"
+	PublishStatusPublished = "published"
+)
+
+// PublishRequest 发布请求
+// publish_at 为空或不晚于当前时间时立即发布，否则设置为定时发布，由调度器到期后发布
+type PublishRequest struct {
+	PublishAt *string `json:"publish_at"`
+}
+
+// PublishCourse 发布课程
+func (s *CourseService) PublishCourse(courseID, instructorUserID, branchID uint, req *PublishRequest) (*CourseInfo, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+	if err := setPublishState(&models.Courses{}, "course_id = ?", []interface{}{courseID}, req); err != nil {
+		return nil, err
+	}
+	return s.GetCourse(courseID, true, false)
+}
+
+// UnpublishCourse 撤回课程到草稿状态
+func (s *CourseService) UnpublishCourse(courseID, instructorUserID, branchID uint) (*CourseInfo, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+	if err := setPublishState(&models.Courses{}, "course_id = ?", []interface{}{courseID}, nil); err != nil {
+		return nil, err
+	}
+	return s.GetCourse(courseID, true, false)
+}
+
+// PublishChapter 发布章节
+func (s *CourseService) PublishChapter(courseID, chapterID, instructorUserID, branchID uint, req *PublishRequest) (*CourseInfo, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+	if _, err := findChapter(database.GetCentralDB(), courseID, chapterID); err != nil {
+		return nil, err
+	}
+	if err := setPublishState(&models.Chapters{}, "chapter_id = ?", []interface{}{chapterID}, req); err != nil {
+		return nil, err
+	}
+	return s.GetCourse(courseID, true, false)
+}
+
+// UnpublishChapter 撤回章节到草稿状态
+func (s *CourseService) UnpublishChapter(courseID, chapterID, instructorUserID, branchID uint) (*CourseInfo, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+	if _, err := findChapter(database.GetCentralDB(), courseID, chapterID); err != nil {
+		return nil, err
+	}
+	if err := setPublishState(&models.Chapters{}, "chapter_id = ?", []interface{}{chapterID}, nil); err != nil {
+		return nil, err
+	}
+	return s.GetCourse(courseID, true, false)
+}
+
+// PublishLesson 发布课时
+func (s *CourseService) PublishLesson(courseID, chapterID, lessonID, instructorUserID, branchID uint, req *PublishRequest) (*CourseInfo, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+	if _, err := findLesson(database.GetCentralDB(), courseID, chapterID, lessonID); err != nil {
+		return nil, err
+	}
+	if err := setPublishState(&models.Lessons{}, "lesson_id = ?", []interface{}{lessonID}, req); err != nil {
+		return nil, err
+	}
+	return s.GetCourse(courseID, true, false)
+}
+
+// UnpublishLesson 撤回课时到草稿状态
+func (s *CourseService) UnpublishLesson(courseID, chapterID, lessonID, instructorUserID, branchID uint) (*CourseInfo, error) {
+	if err := validateCourseOwner(courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+	if _, err := findLesson(database.GetCentralDB(), courseID, chapterID, lessonID); err != nil {
+		return nil, err
+	}
+	if err := setPublishState(&models.Lessons{}, "lesson_id = ?", []interface{}{lessonID}, nil); err != nil {
+		return nil, err
+	}
+	return s.GetCourse(courseID, true, false)
+}
+
+// setPublishState 更新发布状态，req 为 nil 表示撤回为草稿
+func setPublishState(model interface{}, where string, args []interface{}, req *PublishRequest) error {
+	updates := map[string]interface{}{
+		"publish_status": PublishStatusDraft,
+		"publish_at":     nil,
+	}
+
+	if req != nil {
+		now := time.Now()
+		publishAt := &now
+		if req.PublishAt != nil && *req.PublishAt != "" {
+			t, err := parseCourseDate(req.PublishAt)
+			if err != nil {
+				return err
+			}
+			publishAt = t
+		}
+		updates["publish_at"] = *publishAt
+		if !publishAt.After(now) {
+			updates["publish_status"] = PublishStatusPublished
+		}
+	}
+
+	db := database.GetCentralDB()
+	if err := db.Model(model).Where(where, args...).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update publish state: %w", err)
+	}
+	return nil
+}
+
+// visibleLessons 限定为学生可见的课时：课时、所属章节和课程都已发布
+// 查询中需已包含 lessons 表（作为主表或已 JOIN）
+func visibleLessons(db *gorm.DB) *gorm.DB {
+	return db.
+		Joins("JOIN chapters ON chapters.chapter_id = lessons.chapter_id AND chapters.deleted_at IS NULL").
+		Joins("JOIN courses ON courses.course_id = lessons.course_id AND courses.deleted_at IS NULL").
+		Where("lessons.deleted_at IS NULL").
+		Where("lessons.publish_status = ? AND chapters.publish_status = ? AND courses.publish_status = ?",
+			PublishStatusPublished, PublishStatusPublished, PublishStatusPublished)
+}
+
+// ensureLessonVisible 校验课时对学生可见，不可见时按不存在处理
+func ensureLessonVisible(lessonID uint) error {
+	var count int64
+	if err := database.GetCentralDB().Table("lessons").
+		Scopes(visibleLessons).
+		Where("lessons.lesson_id = ?", lessonID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to verify lesson visibility: %w", err)
+	}
+	if count == 0 {
+		return apperrors.ErrLessonNotFound
+	}
+	return nil
+}
+
+// PublishService 定时发布服务
+type PublishService struct{}
+
+// NewPublishService 创建定时发布服务
+func NewPublishService() *PublishService {
+	return &PublishService{}
+}
+
+// RunScheduledPublishing 发布所有已到发布时间的课程、章节和课时
+// 发布会更新 updated_at，下一轮РОК同步会把新发布的内容复制到分支节点
+func (s *PublishService) RunScheduledPublishing() (int64, error) {
+	db := database.GetCentralDB()
+	now := time.Now()
+
+	var published int64
+	for _, model := range []interface{}{&models.Courses{}, &models.Chapters{}, &models.Lessons{}} {
+		result := db.Model(model).
+			Where("publish_status = ? AND publish_at IS NOT NULL AND publish_at <= ?", PublishStatusDraft, now).
+			Update("publish_status", PublishStatusPublished)
+		if result.Error != nil {
+			return published, fmt.Errorf("failed to run scheduled publishing: %w", result.Error)
+		}
+		published += result.RowsAffected
+	}
+	return published, nil
+}
+
+// Start 按固定间隔执行定时发布，直到 stop 被关闭
+func (s *PublishService) Start(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			count, err := s.RunScheduledPublishing()
+			if err != nil {
+				logger.WithError(err).Error("Scheduled publishing failed")
+				continue
+			}
+			if count > 0 {
+				logger.Infof("Scheduled publishing: %d items published", count)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
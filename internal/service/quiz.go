@@ -0,0 +1,739 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/internal/qti"
+	"online-learning-platform/internal/quiz"
+)
+
+// AnswerTypeQuiz 测验作业的类型，逐题作答保存在分支节点的 quiz_responses 表
+const AnswerTypeQuiz = "quiz"
+
+// QuizService 测验题库和自动评分服务
+type QuizService struct{}
+
+// NewQuizService 创建实例
+func NewQuizService() *QuizService {
+	return &QuizService{}
+}
+
+// QuestionRequest 创建或更新题目请求
+type QuestionRequest struct {
+	QuestionType string        `json:"question_type" binding:"required"` // single_choice, multiple_choice, true_false, short_answer, numeric
+	Prompt       string        `json:"prompt" binding:"required"`
+	Options      []quiz.Option `json:"options"`    // 单选和多选题的选项
+	AnswerKey    quiz.Key      `json:"answer_key"` // 标准答案
+	Points       float64       `json:"points"`     // 分值，默认 1
+	Position     int           `json:"position"`   // 题目顺序，为 0 时排在最后
+}
+
+// QuestionInfo 题目信息，学生视角不包含标准答案
+type QuestionInfo struct {
+	QuestionID   uint          `json:"question_id"`
+	TaskID       uint          `json:"task_id"`
+	Position     int           `json:"position"`
+	QuestionType string        `json:"question_type"`
+	Prompt       string        `json:"prompt"`
+	Options      []quiz.Option `json:"options,omitempty"`
+	AnswerKey    *quiz.Key     `json:"answer_key,omitempty"`
+	Points       float64       `json:"points"`
+}
+
+// QuestionResponse 学生对一道题目的作答
+type QuestionResponse struct {
+	QuestionID uint `json:"question_id" binding:"required"`
+	quiz.Response
+}
+
+// SubmitQuizRequest 学生提交测验请求，未作答的题目得 0 分
+type SubmitQuizRequest struct {
+	Responses []QuestionResponse `json:"responses"`
+}
+
+// OverrideResponseRequest 教师调整一道题目的得分
+type OverrideResponseRequest struct {
+	BranchID uint     `json:"branch_id" binding:"required"` // 作业所在的分支ID
+	Points   *float64 `json:"points"`                       // 为 null 时恢复为自动评分
+}
+
+// ResponseInfo 一道题目的作答和得分
+type ResponseInfo struct {
+	ResponseID     uint          `json:"response_id"`
+	QuestionID     uint          `json:"question_id"`
+	Position       int           `json:"position"`
+	QuestionType   string        `json:"question_type"`
+	Prompt         string        `json:"prompt"`
+	Options        []quiz.Option `json:"options,omitempty"`
+	AnswerKey      *quiz.Key     `json:"answer_key,omitempty"` // 只返回给教学团队
+	Response       quiz.Response `json:"response"`
+	MaxPoints      float64       `json:"max_points"`
+	Points         float64       `json:"points"`
+	AutoPoints     float64       `json:"auto_points"`
+	OverridePoints *float64      `json:"override_points"`
+	IsCorrect      bool          `json:"is_correct"`
+	PendingReview  bool          `json:"pending_review"` // 简答题未匹配标准答案且教师尚未批改
+}
+
+// QTIImportResult QTI 题库导入结果
+type QTIImportResult struct {
+	Imported  int            `json:"imported"`
+	Questions []QuestionInfo `json:"questions"` // 导入的题目，排在已有题目之后
+	Issues    []qti.Issue    `json:"issues"`    // 未能导入的题目
+}
+
+// QuizResult 测验作业和逐题得分
+type QuizResult struct {
+	Answer    *models.Answers `json:"answer"`
+	Responses []ResponseInfo  `json:"responses"`
+}
+
+// ListQuestions 教学团队查看题目（包含标准答案）
+func (s *QuizService) ListQuestions(taskID, instructorUserID, branchID uint) ([]QuestionInfo, error) {
+	if err := validateTaskStaff(taskID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+	return listQuestionInfos(taskID, true)
+}
+
+// CreateQuestion 教师为测验任务添加题目
+func (s *QuizService) CreateQuestion(taskID, instructorUserID, branchID uint, req *QuestionRequest) (*QuestionInfo, error) {
+	task, err := findQuizTaskForOwner(taskID, instructorUserID, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	question := models.QuizQuestions{TaskID: task.TaskID}
+	if err := applyQuestionRequest(&question, req); err != nil {
+		return nil, err
+	}
+
+	if err := database.GetCentralDB().Transaction(func(tx *gorm.DB) error {
+		if question.Position == 0 {
+			var maxPosition int
+			if err := tx.Model(&models.QuizQuestions{}).
+				Where("task_id = ?", taskID).
+				Select("COALESCE(MAX(position), 0)").
+				Scan(&maxPosition).Error; err != nil {
+				return fmt.Errorf("failed to query question position: %w", err)
+			}
+			question.Position = maxPosition + 1
+		}
+		if err := tx.Create(&question).Error; err != nil {
+			return fmt.Errorf("failed to create question: %w", err)
+		}
+		_, err := recordRevision(tx, task, RevisionActionUpdate, instructorUserID, branchID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return questionInfo(&question, true)
+}
+
+// UpdateQuestion 教师更新题目，已提交的作业保留提交时的得分
+func (s *QuizService) UpdateQuestion(taskID, questionID, instructorUserID, branchID uint, req *QuestionRequest) (*QuestionInfo, error) {
+	task, err := findQuizTaskForOwner(taskID, instructorUserID, branchID)
+	if err != nil {
+		return nil, err
+	}
+	question, err := findQuestion(taskID, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	position := question.Position
+	if err := applyQuestionRequest(question, req); err != nil {
+		return nil, err
+	}
+	if question.Position == 0 {
+		question.Position = position
+	}
+	if err := database.GetCentralDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(question).Error; err != nil {
+			return fmt.Errorf("failed to update question: %w", err)
+		}
+		_, err := recordRevision(tx, task, RevisionActionUpdate, instructorUserID, branchID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return questionInfo(question, true)
+}
+
+// DeleteQuestion 教师删除题目（软删除，已提交的作答仍能显示题目）
+func (s *QuizService) DeleteQuestion(taskID, questionID, instructorUserID, branchID uint) error {
+	task, err := findQuizTaskForOwner(taskID, instructorUserID, branchID)
+	if err != nil {
+		return err
+	}
+	if _, err := findQuestion(taskID, questionID); err != nil {
+		return err
+	}
+	return database.GetCentralDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("question_id = ?", questionID).Delete(&models.QuizQuestions{}).Error; err != nil {
+			return fmt.Errorf("failed to delete question: %w", err)
+		}
+		_, err := recordRevision(tx, task, RevisionActionUpdate, instructorUserID, branchID)
+		return err
+	})
+}
+
+// ExportQuestionsQTI 将测验题目导出为 QTI 2.1 题库
+func (s *QuizService) ExportQuestionsQTI(taskID, instructorUserID, branchID uint) (*qti.Bank, error) {
+	if err := validateTaskStaff(taskID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	var task models.Tasks
+	if err := database.GetCentralDB().Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	var questions []models.QuizQuestions
+	if err := database.GetCentralDB().Where("task_id = ?", taskID).
+		Order("position ASC, question_id ASC").Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list questions: %w", err)
+	}
+
+	bank := &qti.Bank{Identifier: fmt.Sprintf("task_%d", task.TaskID), Title: task.TaskTitle}
+	for i := range questions {
+		question, err := decodeQuestion(&questions[i])
+		if err != nil {
+			return nil, err
+		}
+		bank.Items = append(bank.Items, qti.Item{
+			Identifier: fmt.Sprintf("question_%d", questions[i].QuestionID),
+			Prompt:     questions[i].Prompt,
+			Question:   question,
+		})
+	}
+	return bank, nil
+}
+
+// ImportQuestionsQTI 从 QTI 2.1 内容包或单个题目文件导入题目，追加到测验任务的已有题目之后
+// 不支持的题目（其他交互类型、多个交互、缺少标准答案等）不导入，逐题返回原因
+func (s *QuizService) ImportQuestionsQTI(taskID, instructorUserID, branchID uint, r io.ReaderAt, size int64) (*QTIImportResult, error) {
+	task, err := findQuizTaskForOwner(taskID, instructorUserID, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	bank, issues, err := qti.Read(r, size)
+	if err != nil {
+		if errors.Is(err, qti.ErrInvalidPackage) {
+			return nil, apperrors.WrapError(apperrors.ErrCodeInvalidPackage, "题库文件格式无效", err)
+		}
+		return nil, fmt.Errorf("failed to read qti package: %w", err)
+	}
+
+	result := &QTIImportResult{Questions: []QuestionInfo{}, Issues: issues}
+	if result.Issues == nil {
+		result.Issues = []qti.Issue{}
+	}
+
+	err = database.GetCentralDB().Transaction(func(tx *gorm.DB) error {
+		var position int
+		if err := tx.Model(&models.QuizQuestions{}).
+			Where("task_id = ?", taskID).
+			Select("COALESCE(MAX(position), 0)").
+			Scan(&position).Error; err != nil {
+			return fmt.Errorf("failed to query question position: %w", err)
+		}
+
+		for _, item := range bank.Items {
+			position++
+			question := models.QuizQuestions{TaskID: taskID}
+			if err := applyQuestionRequest(&question, &QuestionRequest{
+				QuestionType: item.Question.Type,
+				Prompt:       item.Prompt,
+				Options:      item.Question.Options,
+				AnswerKey:    item.Question.Key,
+				Points:       item.Question.Points,
+				Position:     position,
+			}); err != nil {
+				return err
+			}
+			if err := tx.Create(&question).Error; err != nil {
+				return fmt.Errorf("failed to create question: %w", err)
+			}
+			info, err := questionInfo(&question, true)
+			if err != nil {
+				return err
+			}
+			result.Questions = append(result.Questions, *info)
+		}
+		if len(result.Questions) == 0 {
+			return nil
+		}
+		_, err := recordRevision(tx, task, RevisionActionUpdate, instructorUserID, branchID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Imported = len(result.Questions)
+	return result, nil
+}
+
+// GetQuiz 学生获取测验题目（不包含标准答案）
+func (s *QuizService) GetQuiz(taskID uint) ([]QuestionInfo, error) {
+	task, err := NewTaskService().GetTask(taskID, true)
+	if err != nil {
+		return nil, err
+	}
+	if task.TaskType != TaskTypeQuiz {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeNotFound, "任务不是测验")
+	}
+	return listQuestionInfos(taskID, false)
+}
+
+// SubmitQuiz 学生提交测验，自动评分后按任务满分换算为作业分数
+// 每次提交保存为一次新的尝试，之前的作答和得分保留
+func (s *QuizService) SubmitQuiz(userID, branchID, taskID uint, req *SubmitQuizRequest) (*QuizResult, error) {
+	task, courseID, err := findSubmittableTask(userID, branchID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.TaskType != TaskTypeQuiz {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "任务不是测验")
+	}
+
+	var questions []models.QuizQuestions
+	if err := database.GetCentralDB().Where("task_id = ?", taskID).
+		Order("position ASC, question_id ASC").Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load questions: %w", err)
+	}
+	if len(questions) == 0 {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "测验还没有题目")
+	}
+
+	submitted := make(map[uint]quiz.Response, len(req.Responses))
+	for _, r := range req.Responses {
+		submitted[r.QuestionID] = r.Response
+	}
+	known := make(map[uint]bool, len(questions))
+	for _, q := range questions {
+		known[q.QuestionID] = true
+	}
+	for questionID := range submitted {
+		if !known[questionID] {
+			return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, fmt.Sprintf("题目 %d 不属于该测验", questionID))
+		}
+	}
+
+	responses := make([]models.QuizResponses, 0, len(questions))
+	for _, q := range questions {
+		question, err := decodeQuestion(&q)
+		if err != nil {
+			return nil, err
+		}
+		response := submitted[q.QuestionID]
+		data, err := json.Marshal(response)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode response: %w", err)
+		}
+		result := quiz.Grade(question, response)
+		responses = append(responses, models.QuizResponses{
+			TaskID:      taskID,
+			QuestionID:  q.QuestionID,
+			BranchID:    branchID,
+			UserID:      userID,
+			Response:    string(data),
+			MaxPoints:   q.Points,
+			AutoPoints:  result.Points,
+			IsCorrect:   result.Correct,
+			NeedsReview: result.NeedsReview,
+		})
+	}
+
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	lateStatus, err := checkSubmissionDeadline(task, branchID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	answer := models.Answers{
+		TaskID:       taskID,
+		TaskRevision: task.Revision,
+		BranchID:     branchID,
+		UserID:       userID,
+		Type:         AnswerTypeQuiz,
+		SubmittedAt:  now,
+	}
+	setLateStatus(&answer, lateStatus)
+	raw, graded := scoreQuizResponses(responses, task.MaxScore)
+	applyLatePenalty(task, &answer, raw)
+	answer.IsGraded = graded
+
+	created := false
+	if err := branchDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if created, err = createAttempt(tx, task, courseID, &answer); err != nil {
+			return err
+		}
+		for i := range responses {
+			responses[i].AnswerID = answer.AnswerID
+		}
+		if err := tx.Create(&responses).Error; err != nil {
+			return fmt.Errorf("failed to save responses: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// 首次提交计入课程进度
+	if created {
+		refreshStudentProgress(branchDB, userID, courseID)
+	}
+
+	return buildQuizResult(&answer, responses, false)
+}
+
+// GetMyQuizResult 学生查看自己计入成绩的测验得分（不包含标准答案）
+func (s *QuizService) GetMyQuizResult(userID, branchID, taskID uint) (*QuizResult, error) {
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var answer models.Answers
+	if err := branchDB.Where("task_id = ? AND user_id = ? AND type = ? AND is_counted = ?", taskID, userID, AnswerTypeQuiz, true).First(&answer).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrAnswerNotFound
+		}
+		return nil, fmt.Errorf("failed to query answer: %w", err)
+	}
+	responses, err := loadQuizResponses(branchDB, answer.AnswerID)
+	if err != nil {
+		return nil, err
+	}
+	return buildQuizResult(&answer, responses, false)
+}
+
+// ListResponses 教学团队查看一份测验作业的逐题作答（包含标准答案）
+func (s *QuizService) ListResponses(instructorUserID, instructorBranchID, answerID, answerBranchID uint) (*QuizResult, error) {
+	branchDB, answer, err := findAnswerForStaff(instructorUserID, instructorBranchID, answerID, answerBranchID)
+	if err != nil {
+		return nil, err
+	}
+	if answer.Type != AnswerTypeQuiz {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "作业不是测验")
+	}
+	responses, err := loadQuizResponses(branchDB, answer.AnswerID)
+	if err != nil {
+		return nil, err
+	}
+	return buildQuizResult(answer, responses, true)
+}
+
+// OverrideResponse 教学团队调整一道题目的得分（主要用于批改简答题），并重新计算作业分数
+func (s *QuizService) OverrideResponse(instructorUserID, instructorBranchID, answerID, responseID uint, req *OverrideResponseRequest) (*QuizResult, error) {
+	branchDB, answer, err := findAnswerForStaff(instructorUserID, instructorBranchID, answerID, req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+	if answer.Type != AnswerTypeQuiz {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "作业不是测验")
+	}
+
+	var task models.Tasks
+	if err := database.GetCentralDB().Unscoped().Where("task_id = ?", answer.TaskID).First(&task).Error; err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	var responses []models.QuizResponses
+	if err := branchDB.Transaction(func(tx *gorm.DB) error {
+		var response models.QuizResponses
+		if err := tx.Where("response_id = ? AND answer_id = ?", responseID, answer.AnswerID).First(&response).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return apperrors.NewAppError(apperrors.ErrCodeNotFound, "作答不存在")
+			}
+			return fmt.Errorf("failed to get response: %w", err)
+		}
+
+		if req.Points != nil {
+			points := quiz.RoundPoints(*req.Points)
+			if points < 0 || points > response.MaxPoints {
+				return apperrors.NewAppError(apperrors.ErrCodeInvalidScore,
+					fmt.Sprintf("得分应在 0 到 %g 之间", response.MaxPoints))
+			}
+			response.OverridePoints = &points
+			response.GradedBy = &instructorUserID
+		} else {
+			response.OverridePoints = nil
+			response.GradedBy = nil
+		}
+		if err := tx.Save(&response).Error; err != nil {
+			return fmt.Errorf("failed to update response: %w", err)
+		}
+
+		var err error
+		if responses, err = loadQuizResponses(tx, answer.AnswerID); err != nil {
+			return err
+		}
+		raw, graded := scoreQuizResponses(responses, task.MaxScore)
+		applyLatePenalty(&task, answer, raw)
+		answer.IsGraded = graded
+		answer.GradedBy = gradedByOnBranch(tx, instructorUserID, req.BranchID)
+		if err := tx.Save(answer).Error; err != nil {
+			return fmt.Errorf("failed to update answer: %w", err)
+		}
+		return recountAttempts(tx, &task, answer)
+	}); err != nil {
+		return nil, err
+	}
+
+	return buildQuizResult(answer, responses, true)
+}
+
+// hasQuizQuestions 任务是否已有题目
+func hasQuizQuestions(taskID uint) (bool, error) {
+	var count int64
+	if err := database.GetCentralDB().Model(&models.QuizQuestions{}).Where("task_id = ?", taskID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to count questions: %w", err)
+	}
+	return count > 0, nil
+}
+
+// copyQuizQuestions 复制任务的题目（复制课程时使用）
+func copyQuizQuestions(tx *gorm.DB, fromTaskID, toTaskID uint) error {
+	var questions []models.QuizQuestions
+	if err := tx.Where("task_id = ?", fromTaskID).Order("position ASC, question_id ASC").Find(&questions).Error; err != nil {
+		return fmt.Errorf("failed to load questions: %w", err)
+	}
+	for _, q := range questions {
+		newQuestion := models.QuizQuestions{
+			TaskID:       toTaskID,
+			Position:     q.Position,
+			QuestionType: q.QuestionType,
+			Prompt:       q.Prompt,
+			Options:      q.Options,
+			AnswerKey:    q.AnswerKey,
+			Points:       q.Points,
+		}
+		if err := tx.Create(&newQuestion).Error; err != nil {
+			return fmt.Errorf("failed to copy question: %w", err)
+		}
+	}
+	return nil
+}
+
+// scoreQuizResponses 按逐题得分计算作业分数；还有待批改的简答题时作业未批改完成
+func scoreQuizResponses(responses []models.QuizResponses, maxScore int) (int, bool) {
+	earned, total := 0.0, 0.0
+	graded := true
+	for i := range responses {
+		earned += responses[i].Points()
+		total += responses[i].MaxPoints
+		if responses[i].NeedsReview && responses[i].OverridePoints == nil {
+			graded = false
+		}
+	}
+	return quiz.Scale(earned, total, maxScore), graded
+}
+
+// findQuizTaskForOwner 查询可以编辑题目的测验任务
+func findQuizTaskForOwner(taskID, instructorUserID, branchID uint) (*models.Tasks, error) {
+	if err := validateTaskOwner(taskID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+	var task models.Tasks
+	if err := database.GetCentralDB().Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if task.TaskType != TaskTypeQuiz {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "只有测验任务可以添加题目")
+	}
+	return &task, nil
+}
+
+// findQuestion 查询任务下的题目
+func findQuestion(taskID, questionID uint) (*models.QuizQuestions, error) {
+	var question models.QuizQuestions
+	if err := database.GetCentralDB().Where("question_id = ? AND task_id = ?", questionID, taskID).First(&question).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NewAppError(apperrors.ErrCodeNotFound, "题目不存在")
+		}
+		return nil, fmt.Errorf("failed to get question: %w", err)
+	}
+	return &question, nil
+}
+
+// applyQuestionRequest 校验请求并写入题目
+func applyQuestionRequest(question *models.QuizQuestions, req *QuestionRequest) error {
+	points := req.Points
+	if points == 0 {
+		points = 1
+	}
+	points = quiz.RoundPoints(points)
+	if err := quiz.Validate(quiz.Question{
+		Type:    req.QuestionType,
+		Options: req.Options,
+		Key:     req.AnswerKey,
+		Points:  points,
+	}); err != nil {
+		return apperrors.WrapError(apperrors.ErrCodeInvalidParam, "题目无效: "+err.Error(), err)
+	}
+	if req.Position < 0 {
+		return apperrors.ErrInvalidParam
+	}
+
+	// 判断题和填空类题目没有选项
+	options := req.Options
+	if req.QuestionType != quiz.TypeSingleChoice && req.QuestionType != quiz.TypeMultipleChoice {
+		options = nil
+	}
+	if options == nil {
+		options = []quiz.Option{}
+	}
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("failed to encode options: %w", err)
+	}
+	keyJSON, err := json.Marshal(req.AnswerKey)
+	if err != nil {
+		return fmt.Errorf("failed to encode answer key: %w", err)
+	}
+
+	question.QuestionType = req.QuestionType
+	question.Prompt = req.Prompt
+	question.Options = string(optionsJSON)
+	question.AnswerKey = string(keyJSON)
+	question.Points = points
+	question.Position = req.Position
+	return nil
+}
+
+// decodeQuestion 将题目记录转换为评分使用的结构
+func decodeQuestion(q *models.QuizQuestions) (quiz.Question, error) {
+	question := quiz.Question{Type: q.QuestionType, Points: q.Points}
+	if q.Options != "" {
+		if err := json.Unmarshal([]byte(q.Options), &question.Options); err != nil {
+			return question, fmt.Errorf("invalid options of question %d: %w", q.QuestionID, err)
+		}
+	}
+	if q.AnswerKey != "" {
+		if err := json.Unmarshal([]byte(q.AnswerKey), &question.Key); err != nil {
+			return question, fmt.Errorf("invalid answer key of question %d: %w", q.QuestionID, err)
+		}
+	}
+	return question, nil
+}
+
+// questionInfo 转换为接口返回的题目信息，withKey 为 false 时不包含标准答案
+func questionInfo(q *models.QuizQuestions, withKey bool) (*QuestionInfo, error) {
+	question, err := decodeQuestion(q)
+	if err != nil {
+		return nil, err
+	}
+	info := &QuestionInfo{
+		QuestionID:   q.QuestionID,
+		TaskID:       q.TaskID,
+		Position:     q.Position,
+		QuestionType: q.QuestionType,
+		Prompt:       q.Prompt,
+		Options:      question.Options,
+		Points:       q.Points,
+	}
+	if withKey {
+		info.AnswerKey = &question.Key
+	}
+	return info, nil
+}
+
+// listQuestionInfos 按顺序返回任务的所有题目
+func listQuestionInfos(taskID uint, withKey bool) ([]QuestionInfo, error) {
+	var questions []models.QuizQuestions
+	if err := database.GetCentralDB().Where("task_id = ?", taskID).
+		Order("position ASC, question_id ASC").Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list questions: %w", err)
+	}
+	infos := make([]QuestionInfo, 0, len(questions))
+	for i := range questions {
+		info, err := questionInfo(&questions[i], withKey)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, *info)
+	}
+	return infos, nil
+}
+
+// loadQuizResponses 查询作业的逐题作答
+func loadQuizResponses(db *gorm.DB, answerID uint) ([]models.QuizResponses, error) {
+	var responses []models.QuizResponses
+	if err := db.Where("answer_id = ?", answerID).Order("response_id ASC").Find(&responses).Error; err != nil {
+		return nil, fmt.Errorf("failed to load responses: %w", err)
+	}
+	return responses, nil
+}
+
+// buildQuizResult 组合作答和题目信息；题目已删除时仍显示提交时的作答和得分
+func buildQuizResult(answer *models.Answers, responses []models.QuizResponses, withKey bool) (*QuizResult, error) {
+	questionIDs := make([]uint, 0, len(responses))
+	for _, r := range responses {
+		questionIDs = append(questionIDs, r.QuestionID)
+	}
+	var questions []models.QuizQuestions
+	if len(questionIDs) > 0 {
+		if err := database.GetCentralDB().Unscoped().Where("question_id IN ?", questionIDs).Find(&questions).Error; err != nil {
+			return nil, fmt.Errorf("failed to load questions: %w", err)
+		}
+	}
+	byID := make(map[uint]*models.QuizQuestions, len(questions))
+	for i := range questions {
+		byID[questions[i].QuestionID] = &questions[i]
+	}
+
+	result := &QuizResult{Answer: answer, Responses: make([]ResponseInfo, 0, len(responses))}
+	for _, r := range responses {
+		info := ResponseInfo{
+			ResponseID:     r.ResponseID,
+			QuestionID:     r.QuestionID,
+			MaxPoints:      r.MaxPoints,
+			Points:         r.Points(),
+			AutoPoints:     r.AutoPoints,
+			OverridePoints: r.OverridePoints,
+			IsCorrect:      r.IsCorrect,
+			PendingReview:  r.NeedsReview && r.OverridePoints == nil,
+		}
+		if err := json.Unmarshal([]byte(r.Response), &info.Response); err != nil {
+			return nil, fmt.Errorf("invalid response %d: %w", r.ResponseID, err)
+		}
+		if q, ok := byID[r.QuestionID]; ok {
+			question, err := questionInfo(q, withKey)
+			if err != nil {
+				return nil, err
+			}
+			info.Position = question.Position
+			info.QuestionType = question.QuestionType
+			info.Prompt = question.Prompt
+			info.Options = question.Options
+			info.AnswerKey = question.AnswerKey
+		}
+		result.Responses = append(result.Responses, info)
+	}
+	return result, nil
+}
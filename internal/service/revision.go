@@ -0,0 +1,554 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/pkg/utils"
+)
+
+// 修订实体类型
+const (
+	RevisionEntityCourse  = "course"
+	RevisionEntityChapter = "chapter"
+	RevisionEntityLesson  = "lesson"
+	RevisionEntityTask    = "task"
+)
+
+// 修订动作
+const (
+	RevisionActionCreate  = "create"
+	RevisionActionUpdate  = "update"
+	RevisionActionDelete  = "delete"
+	RevisionActionRestore = "restore"
+)
+
+// revisionDiffIgnored 对比修订时忽略的字段（每次保存都会变化，没有比较意义）
+// 视频处理结果和课程报名人数由后台写入，也不参与比较
+var revisionDiffIgnored = []string{"revision", "created_at", "updated_at", "enrollment_count",
+	"processing_status", "processing_error", "processing_started_at", "duration", "thumbnail_url", "hls_url"}
+
+// RevisionService 内容修订历史服务
+type RevisionService struct{}
+
+// NewRevisionService 创建修订历史服务
+func NewRevisionService() *RevisionService {
+	return &RevisionService{}
+}
+
+// RevisionInfo 修订信息
+type RevisionInfo struct {
+	RevisionID     uint            `json:"revision_id"`
+	EntityType     string          `json:"entity_type"`
+	EntityID       uint            `json:"entity_id"`
+	Version        int             `json:"version"`
+	CourseID       uint            `json:"course_id"`
+	Action         string          `json:"action"`
+	AuthorUserID   uint            `json:"author_user_id"`
+	AuthorBranchID uint            `json:"author_branch_id"`
+	Snapshot       json.RawMessage `json:"snapshot,omitempty"`
+	CreatedAt      string          `json:"created_at"`
+}
+
+// RevisionDiff 两个修订版本之间的差异
+type RevisionDiff struct {
+	EntityType  string              `json:"entity_type"`
+	EntityID    uint                `json:"entity_id"`
+	FromVersion int                 `json:"from_version"`
+	ToVersion   int                 `json:"to_version"`
+	Changes     []utils.FieldChange `json:"changes"`
+}
+
+// ListRevisions 获取某个课程、章节、课时或任务的修订列表（不含快照内容）
+func (s *RevisionService) ListRevisions(entityType string, entityID, instructorUserID, branchID uint) ([]RevisionInfo, error) {
+	if err := authorizeRevisionEntity(entityType, entityID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+	var revisions []models.Revisions
+	if err := db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("version DESC").
+		Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+
+	return toRevisionInfos(revisions), nil
+}
+
+// ListCourseRevisions 获取课程及其下所有内容的修订列表，可用于找回已删除的章节、课时和任务
+func (s *RevisionService) ListCourseRevisions(courseID, instructorUserID, branchID uint) ([]RevisionInfo, error) {
+	if err := authorizeRevisionEntity(RevisionEntityCourse, courseID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+	var revisions []models.Revisions
+	if err := db.Where("course_id = ?", courseID).
+		Order("created_at DESC, revision_id DESC").
+		Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list course revisions: %w", err)
+	}
+
+	return toRevisionInfos(revisions), nil
+}
+
+// GetRevision 获取指定版本的修订（含快照内容）
+func (s *RevisionService) GetRevision(entityType string, entityID uint, version int, instructorUserID, branchID uint) (*RevisionInfo, error) {
+	if err := authorizeRevisionEntity(entityType, entityID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	revision, err := findRevision(database.GetCentralDB(), entityType, entityID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	info := toRevisionInfo(revision)
+	info.Snapshot = json.RawMessage(revision.Snapshot)
+	return &info, nil
+}
+
+// DiffRevisions 对比同一内容的两个修订版本
+func (s *RevisionService) DiffRevisions(entityType string, entityID uint, fromVersion, toVersion int, instructorUserID, branchID uint) (*RevisionDiff, error) {
+	if err := authorizeRevisionEntity(entityType, entityID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+	from, err := findRevision(db, entityType, entityID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := findRevision(db, entityType, entityID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := utils.DiffJSONFields([]byte(from.Snapshot), []byte(to.Snapshot), revisionDiffIgnored...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff revisions: %w", err)
+	}
+
+	return &RevisionDiff{
+		EntityType:  entityType,
+		EntityID:    entityID,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Changes:     changes,
+	}, nil
+}
+
+// RestoreRevision 将内容恢复到指定版本，已删除的内容会被重新启用，并生成一条新的 restore 修订
+// 已删除的章节、课时恢复后排在末尾；发布状态保持当前值，不随快照回滚
+func (s *RevisionService) RestoreRevision(entityType string, entityID uint, version int, instructorUserID, branchID uint) (*RevisionInfo, error) {
+	if err := authorizeRevisionEntity(entityType, entityID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+	revision, err := findRevision(db, entityType, entityID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var restored *models.Revisions
+	var entity interface{}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if entity, err = applySnapshot(tx, revision); err != nil {
+			return err
+		}
+		restored, err = recordRevision(tx, entity, RevisionActionRestore, instructorUserID, branchID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	// 恢复任务可能改变计分策略
+	if task, ok := entity.(*models.Tasks); ok {
+		recountTaskAttempts(task)
+	}
+
+	info := toRevisionInfo(restored)
+	info.Snapshot = json.RawMessage(restored.Snapshot)
+	return &info, nil
+}
+
+// recordRevision 在事务内为内容保存一份快照，并把内容的 revision 字段更新为新版本号
+func recordRevision(tx *gorm.DB, entity interface{}, action string, authorUserID, authorBranchID uint) (*models.Revisions, error) {
+	entityType, entityID, err := revisionEntityKey(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	courseID, err := resolveEntityCourseID(tx, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	var version int
+	if err := tx.Model(&models.Revisions{}).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error; err != nil {
+		return nil, fmt.Errorf("failed to get latest revision: %w", err)
+	}
+	version++
+
+	if err := tx.Unscoped().Model(entity).UpdateColumn("revision", version).Error; err != nil {
+		return nil, fmt.Errorf("failed to update revision number: %w", err)
+	}
+	setEntityRevision(entity, version)
+
+	snapshot, err := json.Marshal(entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode revision snapshot: %w", err)
+	}
+
+	revision := models.Revisions{
+		EntityType:     entityType,
+		EntityID:       entityID,
+		Version:        version,
+		CourseID:       courseID,
+		Action:         action,
+		Snapshot:       string(snapshot),
+		AuthorUserID:   authorUserID,
+		AuthorBranchID: authorBranchID,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return nil, fmt.Errorf("failed to create revision: %w", err)
+	}
+
+	if err := syncSearchDocument(tx, entityType, entityID, courseID, entity, action); err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// recordCascadeDeleteRevisions 级联软删除前，为将被删除的任务、课时和章节保存删除快照
+// column 为级联范围所在的列（course_id、chapter_id 或 lesson_id）
+func recordCascadeDeleteRevisions(tx *gorm.DB, column string, id, authorUserID, authorBranchID uint) error {
+	var tasks []models.Tasks
+	lessonIDs := tx.Model(&models.Lessons{}).Select("lesson_id").Where(column+" = ?", id)
+	if err := tx.Where("lesson_id IN (?)", lessonIDs).Find(&tasks).Error; err != nil {
+		return fmt.Errorf("failed to load tasks: %w", err)
+	}
+	for i := range tasks {
+		if _, err := recordRevision(tx, &tasks[i], RevisionActionDelete, authorUserID, authorBranchID); err != nil {
+			return err
+		}
+	}
+
+	var lessons []models.Lessons
+	if err := tx.Where(column+" = ?", id).Find(&lessons).Error; err != nil {
+		return fmt.Errorf("failed to load lessons: %w", err)
+	}
+	for i := range lessons {
+		if _, err := recordRevision(tx, &lessons[i], RevisionActionDelete, authorUserID, authorBranchID); err != nil {
+			return err
+		}
+	}
+
+	if column == "lesson_id" {
+		return nil
+	}
+
+	var chapters []models.Chapters
+	if err := tx.Where(column+" = ?", id).Find(&chapters).Error; err != nil {
+		return fmt.Errorf("failed to load chapters: %w", err)
+	}
+	for i := range chapters {
+		if _, err := recordRevision(tx, &chapters[i], RevisionActionDelete, authorUserID, authorBranchID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applySnapshot 把快照中的可编辑字段写回内容（包括已软删除的内容）
+func applySnapshot(tx *gorm.DB, revision *models.Revisions) (interface{}, error) {
+	switch revision.EntityType {
+	case RevisionEntityCourse:
+		var snapshot, course models.Courses
+		if err := json.Unmarshal([]byte(revision.Snapshot), &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+		}
+		if err := tx.Unscoped().Where("course_id = ?", revision.EntityID).First(&course).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, apperrors.ErrCourseNotFound
+			}
+			return nil, fmt.Errorf("failed to get course: %w", err)
+		}
+		course.CourseTitle = snapshot.CourseTitle
+		course.Description = snapshot.Description
+		course.StartDate = snapshot.StartDate
+		course.EndDate = snapshot.EndDate
+		course.EnrollmentStartAt = snapshot.EnrollmentStartAt
+		course.EnrollmentEndAt = snapshot.EnrollmentEndAt
+		course.Capacity = snapshot.Capacity
+		course.CategoryID = snapshot.CategoryID
+		course.Status = snapshot.Status
+		course.DeletedAt = gorm.DeletedAt{}
+		if err := tx.Unscoped().Save(&course).Error; err != nil {
+			return nil, fmt.Errorf("failed to restore course: %w", err)
+		}
+		return &course, nil
+
+	case RevisionEntityChapter:
+		var snapshot, chapter models.Chapters
+		if err := json.Unmarshal([]byte(revision.Snapshot), &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+		}
+		if err := tx.Unscoped().Where("chapter_id = ?", revision.EntityID).First(&chapter).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, apperrors.ErrChapterNotFound
+			}
+			return nil, fmt.Errorf("failed to get chapter: %w", err)
+		}
+		if chapter.DeletedAt.Valid {
+			if _, err := getCourseByID(chapter.CourseID); err != nil {
+				return nil, err
+			}
+			var maxOrder int
+			tx.Model(&models.Chapters{}).
+				Where("course_id = ?", chapter.CourseID).
+				Select("COALESCE(MAX(chapter_order), 0)").
+				Scan(&maxOrder)
+			chapter.ChapterOrder = maxOrder + 1
+			slug, err := releaseTakenSlug(tx, &models.Chapters{}, "course_id", chapter.CourseID, chapter.Slug, "chapter_id", chapter.ChapterID)
+			if err != nil {
+				return nil, err
+			}
+			chapter.Slug = slug
+		}
+		chapter.ChapterTitle = snapshot.ChapterTitle
+		chapter.Description = snapshot.Description
+		chapter.DeletedAt = gorm.DeletedAt{}
+		if err := tx.Unscoped().Save(&chapter).Error; err != nil {
+			return nil, fmt.Errorf("failed to restore chapter: %w", err)
+		}
+		return &chapter, nil
+
+	case RevisionEntityLesson:
+		var snapshot, lesson models.Lessons
+		if err := json.Unmarshal([]byte(revision.Snapshot), &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+		}
+		if err := tx.Unscoped().Where("lesson_id = ?", revision.EntityID).First(&lesson).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, apperrors.ErrLessonNotFound
+			}
+			return nil, fmt.Errorf("failed to get lesson: %w", err)
+		}
+		if lesson.DeletedAt.Valid {
+			// 所在章节仍被删除时需要先恢复章节
+			if _, err := findChapter(tx, lesson.CourseID, lesson.ChapterID); err != nil {
+				return nil, err
+			}
+			var maxOrder int
+			tx.Model(&models.Lessons{}).
+				Where("chapter_id = ?", lesson.ChapterID).
+				Select("COALESCE(MAX(lesson_order), 0)").
+				Scan(&maxOrder)
+			lesson.LessonOrder = maxOrder + 1
+			slug, err := releaseTakenSlug(tx, &models.Lessons{}, "course_id", lesson.CourseID, lesson.Slug, "lesson_id", lesson.LessonID)
+			if err != nil {
+				return nil, err
+			}
+			lesson.Slug = slug
+		}
+		lesson.LessonTitle = snapshot.LessonTitle
+		if lesson.ContentURL != snapshot.ContentURL || lesson.LessonType != snapshot.LessonType {
+			lesson.ContentURL = snapshot.ContentURL
+			lesson.LessonType = snapshot.LessonType
+			queueVideoProcessing(&lesson)
+		}
+		lesson.DeletedAt = gorm.DeletedAt{}
+		if err := tx.Unscoped().Save(&lesson).Error; err != nil {
+			return nil, fmt.Errorf("failed to restore lesson: %w", err)
+		}
+		return &lesson, nil
+
+	case RevisionEntityTask:
+		var snapshot, task models.Tasks
+		if err := json.Unmarshal([]byte(revision.Snapshot), &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+		}
+		if err := tx.Unscoped().Where("task_id = ?", revision.EntityID).First(&task).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, apperrors.ErrTaskNotFound
+			}
+			return nil, fmt.Errorf("failed to get task: %w", err)
+		}
+		if task.DeletedAt.Valid {
+			// 所在课时仍被删除时需要先恢复课时
+			if err := tx.Where("lesson_id = ?", task.LessonID).First(&models.Lessons{}).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return nil, apperrors.ErrLessonNotFound
+				}
+				return nil, fmt.Errorf("failed to verify lesson: %w", err)
+			}
+			slug, err := releaseTakenSlug(tx, &models.Tasks{}, "lesson_id", task.LessonID, task.Slug, "task_id", task.TaskID)
+			if err != nil {
+				return nil, err
+			}
+			task.Slug = slug
+		}
+		task.TaskTitle = snapshot.TaskTitle
+		task.Description = snapshot.Description
+		task.TaskType = snapshot.TaskType
+		task.MaxScore = snapshot.MaxScore
+		task.AllowedTypes = snapshot.AllowedTypes
+		task.MaxFileSize = snapshot.MaxFileSize
+		task.DueAt = snapshot.DueAt
+		task.CutoffAt = snapshot.CutoffAt
+		task.LatePolicy = snapshot.LatePolicy
+		task.LatePenaltyPercent = snapshot.LatePenaltyPercent
+		task.MaxAttempts = snapshot.MaxAttempts
+		task.AttemptPolicy = snapshot.AttemptPolicy
+		// 早于评分标准功能的快照没有 rubric 字段，保留当前的评分标准
+		if snapshot.Rubric != "" {
+			task.Rubric = snapshot.Rubric
+		}
+		if err := validateTaskRubric(&task); err != nil {
+			return nil, err
+		}
+		task.DeletedAt = gorm.DeletedAt{}
+		if err := tx.Unscoped().Save(&task).Error; err != nil {
+			return nil, fmt.Errorf("failed to restore task: %w", err)
+		}
+		return &task, nil
+	}
+
+	return nil, apperrors.ErrInvalidParam
+}
+
+// releaseTakenSlug 恢复已删除的内容时，如果它的 slug 已被同一范围内的其他内容使用则清空，
+// 避免 Markdown 同步时同一个 slug 匹配到多条记录
+func releaseTakenSlug(tx *gorm.DB, model interface{}, scopeColumn string, scopeID uint, slug, idColumn string, id uint) (string, error) {
+	if slug == "" {
+		return "", nil
+	}
+	var count int64
+	if err := tx.Model(model).
+		Where(scopeColumn+" = ? AND slug = ? AND "+idColumn+" <> ?", scopeID, slug, id).
+		Count(&count).Error; err != nil {
+		return "", fmt.Errorf("failed to check slug: %w", err)
+	}
+	if count > 0 {
+		return "", nil
+	}
+	return slug, nil
+}
+
+// authorizeRevisionEntity 校验当前教师是否为内容所属课程的教师（内容可能已被删除）
+func authorizeRevisionEntity(entityType string, entityID, instructorUserID, branchID uint) error {
+	db := database.GetCentralDB()
+	courseID, err := resolveEntityCourseID(db, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	return checkCourseOwner(db.Unscoped(), courseID, instructorUserID, branchID)
+}
+
+// resolveEntityCourseID 查询内容所属的课程ID（包括已软删除的内容）
+func resolveEntityCourseID(db *gorm.DB, entityType string, entityID uint) (uint, error) {
+	var courseIDs []uint
+	var notFound *apperrors.AppError
+
+	switch entityType {
+	case RevisionEntityCourse:
+		db.Unscoped().Model(&models.Courses{}).Where("course_id = ?", entityID).Pluck("course_id", &courseIDs)
+		notFound = apperrors.ErrCourseNotFound
+	case RevisionEntityChapter:
+		db.Unscoped().Model(&models.Chapters{}).Where("chapter_id = ?", entityID).Pluck("course_id", &courseIDs)
+		notFound = apperrors.ErrChapterNotFound
+	case RevisionEntityLesson:
+		db.Unscoped().Model(&models.Lessons{}).Where("lesson_id = ?", entityID).Pluck("course_id", &courseIDs)
+		notFound = apperrors.ErrLessonNotFound
+	case RevisionEntityTask:
+		db.Unscoped().Table("tasks").
+			Joins("JOIN lessons ON lessons.lesson_id = tasks.lesson_id").
+			Where("tasks.task_id = ?", entityID).
+			Pluck("lessons.course_id", &courseIDs)
+		notFound = apperrors.ErrTaskNotFound
+	default:
+		return 0, apperrors.ErrInvalidParam
+	}
+
+	if len(courseIDs) == 0 {
+		return 0, notFound
+	}
+	return courseIDs[0], nil
+}
+
+// revisionEntityKey 返回内容的类型和主键
+func revisionEntityKey(entity interface{}) (string, uint, error) {
+	switch e := entity.(type) {
+	case *models.Courses:
+		return RevisionEntityCourse, e.CourseID, nil
+	case *models.Chapters:
+		return RevisionEntityChapter, e.ChapterID, nil
+	case *models.Lessons:
+		return RevisionEntityLesson, e.LessonID, nil
+	case *models.Tasks:
+		return RevisionEntityTask, e.TaskID, nil
+	}
+	return "", 0, fmt.Errorf("unsupported revision entity %T", entity)
+}
+
+// setEntityRevision 同步内存中内容的版本号
+func setEntityRevision(entity interface{}, version int) {
+	switch e := entity.(type) {
+	case *models.Courses:
+		e.Revision = version
+	case *models.Chapters:
+		e.Revision = version
+	case *models.Lessons:
+		e.Revision = version
+	case *models.Tasks:
+		e.Revision = version
+	}
+}
+
+// findRevision 查询指定版本的修订
+func findRevision(db *gorm.DB, entityType string, entityID uint, version int) (*models.Revisions, error) {
+	var revision models.Revisions
+	if err := db.Where("entity_type = ? AND entity_id = ? AND version = ?", entityType, entityID, version).
+		First(&revision).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	return &revision, nil
+}
+
+func toRevisionInfo(revision *models.Revisions) RevisionInfo {
+	return RevisionInfo{
+		RevisionID:     revision.RevisionID,
+		EntityType:     revision.EntityType,
+		EntityID:       revision.EntityID,
+		Version:        revision.Version,
+		CourseID:       revision.CourseID,
+		Action:         revision.Action,
+		AuthorUserID:   revision.AuthorUserID,
+		AuthorBranchID: revision.AuthorBranchID,
+		CreatedAt:      revision.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func toRevisionInfos(revisions []models.Revisions) []RevisionInfo {
+	infos := make([]RevisionInfo, 0, len(revisions))
+	for i := range revisions {
+		infos = append(infos, toRevisionInfo(&revisions[i]))
+	}
+	return infos
+}
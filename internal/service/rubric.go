@@ -0,0 +1,252 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/internal/rubric"
+)
+
+// SetRubricRequest 设置任务评分标准请求，criteria 为空时清除评分标准
+type SetRubricRequest struct {
+	Criteria []rubric.Criterion `json:"criteria"`
+}
+
+// RubricInfo 任务的评分标准
+type RubricInfo struct {
+	TaskID   uint               `json:"task_id"`
+	MaxScore int                `json:"max_score"`
+	MaxTotal int                `json:"max_total"` // 各评分项满分之和，等于任务满分
+	Criteria []rubric.Criterion `json:"criteria"`
+}
+
+// AnswerDetail 作业详情，评分项得分和行内批注解析为结构化数据
+type AnswerDetail struct {
+	models.Answers
+	RubricScores   []rubric.Score         `json:"rubric_scores"`
+	InlineComments []rubric.InlineComment `json:"inline_comments"`
+}
+
+// GetRubric 教学团队查看任务的评分标准
+func (s *TaskService) GetRubric(taskID, instructorUserID, branchID uint) (*RubricInfo, error) {
+	if err := validateTaskStaff(taskID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+	var task models.Tasks
+	if err := database.GetCentralDB().Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	return rubricInfo(&task)
+}
+
+// GetTaskRubric 学生查看可见任务的评分标准
+func (s *TaskService) GetTaskRubric(taskID uint) (*RubricInfo, error) {
+	if _, err := s.GetTask(taskID, true); err != nil {
+		return nil, err
+	}
+	var task models.Tasks
+	if err := database.GetCentralDB().Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	return rubricInfo(&task)
+}
+
+// SetRubric 课程教师设置任务的评分标准，已有的评分保留评分时的评分项
+func (s *TaskService) SetRubric(taskID, instructorUserID, branchID uint, req *SetRubricRequest) (*RubricInfo, error) {
+	if err := validateTaskOwner(taskID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+	var task models.Tasks
+	if err := db.Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	criteria, err := rubric.Normalize(req.Criteria)
+	if err != nil {
+		return nil, apperrors.WrapError(apperrors.ErrCodeInvalidParam, "评分标准无效: "+err.Error(), err)
+	}
+	data, err := json.Marshal(criteria)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rubric: %w", err)
+	}
+	task.Rubric = string(data)
+	if err := validateTaskRubric(&task); err != nil {
+		return nil, err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&task).Error; err != nil {
+			return fmt.Errorf("failed to update rubric: %w", err)
+		}
+		_, err := recordRevision(tx, &task, RevisionActionUpdate, instructorUserID, branchID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return rubricInfo(&task)
+}
+
+// rubricInfo 转换为接口返回的评分标准
+func rubricInfo(task *models.Tasks) (*RubricInfo, error) {
+	criteria, err := taskRubric(task)
+	if err != nil {
+		return nil, err
+	}
+	return &RubricInfo{
+		TaskID:   task.TaskID,
+		MaxScore: task.MaxScore,
+		MaxTotal: rubric.MaxTotal(criteria),
+		Criteria: criteria,
+	}, nil
+}
+
+// taskRubric 解析任务的评分标准，没有评分标准时返回空切片
+func taskRubric(task *models.Tasks) ([]rubric.Criterion, error) {
+	criteria := []rubric.Criterion{}
+	if task.Rubric != "" {
+		if err := json.Unmarshal([]byte(task.Rubric), &criteria); err != nil {
+			return nil, fmt.Errorf("invalid rubric of task %d: %w", task.TaskID, err)
+		}
+	}
+	return criteria, nil
+}
+
+// validateTaskRubric 校验评分标准：各评分项满分之和等于任务满分，测验任务不能设置评分标准
+// 修改任务类型或满分时同样校验，需要先调整或清除评分标准
+func validateTaskRubric(task *models.Tasks) error {
+	criteria, err := taskRubric(task)
+	if err != nil {
+		return err
+	}
+	if len(criteria) == 0 {
+		return nil
+	}
+	// 测验的分数由逐题得分计算
+	if task.TaskType == TaskTypeQuiz {
+		return apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "测验任务不能设置评分标准，请先清除评分标准")
+	}
+	if total := rubric.MaxTotal(criteria); total != task.MaxScore {
+		return apperrors.NewAppError(apperrors.ErrCodeInvalidParam,
+			fmt.Sprintf("评分标准的满分（%d）与任务满分（%d）不一致", total, task.MaxScore))
+	}
+	return nil
+}
+
+// gradeScore 校验评分请求，返回扣除迟交分数前的得分和各评分项得分
+// 任务设置了评分标准时按评分项计分，总分为各评分项得分之和
+func gradeScore(task *models.Tasks, req *GradeAnswerRequest) (int, []rubric.Score, error) {
+	criteria, err := taskRubric(task)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if len(criteria) == 0 {
+		if len(req.Criteria) > 0 {
+			return 0, nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "任务没有评分标准，请直接填写分数")
+		}
+		if req.Score == nil {
+			return 0, nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "请填写分数")
+		}
+		if *req.Score < 0 || *req.Score > task.MaxScore {
+			return 0, nil, apperrors.NewAppError(apperrors.ErrCodeInvalidScore,
+				fmt.Sprintf("分数应在 0 到 %d 之间", task.MaxScore))
+		}
+		return *req.Score, []rubric.Score{}, nil
+	}
+
+	scores, total, err := rubric.Grade(criteria, req.Criteria)
+	if err != nil {
+		if errors.Is(err, rubric.ErrOutOfRange) {
+			return 0, nil, apperrors.WrapError(apperrors.ErrCodeInvalidScore, "分数无效: "+err.Error(), err)
+		}
+		return 0, nil, apperrors.WrapError(apperrors.ErrCodeInvalidParam, "评分项无效: "+err.Error(), err)
+	}
+	if req.Score != nil && *req.Score != total {
+		return 0, nil, apperrors.NewAppError(apperrors.ErrCodeInvalidScore,
+			fmt.Sprintf("分数应等于各评分项得分之和（%d）", total))
+	}
+	return total, scores, nil
+}
+
+// applyFeedback 写入评分项得分、评语和行内批注
+// 行内批注按字符位置定位，只能用于文本作业
+func applyFeedback(answer *models.Answers, scores []rubric.Score, req *GradeAnswerRequest) error {
+	comments := []rubric.InlineComment{}
+	if len(req.InlineComments) > 0 {
+		if answer.Type != "text" {
+			return apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "只有文本作业可以添加行内批注")
+		}
+		var err error
+		if comments, err = rubric.AnchorComments(answer.AnswerContent, req.InlineComments); err != nil {
+			return apperrors.WrapError(apperrors.ErrCodeInvalidParam, "行内批注无效: "+err.Error(), err)
+		}
+	}
+
+	scoresJSON, err := json.Marshal(scores)
+	if err != nil {
+		return fmt.Errorf("failed to encode rubric scores: %w", err)
+	}
+	commentsJSON, err := json.Marshal(comments)
+	if err != nil {
+		return fmt.Errorf("failed to encode inline comments: %w", err)
+	}
+	answer.RubricScores = string(scoresJSON)
+	answer.InlineComments = string(commentsJSON)
+	answer.Feedback = strings.TrimSpace(req.Feedback)
+	return nil
+}
+
+// answerDetail 签名作业内容并解析评分项得分和行内批注
+func answerDetail(answer *models.Answers) (*AnswerDetail, error) {
+	if err := signAnswerContent(answer); err != nil {
+		return nil, err
+	}
+	detail := &AnswerDetail{
+		Answers:        *answer,
+		RubricScores:   []rubric.Score{},
+		InlineComments: []rubric.InlineComment{},
+	}
+	if answer.RubricScores != "" {
+		if err := json.Unmarshal([]byte(answer.RubricScores), &detail.RubricScores); err != nil {
+			return nil, fmt.Errorf("invalid rubric scores of answer %d: %w", answer.AnswerID, err)
+		}
+	}
+	if answer.InlineComments != "" {
+		if err := json.Unmarshal([]byte(answer.InlineComments), &detail.InlineComments); err != nil {
+			return nil, fmt.Errorf("invalid inline comments of answer %d: %w", answer.AnswerID, err)
+		}
+	}
+	return detail, nil
+}
+
+// answerDetails 批量转换为作业详情
+func answerDetails(answers []models.Answers) ([]AnswerDetail, error) {
+	details := make([]AnswerDetail, 0, len(answers))
+	for i := range answers {
+		detail, err := answerDetail(&answers[i])
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, *detail)
+	}
+	return details, nil
+}
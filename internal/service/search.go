@@ -0,0 +1,266 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/internal/search"
+)
+
+// searchTSConfig 全文检索使用的 Postgres 文本检索配置，英文按词干匹配，中文由 search 包预先切分
+const searchTSConfig = "english"
+
+// searchSnippetLength 摘要最多包含的字符数
+const searchSnippetLength = 120
+
+// SearchQuery 全文检索请求，由查询参数绑定
+type SearchQuery struct {
+	Q        string `form:"q" binding:"required"`
+	Type     string `form:"type"` // course, chapter, lesson, task，为空时不限
+	CourseID *uint  `form:"course_id"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+}
+
+// SearchHit 检索结果，title 和 snippet 中的匹配内容用 <mark></mark> 标出
+type SearchHit struct {
+	EntityType  string  `json:"entity_type"`
+	EntityID    uint    `json:"entity_id"`
+	CourseID    uint    `json:"course_id"`
+	CourseTitle string  `json:"course_title"`
+	Title       string  `json:"title"`
+	Snippet     string  `json:"snippet"`
+	Rank        float64 `json:"rank"`
+}
+
+// SearchResult 检索结果分页
+type SearchResult struct {
+	Hits     []SearchHit `json:"hits"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+}
+
+// SearchService 全文检索服务
+type SearchService struct{}
+
+// NewSearchService 创建实例
+func NewSearchService() *SearchService {
+	return &SearchService{}
+}
+
+// Search 在学生可见的课程、章节、课时和任务中检索，按相关度排序
+func (s *SearchService) Search(q *SearchQuery) (*SearchResult, error) {
+	text := strings.TrimSpace(q.Q)
+	if text == "" {
+		return nil, apperrors.ErrInvalidParam
+	}
+	switch q.Type {
+	case "", RevisionEntityCourse, RevisionEntityChapter, RevisionEntityLesson, RevisionEntityTask:
+	default:
+		return nil, apperrors.ErrInvalidParam
+	}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 || q.PageSize > 100 {
+		q.PageSize = 10
+	}
+
+	tsquery := "websearch_to_tsquery('" + searchTSConfig + "', ?)"
+	segmented := search.SegmentQuery(text)
+
+	db := database.GetCentralDB()
+	query := db.Model(&models.SearchDocuments{}).
+		Where("search_documents.search_vector @@ "+tsquery, segmented).
+		Scopes(visibleSearchDocuments)
+	if q.Type != "" {
+		query = query.Where("search_documents.entity_type = ?", q.Type)
+	}
+	if q.CourseID != nil {
+		query = query.Where("search_documents.course_id = ?", *q.CourseID)
+	}
+
+	result := &SearchResult{Hits: []SearchHit{}, Page: q.Page, PageSize: q.PageSize}
+	if err := query.Count(&result.Total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	type row struct {
+		models.SearchDocuments
+		Rank float64
+	}
+	var rows []row
+	if err := query.
+		Select("search_documents.*, ts_rank_cd(search_documents.search_vector, "+tsquery+") AS rank", segmented).
+		Order("rank DESC, search_documents.document_id ASC").
+		Offset((q.Page - 1) * q.PageSize).Limit(q.PageSize).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	courseIDs := make([]uint, 0, len(rows))
+	for _, r := range rows {
+		courseIDs = append(courseIDs, r.CourseID)
+	}
+	courseTitles := make(map[uint]string, len(courseIDs))
+	if len(courseIDs) > 0 {
+		var courses []models.Courses
+		if err := db.Select("course_id, course_title").Where("course_id IN ?", courseIDs).Find(&courses).Error; err != nil {
+			return nil, fmt.Errorf("failed to load courses: %w", err)
+		}
+		for _, course := range courses {
+			courseTitles[course.CourseID] = course.CourseTitle
+		}
+	}
+
+	terms := search.Terms(text)
+	for _, r := range rows {
+		hit := SearchHit{
+			EntityType:  r.EntityType,
+			EntityID:    r.EntityID,
+			CourseID:    r.CourseID,
+			CourseTitle: courseTitles[r.CourseID],
+			Title:       search.Snippet(r.Title, terms, len([]rune(r.Title))),
+			Rank:        r.Rank,
+		}
+		if r.Body != "" {
+			hit.Snippet = search.Snippet(r.Body, terms, searchSnippetLength)
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+
+	return result, nil
+}
+
+// RebuildIndex 按中央服务器的现有内容重建全部检索文档，返回索引的文档数
+func (s *SearchService) RebuildIndex() (int, error) {
+	indexed := 0
+	err := database.GetCentralDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM search_documents").Error; err != nil {
+			return fmt.Errorf("failed to clear search documents: %w", err)
+		}
+
+		var courses []models.Courses
+		if err := tx.Find(&courses).Error; err != nil {
+			return fmt.Errorf("failed to load courses: %w", err)
+		}
+		for i := range courses {
+			if err := indexSearchDocument(tx, RevisionEntityCourse, courses[i].CourseID, courses[i].CourseID, &courses[i]); err != nil {
+				return err
+			}
+		}
+		indexed += len(courses)
+
+		var chapters []models.Chapters
+		if err := tx.Find(&chapters).Error; err != nil {
+			return fmt.Errorf("failed to load chapters: %w", err)
+		}
+		for i := range chapters {
+			if err := indexSearchDocument(tx, RevisionEntityChapter, chapters[i].ChapterID, chapters[i].CourseID, &chapters[i]); err != nil {
+				return err
+			}
+		}
+		indexed += len(chapters)
+
+		var lessons []models.Lessons
+		if err := tx.Find(&lessons).Error; err != nil {
+			return fmt.Errorf("failed to load lessons: %w", err)
+		}
+		lessonCourses := make(map[uint]uint, len(lessons))
+		for i := range lessons {
+			lessonCourses[lessons[i].LessonID] = lessons[i].CourseID
+			if err := indexSearchDocument(tx, RevisionEntityLesson, lessons[i].LessonID, lessons[i].CourseID, &lessons[i]); err != nil {
+				return err
+			}
+		}
+		indexed += len(lessons)
+
+		var tasks []models.Tasks
+		if err := tx.Find(&tasks).Error; err != nil {
+			return fmt.Errorf("failed to load tasks: %w", err)
+		}
+		for i := range tasks {
+			courseID, ok := lessonCourses[tasks[i].LessonID]
+			if !ok {
+				continue
+			}
+			if err := indexSearchDocument(tx, RevisionEntityTask, tasks[i].TaskID, courseID, &tasks[i]); err != nil {
+				return err
+			}
+			indexed++
+		}
+		return nil
+	})
+	return indexed, err
+}
+
+// syncSearchDocument 内容变更后更新检索文档，删除时移除文档（由 recordRevision 调用）
+func syncSearchDocument(tx *gorm.DB, entityType string, entityID, courseID uint, entity interface{}, action string) error {
+	if action == RevisionActionDelete {
+		if err := tx.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+			Delete(&models.SearchDocuments{}).Error; err != nil {
+			return fmt.Errorf("failed to remove search document: %w", err)
+		}
+		return nil
+	}
+	return indexSearchDocument(tx, entityType, entityID, courseID, entity)
+}
+
+// indexSearchDocument 写入或更新一条检索文档，标题权重为 A，正文权重为 B
+func indexSearchDocument(tx *gorm.DB, entityType string, entityID, courseID uint, entity interface{}) error {
+	var title, body string
+	switch e := entity.(type) {
+	case *models.Courses:
+		title, body = e.CourseTitle, e.Description
+	case *models.Chapters:
+		title, body = e.ChapterTitle, e.Description
+	case *models.Lessons:
+		title = e.LessonTitle
+	case *models.Tasks:
+		title, body = e.TaskTitle, e.Description
+	default:
+		return fmt.Errorf("unsupported search entity %T", entity)
+	}
+
+	err := tx.Exec(`INSERT INTO search_documents (entity_type, entity_id, course_id, title, body, search_vector, updated_at)
+		VALUES (?, ?, ?, ?, ?,
+			setweight(to_tsvector('`+searchTSConfig+`', ?), 'A') || setweight(to_tsvector('`+searchTSConfig+`', ?), 'B'),
+			NOW())
+		ON CONFLICT (entity_type, entity_id) DO UPDATE SET
+			course_id = EXCLUDED.course_id,
+			title = EXCLUDED.title,
+			body = EXCLUDED.body,
+			search_vector = EXCLUDED.search_vector,
+			updated_at = EXCLUDED.updated_at`,
+		entityType, entityID, courseID, title, body,
+		search.SegmentDocument(title), search.SegmentDocument(body)).Error
+	if err != nil {
+		return fmt.Errorf("failed to index search document: %w", err)
+	}
+	return nil
+}
+
+// visibleSearchDocuments 限定为学生可见的内容：课程已发布且未归档，章节、课时已发布，任务所属课时可见
+func visibleSearchDocuments(db *gorm.DB) *gorm.DB {
+	central := database.GetCentralDB()
+	courses := central.Model(&models.Courses{}).Select("course_id").
+		Where("publish_status = ? AND status = ?", PublishStatusPublished, CourseStatusActive)
+	chapters := central.Model(&models.Chapters{}).Select("chapter_id").
+		Where("publish_status = ?", PublishStatusPublished)
+	lessons := func() *gorm.DB {
+		return central.Table("lessons").Select("lessons.lesson_id").Scopes(visibleLessons)
+	}
+	tasks := central.Model(&models.Tasks{}).Select("task_id").Where("lesson_id IN (?)", lessons())
+
+	return db.Where("search_documents.course_id IN (?)", courses).
+		Where(central.Where("search_documents.entity_type = ?", RevisionEntityCourse).
+			Or("search_documents.entity_type = ? AND search_documents.entity_id IN (?)", RevisionEntityChapter, chapters).
+			Or("search_documents.entity_type = ? AND search_documents.entity_id IN (?)", RevisionEntityLesson, lessons()).
+			Or("search_documents.entity_type = ? AND search_documents.entity_id IN (?)", RevisionEntityTask, tasks))
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/models"
+)
+
+// 课程教学团队角色
+const (
+	StaffRoleInstructor   = "instructor"    // 主讲教师，即 courses.instructor_id，可以删除课程和管理教学团队
+	StaffRoleCoInstructor = "co_instructor" // 协同教师，可以编辑课程内容和批改作业
+	StaffRoleTA           = "ta"            // 助教，只能查看课程、批改作业，不能修改课程结构
+)
+
+var (
+	staffRolesAll     = []string{StaffRoleInstructor, StaffRoleCoInstructor, StaffRoleTA}
+	staffRolesEditors = []string{StaffRoleInstructor, StaffRoleCoInstructor}
+)
+
+// AddCourseStaffRequest 添加教学团队成员请求，成员通过所在分支和分支用户ID指定
+type AddCourseStaffRequest struct {
+	BranchID uint   `json:"branch_id" binding:"required"`
+	UserID   uint   `json:"user_id" binding:"required"`
+	Role     string `json:"role" binding:"required"` // co_instructor, ta
+}
+
+// UpdateCourseStaffRequest 修改教学团队成员角色请求
+type UpdateCourseStaffRequest struct {
+	Role string `json:"role" binding:"required"` // co_instructor, ta
+}
+
+// CourseStaffInfo 教学团队成员信息
+type CourseStaffInfo struct {
+	StaffID      uint   `json:"staff_id"`
+	CourseID     uint   `json:"course_id"`
+	InstructorID uint   `json:"instructor_id"`
+	BranchID     uint   `json:"branch_id"`
+	UserID       uint   `json:"user_id"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// CourseStaffService 课程教学团队服务
+type CourseStaffService struct{}
+
+// NewCourseStaffService 创建实例
+func NewCourseStaffService() *CourseStaffService {
+	return &CourseStaffService{}
+}
+
+// ListStaff 获取课程教学团队，团队成员均可查看
+func (s *CourseStaffService) ListStaff(courseID, userID, branchID uint, role string) ([]CourseStaffInfo, error) {
+	if role == UserRoleAdmin {
+		if err := ensureCourseExists(courseID); err != nil {
+			return nil, err
+		}
+	} else if err := validateCourseStaff(courseID, userID, branchID); err != nil {
+		return nil, err
+	}
+
+	var staff []models.CourseStaff
+	if err := database.GetCentralDB().Preload("Instructor").
+		Where("course_id = ?", courseID).Order("staff_id ASC").Find(&staff).Error; err != nil {
+		return nil, fmt.Errorf("failed to list course staff: %w", err)
+	}
+
+	infos := make([]CourseStaffInfo, 0, len(staff))
+	for i := range staff {
+		infos = append(infos, toCourseStaffInfo(&staff[i]))
+	}
+	return infos, nil
+}
+
+// AddStaff 添加协同教师或助教，成员已在团队中时修改其角色
+func (s *CourseStaffService) AddStaff(courseID, userID, branchID uint, role string, req *AddCourseStaffRequest) (*CourseStaffInfo, error) {
+	if err := authorizeStaffManager(courseID, userID, branchID, role); err != nil {
+		return nil, err
+	}
+	if !isAssignableStaffRole(req.Role) {
+		return nil, apperrors.ErrInvalidParam
+	}
+
+	course, err := getCourseByID(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 成员必须是教师，首次加入时在中央服务器登记
+	member, err := ensureInstructorRecord(req.UserID, req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+	if member.InstructorID == course.InstructorID {
+		return nil, apperrors.ErrInvalidParam
+	}
+
+	db := database.GetCentralDB()
+	var staff models.CourseStaff
+	err = db.Where("course_id = ? AND instructor_id = ?", courseID, member.InstructorID).First(&staff).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to query course staff: %w", err)
+	}
+
+	staff.CourseID = courseID
+	staff.InstructorID = member.InstructorID
+	staff.Role = req.Role
+	if err := db.Save(&staff).Error; err != nil {
+		return nil, fmt.Errorf("failed to save course staff: %w", err)
+	}
+
+	staff.Instructor = member
+	info := toCourseStaffInfo(&staff)
+	return &info, nil
+}
+
+// UpdateStaff 修改教学团队成员角色，主讲教师的角色不能修改
+func (s *CourseStaffService) UpdateStaff(courseID, staffID, userID, branchID uint, role string, req *UpdateCourseStaffRequest) (*CourseStaffInfo, error) {
+	if err := authorizeStaffManager(courseID, userID, branchID, role); err != nil {
+		return nil, err
+	}
+	if !isAssignableStaffRole(req.Role) {
+		return nil, apperrors.ErrInvalidParam
+	}
+
+	staff, err := findCourseStaff(courseID, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if staff.Role == StaffRoleInstructor {
+		return nil, apperrors.ErrForbidden
+	}
+
+	staff.Role = req.Role
+	if err := database.GetCentralDB().Save(staff).Error; err != nil {
+		return nil, fmt.Errorf("failed to update course staff: %w", err)
+	}
+
+	info := toCourseStaffInfo(staff)
+	return &info, nil
+}
+
+// RemoveStaff 将成员移出教学团队，主讲教师不能移除
+func (s *CourseStaffService) RemoveStaff(courseID, staffID, userID, branchID uint, role string) error {
+	if err := authorizeStaffManager(courseID, userID, branchID, role); err != nil {
+		return err
+	}
+
+	staff, err := findCourseStaff(courseID, staffID)
+	if err != nil {
+		return err
+	}
+	if staff.Role == StaffRoleInstructor {
+		return apperrors.ErrForbidden
+	}
+
+	if err := database.GetCentralDB().Delete(staff).Error; err != nil {
+		return fmt.Errorf("failed to remove course staff: %w", err)
+	}
+	return nil
+}
+
+// addPrimaryInstructor 在创建课程的事务中将创建者登记为主讲教师
+func addPrimaryInstructor(tx *gorm.DB, courseID, instructorID uint) error {
+	staff := models.CourseStaff{
+		CourseID:     courseID,
+		InstructorID: instructorID,
+		Role:         StaffRoleInstructor,
+	}
+	if err := tx.Create(&staff).Error; err != nil {
+		return fmt.Errorf("failed to register course instructor: %w", err)
+	}
+	return nil
+}
+
+// authorizeStaffManager 管理员或课程主讲教师可以管理教学团队
+func authorizeStaffManager(courseID, userID, branchID uint, role string) error {
+	if role == UserRoleAdmin {
+		return ensureCourseExists(courseID)
+	}
+	return validateCourseInstructor(courseID, userID, branchID)
+}
+
+func findCourseStaff(courseID, staffID uint) (*models.CourseStaff, error) {
+	var staff models.CourseStaff
+	if err := database.GetCentralDB().Preload("Instructor").
+		Where("staff_id = ? AND course_id = ?", staffID, courseID).First(&staff).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to query course staff: %w", err)
+	}
+	return &staff, nil
+}
+
+func isAssignableStaffRole(role string) bool {
+	return role == StaffRoleCoInstructor || role == StaffRoleTA
+}
+
+func toCourseStaffInfo(staff *models.CourseStaff) CourseStaffInfo {
+	info := CourseStaffInfo{
+		StaffID:      staff.StaffID,
+		CourseID:     staff.CourseID,
+		InstructorID: staff.InstructorID,
+		Role:         staff.Role,
+		CreatedAt:    staff.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if staff.Instructor != nil {
+		info.BranchID = staff.Instructor.BranchID
+		info.UserID = staff.Instructor.BranchUserID
+		info.Username = staff.Instructor.Username
+		info.Email = staff.Instructor.Email
+	}
+	return info
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/database"
+	"online-learning-platform/internal/logger"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/internal/oss"
+	"online-learning-platform/internal/storagegc"
+	"online-learning-platform/internal/video"
+)
+
+// storageGCPrefixes 清理的存储前缀：作业文件、课程内容（课时文件、Markdown 正文和资源）和上传暂存对象
+var storageGCPrefixes = []string{"answers/", "courses/", uploadStagingPrefix}
+
+// StorageGCService 清理对象存储中的孤立对象
+type StorageGCService struct{}
+
+// NewStorageGCService 创建实例
+func NewStorageGCService() *StorageGCService {
+	return &StorageGCService{}
+}
+
+// Run 对比存储中的对象和数据库中的引用，apply 为 false 时只生成报告
+// 任何一个数据库查询失败都会中止，避免把仍被引用的对象当作孤立对象删除
+func (s *StorageGCService) Run(grace time.Duration, apply bool) (*storagegc.Report, error) {
+	now := time.Now()
+
+	// 先收集引用再列举对象：收集之后新写入的对象都在宽限期内，不会被删除
+	refs, err := collectObjectReferences(now)
+	if err != nil {
+		return nil, err
+	}
+
+	report := storagegc.NewReport(storageGCPrefixes, now.Add(-grace), !apply)
+	for _, prefix := range storageGCPrefixes {
+		if err := oss.ListObjects(prefix, func(object oss.ObjectInfo) error {
+			report.Add(object, refs)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if !apply {
+		return report, nil
+	}
+
+	for _, object := range report.Orphans {
+		if err := oss.DeleteObject(object.Key); err != nil {
+			logger.Warnf("Storage gc: failed to delete %s: %v", object.Key, err)
+			report.Failed = append(report.Failed, object.Key)
+			continue
+		}
+		report.Deleted++
+	}
+	logger.Infof("Storage gc deleted %d orphaned objects (%d failed)", report.Deleted, len(report.Failed))
+	return report, nil
+}
+
+// collectObjectReferences 收集中央服务器和所有分支节点引用的对象Key
+func collectObjectReferences(now time.Time) (*storagegc.References, error) {
+	prefix, err := oss.ObjectURL("")
+	if err != nil {
+		return nil, err
+	}
+	refs := storagegc.NewReferences()
+	addURLs := func(text string) {
+		for _, u := range storagegc.ExtractURLs(text, prefix) {
+			if key, ok := oss.ObjectKeyFromURL(u); ok {
+				refs.Add(key)
+			}
+		}
+	}
+	contains := "%" + prefix + "%"
+
+	// 已删除的课时和任务可以通过修订历史恢复，同样保留它们引用的对象
+	central := database.GetCentralDB()
+	if err := scanTextColumn(central.Unscoped().Model(&models.Lessons{}).
+		Select("concat_ws(' ', content_url, hls_url, thumbnail_url)").
+		Where("content_url LIKE ? OR hls_url LIKE ?", contains, contains), addURLs); err != nil {
+		return nil, fmt.Errorf("failed to collect lesson content: %w", err)
+	}
+	if err := scanTextColumn(central.Unscoped().Model(&models.Tasks{}).
+		Select("description").Where("description LIKE ?", contains), addURLs); err != nil {
+		return nil, fmt.Errorf("failed to collect task descriptions: %w", err)
+	}
+	if err := scanTextColumn(central.Model(&models.Revisions{}).
+		Select("snapshot::text").Where("snapshot::text LIKE ?", contains), addURLs); err != nil {
+		return nil, fmt.Errorf("failed to collect revision snapshots: %w", err)
+	}
+	if err := collectUploadReferences(central, now, refs); err != nil {
+		return nil, err
+	}
+
+	for branchID, branchDB := range database.GetAllBranchDBs() {
+		if err := scanTextColumn(branchDB.Unscoped().Model(&models.Answers{}).
+			Select("answer_content").Where("answer_content LIKE ?", contains), addURLs); err != nil {
+			return nil, fmt.Errorf("failed to collect answers of branch %d: %w", branchID, err)
+		}
+		if err := collectUploadReferences(branchDB, now, refs); err != nil {
+			return nil, fmt.Errorf("branch %d: %w", branchID, err)
+		}
+	}
+
+	// 视频转码结果只引用主播放列表，同目录下的分片和封面一并保留；
+	// Markdown 正文引用的资源（courses/{id}/assets/）只出现在正文对象中
+	var markdownKeys []string
+	for _, key := range refs.Keys() {
+		if video.IsMasterPlaylistKey(key) {
+			refs.AddPrefix(strings.TrimSuffix(key, video.MasterPlaylistName))
+		}
+		if strings.HasPrefix(key, "courses/") && strings.Contains(key, "/markdown/") {
+			markdownKeys = append(markdownKeys, key)
+		}
+	}
+	for _, key := range markdownKeys {
+		body, err := readObjectText(key)
+		if err != nil {
+			if errors.Is(err, oss.ErrObjectNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		addURLs(body)
+	}
+	return refs, nil
+}
+
+// collectUploadReferences 尚未关联、也未过期的上传仍可能被使用
+func collectUploadReferences(db *gorm.DB, now time.Time, refs *storagegc.References) error {
+	var keys []string
+	if err := db.Model(&models.Uploads{}).
+		Where("status IN ? AND expires_at > ?", []string{UploadStatusPending, UploadStatusCompleted}, now).
+		Pluck("object_key", &keys).Error; err != nil {
+		return fmt.Errorf("failed to collect uploads: %w", err)
+	}
+	for _, key := range keys {
+		refs.Add(key)
+		refs.Add(uploadStagingKey(key))
+	}
+	return nil
+}
+
+// scanTextColumn 逐行读取查询结果的第一列，避免一次加载全部修订快照
+func scanTextColumn(query *gorm.DB, fn func(string)) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return err
+		}
+		fn(text)
+	}
+	return rows.Err()
+}
+
+// readObjectText 读取文本对象的内容
+func readObjectText(objectKey string) (string, error) {
+	rc, err := oss.GetObject(objectKey)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", objectKey, err)
+	}
+	return string(data), nil
+}
@@ -54,24 +54,33 @@ func (s *SyncService) RunReplication() {
 	}
 
 	if contains("courses") {
-		if err := centralDB.Where("updated_at >= ?", s.lastSync).Find(&data.Courses).Error; err != nil {
+		if err := changedSince(centralDB, s.lastSync).Find(&data.Courses).Error; err != nil {
 			fmt.Printf("replication: failed to fetch courses: %v\n", err)
 		}
 	}
 	if contains("chapters") {
-		if err := centralDB.Where("updated_at >= ?", s.lastSync).Find(&data.Chapters).Error; err != nil {
+		if err := changedSince(centralDB, s.lastSync).Find(&data.Chapters).Error; err != nil {
 			fmt.Printf("replication: failed to fetch chapters: %v\n", err)
 		}
 	}
 	if contains("lessons") {
-		if err := centralDB.Where("updated_at >= ?", s.lastSync).Find(&data.Lessons).Error; err != nil {
+		if err := changedSince(centralDB, s.lastSync).Find(&data.Lessons).Error; err != nil {
 			fmt.Printf("replication: failed to fetch lessons: %v\n", err)
 		}
 	}
 	if contains("tasks") {
-		if err := centralDB.Where("updated_at >= ?", s.lastSync).Find(&data.Tasks).Error; err != nil {
+		if err := changedSince(centralDB, s.lastSync).Find(&data.Tasks).Error; err != nil {
 			fmt.Printf("replication: failed to fetch tasks: %v\n", err)
 		}
+		if err := appendLessonTasks(centralDB, data.Lessons, &data.Tasks); err != nil {
+			fmt.Printf("replication: failed to fetch lesson tasks: %v\n", err)
+		}
+	}
+	lessonStatus, err := taskLessonStatus(centralDB, data.Tasks)
+	if err != nil {
+		// 无法确认课时发布状态时不同步任务，避免把草稿课时的任务暴露到分支
+		fmt.Printf("replication: failed to fetch task lessons: %v\n", err)
+		data.Tasks = nil
 	}
 
 	for branchID, branchDB := range database.GetAllBranchDBs() {
@@ -102,7 +111,7 @@ func (s *SyncService) RunReplication() {
 			rollback()
 			continue
 		}
-		if err := upsertTasks(tx, data.Tasks); err != nil {
+		if err := upsertTasks(tx, data.Tasks, lessonStatus); err != nil {
 			fmt.Printf("replication: branch %d tasks error: %v\n", branchID, err)
 			rollback()
 			continue
@@ -117,9 +126,26 @@ func (s *SyncService) RunReplication() {
 	fmt.Printf("replication finished at %s\n", start.Format(time.RFC3339))
 }
 
+// changedSince 查询自上次同步以来修改或软删除的记录
+// GORM 软删除只更新 deleted_at，因此需要 Unscoped 并同时比较 deleted_at，才能把删除同步到分支
+func changedSince(db *gorm.DB, since time.Time) *gorm.DB {
+	return db.Unscoped().Where("updated_at >= ? OR deleted_at >= ?", since, since)
+}
+
+// replicaDeletedAt 计算分支副本的 deleted_at
+// 未发布的草稿在分支副本中以软删除形式存在，保证分支节点只能读到已发布的内容；
+// 重新发布后 deleted_at 会在下一次同步时被清空
+func replicaDeletedAt(deletedAt gorm.DeletedAt, publishStatus string, updatedAt time.Time) gorm.DeletedAt {
+	if deletedAt.Valid || publishStatus == PublishStatusPublished {
+		return deletedAt
+	}
+	return gorm.DeletedAt{Time: updatedAt, Valid: true}
+}
+
 func upsertCourses(db *gorm.DB, courses []models.Courses) error {
 	for _, course := range courses {
 		c := course
+		c.DeletedAt = replicaDeletedAt(c.DeletedAt, c.PublishStatus, c.UpdatedAt)
 		if err := db.Table("courses").Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "course_id"}},
 			UpdateAll: true,
@@ -133,6 +159,7 @@ func upsertCourses(db *gorm.DB, courses []models.Courses) error {
 func upsertChapters(db *gorm.DB, chapters []models.Chapters) error {
 	for _, chapter := range chapters {
 		c := chapter
+		c.DeletedAt = replicaDeletedAt(c.DeletedAt, c.PublishStatus, c.UpdatedAt)
 		if err := db.Table("chapters").Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "chapter_id"}},
 			UpdateAll: true,
@@ -146,6 +173,7 @@ func upsertChapters(db *gorm.DB, chapters []models.Chapters) error {
 func upsertLessons(db *gorm.DB, lessons []models.Lessons) error {
 	for _, lesson := range lessons {
 		l := lesson
+		l.DeletedAt = replicaDeletedAt(l.DeletedAt, l.PublishStatus, l.UpdatedAt)
 		if err := db.Table("lessons").Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "lesson_id"}},
 			UpdateAll: true,
@@ -156,9 +184,57 @@ func upsertLessons(db *gorm.DB, lessons []models.Lessons) error {
 	return nil
 }
 
-func upsertTasks(db *gorm.DB, tasks []models.Tasks) error {
+// appendLessonTasks 把本轮变更课时下的任务加入同步列表
+// 任务本身未修改时，课时发布或撤回也需要重新计算任务副本的可见性
+func appendLessonTasks(db *gorm.DB, lessons []models.Lessons, tasks *[]models.Tasks) error {
+	if len(lessons) == 0 {
+		return nil
+	}
+	lessonIDs := make([]uint, 0, len(lessons))
+	for _, lesson := range lessons {
+		lessonIDs = append(lessonIDs, lesson.LessonID)
+	}
+	var lessonTasks []models.Tasks
+	if err := db.Unscoped().Where("lesson_id IN ?", lessonIDs).Find(&lessonTasks).Error; err != nil {
+		return err
+	}
+	seen := make(map[uint]bool, len(*tasks))
+	for _, task := range *tasks {
+		seen[task.TaskID] = true
+	}
+	for _, task := range lessonTasks {
+		if !seen[task.TaskID] {
+			*tasks = append(*tasks, task)
+		}
+	}
+	return nil
+}
+
+// taskLessonStatus 查询任务所属课时的发布状态，已删除的课时视为未发布
+func taskLessonStatus(db *gorm.DB, tasks []models.Tasks) (map[uint]string, error) {
+	status := make(map[uint]string)
+	if len(tasks) == 0 {
+		return status, nil
+	}
+	lessonIDs := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		lessonIDs = append(lessonIDs, task.LessonID)
+	}
+	var lessons []models.Lessons
+	if err := db.Select("lesson_id", "publish_status").Where("lesson_id IN ?", lessonIDs).Find(&lessons).Error; err != nil {
+		return nil, err
+	}
+	for _, lesson := range lessons {
+		status[lesson.LessonID] = lesson.PublishStatus
+	}
+	return status, nil
+}
+
+// upsertTasks 按所属课时的发布状态写入任务副本，草稿课时下的任务在分支中同样不可见
+func upsertTasks(db *gorm.DB, tasks []models.Tasks, lessonStatus map[uint]string) error {
 	for _, task := range tasks {
 		t := task
+		t.DeletedAt = replicaDeletedAt(t.DeletedAt, lessonStatus[t.LessonID], t.UpdatedAt)
 		if err := db.Table("tasks").Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "task_id"}},
 			UpdateAll: true,
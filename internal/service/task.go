@@ -2,14 +2,23 @@ package service
 
 import (
 	"fmt"
+	"strings"
 
 	"gorm.io/gorm"
 
+	"online-learning-platform/internal/attempt"
 	"online-learning-platform/internal/database"
 	apperrors "online-learning-platform/internal/errors"
 	"online-learning-platform/internal/models"
 )
 
+// 任务类型
+const (
+	TaskTypeEssay  = "essay"
+	TaskTypeQuiz   = "quiz"
+	TaskTypeUpload = "upload"
+)
+
 // TaskService 任务管理服务
 type TaskService struct{}
 
@@ -24,22 +33,59 @@ type CreateTaskRequest struct {
 	Description string `json:"description"`
 	TaskType    string `json:"task_type"` // essay, quiz, upload
 	MaxScore    int    `json:"max_score"`
+	// 作业文件限制，只能在全局配置的范围内收紧，不填使用全局配置
+	AllowedTypes []string `json:"allowed_types"`
+	MaxFileSize  int64    `json:"max_file_size"`
+	// 截止时间，格式 "2006-01-02 15:04:05"，不填表示不限
+	DueAt    *string `json:"due_at"`
+	CutoffAt *string `json:"cutoff_at"`
+	// 迟交策略：空（不扣分）, percent_per_day（按天扣除 late_penalty_percent）, zero_after_cutoff
+	LatePolicy         string `json:"late_policy"`
+	LatePenaltyPercent int    `json:"late_penalty_percent"`
+	// 最大提交次数（0 表示不限制）和计入成绩的提交：latest（默认）, highest, first
+	MaxAttempts   int    `json:"max_attempts"`
+	AttemptPolicy string `json:"attempt_policy"`
 }
 
 // TaskInfo 任务信息
 type TaskInfo struct {
-	TaskID      uint   `json:"task_id"`
-	LessonID    uint   `json:"lesson_id"`
-	TaskTitle   string `json:"task_title"`
-	Description string `json:"description"`
-	TaskType    string `json:"task_type"`
-	MaxScore    int    `json:"max_score"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	TaskID             uint     `json:"task_id"`
+	LessonID           uint     `json:"lesson_id"`
+	TaskTitle          string   `json:"task_title"`
+	Description        string   `json:"description"`
+	TaskType           string   `json:"task_type"`
+	MaxScore           int      `json:"max_score"`
+	AllowedTypes       []string `json:"allowed_types"`
+	MaxFileSize        int64    `json:"max_file_size"`
+	DueAt              *string  `json:"due_at"`
+	CutoffAt           *string  `json:"cutoff_at"`
+	LatePolicy         string   `json:"late_policy"`
+	LatePenaltyPercent int      `json:"late_penalty_percent"`
+	MaxAttempts        int      `json:"max_attempts"`
+	AttemptPolicy      string   `json:"attempt_policy"`
+	Extended           bool     `json:"extended"` // 学生任务列表中截止时间是否已合并该学生的延期
+	CreatedAt          string   `json:"created_at"`
+	UpdatedAt          string   `json:"updated_at"`
+}
+
+// UpdateTaskRequest 更新任务请求（字段为 null 表示不修改）
+type UpdateTaskRequest struct {
+	TaskTitle          *string   `json:"task_title"`
+	Description        *string   `json:"description"`
+	TaskType           *string   `json:"task_type"` // essay, quiz, upload
+	MaxScore           *int      `json:"max_score"`
+	AllowedTypes       *[]string `json:"allowed_types"` // 空数组表示恢复为全局配置
+	MaxFileSize        *int64    `json:"max_file_size"`
+	DueAt              *string   `json:"due_at"`    // 空字符串表示清除
+	CutoffAt           *string   `json:"cutoff_at"` // 空字符串表示清除
+	LatePolicy         *string   `json:"late_policy"`
+	LatePenaltyPercent *int      `json:"late_penalty_percent"`
+	MaxAttempts        *int      `json:"max_attempts"`
+	AttemptPolicy      *string   `json:"attempt_policy"` // 修改后重新选出每个学生计入成绩的提交
 }
 
 // CreateTask 教师创建任务
-func (s *TaskService) CreateTask(lessonID, instructorID uint, req *CreateTaskRequest) (*models.Tasks, error) {
+func (s *TaskService) CreateTask(lessonID, instructorUserID, branchID uint, req *CreateTaskRequest) (*models.Tasks, error) {
 	db := database.GetCentralDB()
 
 	// 验证课程是否存在且属于该教师
@@ -52,41 +98,209 @@ func (s *TaskService) CreateTask(lessonID, instructorID uint, req *CreateTaskReq
 	}
 
 	// 验证课程是否属于该教师
-	var course models.Courses
-	if err := db.Where("course_id = ? AND instructor_id = ?", lesson.CourseID, instructorID).First(&course).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, apperrors.ErrNotCourseInstructor
-		}
-		return nil, fmt.Errorf("failed to verify course: %w", err)
+	if err := validateCourseOwner(lesson.CourseID, instructorUserID, branchID); err != nil {
+		return nil, err
 	}
 
 	taskType := req.TaskType
 	if taskType == "" {
 		taskType = "essay"
 	}
+	if !isValidTaskType(taskType) {
+		return nil, apperrors.ErrInvalidParam
+	}
 
 	maxScore := req.MaxScore
 	if maxScore == 0 {
 		maxScore = 100
 	}
+	if maxScore < 0 {
+		return nil, apperrors.ErrInvalidParam
+	}
+
+	allowedTypes, err := validateTaskUploadLimits(req.AllowedTypes, req.MaxFileSize)
+	if err != nil {
+		return nil, err
+	}
 
 	task := models.Tasks{
-		LessonID:    lessonID,
-		TaskTitle:   req.TaskTitle,
-		Description: req.Description,
-		TaskType:    taskType,
-		MaxScore:    maxScore,
+		LessonID:           lessonID,
+		TaskTitle:          req.TaskTitle,
+		Description:        req.Description,
+		TaskType:           taskType,
+		MaxScore:           maxScore,
+		AllowedTypes:       allowedTypes,
+		MaxFileSize:        req.MaxFileSize,
+		LatePolicy:         req.LatePolicy,
+		LatePenaltyPercent: req.LatePenaltyPercent,
+		MaxAttempts:        req.MaxAttempts,
+		AttemptPolicy:      req.AttemptPolicy,
+	}
+	if task.AttemptPolicy == "" {
+		task.AttemptPolicy = attempt.PolicyLatest
+	}
+	if err := validateTaskAttempts(&task); err != nil {
+		return nil, err
+	}
+	if task.DueAt, err = parseCourseDate(req.DueAt); err != nil {
+		return nil, err
+	}
+	if task.CutoffAt, err = parseCourseDate(req.CutoffAt); err != nil {
+		return nil, err
+	}
+	if err := validateTaskDeadlines(&task); err != nil {
+		return nil, err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&task).Error; err != nil {
+			return fmt.Errorf("failed to create task: %w", err)
+		}
+		_, err := recordRevision(tx, &task, RevisionActionCreate, instructorUserID, branchID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// UpdateTask 教师更新任务
+func (s *TaskService) UpdateTask(taskID, instructorUserID, branchID uint, req *UpdateTaskRequest) (*models.Tasks, error) {
+	if err := validateTaskOwner(taskID, instructorUserID, branchID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetCentralDB()
+
+	var task models.Tasks
+	if err := db.Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	if req.TaskTitle != nil {
+		if *req.TaskTitle == "" {
+			return nil, apperrors.ErrInvalidParam
+		}
+		task.TaskTitle = *req.TaskTitle
+	}
+	if req.Description != nil {
+		task.Description = *req.Description
+	}
+	if req.TaskType != nil {
+		if !isValidTaskType(*req.TaskType) {
+			return nil, apperrors.ErrInvalidParam
+		}
+		task.TaskType = *req.TaskType
+	}
+	if req.MaxScore != nil {
+		if *req.MaxScore <= 0 {
+			return nil, apperrors.ErrInvalidParam
+		}
+		task.MaxScore = *req.MaxScore
+	}
+	if req.AllowedTypes != nil || req.MaxFileSize != nil {
+		types := strings.Split(task.AllowedTypes, ",")
+		if req.AllowedTypes != nil {
+			types = *req.AllowedTypes
+		}
+		if req.MaxFileSize != nil {
+			task.MaxFileSize = *req.MaxFileSize
+		}
+		allowedTypes, err := validateTaskUploadLimits(types, task.MaxFileSize)
+		if err != nil {
+			return nil, err
+		}
+		task.AllowedTypes = allowedTypes
+	}
+	var err error
+	if req.DueAt != nil {
+		if task.DueAt, err = parseCourseDate(req.DueAt); err != nil {
+			return nil, err
+		}
+	}
+	if req.CutoffAt != nil {
+		if task.CutoffAt, err = parseCourseDate(req.CutoffAt); err != nil {
+			return nil, err
+		}
+	}
+	if req.LatePolicy != nil {
+		task.LatePolicy = *req.LatePolicy
+	}
+	if req.LatePenaltyPercent != nil {
+		task.LatePenaltyPercent = *req.LatePenaltyPercent
+	}
+	if err := validateTaskDeadlines(&task); err != nil {
+		return nil, err
+	}
+	previousPolicy := attemptPolicy(&task)
+	if req.MaxAttempts != nil {
+		task.MaxAttempts = *req.MaxAttempts
+	}
+	if req.AttemptPolicy != nil {
+		task.AttemptPolicy = *req.AttemptPolicy
+	}
+	if err := validateTaskAttempts(&task); err != nil {
+		return nil, err
+	}
+	if err := validateTaskRubric(&task); err != nil {
+		return nil, err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&task).Error; err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+		_, err := recordRevision(tx, &task, RevisionActionUpdate, instructorUserID, branchID)
+		return err
+	}); err != nil {
+		return nil, err
 	}
 
-	if err := db.Create(&task).Error; err != nil {
-		return nil, fmt.Errorf("failed to create task: %w", err)
+	if attemptPolicy(&task) != previousPolicy {
+		recountTaskAttempts(&task)
 	}
 
 	return &task, nil
 }
 
+// DeleteTask 教师删除任务（软删除，已提交的作业保留在分支节点）
+func (s *TaskService) DeleteTask(taskID, instructorUserID, branchID uint) error {
+	if err := validateTaskOwner(taskID, instructorUserID, branchID); err != nil {
+		return err
+	}
+
+	db := database.GetCentralDB()
+	var task models.Tasks
+	if err := db.Where("task_id = ?", taskID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return apperrors.ErrTaskNotFound
+		}
+		return fmt.Errorf("failed to get task: %w", err)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		return deleteTaskTx(tx, &task, instructorUserID, branchID)
+	})
+}
+
+// deleteTaskTx 在事务中软删除任务并记录删除修订
+func deleteTaskTx(tx *gorm.DB, task *models.Tasks, instructorUserID, branchID uint) error {
+	if _, err := recordRevision(tx, task, RevisionActionDelete, instructorUserID, branchID); err != nil {
+		return err
+	}
+	if err := tx.Where("task_id = ?", task.TaskID).Delete(&models.Tasks{}).Error; err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	return nil
+}
+
 // GetTask 获取任务详情
-func (s *TaskService) GetTask(taskID uint) (*TaskInfo, error) {
+// publishedOnly 为 true 时（学生视角）任务所在课时未发布则视为不存在
+func (s *TaskService) GetTask(taskID uint, publishedOnly bool) (*TaskInfo, error) {
 	db := database.GetCentralDB()
 
 	var task models.Tasks
@@ -97,48 +311,86 @@ func (s *TaskService) GetTask(taskID uint) (*TaskInfo, error) {
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
-	return &TaskInfo{
-		TaskID:      task.TaskID,
-		LessonID:    task.LessonID,
-		TaskTitle:   task.TaskTitle,
-		Description: task.Description,
-		TaskType:    task.TaskType,
-		MaxScore:    task.MaxScore,
-		CreatedAt:   task.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt:   task.UpdatedAt.Format("2006-01-02 15:04:05"),
-	}, nil
+	if publishedOnly {
+		if err := ensureLessonVisible(task.LessonID); err != nil {
+			if err == apperrors.ErrLessonNotFound {
+				return nil, apperrors.ErrTaskNotFound
+			}
+			return nil, err
+		}
+	}
+
+	return taskInfo(&task), nil
 }
 
 // ListTasksByCourse 获取课程的所有任务
-func (s *TaskService) ListTasksByCourse(courseID uint) ([]TaskInfo, error) {
-	db := database.GetCentralDB()
+// publishedOnly 为 true 时（学生视角）只返回已发布课时下的任务
+func (s *TaskService) ListTasksByCourse(courseID uint, publishedOnly bool) ([]TaskInfo, error) {
+	tasks, err := listCourseTasks(courseID, publishedOnly)
+	if err != nil {
+		return nil, err
+	}
 
-	// 通过lessons表关联查询
-	var tasks []models.Tasks
-	if err := db.Joins("JOIN lessons ON tasks.lesson_id = lessons.lesson_id").
-		Where("lessons.course_id = ?", courseID).
-		Order("tasks.created_at DESC").
-		Find(&tasks).Error; err != nil {
-		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	taskInfos := make([]TaskInfo, 0, len(tasks))
+	for _, task := range tasks {
+		taskInfos = append(taskInfos, *taskInfo(&task))
+	}
+
+	return taskInfos, nil
+}
+
+// ListStudentTasks 学生查看课程已发布课时下的任务，截止时间合并该学生的延期
+// userID 为 0（未登录）时返回任务设置的截止时间
+func (s *TaskService) ListStudentTasks(courseID, userID, branchID uint) ([]TaskInfo, error) {
+	tasks, err := listCourseTasks(courseID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	extensions := map[uint]*models.TaskExtensions{}
+	if userID != 0 && len(tasks) > 0 {
+		taskIDs := make([]uint, 0, len(tasks))
+		for _, task := range tasks {
+			taskIDs = append(taskIDs, task.TaskID)
+		}
+		if extensions, err = studentExtensions(taskIDs, branchID, userID); err != nil {
+			return nil, err
+		}
 	}
 
 	taskInfos := make([]TaskInfo, 0, len(tasks))
 	for _, task := range tasks {
-		taskInfos = append(taskInfos, TaskInfo{
-			TaskID:      task.TaskID,
-			LessonID:    task.LessonID,
-			TaskTitle:   task.TaskTitle,
-			Description: task.Description,
-			TaskType:    task.TaskType,
-			MaxScore:    task.MaxScore,
-			CreatedAt:   task.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:   task.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+		info := taskInfo(&task)
+		if extension, ok := extensions[task.TaskID]; ok {
+			window := extendedWindow(&task, extension)
+			info.DueAt = formatOptionalTime(window.DueAt)
+			info.CutoffAt = formatOptionalTime(window.CutoffAt)
+			info.Extended = true
+		}
+		taskInfos = append(taskInfos, *info)
 	}
 
 	return taskInfos, nil
 }
 
+// listCourseTasks 查询课程的所有任务，publishedOnly 为 true 时只查询已发布课时下的任务
+func listCourseTasks(courseID uint, publishedOnly bool) ([]models.Tasks, error) {
+	// 通过lessons表关联查询
+	query := database.GetCentralDB().Joins("JOIN lessons ON tasks.lesson_id = lessons.lesson_id").
+		Where("lessons.course_id = ?", courseID)
+	if publishedOnly {
+		query = query.Scopes(visibleLessons)
+	}
+
+	var tasks []models.Tasks
+	if err := query.
+		Order("tasks.created_at DESC").
+		Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return tasks, nil
+}
+
 // ListTasksByLesson 获取课程的所有任务
 func (s *TaskService) ListTasksByLesson(lessonID uint) ([]TaskInfo, error) {
 	db := database.GetCentralDB()
@@ -150,18 +402,56 @@ func (s *TaskService) ListTasksByLesson(lessonID uint) ([]TaskInfo, error) {
 
 	taskInfos := make([]TaskInfo, 0, len(tasks))
 	for _, task := range tasks {
-		taskInfos = append(taskInfos, TaskInfo{
-			TaskID:      task.TaskID,
-			LessonID:    task.LessonID,
-			TaskTitle:   task.TaskTitle,
-			Description: task.Description,
-			TaskType:    task.TaskType,
-			MaxScore:    task.MaxScore,
-			CreatedAt:   task.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:   task.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+		taskInfos = append(taskInfos, *taskInfo(&task))
 	}
 
 	return taskInfos, nil
 }
 
+// taskInfo 转换为任务信息
+func taskInfo(task *models.Tasks) *TaskInfo {
+	return &TaskInfo{
+		TaskID:             task.TaskID,
+		LessonID:           task.LessonID,
+		TaskTitle:          task.TaskTitle,
+		Description:        task.Description,
+		TaskType:           task.TaskType,
+		MaxScore:           task.MaxScore,
+		AllowedTypes:       splitTaskTypes(task.AllowedTypes),
+		MaxFileSize:        task.MaxFileSize,
+		DueAt:              formatOptionalTime(task.DueAt),
+		CutoffAt:           formatOptionalTime(task.CutoffAt),
+		LatePolicy:         task.LatePolicy,
+		LatePenaltyPercent: task.LatePenaltyPercent,
+		MaxAttempts:        task.MaxAttempts,
+		AttemptPolicy:      attemptPolicy(task),
+		CreatedAt:          task.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:          task.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// validateTaskAttempts 校验最大提交次数和计分策略
+func validateTaskAttempts(task *models.Tasks) error {
+	if task.MaxAttempts < 0 {
+		return apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "最大提交次数不能为负数")
+	}
+	if !attempt.ValidPolicy(task.AttemptPolicy) {
+		return apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "计分策略无效，可选 latest、highest、first")
+	}
+	return nil
+}
+
+func isValidTaskType(taskType string) bool {
+	return taskType == TaskTypeEssay || taskType == TaskTypeQuiz || taskType == TaskTypeUpload
+}
+
+// splitTaskTypes 将任务保存的类型列表转换为数组
+func splitTaskTypes(allowedTypes string) []string {
+	types := []string{}
+	for _, t := range strings.Split(allowedTypes, ",") {
+		if t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
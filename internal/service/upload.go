@@ -0,0 +1,696 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/config"
+	"online-learning-platform/internal/database"
+	apperrors "online-learning-platform/internal/errors"
+	"online-learning-platform/internal/logger"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/internal/oss"
+	"online-learning-platform/internal/upload"
+)
+
+// 上传用途：课时文件的上传记录在中央服务器，作业文件的在学生所在的分支节点
+const (
+	UploadPurposeLesson = "lesson"
+	UploadPurposeAnswer = "answer"
+)
+
+// 上传状态
+const (
+	UploadStatusPending   = "pending"
+	UploadStatusCompleted = "completed"
+	UploadStatusAttached  = "attached"
+	UploadStatusRejected  = "rejected"
+	UploadStatusAborted   = "aborted"
+)
+
+const (
+	// uploadURLExpiry 上传地址的有效期，过期后可以通过 GetUpload 重新获取
+	uploadURLExpiry = 2 * time.Hour
+	// uploadSlotTTL 上传位置的有效期，超过后需要重新申请
+	uploadSlotTTL = 24 * time.Hour
+	// uploadStagingPrefix 客户端上传使用的暂存前缀，确认时复制到最终Key
+	uploadStagingPrefix = "uploads/staging/"
+)
+
+// 默认的上传限制，可以在配置文件的 upload 中覆盖
+var (
+	// defaultLessonUploadPolicies 按课时类型限制课时文件，未列出的课时类型不能上传文件
+	defaultLessonUploadPolicies = map[string]upload.Policy{
+		"video": {AllowedTypes: []string{"video/mp4", "video/webm"}, MaxSize: 2 << 30},
+		"text":  {AllowedTypes: []string{"application/pdf", "text/markdown", "text/plain"}, MaxSize: 50 << 20},
+	}
+	// defaultAnswerUploadPolicy 作业文件的限制，任务可以在此范围内收紧
+	defaultAnswerUploadPolicy = upload.Policy{
+		AllowedTypes: []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf"},
+		MaxSize:      20 << 20,
+	}
+	// defaultCourseAssetPolicy Markdown 正文引用的资源文件的限制
+	defaultCourseAssetPolicy = upload.Policy{
+		AllowedTypes: []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf"},
+		MaxSize:      20 << 20,
+	}
+)
+
+// 恶意文件扫描，默认不扫描，由 InitUploadScanner 按配置设置
+var (
+	uploadScanner     upload.Scanner = upload.NopScanner{}
+	uploadScanMaxSize int64          = 25 << 20
+	uploadScanTimeout                = time.Minute
+)
+
+// InitUploadScanner 按配置初始化确认上传时使用的扫描器
+func InitUploadScanner(cfg config.ScannerConfig) error {
+	if cfg.MaxSize > 0 {
+		uploadScanMaxSize = cfg.MaxSize
+	}
+	if cfg.Timeout != "" {
+		timeout, err := time.ParseDuration(cfg.Timeout)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid scanner timeout %q", cfg.Timeout)
+		}
+		uploadScanTimeout = timeout
+	}
+
+	switch cfg.Type {
+	case "", "none":
+		uploadScanner = upload.NopScanner{}
+	case "clamav":
+		scanner, err := upload.NewClamAVScanner(cfg.Address, uploadScanTimeout)
+		if err != nil {
+			return err
+		}
+		uploadScanner = scanner
+	default:
+		return fmt.Errorf("unsupported scanner type %q", cfg.Type)
+	}
+	return nil
+}
+
+// UploadService 直传对象存储的上传服务
+type UploadService struct{}
+
+// NewUploadService 创建实例
+func NewUploadService() *UploadService {
+	return &UploadService{}
+}
+
+// CreateUploadRequest 申请上传位置请求
+type CreateUploadRequest struct {
+	FileName    string `json:"file_name" binding:"required"`
+	ContentType string `json:"content_type" binding:"required"`
+	Size        int64  `json:"size" binding:"required,gt=0"`
+	SHA256      string `json:"sha256" binding:"required"` // 文件内容的 SHA-256（十六进制），确认时校验
+	LessonType  string `json:"lesson_type"`               // 课时文件对应的课时类型（video、text），决定允许的类型和大小，默认 video
+}
+
+// UploadPartURL 分片的上传地址
+type UploadPartURL struct {
+	PartNumber int    `json:"part_number"`
+	URL        string `json:"url"`
+}
+
+// UploadSlot 上传位置
+// PartCount 为 1 时直接 PUT 到 URL（需带上与申请时相同的 Content-Type 请求头）；
+// 否则按 PartSize 切分文件，将第 n 个分片 PUT 到对应的分片地址（不带 Content-Type）。
+// 中断后重新获取上传位置，Parts 只包含尚未上传的分片
+type UploadSlot struct {
+	UploadID      uint            `json:"upload_id"`
+	Purpose       string          `json:"purpose"`
+	FileName      string          `json:"file_name"`
+	ContentType   string          `json:"content_type"`
+	Size          int64           `json:"size"`
+	Status        string          `json:"status"`
+	PartSize      int64           `json:"part_size"`
+	PartCount     int             `json:"part_count"`
+	URL           string          `json:"url,omitempty"`
+	Parts         []UploadPartURL `json:"parts,omitempty"`
+	UploadedParts []int           `json:"uploaded_parts,omitempty"`
+	URLExpiresAt  string          `json:"url_expires_at,omitempty"`
+	ExpiresAt     string          `json:"expires_at"`
+}
+
+// CreateLessonUpload 教师为章节申请课时文件的上传位置
+func (s *UploadService) CreateLessonUpload(courseID, chapterID, userID, branchID uint, req *CreateUploadRequest) (*UploadSlot, error) {
+	if err := validateCourseOwner(courseID, userID, branchID); err != nil {
+		return nil, err
+	}
+	db := database.GetCentralDB()
+	if _, err := findChapter(db, courseID, chapterID); err != nil {
+		return nil, err
+	}
+	lessonType := req.LessonType
+	if lessonType == "" {
+		lessonType = "video"
+	}
+	policy, ok := lessonUploadPolicy(lessonType)
+	if !ok {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "该课时类型不支持上传文件")
+	}
+
+	record := &models.Uploads{
+		Purpose:   UploadPurposeLesson,
+		UserID:    userID,
+		BranchID:  branchID,
+		CourseID:  courseID,
+		ChapterID: chapterID,
+	}
+	return createUpload(db, record, req, policy, func(fileName string) string {
+		return fmt.Sprintf("courses/%d/chapters/%d/lessons/%d_%s", courseID, chapterID, time.Now().Unix(), fileName)
+	})
+}
+
+// CreateAnswerUpload 学生为任务申请作业文件的上传位置
+func (s *UploadService) CreateAnswerUpload(taskID, userID, branchID uint, req *CreateUploadRequest) (*UploadSlot, error) {
+	task, courseID, err := findSubmittableTask(userID, branchID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.Uploads{
+		Purpose:  UploadPurposeAnswer,
+		UserID:   userID,
+		BranchID: branchID,
+		CourseID: courseID,
+		TaskID:   task.TaskID,
+	}
+	return createUpload(branchDB, record, req, taskUploadPolicy(task), func(fileName string) string {
+		return fmt.Sprintf("answers/%d/%d/%d/%d_%s", branchID, userID, taskID, time.Now().Unix(), fileName)
+	})
+}
+
+// GetUpload 查询上传进度，未完成时返回尚未上传分片的新地址，用于断点续传
+func (s *UploadService) GetUpload(purpose string, uploadID, userID, branchID uint) (*UploadSlot, error) {
+	db, err := uploadDB(purpose, branchID)
+	if err != nil {
+		return nil, err
+	}
+	record, err := findUpload(db, purpose, uploadID, userID, branchID)
+	if err != nil {
+		return nil, err
+	}
+	return toUploadSlot(record)
+}
+
+// CompleteUpload 确认上传完成：合并分片，把暂存对象复制到最终Key后校验大小、类型和 SHA-256
+// 签名上传地址在有效期内仍可写入暂存Key，只校验复制后的对象，确认后客户端无法再改写
+// 校验失败时删除对象，需要重新申请上传位置
+func (s *UploadService) CompleteUpload(purpose string, uploadID, userID, branchID uint) (*UploadSlot, error) {
+	db, err := uploadDB(purpose, branchID)
+	if err != nil {
+		return nil, err
+	}
+	record, err := findUpload(db, purpose, uploadID, userID, branchID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != UploadStatusPending {
+		return toUploadSlot(record)
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeUploadNotFound, "上传已过期，请重新申请")
+	}
+
+	stagingKey := uploadStagingKey(record.ObjectKey)
+	if record.StorageUploadID != "" {
+		parts, err := oss.ListParts(stagingKey, record.StorageUploadID)
+		if err != nil {
+			return nil, err
+		}
+		if len(parts) != record.PartCount || parts[len(parts)-1].PartNumber != record.PartCount {
+			return nil, apperrors.NewAppError(apperrors.ErrCodeUploadIncomplete,
+				fmt.Sprintf("已上传 %d/%d 个分片", len(parts), record.PartCount))
+		}
+		if err := oss.CompleteMultipart(stagingKey, record.StorageUploadID, parts); err != nil {
+			return nil, err
+		}
+		// 分片已合并，重试确认时直接校验对象
+		if err := db.Model(record).Update("storage_upload_id", "").Error; err != nil {
+			return nil, fmt.Errorf("failed to update upload: %w", err)
+		}
+	}
+	if err := promoteStagedObject(record); err != nil {
+		return nil, err
+	}
+
+	if err := verifyUploadedObject(record); err != nil {
+		var appErr *apperrors.AppError
+		if !errors.As(err, &appErr) || appErr.Code != apperrors.ErrCodeUploadRejected {
+			return nil, err
+		}
+		if delErr := oss.DeleteObject(record.ObjectKey); delErr != nil {
+			logger.Warnf("Failed to delete rejected upload %d: %v", record.UploadID, delErr)
+		}
+		if dbErr := db.Model(record).Update("status", UploadStatusRejected).Error; dbErr != nil {
+			return nil, fmt.Errorf("failed to update upload: %w", dbErr)
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := db.Model(record).Updates(map[string]interface{}{
+		"status":       UploadStatusCompleted,
+		"completed_at": now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update upload: %w", err)
+	}
+	record.Status = UploadStatusCompleted
+	record.CompletedAt = &now
+	return toUploadSlot(record)
+}
+
+// AbortUpload 取消未关联的上传，删除已上传的分片或对象
+func (s *UploadService) AbortUpload(purpose string, uploadID, userID, branchID uint) error {
+	db, err := uploadDB(purpose, branchID)
+	if err != nil {
+		return err
+	}
+	record, err := findUpload(db, purpose, uploadID, userID, branchID)
+	if err != nil {
+		return err
+	}
+	switch record.Status {
+	case UploadStatusAttached:
+		return apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "文件已关联，不能取消")
+	case UploadStatusAborted, UploadStatusRejected:
+		return nil
+	}
+
+	if record.Status == UploadStatusPending && record.StorageUploadID != "" {
+		if err := oss.AbortMultipart(uploadStagingKey(record.ObjectKey), record.StorageUploadID); err != nil {
+			return err
+		}
+	}
+	for _, key := range []string{uploadStagingKey(record.ObjectKey), record.ObjectKey} {
+		if err := oss.DeleteObject(key); err != nil {
+			return err
+		}
+	}
+	if err := db.Model(record).Update("status", UploadStatusAborted).Error; err != nil {
+		return fmt.Errorf("failed to update upload: %w", err)
+	}
+	return nil
+}
+
+// createUpload 按限制校验申请信息，初始化分片上传并保存上传记录
+func createUpload(db *gorm.DB, record *models.Uploads, req *CreateUploadRequest, policy upload.Policy, objectKey func(fileName string) string) (*UploadSlot, error) {
+	contentType, _, err := mime.ParseMediaType(req.ContentType)
+	if err != nil {
+		return nil, uploadPolicyError(policy, upload.ErrTypeNotAllowed)
+	}
+	if err := policy.Check(contentType, req.Size); err != nil {
+		return nil, uploadPolicyError(policy, err)
+	}
+	if !upload.ValidSHA256(req.SHA256) {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "sha256 必须是 64 位十六进制字符串")
+	}
+	plan, err := upload.PlanParts(req.Size)
+	if err != nil {
+		return nil, apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "文件大小无效")
+	}
+
+	fileName := upload.SanitizeFileName(req.FileName, contentType)
+	record.ObjectKey = objectKey(fileName)
+	record.FileName = fileName
+	record.ContentType = contentType
+	record.Size = req.Size
+	record.SHA256 = strings.ToLower(req.SHA256)
+	record.PartSize = plan.PartSize
+	record.PartCount = plan.PartCount
+	record.Status = UploadStatusPending
+	record.ExpiresAt = time.Now().Add(uploadSlotTTL)
+
+	if plan.Multipart() {
+		record.StorageUploadID, err = oss.InitMultipart(uploadStagingKey(record.ObjectKey), contentType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.Create(record).Error; err != nil {
+		if record.StorageUploadID != "" {
+			_ = oss.AbortMultipart(uploadStagingKey(record.ObjectKey), record.StorageUploadID)
+		}
+		return nil, fmt.Errorf("failed to create upload: %w", err)
+	}
+	return toUploadSlot(record)
+}
+
+// attachUpload 将已校验的上传关联到课时或作业，返回对象地址
+// 在调用方的事务中把状态改为 attached，同一个上传只能使用一次；
+// match 校验上传属于当前课时或任务，policy 校验文件符合当前课时或任务的限制（申请后限制可能已修改）
+func attachUpload(tx *gorm.DB, purpose string, uploadID, userID, branchID uint, match func(*models.Uploads) bool, policy upload.Policy) (string, error) {
+	record, err := findUpload(tx, purpose, uploadID, userID, branchID)
+	if err != nil {
+		return "", err
+	}
+	if !match(record) {
+		return "", apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "上传的文件不属于当前课时或任务")
+	}
+	if err := policy.Check(record.ContentType, record.Size); err != nil {
+		return "", uploadPolicyError(policy, err)
+	}
+	if record.Status != UploadStatusCompleted {
+		if record.Status == UploadStatusPending {
+			return "", apperrors.ErrUploadIncomplete
+		}
+		return "", apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "上传的文件不可用")
+	}
+	// 过期未关联的文件会被存储清理任务删除
+	if time.Now().After(record.ExpiresAt) {
+		return "", apperrors.NewAppError(apperrors.ErrCodeUploadNotFound, "上传已过期，请重新上传")
+	}
+
+	result := tx.Model(&models.Uploads{}).
+		Where("upload_id = ? AND status = ?", record.UploadID, UploadStatusCompleted).
+		Update("status", UploadStatusAttached)
+	if result.Error != nil {
+		return "", fmt.Errorf("failed to attach upload: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return "", apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "上传的文件不可用")
+	}
+	return oss.ObjectURL(record.ObjectKey)
+}
+
+// uploadStagingKey 客户端上传的暂存Key
+func uploadStagingKey(objectKey string) string {
+	return uploadStagingPrefix + objectKey
+}
+
+// promoteStagedObject 把暂存对象复制到最终Key并删除暂存对象
+// 暂存对象不存在时（之前的确认已复制，或尚未上传）由后续校验判断最终Key是否存在
+func promoteStagedObject(record *models.Uploads) error {
+	stagingKey := uploadStagingKey(record.ObjectKey)
+	exists, err := oss.ObjectExists(stagingKey)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+	if _, err := oss.CopyObject(stagingKey, record.ObjectKey); err != nil {
+		return err
+	}
+	if err := oss.DeleteObject(stagingKey); err != nil {
+		logger.Warnf("Failed to delete staged upload %d: %v", record.UploadID, err)
+	}
+	return nil
+}
+
+// verifyUploadedObject 流式读取对象，比对大小、SHA-256 和文件类型
+func verifyUploadedObject(record *models.Uploads) error {
+	info, err := oss.StatObject(record.ObjectKey)
+	if err != nil {
+		if errors.Is(err, oss.ErrObjectNotFound) {
+			return apperrors.ErrUploadIncomplete
+		}
+		return err
+	}
+	if info.Size != record.Size {
+		return rejectUpload(upload.ErrSizeMismatch)
+	}
+
+	rc, err := oss.GetObject(record.ObjectKey)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	result, err := upload.Inspect(rc)
+	if err != nil {
+		return fmt.Errorf("failed to read upload %d: %w", record.UploadID, err)
+	}
+
+	if err := upload.Verify(result, upload.Expected{
+		Size:        record.Size,
+		SHA256:      record.SHA256,
+		ContentType: record.ContentType,
+	}); err != nil {
+		return rejectUpload(err)
+	}
+	return scanUploadedObject(record)
+}
+
+// scanUploadedObject 交给扫描器检查对象内容
+func scanUploadedObject(record *models.Uploads) error {
+	return scanContent(fmt.Sprintf("upload %d by user %d", record.UploadID, record.UserID), record.Size,
+		func() (io.ReadCloser, error) { return oss.GetObject(record.ObjectKey) })
+}
+
+// scanContent 交给扫描器检查内容，超过扫描大小的文件跳过；name 用于日志
+func scanContent(name string, size int64, open func() (io.ReadCloser, error)) error {
+	if _, ok := uploadScanner.(upload.NopScanner); ok {
+		return nil
+	}
+	if size > uploadScanMaxSize {
+		logger.Infof("Scan of %s (%d bytes) skipped: exceeds scan size limit", name, size)
+		return nil
+	}
+
+	rc, err := open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadScanTimeout)
+	defer cancel()
+	threat, err := uploadScanner.Scan(ctx, rc)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", name, err)
+	}
+	if threat != "" {
+		logger.Warnf("Scanner rejected %s: %s", name, threat)
+		return rejectUpload(upload.ErrThreatDetected)
+	}
+	return nil
+}
+
+// checkImportedFile 课程包导入和 Markdown 同步的文件不经过上传接口，按同样的步骤检查：
+// 按扩展名确定类型后校验限制，读取内容比对嗅探到的类型，再交给扫描器；返回文件类型
+// 不符合限制或未通过校验时返回 AppError，调用方跳过该文件所属的课时
+func checkImportedFile(policy upload.Policy, name string, open func() (io.ReadCloser, error)) (string, error) {
+	contentType := upload.TypeByExtension(name)
+	if !policy.Allows(contentType) {
+		return "", uploadPolicyError(policy, upload.ErrTypeNotAllowed)
+	}
+
+	rc, err := open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	result, err := upload.Inspect(rc)
+	rc.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if err := policy.Check(contentType, result.Size); err != nil {
+		return "", uploadPolicyError(policy, err)
+	}
+	if !upload.TypeMatches(contentType, result.ContentType) {
+		return "", rejectUpload(upload.ErrTypeMismatch)
+	}
+	if err := scanContent(name, result.Size, open); err != nil {
+		return "", err
+	}
+	return contentType, nil
+}
+
+// importRejection 返回 checkImportedFile 校验失败的原因，读取或扫描出错时返回 false
+func importRejection(err error) (string, bool) {
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) {
+		return "", false
+	}
+	return appErr.Message, true
+}
+
+// rejectUpload 校验失败的错误
+func rejectUpload(err error) error {
+	var msg string
+	switch err {
+	case upload.ErrSizeMismatch:
+		msg = "文件大小与申请时不一致"
+	case upload.ErrChecksumMismatch:
+		msg = "文件校验和与申请时不一致"
+	case upload.ErrTypeMismatch:
+		msg = "文件内容与声明的类型不一致"
+	case upload.ErrThreatDetected:
+		msg = "文件未通过安全扫描"
+	default:
+		msg = "文件校验失败"
+	}
+	return apperrors.WrapError(apperrors.ErrCodeUploadRejected, msg, err)
+}
+
+// findUpload 查询当前用户的上传记录
+func findUpload(db *gorm.DB, purpose string, uploadID, userID, branchID uint) (*models.Uploads, error) {
+	var record models.Uploads
+	if err := db.Where("upload_id = ? AND purpose = ? AND user_id = ? AND branch_id = ?",
+		uploadID, purpose, userID, branchID).First(&record).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.ErrUploadNotFound
+		}
+		return nil, fmt.Errorf("failed to query upload: %w", err)
+	}
+	return &record, nil
+}
+
+// uploadDB 返回上传记录所在的数据库
+func uploadDB(purpose string, branchID uint) (*gorm.DB, error) {
+	if purpose == UploadPurposeLesson {
+		return database.GetCentralDB(), nil
+	}
+	return database.GetBranchDBByBranchID(branchID)
+}
+
+// toUploadSlot 转换为返回结构，等待上传时生成新的上传地址
+func toUploadSlot(record *models.Uploads) (*UploadSlot, error) {
+	slot := &UploadSlot{
+		UploadID:    record.UploadID,
+		Purpose:     record.Purpose,
+		FileName:    record.FileName,
+		ContentType: record.ContentType,
+		Size:        record.Size,
+		Status:      record.Status,
+		PartSize:    record.PartSize,
+		PartCount:   record.PartCount,
+		ExpiresAt:   record.ExpiresAt.Format("2006-01-02 15:04:05"),
+	}
+	if record.Status != UploadStatusPending || time.Now().After(record.ExpiresAt) {
+		return slot, nil
+	}
+	slot.URLExpiresAt = time.Now().Add(uploadURLExpiry).Format("2006-01-02 15:04:05")
+
+	if record.StorageUploadID == "" {
+		url, err := oss.SignPutURL(uploadStagingKey(record.ObjectKey), record.ContentType, uploadURLExpiry)
+		if err != nil {
+			return nil, err
+		}
+		slot.URL = url
+		return slot, nil
+	}
+
+	parts, err := oss.ListParts(uploadStagingKey(record.ObjectKey), record.StorageUploadID)
+	if err != nil {
+		return nil, err
+	}
+	uploaded := make(map[int]bool, len(parts))
+	for _, p := range parts {
+		uploaded[p.PartNumber] = true
+		slot.UploadedParts = append(slot.UploadedParts, p.PartNumber)
+	}
+	for n := 1; n <= record.PartCount; n++ {
+		if uploaded[n] {
+			continue
+		}
+		url, err := oss.SignPartURL(uploadStagingKey(record.ObjectKey), record.StorageUploadID, n, uploadURLExpiry)
+		if err != nil {
+			return nil, err
+		}
+		slot.Parts = append(slot.Parts, UploadPartURL{PartNumber: n, URL: url})
+	}
+	return slot, nil
+}
+
+// uploadPolicyError 不符合上传限制的错误
+func uploadPolicyError(policy upload.Policy, err error) error {
+	if err == upload.ErrTooLarge {
+		return apperrors.NewAppError(apperrors.ErrCodeInvalidParam,
+			fmt.Sprintf("文件大小不能超过 %s", formatFileSize(policy.MaxSize)))
+	}
+	return apperrors.NewAppError(apperrors.ErrCodeInvalidParam,
+		fmt.Sprintf("不支持的文件类型，允许：%s", strings.Join(policy.AllowedTypes, ", ")))
+}
+
+// formatFileSize 以 KB、MB 或 GB 显示大小
+func formatFileSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%gGB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%gMB", float64(size)/(1<<20))
+	default:
+		return fmt.Sprintf("%gKB", float64(size)/(1<<10))
+	}
+}
+
+// lessonUploadPolicy 课时类型对应的限制，配置中的项覆盖默认值
+func lessonUploadPolicy(lessonType string) (upload.Policy, bool) {
+	policy, ok := defaultLessonUploadPolicies[lessonType]
+	if cfg := config.GetConfig(); cfg != nil {
+		if limit, configured := cfg.Upload.Lesson[lessonType]; configured {
+			policy, ok = applyUploadLimit(policy, limit), true
+		}
+	}
+	return policy, ok && len(policy.AllowedTypes) > 0
+}
+
+// courseAssetUploadPolicy Markdown 正文引用的资源文件的限制
+func courseAssetUploadPolicy() upload.Policy {
+	policy := defaultCourseAssetPolicy
+	if cfg := config.GetConfig(); cfg != nil {
+		policy = applyUploadLimit(policy, cfg.Upload.Asset)
+	}
+	return policy
+}
+
+// answerUploadPolicy 作业文件的全局限制
+func answerUploadPolicy() upload.Policy {
+	policy := defaultAnswerUploadPolicy
+	if cfg := config.GetConfig(); cfg != nil {
+		policy = applyUploadLimit(policy, cfg.Upload.Answer)
+	}
+	return policy
+}
+
+// taskUploadPolicy 任务的作业文件限制：在全局限制内按任务设置收紧
+func taskUploadPolicy(task *models.Tasks) upload.Policy {
+	return answerUploadPolicy().Narrow(splitTaskTypes(task.AllowedTypes), task.MaxFileSize)
+}
+
+// applyUploadLimit 用配置覆盖限制，未配置的项保持不变
+func applyUploadLimit(policy upload.Policy, limit config.UploadLimitConfig) upload.Policy {
+	if len(limit.AllowedTypes) > 0 {
+		policy.AllowedTypes = limit.AllowedTypes
+	}
+	if limit.MaxSize > 0 {
+		policy.MaxSize = limit.MaxSize
+	}
+	return policy
+}
+
+// validateTaskUploadLimits 校验任务的作业文件限制只在全局限制内收紧，返回保存用的类型列表
+func validateTaskUploadLimits(allowedTypes []string, maxFileSize int64) (string, error) {
+	types, err := upload.ParseTypes(strings.Join(allowedTypes, ","))
+	if err != nil {
+		return "", apperrors.NewAppError(apperrors.ErrCodeInvalidParam, "allowed_types 包含无效的文件类型")
+	}
+	policy := answerUploadPolicy()
+	for _, t := range types {
+		if !policy.Allows(t) {
+			return "", apperrors.NewAppError(apperrors.ErrCodeInvalidParam,
+				fmt.Sprintf("不支持的文件类型 %s，允许：%s", t, strings.Join(policy.AllowedTypes, ", ")))
+		}
+	}
+	if maxFileSize < 0 || (policy.MaxSize > 0 && maxFileSize > policy.MaxSize) {
+		return "", apperrors.NewAppError(apperrors.ErrCodeInvalidParam,
+			fmt.Sprintf("max_file_size 不能超过 %s", formatFileSize(policy.MaxSize)))
+	}
+	return strings.Join(types, ","), nil
+}
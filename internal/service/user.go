@@ -182,9 +182,9 @@ func (s *UserService) Login(req *LoginRequest) (*LoginResponse, error) {
 }
 
 // GetUserInfo 获取用户信息
-func (s *UserService) GetUserInfo(userID uint) (*UserInfo, error) {
-	// 根据user_id找到对应的分支节点
-	branchDB, err := database.GetBranchDBByUserID(userID)
+// branchID 来自JWT，用户ID只在所属分支内唯一，因此直接路由到对应分支节点
+func (s *UserService) GetUserInfo(userID, branchID uint) (*UserInfo, error) {
+	branchDB, err := database.GetBranchDBByBranchID(branchID)
 	if err != nil {
 		return nil, apperrors.ErrUserNotFound
 	}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"online-learning-platform/internal/config"
+	"online-learning-platform/internal/database"
+	"online-learning-platform/internal/logger"
+	"online-learning-platform/internal/models"
+	"online-learning-platform/internal/oss"
+	"online-learning-platform/internal/video"
+)
+
+// 课时视频处理状态；外部链接和非视频课时的状态为空
+const (
+	VideoStatusPending    = "pending"
+	VideoStatusProcessing = "processing"
+	VideoStatusReady      = "ready"
+	VideoStatusFailed     = "failed"
+)
+
+const (
+	// videoProcessingStale 处理中的课时超过该时长仍未完成时视为处理实例已退出，重新处理
+	videoProcessingStale = 2 * time.Hour
+	// maxProcessingError 保存的错误信息长度
+	maxProcessingError = 1000
+)
+
+// videoProcessingColumns 由视频处理任务写入的列，教师保存课时但未更换视频时不覆盖
+var videoProcessingColumns = []string{
+	"processing_status", "processing_error", "processing_started_at", "duration", "thumbnail_url", "hls_url",
+}
+
+// queueVideoProcessing 课时的视频更换后清空处理结果；本Bucket中的视频进入处理队列
+func queueVideoProcessing(lesson *models.Lessons) {
+	lesson.ProcessingStatus = ""
+	lesson.ProcessingError = ""
+	lesson.ProcessingStartedAt = nil
+	lesson.Duration = 0
+	lesson.ThumbnailURL = ""
+	lesson.HLSURL = ""
+	if lesson.LessonType != LessonTypeVideo {
+		return
+	}
+	if _, ok := oss.ObjectKeyFromURL(lesson.ContentURL); ok {
+		lesson.ProcessingStatus = VideoStatusPending
+	}
+}
+
+// copyVideoProcessing 复制处理结果（引用同一个视频对象时处理结果仍然有效）
+func copyVideoProcessing(dst, src *models.Lessons) {
+	dst.ProcessingStatus = src.ProcessingStatus
+	dst.ProcessingError = src.ProcessingError
+	dst.ProcessingStartedAt = nil
+	dst.Duration = src.Duration
+	dst.ThumbnailURL = src.ThumbnailURL
+	dst.HLSURL = src.HLSURL
+	if dst.ProcessingStatus == VideoStatusProcessing {
+		dst.ProcessingStatus = VideoStatusPending
+	}
+}
+
+// VideoService 课时视频处理：读取时长、截取封面并转码为多码率 HLS
+type VideoService struct {
+	transcoder *video.Transcoder
+	workers    int
+	tempDir    string
+}
+
+// NewVideoService 创建实例
+func NewVideoService(cfg config.VideoConfig) *VideoService {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	return &VideoService{
+		transcoder: video.NewTranscoder(cfg.FFmpegPath, cfg.FFprobePath),
+		workers:    workers,
+		tempDir:    cfg.TempDir,
+	}
+}
+
+// Start 定时处理待处理的视频，直到 stop 被关闭
+// 关闭时中止正在进行的转码，课时重新回到待处理状态
+func (s *VideoService) Start(interval time.Duration, stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := s.ProcessPending(ctx)
+		if err != nil {
+			logger.WithError(err).Error("Video processing failed")
+		}
+		if count > 0 {
+			logger.Infof("Video processing: %d lessons processed", count)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// ProcessPending 处理所有待处理的视频，每批最多同时处理 workers 个，返回处理的课时数
+func (s *VideoService) ProcessPending(ctx context.Context) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		lessonIDs, err := claimVideoLessons(s.workers)
+		if err != nil {
+			return total, err
+		}
+		if len(lessonIDs) == 0 {
+			break
+		}
+
+		var wg sync.WaitGroup
+		for _, lessonID := range lessonIDs {
+			wg.Add(1)
+			go func(lessonID uint) {
+				defer wg.Done()
+				s.processLesson(ctx, lessonID)
+			}(lessonID)
+		}
+		wg.Wait()
+		total += len(lessonIDs)
+	}
+	return total, nil
+}
+
+// claimVideoLessons 将最多 limit 个待处理的课时标记为处理中
+// 条件更新保证多个实例同时运行时同一课时只被一个实例处理
+func claimVideoLessons(limit int) ([]uint, error) {
+	db := database.GetCentralDB()
+	now := time.Now()
+	claimable := "(processing_status = ? OR (processing_status = ? AND processing_started_at < ?))"
+	stale := now.Add(-videoProcessingStale)
+
+	var candidates []uint
+	if err := db.Model(&models.Lessons{}).
+		Where(claimable, VideoStatusPending, VideoStatusProcessing, stale).
+		Order("lesson_id ASC").
+		Limit(limit).
+		Pluck("lesson_id", &candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to query pending videos: %w", err)
+	}
+
+	claimed := make([]uint, 0, len(candidates))
+	for _, lessonID := range candidates {
+		result := db.Model(&models.Lessons{}).
+			Where("lesson_id = ?", lessonID).
+			Where(claimable, VideoStatusPending, VideoStatusProcessing, stale).
+			Updates(map[string]interface{}{
+				"processing_status":     VideoStatusProcessing,
+				"processing_started_at": now,
+			})
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to claim lesson %d: %w", lessonID, result.Error)
+		}
+		if result.RowsAffected == 1 {
+			claimed = append(claimed, lessonID)
+		}
+	}
+	return claimed, nil
+}
+
+// videoResult 一个视频的处理结果
+type videoResult struct {
+	duration     int
+	thumbnailURL string
+	hlsURL       string
+}
+
+// processLesson 处理一个课时的视频，结果只在课时仍引用同一个视频时写入
+func (s *VideoService) processLesson(ctx context.Context, lessonID uint) {
+	db := database.GetCentralDB()
+
+	var lesson models.Lessons
+	if err := db.Where("lesson_id = ?", lessonID).First(&lesson).Error; err != nil {
+		logger.Warnf("Video processing: failed to load lesson %d: %v", lessonID, err)
+		return
+	}
+	// 只更新仍由本次处理负责的课时
+	current := func() *gorm.DB {
+		return db.Model(&models.Lessons{}).Where("lesson_id = ? AND content_url = ? AND processing_status = ?",
+			lessonID, lesson.ContentURL, VideoStatusProcessing)
+	}
+
+	objectKey, ok := oss.ObjectKeyFromURL(lesson.ContentURL)
+	if lesson.LessonType != LessonTypeVideo || !ok {
+		current().Update("processing_status", "")
+		return
+	}
+
+	started := time.Now()
+	result, err := s.transcode(ctx, objectKey)
+	if err != nil {
+		if ctx.Err() != nil {
+			current().Update("processing_status", VideoStatusPending)
+			return
+		}
+		logger.Warnf("Video processing: lesson %d failed: %v", lessonID, err)
+		msg := err.Error()
+		if len(msg) > maxProcessingError {
+			msg = msg[:maxProcessingError]
+		}
+		current().Updates(map[string]interface{}{
+			"processing_status": VideoStatusFailed,
+			"processing_error":  strings.ToValidUTF8(msg, ""),
+		})
+		return
+	}
+
+	// 处理期间教师可能更换了视频，此时结果不写入，上传的对象由存储清理任务删除
+	update := current().Updates(map[string]interface{}{
+		"processing_status": VideoStatusReady,
+		"processing_error":  "",
+		"duration":          result.duration,
+		"thumbnail_url":     result.thumbnailURL,
+		"hls_url":           result.hlsURL,
+	})
+	if update.Error != nil {
+		logger.Warnf("Video processing: failed to save lesson %d: %v", lessonID, update.Error)
+		return
+	}
+	if update.RowsAffected == 0 {
+		logger.Infof("Video processing: lesson %d changed during processing, result discarded", lessonID)
+		return
+	}
+	logger.Infof("Video processing: lesson %d ready in %s", lessonID, time.Since(started).Round(time.Second))
+}
+
+// transcode 下载原视频，生成封面和 HLS 并上传到原视频旁边的目录
+func (s *VideoService) transcode(ctx context.Context, objectKey string) (*videoResult, error) {
+	dir, err := os.MkdirTemp(s.tempDir, "lesson-video-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "source"+path.Ext(objectKey))
+	if err := downloadObject(objectKey, src); err != nil {
+		return nil, err
+	}
+
+	probe, err := s.transcoder.Probe(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	out := filepath.Join(dir, "hls")
+	renditions := video.SelectRenditions(video.DefaultLadder, probe.Height)
+	if err := s.transcoder.HLS(ctx, src, out, renditions, probe); err != nil {
+		return nil, err
+	}
+	if err := s.transcoder.Thumbnail(ctx, src, filepath.Join(out, video.ThumbnailName), video.ThumbnailOffset(probe.Duration)); err != nil {
+		return nil, err
+	}
+
+	prefix := video.DerivedPrefix(objectKey)
+	if err := uploadDirectory(ctx, out, prefix); err != nil {
+		return nil, err
+	}
+	thumbnailURL, err := oss.ObjectURL(prefix + video.ThumbnailName)
+	if err != nil {
+		return nil, err
+	}
+	hlsURL, err := oss.ObjectURL(prefix + video.MasterPlaylistName)
+	if err != nil {
+		return nil, err
+	}
+	return &videoResult{
+		duration:     int(probe.Duration + 0.5),
+		thumbnailURL: thumbnailURL,
+		hlsURL:       hlsURL,
+	}, nil
+}
+
+// downloadObject 将对象下载到本地文件
+func downloadObject(objectKey, dst string) error {
+	rc, err := oss.GetObject(objectKey)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, rc); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to download %s: %w", objectKey, err)
+	}
+	return f.Close()
+}
+
+// uploadDirectory 上传目录下的所有文件，主播放列表最后上传，保证它存在时其余文件都已上传
+func uploadDirectory(ctx context.Context, dir, prefix string) error {
+	var files []string
+	if err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	}); err != nil {
+		return err
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[j] == video.MasterPlaylistName && files[i] != video.MasterPlaylistName
+	})
+
+	for _, rel := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := oss.UploadFile(ctx, prefix+rel, filepath.Join(dir, filepath.FromSlash(rel))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Package storagegc 找出对象存储中没有被任何数据库引用的对象
+//
+// 重新提交作业、替换课时视频或重新同步 Markdown 后，旧对象不会被删除。清理任务先收集中央服务器和
+// 各分支节点引用的对象Key，再列举存储前缀下的对象：未被引用、并且最后修改时间早于宽限期的对象视为孤立对象。
+// 宽限期用于避开已上传但引用尚未写入数据库的对象（例如正在进行的上传、导入和克隆）。
+package storagegc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"online-learning-platform/internal/oss"
+)
+
+// urlDelimiters 文本中对象地址的结束字符（Markdown 链接、HTML 属性、JSON 字符串等）
+const urlDelimiters = " \t\r\n)\"'<>\\"
+
+// References 被引用的对象Key，以及整体保留的前缀（例如视频转码生成的 HLS 目录）
+type References struct {
+	keys     map[string]bool
+	prefixes []string
+}
+
+// NewReferences 创建实例
+func NewReferences() *References {
+	return &References{keys: make(map[string]bool)}
+}
+
+// Add 记录一个被引用的对象Key
+func (r *References) Add(key string) {
+	if key != "" {
+		r.keys[key] = true
+	}
+}
+
+// AddPrefix 保留前缀下的所有对象
+func (r *References) AddPrefix(prefix string) {
+	if prefix != "" {
+		r.prefixes = append(r.prefixes, prefix)
+	}
+}
+
+// Has 判断对象是否被引用
+func (r *References) Has(key string) bool {
+	if r.keys[key] {
+		return true
+	}
+	for _, prefix := range r.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys 返回直接引用的对象Key
+func (r *References) Keys() []string {
+	keys := make([]string, 0, len(r.keys))
+	for key := range r.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// ExtractURLs 找出文本中以 prefix 开头的所有对象地址
+func ExtractURLs(text, prefix string) []string {
+	if prefix == "" {
+		return nil
+	}
+	var urls []string
+	for {
+		i := strings.Index(text, prefix)
+		if i < 0 {
+			return urls
+		}
+		end := i + len(prefix)
+		for end < len(text) && !strings.ContainsRune(urlDelimiters, rune(text[end])) {
+			end++
+		}
+		if end > i+len(prefix) {
+			urls = append(urls, text[i:end])
+		}
+		text = text[end:]
+	}
+}
+
+// Report 一次清理的结果；DryRun 时只统计不删除
+type Report struct {
+	DryRun      bool
+	Cutoff      time.Time // 最后修改时间早于该时间的孤立对象才会被删除
+	Prefixes    []string
+	Scanned     int
+	Referenced  int
+	Recent      int // 未被引用但仍在宽限期内的对象
+	Orphans     []oss.ObjectInfo
+	OrphanBytes int64
+	Deleted     int
+	Failed      []string
+}
+
+// NewReport 创建报告
+func NewReport(prefixes []string, cutoff time.Time, dryRun bool) *Report {
+	return &Report{DryRun: dryRun, Cutoff: cutoff, Prefixes: prefixes}
+}
+
+// Add 对列举到的对象分类
+func (r *Report) Add(object oss.ObjectInfo, refs *References) {
+	r.Scanned++
+	switch {
+	case refs.Has(object.Key):
+		r.Referenced++
+	case !object.LastModified.Before(r.Cutoff):
+		r.Recent++
+	default:
+		r.Orphans = append(r.Orphans, object)
+		r.OrphanBytes += object.Size
+	}
+}
+
+// String 打印报告，孤立对象逐行列出
+func (r *Report) String() string {
+	var b strings.Builder
+	mode := "apply"
+	if r.DryRun {
+		mode = "dry run"
+	}
+	fmt.Fprintf(&b, "storage gc (%s), prefixes %s, cutoff %s\n",
+		mode, strings.Join(r.Prefixes, ", "), r.Cutoff.Format("2006-01-02 15:04:05"))
+	for _, o := range r.Orphans {
+		fmt.Fprintf(&b, "  orphan %s  %d bytes  %s\n", o.Key, o.Size, o.LastModified.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Fprintf(&b, "scanned %d objects: %d referenced, %d within grace period, %d orphaned (%d bytes)\n",
+		r.Scanned, r.Referenced, r.Recent, len(r.Orphans), r.OrphanBytes)
+	if !r.DryRun {
+		fmt.Fprintf(&b, "deleted %d objects, %d failed\n", r.Deleted, len(r.Failed))
+		for _, key := range r.Failed {
+			fmt.Fprintf(&b, "  failed %s\n", key)
+		}
+	}
+	return b.String()
+}
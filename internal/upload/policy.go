@@ -0,0 +1,177 @@
+package upload
+
+import (
+	"errors"
+	"mime"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxFileNameLen 文件名（不含扩展名）保留的最大字符数
+const maxFileNameLen = 80
+
+var (
+	ErrTypeNotAllowed = errors.New("content type not allowed")
+	ErrTooLarge       = errors.New("file too large")
+)
+
+// typeExtensions 允许上传的类型对应的扩展名，第一个为默认扩展名
+var typeExtensions = map[string][]string{
+	"video/mp4":       {".mp4", ".m4v"},
+	"video/webm":      {".webm"},
+	"application/pdf": {".pdf"},
+	"text/markdown":   {".md", ".markdown"},
+	"text/plain":      {".txt"},
+	"image/png":       {".png"},
+	"image/jpeg":      {".jpg", ".jpeg"},
+	"image/gif":       {".gif"},
+	"image/webp":      {".webp"},
+}
+
+// Policy 上传限制：允许的文件类型和最大大小（字节），MaxSize 为 0 表示不限制
+type Policy struct {
+	AllowedTypes []string
+	MaxSize      int64
+}
+
+// Allows 判断是否允许该类型
+func (p Policy) Allows(contentType string) bool {
+	for _, t := range p.AllowedTypes {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// Check 校验声明的类型和大小
+func (p Policy) Check(contentType string, size int64) error {
+	if !p.Allows(contentType) {
+		return ErrTypeNotAllowed
+	}
+	if p.MaxSize > 0 && size > p.MaxSize {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+// Narrow 在当前限制内收紧：只保留同时允许的类型，取较小的大小上限
+func (p Policy) Narrow(types []string, maxSize int64) Policy {
+	narrowed := Policy{MaxSize: p.MaxSize}
+	if len(types) == 0 {
+		narrowed.AllowedTypes = p.AllowedTypes
+	}
+	for _, t := range types {
+		if p.Allows(t) {
+			narrowed.AllowedTypes = append(narrowed.AllowedTypes, t)
+		}
+	}
+	if maxSize > 0 && (narrowed.MaxSize == 0 || maxSize < narrowed.MaxSize) {
+		narrowed.MaxSize = maxSize
+	}
+	return narrowed
+}
+
+// ParseTypes 解析逗号分隔的类型列表，去掉参数并转为小写，无效的类型返回错误
+func ParseTypes(s string) ([]string, error) {
+	var types []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		t, _, err := mime.ParseMediaType(item)
+		if err != nil || !strings.Contains(t, "/") {
+			return nil, ErrTypeNotAllowed
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// SanitizeFileName 清理客户端提供的文件名，用于对象路径
+// 去掉路径部分，只保留字母、数字、'-' 和 '_'（其余字符替换为 '_'），扩展名与 contentType 不一致时改为对应的扩展名，
+// 结果中只有一个 '.'，不会以 '.' 开头
+func SanitizeFileName(name, contentType string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = name[strings.LastIndex(name, "/")+1:]
+
+	ext := strings.ToLower(path.Ext(name))
+	base := strings.TrimSuffix(name, path.Ext(name))
+	if !extensionMatches(ext, contentType) {
+		base = name
+		ext = defaultExtension(contentType)
+	}
+
+	var b strings.Builder
+	underscore := false
+	for _, r := range base {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		if !underscore {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	base = strings.Trim(b.String(), "_")
+	if utf8.RuneCountInString(base) > maxFileNameLen {
+		base = strings.TrimRight(string([]rune(base)[:maxFileNameLen]), "_")
+	}
+	if base == "" {
+		base = "file"
+	}
+	return base + ext
+}
+
+// TypeByExtension 按文件扩展名确定类型，用于没有声明类型的文件（课程包、Markdown 目录），不能识别时返回空字符串
+func TypeByExtension(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if ext == "" {
+		return ""
+	}
+	for contentType, exts := range typeExtensions {
+		for _, e := range exts {
+			if e == ext {
+				return contentType
+			}
+		}
+	}
+	contentType, _, err := mime.ParseMediaType(mime.TypeByExtension(ext))
+	if err != nil {
+		return ""
+	}
+	return contentType
+}
+
+// extensionMatches 判断扩展名是否与类型一致
+func extensionMatches(ext, contentType string) bool {
+	if ext == "" {
+		return false
+	}
+	exts, ok := typeExtensions[contentType]
+	if !ok {
+		exts, _ = mime.ExtensionsByType(contentType)
+	}
+	for _, e := range exts {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultExtension 返回类型的默认扩展名，未知类型返回空字符串
+func defaultExtension(contentType string) string {
+	if exts, ok := typeExtensions[contentType]; ok {
+		return exts[0]
+	}
+	if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
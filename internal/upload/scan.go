@@ -0,0 +1,143 @@
+package upload
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize INSTREAM 每个数据块的大小
+const clamdChunkSize = 32 << 10
+
+// ErrThreatDetected 扫描器发现威胁
+var ErrThreatDetected = errors.New("threat detected")
+
+// Scanner 恶意文件扫描器，确认上传时在文件通过校验后调用
+type Scanner interface {
+	// Scan 读取全部内容进行扫描，发现威胁时返回威胁名称，未发现时返回空字符串
+	Scan(ctx context.Context, r io.Reader) (threat string, err error)
+}
+
+// NopScanner 不做扫描，用于本地开发
+type NopScanner struct{}
+
+// Scan 始终认为文件安全
+func (NopScanner) Scan(ctx context.Context, r io.Reader) (string, error) {
+	return "", nil
+}
+
+// ClamAVScanner 通过 clamd 的 INSTREAM 命令扫描
+type ClamAVScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamAVScanner 创建 clamd 扫描器
+// address 支持 tcp://host:port、unix:///path/to/clamd.sock，或不带协议的 host:port；timeout 为单次扫描的超时时间
+func NewClamAVScanner(address string, timeout time.Duration) (*ClamAVScanner, error) {
+	network := "tcp"
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network, address = "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		address = strings.TrimPrefix(address, "tcp://")
+	}
+	if address == "" {
+		return nil, fmt.Errorf("clamd address is required")
+	}
+	return &ClamAVScanner{network: network, address: address, timeout: timeout}, nil
+}
+
+// Scan 将内容分块发送给 clamd 并解析扫描结果
+func (s *ClamAVScanner) Scan(ctx context.Context, r io.Reader) (string, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect clamd: %w", err)
+	}
+	defer conn.Close()
+	if s.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(s.timeout))
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if err := s.stream(conn, r); err != nil {
+		// clamd 超出 StreamMaxLength 时会提前返回错误并关闭连接，优先返回它的回复
+		if reply, readErr := readClamdReply(conn); readErr == nil {
+			if _, replyErr := parseClamdReply(reply); replyErr != nil {
+				return "", replyErr
+			}
+		}
+		return "", fmt.Errorf("failed to send data to clamd: %w", err)
+	}
+
+	reply, err := readClamdReply(conn)
+	if err != nil {
+		return "", fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return parseClamdReply(reply)
+}
+
+// stream 发送 INSTREAM 命令：每块数据前加 4 字节大端长度，以长度 0 结束
+func (s *ClamAVScanner) stream(conn net.Conn, r io.Reader) error {
+	w := bufio.NewWriterSize(conn, clamdChunkSize+4)
+	if _, err := w.WriteString("zINSTREAM\x00"); err != nil {
+		return err
+	}
+	buf := make([]byte, clamdChunkSize)
+	var size [4]byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size[:], uint32(n))
+			if _, werr := w.Write(size[:]); werr != nil {
+				return werr
+			}
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	binary.BigEndian.PutUint32(size[:], 0)
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// readClamdReply 读取以 NUL 结尾的回复
+func readClamdReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return "", err
+	}
+	return strings.TrimRight(reply, "\x00\n"), nil
+}
+
+// parseClamdReply 解析回复：stream: OK 表示安全，stream: <名称> FOUND 表示发现威胁，其他为错误
+func parseClamdReply(reply string) (string, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return "", nil
+	case strings.HasSuffix(result, " FOUND"):
+		return strings.TrimSuffix(result, " FOUND"), nil
+	default:
+		return "", fmt.Errorf("clamd: %s", result)
+	}
+}
@@ -0,0 +1,156 @@
+// Package upload 直传对象存储的分片规划和上传结果校验
+//
+// 客户端先申请上传位置，按 Plan 的分片大小直接上传到对象存储，确认时由服务端
+// 读取对象计算大小、SHA-256 并嗅探文件类型，与申请时声明的信息比对。
+// Policy 限制允许的类型和大小，Scanner 在校验通过后扫描恶意文件。
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	// MinPartSize 分片的最小大小，不超过该大小的文件一次上传
+	MinPartSize int64 = 8 << 20
+	// MaxPartSize 单个分片的最大大小（S3 和 OSS 的上限）
+	MaxPartSize int64 = 5 << 30
+	// MaxParts 最多分片数，保证一次列举即可取得全部已上传分片
+	MaxParts = 1000
+
+	sniffLen = 512
+)
+
+var (
+	ErrInvalidSize      = errors.New("invalid file size")
+	ErrInvalidChecksum  = errors.New("invalid sha256 checksum")
+	ErrSizeMismatch     = errors.New("uploaded size does not match")
+	ErrChecksumMismatch = errors.New("uploaded checksum does not match")
+	ErrTypeMismatch     = errors.New("uploaded content does not match declared type")
+)
+
+// Plan 分片方案，PartCount 为 1 时使用单次 PUT 上传
+type Plan struct {
+	PartSize  int64 `json:"part_size"`
+	PartCount int   `json:"part_count"`
+}
+
+// Multipart 是否需要分片上传
+func (p Plan) Multipart() bool {
+	return p.PartCount > 1
+}
+
+// PartRange 返回第 n 个分片（从 1 开始）在文件中的偏移和长度
+func (p Plan) PartRange(n int, size int64) (offset, length int64) {
+	offset = int64(n-1) * p.PartSize
+	length = p.PartSize
+	if offset+length > size {
+		length = size - offset
+	}
+	return offset, length
+}
+
+// PlanParts 按文件大小计算分片方案，分片大小取 MinPartSize 和 size/MaxParts 中的较大值（按 1MB 取整）
+func PlanParts(size int64) (Plan, error) {
+	if size <= 0 {
+		return Plan{}, ErrInvalidSize
+	}
+	if size <= MinPartSize {
+		return Plan{PartSize: size, PartCount: 1}, nil
+	}
+
+	partSize := MinPartSize
+	if perPart := (size + MaxParts - 1) / MaxParts; perPart > partSize {
+		partSize = (perPart + (1<<20 - 1)) &^ (1<<20 - 1)
+	}
+	if partSize > MaxPartSize {
+		return Plan{}, ErrInvalidSize
+	}
+	return Plan{PartSize: partSize, PartCount: int((size + partSize - 1) / partSize)}, nil
+}
+
+// Expected 申请上传时声明的文件信息
+type Expected struct {
+	Size        int64
+	SHA256      string
+	ContentType string
+}
+
+// Result 读取上传对象得到的实际信息
+type Result struct {
+	Size        int64
+	SHA256      string
+	ContentType string // 根据文件头嗅探的类型
+}
+
+// Inspect 流式读取对象内容，计算大小和 SHA-256 并嗅探类型
+func Inspect(r io.Reader) (Result, error) {
+	h := sha256.New()
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return Result{}, err
+	}
+	head = head[:n]
+	h.Write(head)
+
+	rest, err := io.Copy(h, r)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Size:        int64(n) + rest,
+		SHA256:      hex.EncodeToString(h.Sum(nil)),
+		ContentType: http.DetectContentType(head),
+	}, nil
+}
+
+// Verify 比对实际结果和声明的信息
+func Verify(result Result, expected Expected) error {
+	if result.Size != expected.Size {
+		return ErrSizeMismatch
+	}
+	if !strings.EqualFold(result.SHA256, expected.SHA256) {
+		return ErrChecksumMismatch
+	}
+	if !TypeMatches(expected.ContentType, result.ContentType) {
+		return ErrTypeMismatch
+	}
+	return nil
+}
+
+// TypeMatches 判断嗅探到的类型是否与声明的类型一致
+// 嗅探只能识别常见格式，因此只比较主类型（video、image 等）；无法识别的二进制内容不匹配任何类型，
+// 内容像 HTML 的文件只匹配 text/html，避免以文本的名义上传网页
+func TypeMatches(declared, sniffed string) bool {
+	declaredType, _, err := mime.ParseMediaType(declared)
+	if err != nil {
+		return false
+	}
+	sniffedType, _, err := mime.ParseMediaType(sniffed)
+	if err != nil || sniffedType == "application/octet-stream" {
+		return false
+	}
+	if sniffedType == "text/html" {
+		return declaredType == sniffedType
+	}
+	if declaredType == sniffedType {
+		return true
+	}
+	major := func(t string) string { return t[:strings.Index(t, "/")] }
+	return major(declaredType) != "application" && major(declaredType) == major(sniffedType)
+}
+
+// ValidSHA256 校验十六进制 SHA-256 字符串
+func ValidSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
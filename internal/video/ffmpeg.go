@@ -0,0 +1,96 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// stderrTail 命令失败时错误信息中保留的 stderr 长度
+const stderrTail = 500
+
+// Transcoder 调用 ffprobe 和 ffmpeg 处理本地视频文件
+type Transcoder struct {
+	FFmpeg  string
+	FFprobe string
+}
+
+// NewTranscoder 创建实例，路径为空时从 PATH 中查找
+func NewTranscoder(ffmpegPath, ffprobePath string) *Transcoder {
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+	if ffprobePath == "" {
+		ffprobePath = "ffprobe"
+	}
+	return &Transcoder{FFmpeg: ffmpegPath, FFprobe: ffprobePath}
+}
+
+// Probe 读取视频的时长和分辨率
+func (t *Transcoder) Probe(ctx context.Context, src string) (Probe, error) {
+	out, err := run(ctx, t.FFprobe, "-v", "error", "-print_format", "json", "-show_format", "-show_streams", src)
+	if err != nil {
+		return Probe{}, err
+	}
+	return ParseProbe(out)
+}
+
+// Thumbnail 截取 offset 秒处的一帧作为封面，宽度缩放到 640
+func (t *Transcoder) Thumbnail(ctx context.Context, src, dst string, offset float64) error {
+	_, err := run(ctx, t.FFmpeg, "-y", "-v", "error",
+		"-ss", strconv.FormatFloat(offset, 'f', 3, 64), "-i", src,
+		"-frames:v", "1", "-vf", "scale=640:-2", "-q:v", "3", dst)
+	return err
+}
+
+// HLS 将视频转码为各档位的 HLS，输出到 dir/{档位}/ 下，并在 dir 下写入主播放列表
+func (t *Transcoder) HLS(ctx context.Context, src, dir string, renditions []Rendition, probe Probe) error {
+	for _, r := range renditions {
+		out := filepath.Join(dir, r.Name)
+		if err := os.MkdirAll(out, 0o755); err != nil {
+			return err
+		}
+		args := []string{"-y", "-v", "error", "-i", src,
+			"-map", "0:v:0",
+			"-vf", fmt.Sprintf("scale=-2:%d", r.Height),
+			"-c:v", "libx264", "-preset", "veryfast", "-profile:v", "main", "-pix_fmt", "yuv420p",
+			"-b:v", fmt.Sprintf("%dk", r.VideoBitrate),
+			"-maxrate", fmt.Sprintf("%dk", r.VideoBitrate*107/100),
+			"-bufsize", fmt.Sprintf("%dk", r.VideoBitrate*3/2),
+			// 固定关键帧间隔，保证各档位的分片边界一致，便于切换码率
+			"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", SegmentSeconds),
+		}
+		if probe.HasAudio {
+			args = append(args, "-map", "0:a:0", "-c:a", "aac", "-ac", "2", "-b:a", fmt.Sprintf("%dk", r.AudioBitrate))
+		}
+		args = append(args,
+			"-f", "hls", "-hls_time", strconv.Itoa(SegmentSeconds), "-hls_playlist_type", "vod",
+			"-hls_segment_filename", filepath.Join(out, "seg_%03d.ts"),
+			filepath.Join(out, "index.m3u8"))
+		if _, err := run(ctx, t.FFmpeg, args...); err != nil {
+			return fmt.Errorf("rendition %s: %w", r.Name, err)
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, MasterPlaylistName), []byte(MasterPlaylist(renditions, probe)), 0o644)
+}
+
+// run 执行命令并返回标准输出，失败时错误中带上 stderr 的最后一部分
+func run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > stderrTail {
+			msg = msg[len(msg)-stderrTail:]
+		}
+		return nil, fmt.Errorf("%s failed: %w: %s", filepath.Base(name), err, msg)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,182 @@
+// Package video 课时视频的处理：读取时长、截取封面并转码为多码率 HLS
+//
+// 处理结果保存在原视频旁边的 {原Key去掉扩展名}_hls/ 目录下：
+//
+//	master.m3u8          主播放列表
+//	720p/index.m3u8      各码率的播放列表
+//	720p/seg_000.ts      分片
+//	thumbnail.jpg        封面
+package video
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MasterPlaylistName 主播放列表的文件名
+	MasterPlaylistName = "master.m3u8"
+	// ThumbnailName 封面的文件名
+	ThumbnailName = "thumbnail.jpg"
+	// SegmentSeconds 每个 HLS 分片的时长
+	SegmentSeconds = 6
+
+	derivedSuffix = "_hls/"
+)
+
+// Rendition 一个码率档位
+type Rendition struct {
+	Name         string
+	Height       int
+	VideoBitrate int // kbps
+	AudioBitrate int // kbps
+}
+
+// DefaultLadder 默认的码率档位，从高到低
+var DefaultLadder = []Rendition{
+	{Name: "1080p", Height: 1080, VideoBitrate: 5000, AudioBitrate: 192},
+	{Name: "720p", Height: 720, VideoBitrate: 2800, AudioBitrate: 128},
+	{Name: "480p", Height: 480, VideoBitrate: 1400, AudioBitrate: 128},
+	{Name: "360p", Height: 360, VideoBitrate: 800, AudioBitrate: 96},
+}
+
+// Probe 视频的基本信息
+type Probe struct {
+	Duration float64 // 秒
+	Width    int
+	Height   int
+	HasAudio bool
+}
+
+// ParseProbe 解析 ffprobe -print_format json -show_format -show_streams 的输出
+func ParseProbe(data []byte) (Probe, error) {
+	var out struct {
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+			Duration  string `json:"duration"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return Probe{}, fmt.Errorf("invalid ffprobe output: %w", err)
+	}
+
+	var probe Probe
+	probe.Duration, _ = strconv.ParseFloat(out.Format.Duration, 64)
+	hasVideo := false
+	for _, s := range out.Streams {
+		switch s.CodecType {
+		case "video":
+			if hasVideo {
+				continue
+			}
+			hasVideo = true
+			probe.Width, probe.Height = s.Width, s.Height
+			if probe.Duration == 0 {
+				probe.Duration, _ = strconv.ParseFloat(s.Duration, 64)
+			}
+		case "audio":
+			probe.HasAudio = true
+		}
+	}
+	if !hasVideo || probe.Width <= 0 || probe.Height <= 0 {
+		return Probe{}, fmt.Errorf("no video stream found")
+	}
+	if probe.Duration <= 0 {
+		return Probe{}, fmt.Errorf("unknown video duration")
+	}
+	return probe, nil
+}
+
+// SelectRenditions 选择不高于原视频分辨率的档位，不放大视频
+// 原视频低于最低档位时只输出一个原分辨率的档位
+func SelectRenditions(ladder []Rendition, sourceHeight int) []Rendition {
+	var selected []Rendition
+	for _, r := range ladder {
+		if r.Height <= sourceHeight {
+			selected = append(selected, r)
+		}
+	}
+	if len(selected) == 0 && len(ladder) > 0 {
+		lowest := ladder[len(ladder)-1]
+		lowest.Height = sourceHeight &^ 1
+		lowest.Name = fmt.Sprintf("%dp", lowest.Height)
+		selected = append(selected, lowest)
+	}
+	return selected
+}
+
+// ScaledWidth 按原视频宽高比计算档位的宽度（偶数，满足 H.264 的要求）
+func ScaledWidth(probe Probe, height int) int {
+	if probe.Height == 0 {
+		return 0
+	}
+	width := (probe.Width*height + probe.Height/2) / probe.Height
+	return (width + 1) &^ 1
+}
+
+// ThumbnailOffset 封面的截取位置：视频的 10%，最多第 10 秒，避开片头黑屏
+func ThumbnailOffset(duration float64) float64 {
+	offset := duration * 0.1
+	if offset > 10 {
+		offset = 10
+	}
+	return offset
+}
+
+// MasterPlaylist 生成主播放列表，各档位的播放列表使用相对路径
+func MasterPlaylist(renditions []Rendition, probe Probe) string {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	for _, r := range renditions {
+		bandwidth := r.VideoBitrate * 1000
+		if probe.HasAudio {
+			bandwidth += r.AudioBitrate * 1000
+		}
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d\n%s/index.m3u8\n",
+			bandwidth, ScaledWidth(probe, r.Height), r.Height, r.Name)
+	}
+	return b.String()
+}
+
+// DerivedPrefix 视频处理结果的对象Key前缀
+func DerivedPrefix(objectKey string) string {
+	return strings.TrimSuffix(objectKey, path.Ext(objectKey)) + derivedSuffix
+}
+
+// IsMasterPlaylistKey 判断对象Key是否为处理结果的主播放列表
+func IsMasterPlaylistKey(objectKey string) bool {
+	return strings.HasSuffix(objectKey, derivedSuffix+MasterPlaylistName)
+}
+
+var playlistNamePattern = regexp.MustCompile(`^[0-9a-z]+/index\.m3u8$`)
+
+// ValidPlaylistName 判断是否为主播放列表或某个档位的播放列表
+func ValidPlaylistName(name string) bool {
+	return name == MasterPlaylistName || playlistNamePattern.MatchString(name)
+}
+
+// RewriteSegments 替换播放列表中的分片地址（例如换成签名URL），播放列表地址保持相对路径
+func RewriteSegments(playlist string, rewrite func(uri string) (string, error)) (string, error) {
+	lines := strings.Split(playlist, "\n")
+	for i, line := range lines {
+		uri := strings.TrimSpace(line)
+		if uri == "" || strings.HasPrefix(uri, "#") || strings.HasSuffix(uri, ".m3u8") {
+			continue
+		}
+		rewritten, err := rewrite(uri)
+		if err != nil {
+			return "", err
+		}
+		lines[i] = rewritten
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -36,6 +36,9 @@ CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
 CREATE TABLE IF NOT EXISTS answers (
     answer_id SERIAL PRIMARY KEY,
     task_id INTEGER NOT NULL,
+    attempt INTEGER DEFAULT 1,
+    is_counted BOOLEAN DEFAULT TRUE,
+    task_revision INTEGER DEFAULT 0,
     branch_id INTEGER NOT NULL REFERENCES branches(branch_id) ON DELETE RESTRICT,
     user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
     graded_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
@@ -43,6 +46,14 @@ CREATE TABLE IF NOT EXISTS answers (
     type VARCHAR(50) DEFAULT 'text',
     score INTEGER DEFAULT 0,
     is_graded BOOLEAN DEFAULT FALSE,
+    rubric_scores JSONB DEFAULT '[]',
+    feedback TEXT,
+    inline_comments JSONB DEFAULT '[]',
+    raw_score INTEGER DEFAULT 0,
+    late_penalty INTEGER DEFAULT 0,
+    is_late BOOLEAN DEFAULT FALSE,
+    late_days INTEGER DEFAULT 0,
+    after_cutoff BOOLEAN DEFAULT FALSE,
     submitted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
@@ -52,6 +63,7 @@ CREATE INDEX IF NOT EXISTS idx_answers_task_id ON answers(task_id);
 CREATE INDEX IF NOT EXISTS idx_answers_branch_id ON answers(branch_id);
 CREATE INDEX IF NOT EXISTS idx_answers_user_id ON answers(user_id);
 CREATE INDEX IF NOT EXISTS idx_answers_graded_by ON answers(graded_by);
+CREATE INDEX IF NOT EXISTS idx_answers_task_user ON answers(task_id, user_id, attempt);
 
 CREATE TABLE IF NOT EXISTS comments (
     comment_id SERIAL PRIMARY KEY,
@@ -84,6 +96,37 @@ CREATE TABLE IF NOT EXISTS learning (
 CREATE INDEX IF NOT EXISTS idx_learning_user_id ON learning(user_id);
 CREATE INDEX IF NOT EXISTS idx_learning_course_id ON learning(course_id);
 CREATE INDEX IF NOT EXISTS idx_learning_user_course ON learning(user_id, course_id);
+CREATE INDEX IF NOT EXISTS idx_learning_course_status ON learning(course_id, status);
+
+CREATE TABLE IF NOT EXISTS lesson_progress (
+    lesson_progress_id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    course_id INTEGER NOT NULL,
+    lesson_id INTEGER NOT NULL,
+    completed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, lesson_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lesson_progress_user_course ON lesson_progress(user_id, course_id);
+
+CREATE TABLE IF NOT EXISTS video_progress (
+    video_progress_id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    course_id INTEGER NOT NULL,
+    lesson_id INTEGER NOT NULL,
+    position DOUBLE PRECISION DEFAULT 0,
+    duration DOUBLE PRECISION DEFAULT 0,
+    watched_seconds DOUBLE PRECISION DEFAULT 0,
+    intervals TEXT,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, lesson_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_progress_course_id ON video_progress(course_id);
 
 -- ============================================
 -- 课程相关表的只读副本（通过РОК同步获得）
@@ -95,15 +138,23 @@ CREATE TABLE IF NOT EXISTS courses (
     course_title VARCHAR(255) NOT NULL,
     description TEXT,
     instructor_id INTEGER NOT NULL,
+    category_id INTEGER,
     start_date TIMESTAMP,
     end_date TIMESTAMP,
+    enrollment_start_at TIMESTAMP,
+    enrollment_end_at TIMESTAMP,
+    capacity INTEGER DEFAULT 0,
     status VARCHAR(50) DEFAULT 'active',
+    publish_status VARCHAR(20) DEFAULT 'draft',
+    publish_at TIMESTAMP,
+    revision INTEGER DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 CREATE INDEX IF NOT EXISTS idx_courses_instructor_id ON courses(instructor_id);
 CREATE INDEX IF NOT EXISTS idx_courses_status ON courses(status);
+CREATE INDEX IF NOT EXISTS idx_courses_publish_status ON courses(publish_status);
 
 CREATE TABLE IF NOT EXISTS chapters (
     chapter_id SERIAL PRIMARY KEY,
@@ -111,6 +162,10 @@ CREATE TABLE IF NOT EXISTS chapters (
     chapter_title VARCHAR(255) NOT NULL,
     chapter_order INTEGER NOT NULL,
     description TEXT,
+    slug VARCHAR(255) DEFAULT '',
+    publish_status VARCHAR(20) DEFAULT 'draft',
+    publish_at TIMESTAMP,
+    revision INTEGER DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -126,6 +181,16 @@ CREATE TABLE IF NOT EXISTS lessons (
     content_url TEXT,
     lesson_type VARCHAR(50) DEFAULT 'video',
     lesson_order INTEGER NOT NULL,
+    slug VARCHAR(255) DEFAULT '',
+    publish_status VARCHAR(20) DEFAULT 'draft',
+    publish_at TIMESTAMP,
+    revision INTEGER DEFAULT 0,
+    processing_status VARCHAR(20) DEFAULT '',
+    processing_error TEXT,
+    processing_started_at TIMESTAMP,
+    duration INTEGER DEFAULT 0,
+    thumbnail_url TEXT,
+    hls_url TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -141,6 +206,17 @@ CREATE TABLE IF NOT EXISTS tasks (
     description TEXT,
     task_type VARCHAR(50) DEFAULT 'essay',
     max_score INTEGER DEFAULT 100,
+    allowed_types VARCHAR(500) DEFAULT '',
+    max_file_size BIGINT DEFAULT 0,
+    due_at TIMESTAMP,
+    cutoff_at TIMESTAMP,
+    late_policy VARCHAR(30) DEFAULT '',
+    late_penalty_percent INTEGER DEFAULT 0,
+    rubric JSONB DEFAULT '[]',
+    max_attempts INTEGER DEFAULT 0,
+    attempt_policy VARCHAR(20) DEFAULT 'latest',
+    slug VARCHAR(255) DEFAULT '',
+    revision INTEGER DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -148,3 +224,49 @@ CREATE TABLE IF NOT EXISTS tasks (
 CREATE INDEX IF NOT EXISTS idx_tasks_lesson_id ON tasks(lesson_id);
 
 
+
+CREATE TABLE IF NOT EXISTS uploads (
+    upload_id SERIAL PRIMARY KEY,
+    purpose VARCHAR(20) NOT NULL,
+    user_id INTEGER NOT NULL,
+    branch_id INTEGER NOT NULL,
+    course_id INTEGER,
+    chapter_id INTEGER,
+    task_id INTEGER,
+    object_key VARCHAR(500) NOT NULL,
+    file_name VARCHAR(255),
+    content_type VARCHAR(100),
+    size BIGINT DEFAULT 0,
+    sha256 VARCHAR(64),
+    part_size BIGINT DEFAULT 0,
+    part_count INTEGER DEFAULT 1,
+    storage_upload_id VARCHAR(255),
+    status VARCHAR(20) DEFAULT 'pending',
+    expires_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_uploads_user ON uploads(user_id, branch_id);
+CREATE INDEX IF NOT EXISTS idx_uploads_status_expires ON uploads(status, expires_at);
+
+CREATE TABLE IF NOT EXISTS quiz_responses (
+    response_id SERIAL PRIMARY KEY,
+    answer_id INTEGER NOT NULL REFERENCES answers(answer_id) ON DELETE CASCADE,
+    task_id INTEGER NOT NULL,
+    question_id INTEGER NOT NULL,
+    branch_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    response JSONB NOT NULL,
+    max_points NUMERIC(8,2) DEFAULT 0,
+    auto_points NUMERIC(8,2) DEFAULT 0,
+    override_points NUMERIC(8,2),
+    is_correct BOOLEAN DEFAULT FALSE,
+    needs_review BOOLEAN DEFAULT FALSE,
+    graded_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_responses_answer_id ON quiz_responses(answer_id);
@@ -6,15 +6,26 @@ CREATE TABLE IF NOT EXISTS courses (
     course_title VARCHAR(255) NOT NULL,
     description TEXT,
     instructor_id INTEGER NOT NULL,
+    category_id INTEGER,
     start_date TIMESTAMP,
     end_date TIMESTAMP,
+    enrollment_start_at TIMESTAMP,
+    enrollment_end_at TIMESTAMP,
+    capacity INTEGER DEFAULT 0,
+    enrollment_count INTEGER DEFAULT 0,
     status VARCHAR(50) DEFAULT 'active',
+    publish_status VARCHAR(20) DEFAULT 'draft',
+    publish_at TIMESTAMP,
+    revision INTEGER DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 CREATE INDEX IF NOT EXISTS idx_courses_instructor_id ON courses(instructor_id);
+CREATE INDEX IF NOT EXISTS idx_courses_category_id ON courses(category_id);
 CREATE INDEX IF NOT EXISTS idx_courses_status ON courses(status);
+CREATE INDEX IF NOT EXISTS idx_courses_publish_status ON courses(publish_status);
+CREATE INDEX IF NOT EXISTS idx_courses_enrollment_count ON courses(enrollment_count);
 
 CREATE TABLE IF NOT EXISTS chapters (
     chapter_id SERIAL PRIMARY KEY,
@@ -22,12 +33,17 @@ CREATE TABLE IF NOT EXISTS chapters (
     chapter_title VARCHAR(255) NOT NULL,
     chapter_order INTEGER NOT NULL,
     description TEXT,
+    slug VARCHAR(255) DEFAULT '',
+    publish_status VARCHAR(20) DEFAULT 'draft',
+    publish_at TIMESTAMP,
+    revision INTEGER DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 CREATE INDEX IF NOT EXISTS idx_chapters_course_id ON chapters(course_id);
 CREATE INDEX IF NOT EXISTS idx_chapters_course_order ON chapters(course_id, chapter_order);
+CREATE INDEX IF NOT EXISTS idx_chapters_course_slug ON chapters(course_id, slug);
 
 CREATE TABLE IF NOT EXISTS lessons (
     lesson_id SERIAL PRIMARY KEY,
@@ -37,6 +53,16 @@ CREATE TABLE IF NOT EXISTS lessons (
     content_url TEXT,
     lesson_type VARCHAR(50) DEFAULT 'video',
     lesson_order INTEGER NOT NULL,
+    slug VARCHAR(255) DEFAULT '',
+    publish_status VARCHAR(20) DEFAULT 'draft',
+    publish_at TIMESTAMP,
+    revision INTEGER DEFAULT 0,
+    processing_status VARCHAR(20) DEFAULT '',
+    processing_error TEXT,
+    processing_started_at TIMESTAMP,
+    duration INTEGER DEFAULT 0,
+    thumbnail_url TEXT,
+    hls_url TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -44,6 +70,8 @@ CREATE TABLE IF NOT EXISTS lessons (
 CREATE INDEX IF NOT EXISTS idx_lessons_course_id ON lessons(course_id);
 CREATE INDEX IF NOT EXISTS idx_lessons_chapter_id ON lessons(chapter_id);
 CREATE INDEX IF NOT EXISTS idx_lessons_chapter_order ON lessons(chapter_id, lesson_order);
+CREATE INDEX IF NOT EXISTS idx_lessons_course_slug ON lessons(course_id, slug);
+CREATE INDEX IF NOT EXISTS idx_lessons_processing_status ON lessons(processing_status);
 
 CREATE TABLE IF NOT EXISTS tasks (
     task_id SERIAL PRIMARY KEY,
@@ -52,9 +80,164 @@ CREATE TABLE IF NOT EXISTS tasks (
     description TEXT,
     task_type VARCHAR(50) DEFAULT 'essay',
     max_score INTEGER DEFAULT 100,
+    allowed_types VARCHAR(500) DEFAULT '',
+    max_file_size BIGINT DEFAULT 0,
+    due_at TIMESTAMP,
+    cutoff_at TIMESTAMP,
+    late_policy VARCHAR(30) DEFAULT '',
+    late_penalty_percent INTEGER DEFAULT 0,
+    rubric JSONB DEFAULT '[]',
+    max_attempts INTEGER DEFAULT 0,
+    attempt_policy VARCHAR(20) DEFAULT 'latest',
+    slug VARCHAR(255) DEFAULT '',
+    revision INTEGER DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 CREATE INDEX IF NOT EXISTS idx_tasks_lesson_id ON tasks(lesson_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_lesson_slug ON tasks(lesson_id, slug);
 
+CREATE TABLE IF NOT EXISTS revisions (
+    revision_id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    course_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    snapshot JSONB NOT NULL,
+    author_user_id INTEGER,
+    author_branch_id INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_revisions_entity_version ON revisions(entity_type, entity_id, version);
+CREATE INDEX IF NOT EXISTS idx_revisions_course_id ON revisions(course_id);
+
+CREATE TABLE IF NOT EXISTS course_prerequisites (
+    prerequisite_id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    prerequisite_course_id INTEGER NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    min_status VARCHAR(20) NOT NULL DEFAULT 'completed',
+    min_score INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_course_prerequisites_pair ON course_prerequisites(course_id, prerequisite_course_id);
+CREATE INDEX IF NOT EXISTS idx_course_prerequisites_prerequisite_course_id ON course_prerequisites(prerequisite_course_id);
+
+CREATE TABLE IF NOT EXISTS prerequisite_overrides (
+    override_id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    branch_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    reason TEXT,
+    granted_by_user_id INTEGER,
+    granted_by_branch_id INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prerequisite_overrides_student ON prerequisite_overrides(course_id, branch_id, user_id);
+
+CREATE TABLE IF NOT EXISTS task_extensions (
+    extension_id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(task_id) ON DELETE CASCADE,
+    branch_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    due_at TIMESTAMP,
+    cutoff_at TIMESTAMP,
+    reason TEXT,
+    granted_by_user_id INTEGER,
+    granted_by_branch_id INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_extensions_student ON task_extensions(task_id, branch_id, user_id);
+
+CREATE TABLE IF NOT EXISTS course_staff (
+    staff_id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    instructor_id INTEGER NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_course_staff_member ON course_staff(course_id, instructor_id);
+CREATE INDEX IF NOT EXISTS idx_course_staff_instructor_id ON course_staff(instructor_id);
+
+CREATE TABLE IF NOT EXISTS categories (
+    category_id SERIAL PRIMARY KEY,
+    category_name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS course_tags (
+    course_id INTEGER NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    PRIMARY KEY (course_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_course_tags_tag ON course_tags(tag);
+
+CREATE TABLE IF NOT EXISTS search_documents (
+    document_id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    course_id INTEGER NOT NULL,
+    title TEXT,
+    body TEXT,
+    search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_documents_entity ON search_documents(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_search_documents_course_id ON search_documents(course_id);
+CREATE INDEX IF NOT EXISTS idx_search_documents_vector ON search_documents USING GIN (search_vector);
+
+CREATE TABLE IF NOT EXISTS uploads (
+    upload_id SERIAL PRIMARY KEY,
+    purpose VARCHAR(20) NOT NULL,
+    user_id INTEGER NOT NULL,
+    branch_id INTEGER NOT NULL,
+    course_id INTEGER,
+    chapter_id INTEGER,
+    task_id INTEGER,
+    object_key VARCHAR(500) NOT NULL,
+    file_name VARCHAR(255),
+    content_type VARCHAR(100),
+    size BIGINT DEFAULT 0,
+    sha256 VARCHAR(64),
+    part_size BIGINT DEFAULT 0,
+    part_count INTEGER DEFAULT 1,
+    storage_upload_id VARCHAR(255),
+    status VARCHAR(20) DEFAULT 'pending',
+    expires_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_uploads_user ON uploads(user_id, branch_id);
+CREATE INDEX IF NOT EXISTS idx_uploads_status_expires ON uploads(status, expires_at);
+
+CREATE TABLE IF NOT EXISTS quiz_questions (
+    question_id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(task_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    question_type VARCHAR(30) NOT NULL,
+    prompt TEXT NOT NULL,
+    options JSONB DEFAULT '[]',
+    answer_key JSONB DEFAULT '{}',
+    points NUMERIC(8,2) DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_questions_task_id ON quiz_questions(task_id, position);
+CREATE INDEX IF NOT EXISTS idx_quiz_questions_deleted_at ON quiz_questions(deleted_at);
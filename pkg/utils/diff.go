@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// FieldChange 单个字段的变化
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// DiffJSONFields 比较两个 JSON 对象的顶层字段，返回按字段名排序的变化列表
+// ignore 中的字段不参与比较；只在一侧出现的字段，另一侧的值为 nil
+func DiffJSONFields(from, to []byte, ignore ...string) ([]FieldChange, error) {
+	var fromFields, toFields map[string]interface{}
+	if err := json.Unmarshal(from, &fromFields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(to, &toFields); err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool, len(ignore))
+	for _, field := range ignore {
+		skip[field] = true
+	}
+
+	keys := make([]string, 0, len(fromFields)+len(toFields))
+	for key := range fromFields {
+		keys = append(keys, key)
+	}
+	for key := range toFields {
+		if _, ok := fromFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make([]FieldChange, 0)
+	for _, key := range keys {
+		if skip[key] || reflect.DeepEqual(fromFields[key], toFields[key]) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: key, From: fromFields[key], To: toFields[key]})
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,26 @@
+package utils
+
+// NormalizeOrder 规范化排序结果
+// requested 为客户端提交的新顺序，existing 为当前顺序：
+// 重复的ID只保留第一次出现的位置，requested 中未包含的现有ID按原顺序追加到末尾
+func NormalizeOrder(requested, existing []uint) []uint {
+	seen := make(map[uint]bool, len(requested)+len(existing))
+	result := make([]uint, 0, len(requested)+len(existing))
+
+	for _, id := range requested {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	for _, id := range existing {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+
+	return result
+}
@@ -0,0 +1,12 @@
+-- 作业的多次提交记录（分支节点）
+-- 任务只读副本添加对应列；每次提交保存为一条 answers 记录，is_counted 标记计入成绩的一次
+-- 已有作业视为第一次提交并计入成绩
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS max_attempts INTEGER DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS attempt_policy VARCHAR(20) DEFAULT 'latest';
+
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS attempt INTEGER DEFAULT 1;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS is_counted BOOLEAN DEFAULT TRUE;
+
+CREATE INDEX IF NOT EXISTS idx_answers_task_user ON answers(task_id, user_id, attempt);
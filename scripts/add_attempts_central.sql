@@ -0,0 +1,6 @@
+-- 任务的提交次数限制和计分策略（中央服务器）
+-- max_attempts 为 0 时不限制；attempt_policy 为 latest（最近一次）, highest（最高分）, first（第一次）
+-- 在中央服务器数据库（learning_central）中执行
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS max_attempts INTEGER DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS attempt_policy VARCHAR(20) DEFAULT 'latest';
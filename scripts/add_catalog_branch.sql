@@ -0,0 +1,5 @@
+-- 课程分类（分支节点）
+-- 课程只读副本添加 category_id 列，与中央服务器保持一致
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS category_id INTEGER;
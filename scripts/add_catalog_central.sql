@@ -0,0 +1,22 @@
+-- 课程分类与标签（中央服务器）
+-- 创建 categories 和 course_tags 表，为 courses 表添加 category_id
+-- 在中央服务器数据库（learning_central）中执行
+
+CREATE TABLE IF NOT EXISTS categories (
+    category_id SERIAL PRIMARY KEY,
+    category_name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS course_tags (
+    course_id INTEGER NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    PRIMARY KEY (course_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_course_tags_tag ON course_tags(tag);
+
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS category_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_courses_category_id ON courses(category_id);
@@ -0,0 +1,19 @@
+-- 课程教学团队（中央服务器）
+-- 创建 course_staff 表，并将现有课程的 instructor_id 登记为主讲教师
+-- 在中央服务器数据库（learning_central）中执行
+
+CREATE TABLE IF NOT EXISTS course_staff (
+    staff_id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    instructor_id INTEGER NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_course_staff_member ON course_staff(course_id, instructor_id);
+CREATE INDEX IF NOT EXISTS idx_course_staff_instructor_id ON course_staff(instructor_id);
+
+INSERT INTO course_staff (course_id, instructor_id, role)
+SELECT course_id, instructor_id, 'instructor' FROM courses
+ON CONFLICT (course_id, instructor_id) DO NOTHING;
@@ -0,0 +1,17 @@
+-- 任务截止时间和作业迟交标记（分支节点）
+-- 任务只读副本添加对应列，作业记录提交时的迟交状态和迟交扣分
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS cutoff_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS late_policy VARCHAR(30) DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS late_penalty_percent INTEGER DEFAULT 0;
+
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS raw_score INTEGER DEFAULT 0;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS late_penalty INTEGER DEFAULT 0;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS is_late BOOLEAN DEFAULT FALSE;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS late_days INTEGER DEFAULT 0;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS after_cutoff BOOLEAN DEFAULT FALSE;
+
+-- 已有作业的原始得分即为当前得分
+UPDATE answers SET raw_score = score WHERE raw_score = 0 AND score <> 0;
@@ -0,0 +1,24 @@
+-- 任务截止时间、迟交策略和学生延期（中央服务器）
+-- late_policy 为空时迟交不扣分，percent_per_day 按天扣除 late_penalty_percent，zero_after_cutoff 最终截止后计 0 分
+-- 在中央服务器数据库（learning_central）中执行
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS cutoff_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS late_policy VARCHAR(30) DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS late_penalty_percent INTEGER DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS task_extensions (
+    extension_id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(task_id) ON DELETE CASCADE,
+    branch_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    due_at TIMESTAMP,
+    cutoff_at TIMESTAMP,
+    reason TEXT,
+    granted_by_user_id INTEGER,
+    granted_by_branch_id INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_extensions_student ON task_extensions(task_id, branch_id, user_id);
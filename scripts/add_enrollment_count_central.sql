@@ -0,0 +1,7 @@
+-- 课程报名人数（中央服务器）
+-- 课程目录按热度排序时直接读取 enrollment_count，不再逐个分支统计
+-- 服务启动时会按各分支的学习记录重新统计，已有课程无需手动回填
+-- 在中央服务器数据库（learning_central）中执行
+
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS enrollment_count INTEGER DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_courses_enrollment_count ON courses(enrollment_count);
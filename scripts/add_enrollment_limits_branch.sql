@@ -0,0 +1,9 @@
+-- 报名时间窗口与人数上限（分支节点）
+-- 只读副本添加对应列，learning 表增加按课程和状态统计报名人数的索引
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS enrollment_start_at TIMESTAMP;
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS enrollment_end_at TIMESTAMP;
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS capacity INTEGER DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_learning_course_status ON learning(course_id, status);
@@ -0,0 +1,7 @@
+-- 报名时间窗口与人数上限（中央服务器）
+-- 为 courses 表添加报名开始/截止时间和人数上限
+-- 在中央服务器数据库（learning_central）中执行
+
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS enrollment_start_at TIMESTAMP;
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS enrollment_end_at TIMESTAMP;
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS capacity INTEGER DEFAULT 0;
@@ -0,0 +1,16 @@
+-- 课时完成记录（分支节点）
+-- 课程进度由已完成课时和已提交任务计算，学生不能再直接修改进度百分比
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+CREATE TABLE IF NOT EXISTS lesson_progress (
+    lesson_progress_id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    course_id INTEGER NOT NULL,
+    lesson_id INTEGER NOT NULL,
+    completed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, lesson_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lesson_progress_user_course ON lesson_progress(user_id, course_id);
@@ -0,0 +1,29 @@
+-- 先修课程与报名限制（中央服务器）
+-- 创建 course_prerequisites 和 prerequisite_overrides 表
+-- 在中央服务器数据库（learning_central）中执行
+
+CREATE TABLE IF NOT EXISTS course_prerequisites (
+    prerequisite_id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    prerequisite_course_id INTEGER NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    min_status VARCHAR(20) NOT NULL DEFAULT 'completed',
+    min_score INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_course_prerequisites_pair ON course_prerequisites(course_id, prerequisite_course_id);
+CREATE INDEX IF NOT EXISTS idx_course_prerequisites_prerequisite_course_id ON course_prerequisites(prerequisite_course_id);
+
+CREATE TABLE IF NOT EXISTS prerequisite_overrides (
+    override_id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    branch_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    reason TEXT,
+    granted_by_user_id INTEGER,
+    granted_by_branch_id INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prerequisite_overrides_student ON prerequisite_overrides(course_id, branch_id, user_id);
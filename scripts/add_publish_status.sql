@@ -0,0 +1,22 @@
+-- 为课程/章节/课时添加发布状态和定时发布时间
+-- 中央服务器和分支节点（只读副本）都需要执行
+-- 已有内容视为已发布，避免升级后从学生目录中消失；之后新建的内容默认为草稿
+
+-- courses表
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS publish_status VARCHAR(20);
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP;
+UPDATE courses SET publish_status = 'published' WHERE publish_status IS NULL;
+ALTER TABLE courses ALTER COLUMN publish_status SET DEFAULT 'draft';
+CREATE INDEX IF NOT EXISTS idx_courses_publish_status ON courses(publish_status);
+
+-- chapters表
+ALTER TABLE chapters ADD COLUMN IF NOT EXISTS publish_status VARCHAR(20);
+ALTER TABLE chapters ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP;
+UPDATE chapters SET publish_status = 'published' WHERE publish_status IS NULL;
+ALTER TABLE chapters ALTER COLUMN publish_status SET DEFAULT 'draft';
+
+-- lessons表
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS publish_status VARCHAR(20);
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP;
+UPDATE lessons SET publish_status = 'published' WHERE publish_status IS NULL;
+ALTER TABLE lessons ALTER COLUMN publish_status SET DEFAULT 'draft';
@@ -0,0 +1,22 @@
+-- 测验逐题作答（分支节点）
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+CREATE TABLE IF NOT EXISTS quiz_responses (
+    response_id SERIAL PRIMARY KEY,
+    answer_id INTEGER NOT NULL REFERENCES answers(answer_id) ON DELETE CASCADE,
+    task_id INTEGER NOT NULL,
+    question_id INTEGER NOT NULL,
+    branch_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    response JSONB NOT NULL,
+    max_points NUMERIC(8,2) DEFAULT 0,
+    auto_points NUMERIC(8,2) DEFAULT 0,
+    override_points NUMERIC(8,2),
+    is_correct BOOLEAN DEFAULT FALSE,
+    needs_review BOOLEAN DEFAULT FALSE,
+    graded_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_responses_answer_id ON quiz_responses(answer_id);
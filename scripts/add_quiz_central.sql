@@ -0,0 +1,19 @@
+-- 测验题库（中央服务器）
+-- 在中央服务器数据库（learning_central）中执行
+
+CREATE TABLE IF NOT EXISTS quiz_questions (
+    question_id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(task_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    question_type VARCHAR(30) NOT NULL,
+    prompt TEXT NOT NULL,
+    options JSONB DEFAULT '[]',
+    answer_key JSONB DEFAULT '{}',
+    points NUMERIC(8,2) DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_questions_task_id ON quiz_questions(task_id, position);
+CREATE INDEX IF NOT EXISTS idx_quiz_questions_deleted_at ON quiz_questions(deleted_at);
@@ -0,0 +1,10 @@
+-- 内容修订历史（分支节点）
+-- 只读副本添加 revision 列，answers 表记录提交时的任务版本号
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS revision INTEGER DEFAULT 0;
+ALTER TABLE chapters ADD COLUMN IF NOT EXISTS revision INTEGER DEFAULT 0;
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS revision INTEGER DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS revision INTEGER DEFAULT 0;
+
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS task_revision INTEGER DEFAULT 0;
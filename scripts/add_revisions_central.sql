@@ -0,0 +1,24 @@
+-- 内容修订历史（中央服务器）
+-- 创建 revisions 表，并为课程/章节/课时/任务添加当前版本号
+-- 在中央服务器数据库（learning_central）中执行
+
+CREATE TABLE IF NOT EXISTS revisions (
+    revision_id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    course_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    snapshot JSONB NOT NULL,
+    author_user_id INTEGER,
+    author_branch_id INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_revisions_entity_version ON revisions(entity_type, entity_id, version);
+CREATE INDEX IF NOT EXISTS idx_revisions_course_id ON revisions(course_id);
+
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS revision INTEGER DEFAULT 0;
+ALTER TABLE chapters ADD COLUMN IF NOT EXISTS revision INTEGER DEFAULT 0;
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS revision INTEGER DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS revision INTEGER DEFAULT 0;
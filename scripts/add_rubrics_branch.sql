@@ -0,0 +1,9 @@
+-- 评分标准和批改评语（分支节点）
+-- 任务只读副本添加 rubric 列；作业保存逐项得分、文字评语和行内批注
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS rubric JSONB DEFAULT '[]';
+
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS rubric_scores JSONB DEFAULT '[]';
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS feedback TEXT;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS inline_comments JSONB DEFAULT '[]';
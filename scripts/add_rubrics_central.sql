@@ -0,0 +1,5 @@
+-- 任务的评分标准（中央服务器）
+-- rubric 为评分项数组，每个评分项包含满分和若干等级
+-- 在中央服务器数据库（learning_central）中执行
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS rubric JSONB DEFAULT '[]';
@@ -0,0 +1,18 @@
+-- 全文检索（中央服务器）
+-- 创建 search_documents 表和 GIN 索引，执行后运行 go run ./cmd/searchindex 为已有内容建立索引
+-- 在中央服务器数据库（learning_central）中执行
+
+CREATE TABLE IF NOT EXISTS search_documents (
+    document_id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    course_id INTEGER NOT NULL,
+    title TEXT,
+    body TEXT,
+    search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_documents_entity ON search_documents(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_search_documents_course_id ON search_documents(course_id);
+CREATE INDEX IF NOT EXISTS idx_search_documents_vector ON search_documents USING GIN (search_vector);
@@ -0,0 +1,7 @@
+-- Markdown 课程同步（分支节点）
+-- 只读副本添加 slug 列
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+ALTER TABLE chapters ADD COLUMN IF NOT EXISTS slug VARCHAR(255) DEFAULT '';
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS slug VARCHAR(255) DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS slug VARCHAR(255) DEFAULT '';
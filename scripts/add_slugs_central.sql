@@ -0,0 +1,12 @@
+-- Markdown 课程同步（中央服务器）
+-- 为章节/课时/任务添加稳定标识 slug，同步时按 slug 匹配已有记录
+-- slug 的唯一性由同步逻辑保证（软删除的记录保留原 slug，因此不建唯一索引）
+-- 在中央服务器数据库（learning_central）中执行
+
+ALTER TABLE chapters ADD COLUMN IF NOT EXISTS slug VARCHAR(255) DEFAULT '';
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS slug VARCHAR(255) DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS slug VARCHAR(255) DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_chapters_course_slug ON chapters(course_id, slug);
+CREATE INDEX IF NOT EXISTS idx_lessons_course_slug ON lessons(course_id, slug);
+CREATE INDEX IF NOT EXISTS idx_tasks_lesson_slug ON tasks(lesson_id, slug);
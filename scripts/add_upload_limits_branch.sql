@@ -0,0 +1,6 @@
+-- 任务的作业文件限制（分支节点）
+-- 只读副本添加对应列
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS allowed_types VARCHAR(500) DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS max_file_size BIGINT DEFAULT 0;
@@ -0,0 +1,6 @@
+-- 任务的作业文件限制（中央服务器）
+-- allowed_types 为逗号分隔的 MIME 类型，为空和 max_file_size 为 0 时使用全局配置
+-- 在中央服务器数据库（learning_central）中执行
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS allowed_types VARCHAR(500) DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS max_file_size BIGINT DEFAULT 0;
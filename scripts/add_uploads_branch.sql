@@ -0,0 +1,29 @@
+-- 作业文件直传上传记录（分支节点）
+-- 客户端申请上传位置后直接上传到对象存储，确认时校验大小、类型和 SHA-256
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+CREATE TABLE IF NOT EXISTS uploads (
+    upload_id SERIAL PRIMARY KEY,
+    purpose VARCHAR(20) NOT NULL,
+    user_id INTEGER NOT NULL,
+    branch_id INTEGER NOT NULL,
+    course_id INTEGER,
+    chapter_id INTEGER,
+    task_id INTEGER,
+    object_key VARCHAR(500) NOT NULL,
+    file_name VARCHAR(255),
+    content_type VARCHAR(100),
+    size BIGINT DEFAULT 0,
+    sha256 VARCHAR(64),
+    part_size BIGINT DEFAULT 0,
+    part_count INTEGER DEFAULT 1,
+    storage_upload_id VARCHAR(255),
+    status VARCHAR(20) DEFAULT 'pending',
+    expires_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_uploads_user ON uploads(user_id, branch_id);
+CREATE INDEX IF NOT EXISTS idx_uploads_status_expires ON uploads(status, expires_at);
@@ -0,0 +1,10 @@
+-- 课时视频处理（分支节点）
+-- 只读副本添加对应列
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS processing_status VARCHAR(20) DEFAULT '';
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS processing_error TEXT;
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS processing_started_at TIMESTAMP;
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS duration INTEGER DEFAULT 0;
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS thumbnail_url TEXT;
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS hls_url TEXT;
@@ -0,0 +1,16 @@
+-- 课时视频处理（中央服务器）
+-- 本Bucket中的视频课时由后台任务读取时长、截取封面并转码为 HLS
+-- 在中央服务器数据库（learning_central）中执行
+
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS processing_status VARCHAR(20) DEFAULT '';
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS processing_error TEXT;
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS processing_started_at TIMESTAMP;
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS duration INTEGER DEFAULT 0;
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS thumbnail_url TEXT;
+ALTER TABLE lessons ADD COLUMN IF NOT EXISTS hls_url TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_lessons_processing_status ON lessons(processing_status);
+
+-- 已有的视频课时加入处理队列
+UPDATE lessons SET processing_status = 'pending'
+WHERE lesson_type = 'video' AND content_url LIKE 'http%' AND processing_status = '' AND deleted_at IS NULL;
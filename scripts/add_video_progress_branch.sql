@@ -0,0 +1,20 @@
+-- 视频观看进度（分支节点）
+-- 记录每个学生在视频课时上的已观看区间和续播位置
+-- 在每个分支节点数据库中执行（learning_branch1, learning_branch2等）
+
+CREATE TABLE IF NOT EXISTS video_progress (
+    video_progress_id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    course_id INTEGER NOT NULL,
+    lesson_id INTEGER NOT NULL,
+    position DOUBLE PRECISION DEFAULT 0,
+    duration DOUBLE PRECISION DEFAULT 0,
+    watched_seconds DOUBLE PRECISION DEFAULT 0,
+    intervals TEXT,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, lesson_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_progress_course_id ON video_progress(course_id);
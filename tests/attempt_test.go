@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"testing"
+
+	"online-learning-platform/internal/attempt"
+)
+
+func TestCountedAttempt(t *testing.T) {
+	attempts := []attempt.Attempt{
+		{Number: 1, Score: 70, Graded: true},
+		{Number: 2, Score: 85, Graded: true},
+		{Number: 3, Score: 85, Graded: true},
+		{Number: 4},
+	}
+	cases := []struct {
+		policy   string
+		attempts []attempt.Attempt
+		want     int
+	}{
+		{attempt.PolicyLatest, attempts, 4},
+		{"", attempts, 4},
+		{attempt.PolicyFirst, attempts, 1},
+		{attempt.PolicyHighest, attempts, 2},
+		{attempt.PolicyHighest, []attempt.Attempt{{Number: 1}, {Number: 2}}, 2},
+		{attempt.PolicyHighest, []attempt.Attempt{{Number: 2, Score: 0, Graded: true}, {Number: 1, Score: 90}}, 2},
+		{attempt.PolicyLatest, nil, 0},
+	}
+	for _, c := range cases {
+		if got := attempt.Counted(c.policy, c.attempts); got != c.want {
+			t.Errorf("Counted(%q, %+v) = %d, want %d", c.policy, c.attempts, got, c.want)
+		}
+	}
+
+	if attempt.ValidPolicy("best") || !attempt.ValidPolicy(attempt.PolicyHighest) {
+		t.Error("ValidPolicy mismatch")
+	}
+	for _, c := range []struct{ max, used, want int }{{0, 5, -1}, {3, 1, 2}, {3, 3, 0}, {3, 4, 0}} {
+		if got := attempt.Remaining(c.max, c.used); got != c.want {
+			t.Errorf("Remaining(%d, %d) = %d, want %d", c.max, c.used, got, c.want)
+		}
+	}
+}
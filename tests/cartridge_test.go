@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"online-learning-platform/internal/cartridge"
+)
+
+func TestCartridgeRoundTrip(t *testing.T) {
+	video := []byte("fake video bytes")
+	course := &cartridge.Course{
+		Identifier:  "course_1",
+		Title:       "Go 语言入门",
+		Description: "从零开始学习 Go",
+		Chapters: []cartridge.Chapter{
+			{
+				Title: "第一章",
+				Lessons: []cartridge.Lesson{
+					{
+						Title: "安装",
+						Type:  cartridge.LessonTypeVideo,
+						File: &cartridge.File{Name: "install.mp4", Open: func() (io.ReadCloser, error) {
+							return io.NopCloser(bytes.NewReader(video)), nil
+						}},
+						Tasks: []cartridge.Task{
+							{Title: "安装截图", Description: "上传安装成功的截图", TaskType: cartridge.TaskTypeUpload, MaxScore: 10},
+						},
+					},
+					{Title: "官方文档", Type: cartridge.LessonTypeText, URL: "https://go.dev/doc/"},
+				},
+			},
+			{
+				Title: "第二章",
+				Lessons: []cartridge.Lesson{
+					{Title: "变量", Type: cartridge.LessonTypeText, Tasks: []cartridge.Task{
+						{Title: "简答题", Description: "什么是零值？", TaskType: cartridge.TaskTypeEssay, MaxScore: 100},
+					}},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := cartridge.Write(&buf, course); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	got, issues, err := cartridge.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("unexpected issues: %+v", issues)
+	}
+
+	if got.Title != course.Title || got.Description != course.Description {
+		t.Errorf("course metadata = %q/%q", got.Title, got.Description)
+	}
+	if len(got.Chapters) != 2 || len(got.Chapters[0].Lessons) != 2 || len(got.Chapters[1].Lessons) != 1 {
+		t.Fatalf("unexpected structure: %+v", got.Chapters)
+	}
+
+	install := got.Chapters[0].Lessons[0]
+	if install.Type != cartridge.LessonTypeVideo || install.File == nil || install.File.Name != "install.mp4" {
+		t.Fatalf("install lesson = %+v", install)
+	}
+	rc, err := install.File.Open()
+	if err != nil {
+		t.Fatalf("open lesson file: %v", err)
+	}
+	content, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(content, video) {
+		t.Errorf("lesson file content = %q", content)
+	}
+	if len(install.Tasks) != 1 || install.Tasks[0].TaskType != cartridge.TaskTypeUpload || install.Tasks[0].MaxScore != 10 {
+		t.Errorf("install tasks = %+v", install.Tasks)
+	}
+
+	if link := got.Chapters[0].Lessons[1]; link.URL != "https://go.dev/doc/" {
+		t.Errorf("web link lesson = %+v", link)
+	}
+
+	essay := got.Chapters[1].Lessons[0]
+	if len(essay.Tasks) != 1 || essay.Tasks[0].Description != "什么是零值？" || essay.Tasks[0].TaskType != cartridge.TaskTypeEssay {
+		t.Errorf("essay tasks = %+v", essay.Tasks)
+	}
+}
+
+func TestCartridgeReadReportsUnsupportedItems(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<manifest identifier="m" xmlns="http://www.imsglobal.org/xsd/imsccv1p1/imscp_v1p1">
+  <organizations>
+    <organization identifier="org" structure="rooted-hierarchy">
+      <item identifier="root">
+        <item identifier="week1">
+          <title>Week 1</title>
+          <item identifier="i1" identifierref="r1"><title>Reading</title></item>
+          <item identifier="i2" identifierref="r2"><title>Forum</title></item>
+          <item identifier="i3" identifierref="r3"><title>Quiz</title></item>
+        </item>
+      </item>
+    </organization>
+  </organizations>
+  <resources>
+    <resource identifier="r1" type="webcontent" href="reading.html"><file href="reading.html"/></resource>
+    <resource identifier="r2" type="imsdt_xmlv1p1"><file href="forum.xml"/></resource>
+    <resource identifier="r3" type="imsqti_xmlv1p2/imscc_xmlv1p1/assessment"><file href="quiz.xml"/></resource>
+  </resources>
+</manifest>`
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range map[string]string{"imsmanifest.xml": manifest, "reading.html": "<p>hi</p>"} {
+		w, _ := zw.Create(name)
+		w.Write([]byte(body))
+	}
+	zw.Close()
+
+	course, issues, err := cartridge.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(course.Chapters) != 1 || len(course.Chapters[0].Lessons) != 1 {
+		t.Fatalf("unexpected structure: %+v", course.Chapters)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("issues = %+v", issues)
+	}
+	if !strings.Contains(issues[0].Reason, "discussion") || !strings.Contains(issues[1].Reason, "quiz") {
+		t.Errorf("issue reasons = %+v", issues)
+	}
+
+	if _, _, err := cartridge.Read(bytes.NewReader([]byte("not a zip")), 9); err == nil {
+		t.Error("expected error for invalid package")
+	}
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"online-learning-platform/internal/deadline"
+)
+
+func TestDeadlineEvaluate(t *testing.T) {
+	due := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+	cutoff := due.Add(3 * deadline.Day)
+	w := deadline.Window{DueAt: &due, CutoffAt: &cutoff}
+
+	cases := []struct {
+		at   time.Time
+		want deadline.Status
+	}{
+		{due, deadline.Status{}},
+		{due.Add(time.Minute), deadline.Status{Late: true, Days: 1}},
+		{due.Add(deadline.Day), deadline.Status{Late: true, Days: 1}},
+		{due.Add(deadline.Day + time.Second), deadline.Status{Late: true, Days: 2}},
+		{cutoff.Add(time.Second), deadline.Status{Late: true, Days: 4, AfterCutoff: true}},
+	}
+	for _, c := range cases {
+		if got := w.Evaluate(c.at); got != c.want {
+			t.Errorf("Evaluate(%v) = %+v, want %+v", c.at, got, c.want)
+		}
+	}
+
+	// 只设置最终截止时间时，之后的提交记为迟交
+	onlyCutoff := deadline.Window{CutoffAt: &cutoff}
+	if got := onlyCutoff.Evaluate(cutoff.Add(time.Hour)); !got.Late || !got.AfterCutoff || got.Days != 1 {
+		t.Errorf("only cutoff = %+v", got)
+	}
+	if got := (deadline.Window{}).Evaluate(due); got != (deadline.Status{}) {
+		t.Errorf("no deadline = %+v", got)
+	}
+
+	// 延期只覆盖设置了的时间点
+	extendedDue := due.Add(2 * deadline.Day)
+	extended := w.Extend(deadline.Window{DueAt: &extendedDue})
+	if *extended.DueAt != extendedDue || *extended.CutoffAt != cutoff {
+		t.Errorf("extended = %v, %v", extended.DueAt, extended.CutoffAt)
+	}
+	early := due.Add(-time.Hour)
+	if err := w.Extend(deadline.Window{CutoffAt: &early}).Validate(); err != deadline.ErrCutoffBeforeDue {
+		t.Errorf("Validate() = %v, want ErrCutoffBeforeDue", err)
+	}
+}
+
+func TestLatePolicy(t *testing.T) {
+	onTime := deadline.Status{}
+	late := deadline.Status{Late: true, Days: 2}
+	closed := deadline.Status{Late: true, Days: 5, AfterCutoff: true}
+
+	perDay := deadline.Policy{Type: deadline.PolicyPercentPerDay, PercentPerDay: 15}
+	cases := []struct {
+		name        string
+		policy      deadline.Policy
+		raw         int
+		status      deadline.Status
+		score, cost int
+	}{
+		{"on time", perDay, 80, onTime, 80, 0},
+		{"two days", perDay, 80, late, 56, 24},
+		{"rounded", perDay, 7, late, 5, 2},
+		{"capped", deadline.Policy{Type: deadline.PolicyPercentPerDay, PercentPerDay: 60}, 80, late, 0, 80},
+		{"none", deadline.Policy{}, 80, late, 80, 0},
+		{"zero before cutoff", deadline.Policy{Type: deadline.PolicyZeroAfterCutoff}, 80, late, 80, 0},
+		{"zero after cutoff", deadline.Policy{Type: deadline.PolicyZeroAfterCutoff}, 80, closed, 0, 80},
+		{"zero score", perDay, 0, late, 0, 0},
+	}
+	for _, c := range cases {
+		score, cost := c.policy.Apply(c.raw, c.status)
+		if score != c.score || cost != c.cost {
+			t.Errorf("%s: Apply = %d/%d, want %d/%d", c.name, score, cost, c.score, c.cost)
+		}
+	}
+
+	if perDay.Accepts(closed) || (deadline.Policy{}).Accepts(closed) {
+		t.Error("submissions after cut-off should be rejected")
+	}
+	if !(deadline.Policy{Type: deadline.PolicyZeroAfterCutoff}).Accepts(closed) || !perDay.Accepts(late) {
+		t.Error("submission should be accepted")
+	}
+
+	for _, p := range []deadline.Policy{
+		{Type: deadline.PolicyPercentPerDay},
+		{Type: deadline.PolicyPercentPerDay, PercentPerDay: 101},
+		{Type: "weekly"},
+	} {
+		if p.Validate() == nil {
+			t.Errorf("Validate(%+v) = nil, want error", p)
+		}
+	}
+}
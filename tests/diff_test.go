@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"online-learning-platform/pkg/utils"
+)
+
+func TestDiffJSONFields(t *testing.T) {
+	from := []byte(`{"task_title":"作业一","max_score":100,"revision":1,"updated_at":"2024-01-01T00:00:00Z"}`)
+	to := []byte(`{"task_title":"作业一","max_score":80,"revision":2,"updated_at":"2024-02-01T00:00:00Z","description":"新增说明"}`)
+
+	got, err := utils.DiffJSONFields(from, to, "revision", "updated_at")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []utils.FieldChange{
+		{Field: "description", From: nil, To: "新增说明"},
+		{Field: "max_score", From: float64(100), To: float64(80)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := utils.DiffJSONFields([]byte(`not json`), to); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"online-learning-platform/internal/mdsync"
+)
+
+func TestMarkdownParse(t *testing.T) {
+	fsys := fstest.MapFS{
+		"course/README.md":                  {Data: []byte("# 课程说明\n")},
+		"course/02-types/01-structs.md":     {Data: []byte("# 结构体\n\n正文\n")},
+		"course/01-intro/_chapter.md":       {Data: []byte("---\ntitle: 入门\ndescription: 环境和第一个程序\n---\n")},
+		"course/01-intro/10-hello.md":       {Data: []byte("---\nslug: hello-world\ntasks:\n  - title: 运行截图\n    type: upload\n    max_score: 10\n---\n# Hello\n\n![图](images/run.png) [文档](https://go.dev)\n")},
+		"course/01-intro/2-install.md":      {Data: []byte("---\ntitle: 安装\nvideo: media/install.mp4\n---\n")},
+		"course/01-intro/images/run.png":    {Data: []byte("png")},
+		"course/01-intro/media/install.mp4": {Data: []byte("mp4")},
+		"course/01-intro/images/.DS_Store":  {Data: []byte{}},
+		"course/.git/config":                {Data: []byte{}},
+	}
+
+	course, err := mdsync.Parse(fsys)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(course.Chapters) != 2 {
+		t.Fatalf("expected 2 chapters, got %d", len(course.Chapters))
+	}
+
+	intro := course.Chapters[0]
+	if intro.Slug != "intro" || intro.Title != "入门" || intro.Description != "环境和第一个程序" {
+		t.Errorf("unexpected chapter: %+v", intro)
+	}
+	if len(intro.Lessons) != 2 {
+		t.Fatalf("expected 2 lessons, got %d", len(intro.Lessons))
+	}
+
+	// 数字前缀按数值排序：2-install 在 10-hello 之前
+	install, hello := intro.Lessons[0], intro.Lessons[1]
+	if install.Slug != "install" || install.Type != mdsync.LessonTypeVideo || !install.VideoFile || install.Video != "01-intro/media/install.mp4" {
+		t.Errorf("unexpected video lesson: %+v", install)
+	}
+	if hello.Slug != "hello-world" || hello.Title != "Hello" || hello.Type != mdsync.LessonTypeText {
+		t.Errorf("unexpected text lesson: %+v", hello)
+	}
+	if len(hello.Assets) != 1 || hello.Assets[0] != "01-intro/images/run.png" {
+		t.Errorf("unexpected assets: %v", hello.Assets)
+	}
+	if len(hello.Tasks) != 1 || hello.Tasks[0].Slug != "task-1" || hello.Tasks[0].TaskType != "upload" || hello.Tasks[0].MaxScore != 10 {
+		t.Errorf("unexpected tasks: %+v", hello.Tasks)
+	}
+
+	body := mdsync.RewriteLinks(hello.Body, hello.Path, map[string]string{"01-intro/images/run.png": "https://cdn.example.com/run.png"})
+	if !strings.Contains(body, "![图](https://cdn.example.com/run.png)") || !strings.Contains(body, "[文档](https://go.dev)") {
+		t.Errorf("unexpected rewritten body: %q", body)
+	}
+
+	if types := course.Chapters[1]; types.Slug != "types" || types.Title != "types" || types.Lessons[0].Title != "结构体" {
+		t.Errorf("unexpected chapter: %+v", types)
+	}
+}
+
+func TestMarkdownParseDuplicateSlug(t *testing.T) {
+	fsys := fstest.MapFS{
+		"01-a/01-intro.md": {Data: []byte("# A\n")},
+		"02-b/01-intro.md": {Data: []byte("# B\n")},
+	}
+
+	if _, err := mdsync.Parse(fsys); err == nil || !strings.Contains(err.Error(), "duplicate lesson slug") {
+		t.Fatalf("expected duplicate slug error, got %v", err)
+	}
+}
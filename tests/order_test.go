@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"online-learning-platform/pkg/utils"
+)
+
+func TestNormalizeOrder(t *testing.T) {
+	cases := []struct {
+		name      string
+		requested []uint
+		existing  []uint
+		want      []uint
+	}{
+		{"完整重排", []uint{3, 1, 2}, []uint{1, 2, 3}, []uint{3, 1, 2}},
+		{"去除重复", []uint{2, 2, 1, 2}, []uint{1, 2}, []uint{2, 1}},
+		{"未列出的追加到末尾", []uint{3}, []uint{1, 2, 3}, []uint{3, 1, 2}},
+		{"移入其他章节的课时", []uint{9, 1}, []uint{1, 2}, []uint{9, 1, 2}},
+		{"空请求保持原顺序", nil, []uint{4, 5}, []uint{4, 5}},
+	}
+
+	for _, tc := range cases {
+		got := utils.NormalizeOrder(tc.requested, tc.existing)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"online-learning-platform/internal/playback"
+)
+
+func TestPlaybackMerge(t *testing.T) {
+	merged := playback.Merge([]playback.Interval{
+		{Start: 30, End: 40},
+		{Start: 0, End: 10},
+		{Start: 10.5, End: 20}, // 空隙小于 MergeGap，视为连续
+		{Start: 5, End: 8},
+		{Start: 35, End: 50},
+	})
+	want := []playback.Interval{{Start: 0, End: 20}, {Start: 30, End: 50}}
+	if len(merged) != len(want) {
+		t.Fatalf("expected %v, got %v", want, merged)
+	}
+	for i := range want {
+		if merged[i] != want[i] {
+			t.Errorf("interval %d: expected %v, got %v", i, want[i], merged[i])
+		}
+	}
+
+	// 重复观看的部分只计一次
+	if got := playback.Watched([]playback.Interval{{Start: 0, End: 60}, {Start: 20, End: 40}}); got != 60 {
+		t.Errorf("expected 60 watched seconds, got %v", got)
+	}
+
+	if _, ok := playback.Clamp(playback.Interval{Start: 120, End: 130}, 100); ok {
+		t.Error("interval past the end of the video should be dropped")
+	}
+	if iv, _ := playback.Clamp(playback.Interval{Start: -5, End: 130}, 100); iv.Start != 0 || iv.End != 100 {
+		t.Errorf("unexpected clamped interval %v", iv)
+	}
+}
+
+func TestPlaybackBuffer(t *testing.T) {
+	buf := playback.NewBuffer()
+	key := playback.Key{BranchID: 1, UserID: 7, LessonID: 3}
+	now := time.Now()
+
+	if _, ok := buf.Record(key, playback.Interval{Start: 0, End: 10}, 10, now); ok {
+		t.Fatal("record should require a seeded entry")
+	}
+
+	buf.Seed(playback.Entry{Key: key, CourseID: 2, Duration: 100, Saved: []playback.Interval{{Start: 0, End: 30}}})
+	buf.Record(key, playback.Interval{Start: 30, End: 40}, 40, now)
+	entry, ok := buf.Record(key, playback.Interval{Start: 40, End: 50}, 50, now.Add(10*time.Second))
+	if !ok {
+		t.Fatal("expected seeded entry")
+	}
+	if len(entry.Pending) != 1 || entry.Pending[0] != (playback.Interval{Start: 30, End: 50}) {
+		t.Errorf("pending heartbeats should be merged, got %v", entry.Pending)
+	}
+	if got := playback.Watched(entry.Intervals()); got != 50 {
+		t.Errorf("expected 50 watched seconds, got %v", got)
+	}
+	if entry.Position != 50 {
+		t.Errorf("expected position 50, got %v", entry.Position)
+	}
+
+	drained := buf.Drain()
+	if len(drained) != 1 || buf.Len() != 0 {
+		t.Fatalf("drain should empty the buffer, got %d entries and %d left", len(drained), buf.Len())
+	}
+
+	// 写入失败放回缓冲后，与新心跳合并
+	buf.Seed(playback.Entry{Key: key, CourseID: 2, Duration: 100})
+	buf.Record(key, playback.Interval{Start: 60, End: 70}, 70, now)
+	buf.Restore(drained[0])
+	entry, _ = buf.Get(key)
+	want := []playback.Interval{{Start: 30, End: 50}, {Start: 60, End: 70}}
+	if len(entry.Pending) != 2 || entry.Pending[0] != want[0] || entry.Pending[1] != want[1] {
+		t.Errorf("expected %v after restore, got %v", want, entry.Pending)
+	}
+
+	// 区间和播放位置按服务端时长截断，时长未知时按原样记录
+	other := playback.Key{BranchID: 1, UserID: 8, LessonID: 3}
+	buf.Seed(playback.Entry{Key: other, Duration: 100})
+	if entry, _ := buf.Record(other, playback.Interval{Start: 90, End: 140}, 140, now); entry.Pending[0].End != 100 || entry.Position != 100 {
+		t.Errorf("expected heartbeat clamped to 100, got %v at %v", entry.Pending, entry.Position)
+	}
+	unknown := playback.Key{BranchID: 1, UserID: 9, LessonID: 3}
+	buf.Seed(playback.Entry{Key: unknown})
+	if entry, _ := buf.Record(unknown, playback.Interval{Start: 0, End: 1}, 1, now); len(entry.Pending) != 1 || entry.Duration != 0 {
+		t.Errorf("expected heartbeat recorded without duration, got %+v", entry)
+	}
+
+	// 连续快速上报时，计入的长度不超过距上次心跳的实际时间
+	fast := playback.Key{BranchID: 1, UserID: 10, LessonID: 3}
+	buf.Seed(playback.Entry{Key: fast, Duration: 3600, UpdatedAt: now})
+	buf.Record(fast, playback.Interval{Start: 0, End: 60}, 60, now.Add(60*time.Second))
+	entry, _ = buf.Record(fast, playback.Interval{Start: 60, End: 120}, 120, now.Add(61*time.Second))
+	if got := playback.Watched(entry.Intervals()); got != 60+1+playback.HeartbeatTolerance {
+		t.Errorf("expected rapid heartbeat limited by elapsed time, got %v watched", got)
+	}
+}
+
+func TestPlaybackClampAll(t *testing.T) {
+	got := playback.ClampAll([]playback.Interval{{Start: -5, End: 20}, {Start: 30, End: 500}, {Start: 150, End: 200}}, 100)
+	want := []playback.Interval{{Start: 0, End: 20}, {Start: 30, End: 100}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ClampAll = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"online-learning-platform/internal/qti"
+	"online-learning-platform/internal/quiz"
+)
+
+func TestQTIRoundTrip(t *testing.T) {
+	bank := &qti.Bank{
+		Identifier: "task_7",
+		Items: []qti.Item{
+			{Identifier: "q_1", Prompt: "Go 的零值是什么？", Question: quiz.Question{
+				Type:    quiz.TypeSingleChoice,
+				Options: []quiz.Option{{ID: "a", Text: "nil"}, {ID: "b", Text: "取决于类型"}},
+				Key:     quiz.Key{Choices: []string{"b"}},
+				Points:  2,
+			}},
+			{Identifier: "q_2", Prompt: "哪些是引用类型？", Question: quiz.Question{
+				Type:    quiz.TypeMultipleChoice,
+				Options: []quiz.Option{{ID: "1", Text: "map"}, {ID: "2", Text: "slice"}, {ID: "3", Text: "int"}},
+				Key:     quiz.Key{Choices: []string{"1", "2"}},
+				Points:  3,
+			}},
+			{Identifier: "q_3", Prompt: "goroutine 由操作系统调度", Question: quiz.Question{
+				Type:   quiz.TypeTrueFalse,
+				Key:    quiz.Key{Choices: []string{quiz.ChoiceFalse}},
+				Points: 1,
+			}},
+			{Identifier: "q_4", Prompt: "启动协程的关键字\n（小写）", Question: quiz.Question{
+				Type:   quiz.TypeShortAnswer,
+				Key:    quiz.Key{Texts: []string{"go", "Go"}},
+				Points: 1,
+			}},
+			{Identifier: "q_5", Prompt: "圆周率保留两位小数", Question: quiz.Question{
+				Type:   quiz.TypeNumeric,
+				Key:    quiz.Key{Number: floatPtr(3.14), Tolerance: 0.005},
+				Points: 1.5,
+			}},
+			{Identifier: "q_6", Prompt: "简述 channel 的用途", Question: quiz.Question{
+				Type:   quiz.TypeShortAnswer,
+				Points: 5,
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := qti.Write(&buf, bank); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got, issues, err := qti.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("unexpected issues: %+v", issues)
+	}
+	if len(got.Items) != len(bank.Items) {
+		t.Fatalf("items = %d, want %d", len(got.Items), len(bank.Items))
+	}
+
+	// 选项ID不是合法的 QTI 标识符时按顺序重新编号
+	renumbered := bank.Items[1].Question
+	renumbered.Options = []quiz.Option{{ID: "choice_1", Text: "map"}, {ID: "choice_2", Text: "slice"}, {ID: "choice_3", Text: "int"}}
+	renumbered.Key = quiz.Key{Choices: []string{"choice_1", "choice_2"}}
+	bank.Items[1].Question = renumbered
+
+	for i, want := range bank.Items {
+		it := got.Items[i]
+		if it.Identifier != want.Identifier || it.Prompt != want.Prompt {
+			t.Errorf("item %d = %q/%q, want %q/%q", i, it.Identifier, it.Prompt, want.Identifier, want.Prompt)
+		}
+		if !reflect.DeepEqual(it.Question, want.Question) {
+			t.Errorf("item %d question = %+v, want %+v", i, it.Question, want.Question)
+		}
+	}
+}
+
+func TestQTIReadUnsupported(t *testing.T) {
+	item := func(id, body, declarations string) string {
+		return `<?xml version="1.0" encoding="UTF-8"?>
+<assessmentItem xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" identifier="` + id + `" title="` + id + `" adaptive="false" timeDependent="false">
+` + declarations + `
+<itemBody>` + body + `</itemBody>
+</assessmentItem>`
+	}
+	files := map[string]string{
+		"choice.xml": item("choice", `<p>Capital of France?&nbsp;</p>
+<choiceInteraction responseIdentifier="RESPONSE" maxChoices="1"><simpleChoice identifier="A">Paris</simpleChoice><simpleChoice identifier="B">Rome</simpleChoice></choiceInteraction>`,
+			`<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="identifier"><correctResponse><value>A</value></correctResponse></responseDeclaration>`),
+		"essay.xml": item("essay", `<extendedTextInteraction responseIdentifier="RESPONSE"/>`,
+			`<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="string"/>`),
+		"order.xml": item("order", `<orderInteraction responseIdentifier="RESPONSE"><simpleChoice identifier="A">1</simpleChoice></orderInteraction>`,
+			`<responseDeclaration identifier="RESPONSE" cardinality="ordered" baseType="identifier"/>`),
+		"two.xml": item("two", `<p><textEntryInteraction responseIdentifier="R1"/><textEntryInteraction responseIdentifier="R2"/></p>`,
+			`<responseDeclaration identifier="R1" baseType="string"/><responseDeclaration identifier="R2" baseType="string"/>`),
+		"relative.xml": item("relative", `<p>g = ? <textEntryInteraction responseIdentifier="RESPONSE"/></p>`,
+			`<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="float"><correctResponse><value>9.8</value></correctResponse></responseDeclaration>`),
+	}
+	files["relative.xml"] = strings.Replace(files["relative.xml"], "</assessmentItem>",
+		`<responseProcessing><responseCondition><responseIf><equal toleranceMode="relative" tolerance="10"><variable identifier="RESPONSE"/><correct identifier="RESPONSE"/></equal></responseIf></responseCondition></responseProcessing></assessmentItem>`, 1)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	manifest := `<?xml version="1.0"?><manifest identifier="m" xmlns="http://www.imsglobal.org/xsd/imscp_v1p1"><resources>`
+	for _, name := range []string{"choice.xml", "essay.xml", "order.xml", "two.xml", "relative.xml"} {
+		manifest += `<resource identifier="` + strings.TrimSuffix(name, ".xml") + `" type="imsqti_item_xmlv2p1" href="` + name + `"/>`
+		w, _ := zw.Create(name)
+		w.Write([]byte(files[name]))
+	}
+	manifest += `<resource identifier="old" type="imsqti_xmlv1p2" href="old.xml"/><resource identifier="missing" type="imsqti_item_xmlv2p1" href="missing.xml"/></resources></manifest>`
+	w, _ := zw.Create("imsmanifest.xml")
+	w.Write([]byte(manifest))
+	zw.Close()
+
+	bank, issues, err := qti.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(bank.Items) != 2 {
+		t.Fatalf("items = %+v", bank.Items)
+	}
+	choice := bank.Items[0]
+	if choice.Prompt != "Capital of France?" || choice.Question.Type != quiz.TypeSingleChoice ||
+		len(choice.Question.Options) != 2 || choice.Question.Key.Choices[0] != "A" || choice.Question.Points != 1 {
+		t.Errorf("choice item = %+v", choice)
+	}
+	relative := bank.Items[1].Question
+	if relative.Type != quiz.TypeNumeric || *relative.Key.Number != 9.8 || relative.Key.Tolerance < 0.979 || relative.Key.Tolerance > 0.981 {
+		t.Errorf("relative item = %+v", relative)
+	}
+
+	reasons := map[string]string{}
+	for _, issue := range issues {
+		reasons[issue.Identifier] = issue.Reason
+	}
+	for id, want := range map[string]string{
+		"essay":   "extendedTextInteraction is not supported",
+		"order":   "orderInteraction is not supported",
+		"two":     "more than one interaction",
+		"old":     "QTI 1.x",
+		"missing": "not found",
+	} {
+		if !strings.Contains(reasons[id], want) {
+			t.Errorf("issue %s = %q, want %q", id, reasons[id], want)
+		}
+	}
+
+	// 单个题目文件
+	single := []byte(files["choice.xml"])
+	bank, issues, err = qti.Read(bytes.NewReader(single), int64(len(single)))
+	if err != nil || len(bank.Items) != 1 || len(issues) != 0 {
+		t.Errorf("read single item: %v, %+v, %+v", err, bank, issues)
+	}
+	if _, _, err := qti.Read(strings.NewReader("hello"), 5); err == nil {
+		t.Error("read invalid file: want error")
+	}
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"testing"
+
+	"online-learning-platform/internal/quiz"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestQuizValidate(t *testing.T) {
+	options := []quiz.Option{{ID: "a", Text: "A"}, {ID: "b", Text: "B"}, {ID: "c", Text: "C"}}
+	tests := []struct {
+		name    string
+		q       quiz.Question
+		wantErr bool
+	}{
+		{"single choice", quiz.Question{Type: quiz.TypeSingleChoice, Options: options, Key: quiz.Key{Choices: []string{"b"}}, Points: 1}, false},
+		{"single choice with two answers", quiz.Question{Type: quiz.TypeSingleChoice, Options: options, Key: quiz.Key{Choices: []string{"a", "b"}}, Points: 1}, true},
+		{"multiple choice", quiz.Question{Type: quiz.TypeMultipleChoice, Options: options, Key: quiz.Key{Choices: []string{"a", "c"}}, Points: 2}, false},
+		{"unknown choice", quiz.Question{Type: quiz.TypeMultipleChoice, Options: options, Key: quiz.Key{Choices: []string{"d"}}, Points: 2}, true},
+		{"duplicate option", quiz.Question{Type: quiz.TypeSingleChoice, Options: []quiz.Option{{ID: "a"}, {ID: "a"}}, Key: quiz.Key{Choices: []string{"a"}}, Points: 1}, true},
+		{"true false", quiz.Question{Type: quiz.TypeTrueFalse, Key: quiz.Key{Choices: []string{quiz.ChoiceFalse}}, Points: 1}, false},
+		{"true false invalid", quiz.Question{Type: quiz.TypeTrueFalse, Key: quiz.Key{Choices: []string{"yes"}}, Points: 1}, true},
+		{"short answer without key", quiz.Question{Type: quiz.TypeShortAnswer, Points: 5}, false},
+		{"numeric without answer", quiz.Question{Type: quiz.TypeNumeric, Points: 1}, true},
+		{"zero points", quiz.Question{Type: quiz.TypeNumeric, Key: quiz.Key{Number: floatPtr(1)}}, true},
+		{"unknown type", quiz.Question{Type: "essay", Points: 1}, true},
+	}
+	for _, tt := range tests {
+		if err := quiz.Validate(tt.q); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestQuizGrade(t *testing.T) {
+	options := []quiz.Option{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+	multiple := quiz.Question{Type: quiz.TypeMultipleChoice, Options: options, Key: quiz.Key{Choices: []string{"a", "b", "c"}}, Points: 3}
+	short := quiz.Question{Type: quiz.TypeShortAnswer, Key: quiz.Key{Texts: []string{"Go Routine"}}, Points: 2}
+	numeric := quiz.Question{Type: quiz.TypeNumeric, Key: quiz.Key{Number: floatPtr(3.14), Tolerance: 0.01}, Points: 1}
+
+	tests := []struct {
+		name string
+		q    quiz.Question
+		r    quiz.Response
+		want quiz.Result
+	}{
+		{"single choice", quiz.Question{Type: quiz.TypeSingleChoice, Key: quiz.Key{Choices: []string{"b"}}, Points: 1}, quiz.Response{Choices: []string{"b"}}, quiz.Result{Points: 1, Correct: true}},
+		{"single choice with extra", quiz.Question{Type: quiz.TypeSingleChoice, Key: quiz.Key{Choices: []string{"b"}}, Points: 1}, quiz.Response{Choices: []string{"b", "c"}}, quiz.Result{}},
+		{"multiple all", multiple, quiz.Response{Choices: []string{"c", "a", "b"}}, quiz.Result{Points: 3, Correct: true}},
+		{"multiple partial", multiple, quiz.Response{Choices: []string{"a", "b"}}, quiz.Result{Points: 2}},
+		{"multiple with wrong", multiple, quiz.Response{Choices: []string{"a", "b", "d"}}, quiz.Result{Points: 1}},
+		{"multiple duplicates", multiple, quiz.Response{Choices: []string{"a", "a", "a"}}, quiz.Result{Points: 1}},
+		{"multiple floor", multiple, quiz.Response{Choices: []string{"d"}}, quiz.Result{}},
+		{"short answer match", short, quiz.Response{Text: "  go   routine "}, quiz.Result{Points: 2, Correct: true}},
+		{"short answer review", short, quiz.Response{Text: "thread"}, quiz.Result{NeedsReview: true}},
+		{"short answer empty", short, quiz.Response{}, quiz.Result{}},
+		{"short answer without key", quiz.Question{Type: quiz.TypeShortAnswer, Points: 2}, quiz.Response{Text: "goroutine"}, quiz.Result{NeedsReview: true}},
+		{"short answer without key empty", quiz.Question{Type: quiz.TypeShortAnswer, Points: 2}, quiz.Response{Text: "  "}, quiz.Result{}},
+		{"short answer case sensitive", quiz.Question{Type: quiz.TypeShortAnswer, Key: quiz.Key{Texts: []string{"Go"}, CaseSensitive: true}, Points: 1}, quiz.Response{Text: "go"}, quiz.Result{NeedsReview: true}},
+		{"numeric within tolerance", numeric, quiz.Response{Number: floatPtr(3.15)}, quiz.Result{Points: 1, Correct: true}},
+		{"numeric outside tolerance", numeric, quiz.Response{Number: floatPtr(3.2)}, quiz.Result{}},
+		{"numeric missing", numeric, quiz.Response{}, quiz.Result{}},
+	}
+	for _, tt := range tests {
+		if got := quiz.Grade(tt.q, tt.r); got != tt.want {
+			t.Errorf("%s: Grade() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQuizScale(t *testing.T) {
+	tests := []struct {
+		earned, total float64
+		maxScore      int
+		want          int
+	}{
+		{3, 4, 100, 75},
+		{2, 3, 100, 67},
+		{0, 4, 100, 0},
+		{5, 4, 10, 10},
+		{1, 0, 100, 0},
+	}
+	for _, tt := range tests {
+		if got := quiz.Scale(tt.earned, tt.total, tt.maxScore); got != tt.want {
+			t.Errorf("Scale(%g, %g, %d) = %d, want %d", tt.earned, tt.total, tt.maxScore, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"online-learning-platform/internal/rubric"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestRubricNormalize(t *testing.T) {
+	criteria, err := rubric.Normalize([]rubric.Criterion{
+		{Title: " 论证 ", Levels: []rubric.Level{{Title: "优秀", Points: 10}, {Title: "合格", Points: 6}}},
+		{ID: "style", Title: "表达", MaxPoints: 5, Levels: []rubric.Level{{ID: "a", Title: "清晰", Points: 5}, {ID: "a", Title: "一般", Points: 3}}},
+		{ID: "style", Title: "格式", MaxPoints: 2},
+	})
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if criteria[0].ID != "criterion_1" || criteria[0].Title != "论证" || criteria[0].MaxPoints != 10 {
+		t.Errorf("criteria[0] = %+v", criteria[0])
+	}
+	if criteria[1].Levels[0].ID != "a" || criteria[1].Levels[1].ID != "level_2" {
+		t.Errorf("levels = %+v", criteria[1].Levels)
+	}
+	if criteria[2].ID != "criterion_3" {
+		t.Errorf("duplicate criterion id = %q", criteria[2].ID)
+	}
+	if got := rubric.MaxTotal(criteria); got != 17 {
+		t.Errorf("MaxTotal() = %d, want 17", got)
+	}
+
+	for _, bad := range [][]rubric.Criterion{
+		{{Title: ""}},
+		{{Title: "无分数"}},
+		{{Title: "负分", Levels: []rubric.Level{{Title: "差", Points: -1}}}},
+		{{Title: "超出满分", MaxPoints: 3, Levels: []rubric.Level{{Title: "好", Points: 5}}}},
+	} {
+		if _, err := rubric.Normalize(bad); !errors.Is(err, rubric.ErrInvalidRubric) {
+			t.Errorf("Normalize(%+v) = %v, want ErrInvalidRubric", bad, err)
+		}
+	}
+}
+
+func TestRubricGrade(t *testing.T) {
+	criteria, err := rubric.Normalize([]rubric.Criterion{
+		{ID: "logic", Title: "论证", Levels: []rubric.Level{{ID: "good", Title: "优秀", Points: 10}, {ID: "ok", Title: "合格", Points: 6}}},
+		{ID: "style", Title: "表达", MaxPoints: 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scores, total, err := rubric.Grade(criteria, []rubric.ScoreInput{
+		{CriterionID: "style", Points: intPtr(4), Comment: " 用词准确 "},
+		{CriterionID: "logic", LevelID: "ok"},
+	})
+	if err != nil {
+		t.Fatalf("Grade() error = %v", err)
+	}
+	if total != 10 || len(scores) != 2 {
+		t.Fatalf("Grade() = %+v, %d", scores, total)
+	}
+	if scores[0].CriterionID != "logic" || scores[0].LevelTitle != "合格" || scores[0].Points != 6 || scores[0].MaxPoints != 10 {
+		t.Errorf("scores[0] = %+v", scores[0])
+	}
+	if scores[1].Comment != "用词准确" {
+		t.Errorf("comment = %q", scores[1].Comment)
+	}
+
+	// 选择等级后仍可微调分数
+	if _, total, _ := rubric.Grade(criteria, []rubric.ScoreInput{
+		{CriterionID: "logic", LevelID: "good", Points: intPtr(9)},
+		{CriterionID: "style", Points: intPtr(0)},
+	}); total != 9 {
+		t.Errorf("adjusted total = %d, want 9", total)
+	}
+
+	cases := []struct {
+		name   string
+		inputs []rubric.ScoreInput
+		want   error
+	}{
+		{"missing", []rubric.ScoreInput{{CriterionID: "logic", LevelID: "ok"}}, rubric.ErrInvalidScores},
+		{"duplicate", []rubric.ScoreInput{{CriterionID: "logic", LevelID: "ok"}, {CriterionID: "logic", LevelID: "ok"}, {CriterionID: "style", Points: intPtr(1)}}, rubric.ErrInvalidScores},
+		{"unknown criterion", []rubric.ScoreInput{{CriterionID: "logic", LevelID: "ok"}, {CriterionID: "style", Points: intPtr(1)}, {CriterionID: "extra", Points: intPtr(1)}}, rubric.ErrInvalidScores},
+		{"unknown level", []rubric.ScoreInput{{CriterionID: "logic", LevelID: "bad"}, {CriterionID: "style", Points: intPtr(1)}}, rubric.ErrInvalidScores},
+		{"no points", []rubric.ScoreInput{{CriterionID: "logic", LevelID: "ok"}, {CriterionID: "style"}}, rubric.ErrInvalidScores},
+		{"too high", []rubric.ScoreInput{{CriterionID: "logic", LevelID: "ok"}, {CriterionID: "style", Points: intPtr(6)}}, rubric.ErrOutOfRange},
+		{"negative", []rubric.ScoreInput{{CriterionID: "logic", Points: intPtr(-1)}, {CriterionID: "style", Points: intPtr(1)}}, rubric.ErrOutOfRange},
+	}
+	for _, c := range cases {
+		if _, _, err := rubric.Grade(criteria, c.inputs); !errors.Is(err, c.want) {
+			t.Errorf("%s: Grade() error = %v, want %v", c.name, err, c.want)
+		}
+	}
+}
+
+func TestAnchorComments(t *testing.T) {
+	comments, err := rubric.AnchorComments("光合作用需要光照", []rubric.InlineComment{
+		{Start: 0, End: 4, Comment: " 术语正确 "},
+	})
+	if err != nil {
+		t.Fatalf("AnchorComments() error = %v", err)
+	}
+	if comments[0].Quote != "光合作用" || comments[0].Comment != "术语正确" {
+		t.Errorf("comments[0] = %+v", comments[0])
+	}
+
+	for _, bad := range []rubric.InlineComment{
+		{Start: 0, End: 4},
+		{Start: 3, End: 3, Comment: "空范围"},
+		{Start: 5, End: 9, Comment: "超出文本"},
+		{Start: -1, End: 2, Comment: "负数"},
+	} {
+		if _, err := rubric.AnchorComments("光合作用需要光照", []rubric.InlineComment{bad}); !errors.Is(err, rubric.ErrInvalidComment) {
+			t.Errorf("AnchorComments(%+v) = %v, want ErrInvalidComment", bad, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"online-learning-platform/internal/upload"
+)
+
+// fakeClamd 模拟 clamd 的 INSTREAM 命令，内容包含 EICAR 时报告威胁
+func fakeClamd(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				if cmd, err := r.ReadString(0); err != nil || cmd != "zINSTREAM\x00" {
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+				var data bytes.Buffer
+				for {
+					var size uint32
+					if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					if _, err := io.CopyN(&data, r, int64(size)); err != nil {
+						return
+					}
+				}
+				if strings.Contains(data.String(), "EICAR") {
+					conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+					return
+				}
+				conn.Write([]byte("stream: OK\x00"))
+			}(conn)
+		}
+	}()
+	return "tcp://" + ln.Addr().String()
+}
+
+func TestClamAVScanner(t *testing.T) {
+	scanner, err := upload.NewClamAVScanner(fakeClamd(t), 5*time.Second)
+	if err != nil {
+		t.Fatalf("new scanner: %v", err)
+	}
+
+	// 超过一个数据块的内容需要分块发送
+	clean := bytes.Repeat([]byte("clean content "), 10000)
+	threat, err := scanner.Scan(context.Background(), bytes.NewReader(clean))
+	if err != nil || threat != "" {
+		t.Errorf("clean file: threat %q, err %v", threat, err)
+	}
+
+	threat, err = scanner.Scan(context.Background(), strings.NewReader("X5O!P%@AP EICAR-STANDARD-ANTIVIRUS-TEST-FILE"))
+	if err != nil || threat != "Eicar-Test-Signature" {
+		t.Errorf("infected file: threat %q, err %v", threat, err)
+	}
+
+	if _, err := upload.NewClamAVScanner("", time.Second); err == nil {
+		t.Errorf("empty address should be rejected")
+	}
+}
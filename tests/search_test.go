@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"online-learning-platform/internal/search"
+)
+
+func TestSearchSegment(t *testing.T) {
+	if got := search.SegmentDocument("Go 机器学习入门"); got != "Go 机 器 学 习 入 门 机器 器学 学习 习入 入门" {
+		t.Fatalf("unexpected document segmentation: %q", got)
+	}
+	if got := search.SegmentQuery("机器学习 tutorials"); got != "机器 器学 学习 tutorials" {
+		t.Fatalf("unexpected query segmentation: %q", got)
+	}
+	if got := search.SegmentQuery("go -入门课"); got != `go -"入门 门课"` {
+		t.Fatalf("unexpected excluded phrase: %q", got)
+	}
+	if got := search.SegmentQuery("学"); got != "学" {
+		t.Fatalf("single character should stay as is: %q", got)
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	terms := search.Terms("Learning 机器 -draft")
+	if !reflect.DeepEqual(terms, []string{"learn", "机器"}) {
+		t.Fatalf("unexpected terms: %v", terms)
+	}
+
+	got := search.Snippet("Machine learning 入门：机器学习 <basics>", terms, 100)
+	want := "Machine <mark>learning</mark> 入门：<mark>机器</mark>学习 &lt;basics&gt;"
+	if got != want {
+		t.Fatalf("unexpected snippet:\n got %q\nwant %q", got, want)
+	}
+
+	got = search.Snippet("0123456789 relearn learned", []string{"learn"}, 12)
+	if got != "…earn <mark>learned</mark>" {
+		t.Fatalf("unexpected window: %q", got)
+	}
+}
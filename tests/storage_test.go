@@ -0,0 +1,235 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"online-learning-platform/internal/config"
+	"online-learning-platform/internal/oss"
+)
+
+func TestLocalStorage(t *testing.T) {
+	err := oss.InitOSSClient(config.OSSConfig{
+		Provider:        oss.ProviderLocal,
+		LocalDir:        t.TempDir(),
+		PublicURL:       "http://localhost:8080/",
+		AccessKeySecret: "secret",
+	})
+	if err != nil {
+		t.Fatalf("init local storage: %v", err)
+	}
+
+	objectURL, err := oss.UploadReader(context.Background(), "courses/1/lessons/a.txt", bytes.NewReader([]byte("hello")))
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if objectURL != "http://localhost:8080/files/courses/1/lessons/a.txt" {
+		t.Errorf("unexpected object url %s", objectURL)
+	}
+	if key, ok := oss.ObjectKeyFromURL(objectURL); !ok || key != "courses/1/lessons/a.txt" {
+		t.Errorf("unexpected key %q", key)
+	}
+
+	info, err := oss.StatObject("courses/1/lessons/a.txt")
+	if err != nil || info.Size != 5 {
+		t.Fatalf("stat: %v %+v", err, info)
+	}
+	if exists, _ := oss.ObjectExists("courses/1/lessons/missing.txt"); exists {
+		t.Errorf("missing object should not exist")
+	}
+	if _, err := oss.UploadReader(context.Background(), "../escape.txt", strings.NewReader("x")); err == nil {
+		t.Errorf("keys outside the root should be rejected")
+	}
+
+	h, ok := oss.LocalHandler()
+	if !ok {
+		t.Fatal("local handler should be available")
+	}
+	server := httptest.NewServer(http.StripPrefix("/files/", h))
+	defer server.Close()
+	toServer := func(signed string) string {
+		return strings.Replace(signed, "http://localhost:8080", server.URL, 1)
+	}
+
+	signed, err := oss.SignObjectURL(objectURL, time.Minute)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	resp, err := http.Get(toServer(signed))
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "hello" {
+		t.Errorf("signed get: status %d, body %q", resp.StatusCode, body)
+	}
+
+	// 未签名或签名的方法不匹配时拒绝访问
+	resp, err = http.Get(server.URL + "/files/courses/1/lessons/a.txt")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("unsigned get: expected 403, got %d", resp.StatusCode)
+	}
+	req, _ := http.NewRequest(http.MethodPut, toServer(signed), strings.NewReader("overwrite"))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("put with get signature: expected 403, got %d", resp.StatusCode)
+	}
+
+	if err := oss.DeleteObject("courses/1/lessons/a.txt"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := oss.StatObject("courses/1/lessons/a.txt"); err != oss.ErrObjectNotFound {
+		t.Errorf("expected ErrObjectNotFound after delete, got %v", err)
+	}
+}
+
+func TestLocalStorageMultipart(t *testing.T) {
+	err := oss.InitOSSClient(config.OSSConfig{
+		Provider:        oss.ProviderLocal,
+		LocalDir:        t.TempDir(),
+		PublicURL:       "http://localhost:8080",
+		AccessKeySecret: "secret",
+	})
+	if err != nil {
+		t.Fatalf("init local storage: %v", err)
+	}
+	h, _ := oss.LocalHandler()
+	server := httptest.NewServer(http.StripPrefix("/files/", h))
+	defer server.Close()
+
+	key := "courses/1/chapters/2/lessons/video.mp4"
+	uploadID, err := oss.InitMultipart(key, "video/mp4")
+	if err != nil {
+		t.Fatalf("init multipart: %v", err)
+	}
+
+	// 分片乱序上传，合并时按分片号排序
+	for _, part := range []struct {
+		n    int
+		data string
+	}{{2, "world"}, {1, "hello "}} {
+		signed, err := oss.SignPartURL(key, uploadID, part.n, time.Minute)
+		if err != nil {
+			t.Fatalf("sign part: %v", err)
+		}
+		req, _ := http.NewRequest(http.MethodPut, strings.Replace(signed, "http://localhost:8080", server.URL, 1), strings.NewReader(part.data))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("put part: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("put part %d: status %d", part.n, resp.StatusCode)
+		}
+	}
+
+	parts, err := oss.ListParts(key, uploadID)
+	if err != nil || len(parts) != 2 || parts[0].PartNumber != 1 {
+		t.Fatalf("list parts: %v %+v", err, parts)
+	}
+	if err := oss.CompleteMultipart(key, uploadID, parts); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	rc, err := oss.GetObject(key)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	body, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(body) != "hello world" {
+		t.Errorf("unexpected merged object %q", body)
+	}
+}
+
+func TestLocalStorageList(t *testing.T) {
+	err := oss.InitOSSClient(config.OSSConfig{
+		Provider:        oss.ProviderLocal,
+		LocalDir:        t.TempDir(),
+		PublicURL:       "http://localhost:8080",
+		AccessKeySecret: "secret",
+	})
+	if err != nil {
+		t.Fatalf("init local storage: %v", err)
+	}
+
+	keys := []string{"answers/1/2/3/a.png", "answers/1/2/4/b.png", "courses/1/assets/c.png", "courses/10/d.md"}
+	for _, key := range keys {
+		if _, err := oss.UploadReader(context.Background(), key, strings.NewReader(key)); err != nil {
+			t.Fatalf("upload %s: %v", key, err)
+		}
+	}
+	// 未完成的分片上传不是对象
+	if _, err := oss.InitMultipart("courses/1/video.mp4", "video/mp4"); err != nil {
+		t.Fatalf("init multipart: %v", err)
+	}
+
+	var listed []string
+	if err := oss.ListObjects("courses/1/", func(o oss.ObjectInfo) error {
+		listed = append(listed, o.Key)
+		return nil
+	}); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(listed) != 1 || listed[0] != "courses/1/assets/c.png" {
+		t.Errorf("unexpected objects under courses/1/: %v", listed)
+	}
+
+	storage, _ := oss.GetStorage()
+	page, next, err := storage.List(context.Background(), "", "", 3)
+	if err != nil || len(page) != 3 || next != page[2].Key {
+		t.Fatalf("first page: %v %v %q", err, page, next)
+	}
+	page, next, err = storage.List(context.Background(), "", next, 3)
+	if err != nil || len(page) != 1 || page[0].Key != "courses/10/d.md" || next != "" {
+		t.Errorf("last page: %v %v %q", err, page, next)
+	}
+}
+
+// 未复制内容的克隆课程引用原课程的 Markdown 正文，正文中原课程的资源仍需签名
+func TestSignEmbeddedURLsSharedClone(t *testing.T) {
+	err := oss.InitOSSClient(config.OSSConfig{
+		Provider:        oss.ProviderLocal,
+		LocalDir:        t.TempDir(),
+		PublicURL:       "http://localhost:8080",
+		AccessKeySecret: "secret",
+	})
+	if err != nil {
+		t.Fatalf("init local storage: %v", err)
+	}
+
+	base := "http://localhost:8080/files/"
+	body := "![图](" + base + "courses/1/assets/a.png)\n" +
+		"<img src=\"" + base + "courses/2/assets/b.png\"> https://example.com/c.png"
+	signed, err := oss.SignEmbeddedURLs(body, "courses/1/", time.Minute)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if strings.Contains(signed, "("+base+"courses/1/assets/a.png)") {
+		t.Errorf("asset of the source course should be signed: %s", signed)
+	}
+	if !strings.Contains(signed, base+"courses/1/assets/a.png?") {
+		t.Errorf("signed asset should keep its key: %s", signed)
+	}
+	if !strings.Contains(signed, "\""+base+"courses/2/assets/b.png\"") {
+		t.Errorf("asset outside the scope should be kept as is: %s", signed)
+	}
+	if !strings.HasSuffix(signed, " https://example.com/c.png") {
+		t.Errorf("external link should be kept as is: %s", signed)
+	}
+}
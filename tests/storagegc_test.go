@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"online-learning-platform/internal/oss"
+	"online-learning-platform/internal/storagegc"
+)
+
+func TestStorageGCExtractURLs(t *testing.T) {
+	prefix := "https://bucket.oss.example.com/"
+	text := `![图](https://bucket.oss.example.com/courses/1/assets/a.png) 见 <img src="https://bucket.oss.example.com/courses/1/assets/b.png">` +
+		`{"content_url":"https://bucket.oss.example.com/courses/1/chapters/2/lessons/v.mp4","description":"https://bucket.oss.example.com/x.pdf\n"}` +
+		` https://other.example.com/courses/1/c.png https://bucket.oss.example.com/`
+	got := storagegc.ExtractURLs(text, prefix)
+	want := []string{
+		prefix + "courses/1/assets/a.png",
+		prefix + "courses/1/assets/b.png",
+		prefix + "courses/1/chapters/2/lessons/v.mp4",
+		prefix + "x.pdf",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ExtractURLs = %v, want %v", got, want)
+	}
+}
+
+func TestStorageGCReport(t *testing.T) {
+	now := time.Now()
+	report := storagegc.NewReport([]string{"answers/", "courses/"}, now.Add(-72*time.Hour), true)
+	refs := storagegc.NewReferences()
+	refs.Add("answers/1/1/1/a.png")
+	refs.AddPrefix("courses/1/chapters/2/lessons/1_v_hls/")
+
+	report.Add(oss.ObjectInfo{Key: "answers/1/1/1/a.png", Size: 10, LastModified: now.Add(-100 * time.Hour)}, refs)
+	report.Add(oss.ObjectInfo{Key: "answers/1/1/1/b.png", Size: 20, LastModified: now.Add(-100 * time.Hour)}, refs)
+	report.Add(oss.ObjectInfo{Key: "answers/1/1/1/c.png", Size: 30, LastModified: now.Add(-time.Hour)}, refs)
+	report.Add(oss.ObjectInfo{Key: "courses/1/chapters/2/lessons/1_v_hls/720p/seg_000.ts", Size: 40, LastModified: now.Add(-100 * time.Hour)}, refs)
+
+	if report.Scanned != 4 || report.Referenced != 2 || report.Recent != 1 {
+		t.Errorf("unexpected counts %+v", report)
+	}
+	if len(report.Orphans) != 1 || report.Orphans[0].Key != "answers/1/1/1/b.png" || report.OrphanBytes != 20 {
+		t.Errorf("unexpected orphans %+v", report.Orphans)
+	}
+	if out := report.String(); !strings.Contains(out, "dry run") || !strings.Contains(out, "orphan answers/1/1/1/b.png") {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+}
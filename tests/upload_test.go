@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"online-learning-platform/internal/upload"
+)
+
+func TestUploadPlanParts(t *testing.T) {
+	plan, err := upload.PlanParts(3 << 20)
+	if err != nil || plan.Multipart() || plan.PartSize != 3<<20 {
+		t.Errorf("small files should be uploaded at once, got %+v %v", plan, err)
+	}
+
+	plan, err = upload.PlanParts(20 << 20)
+	if err != nil || plan.PartSize != upload.MinPartSize || plan.PartCount != 3 {
+		t.Errorf("unexpected plan for 20MB: %+v %v", plan, err)
+	}
+	if offset, length := plan.PartRange(3, 20<<20); offset != 16<<20 || length != 4<<20 {
+		t.Errorf("unexpected last part range %d+%d", offset, length)
+	}
+
+	// 2GB 的视频分片数不超过 MaxParts，分片大小按 1MB 取整
+	plan, err = upload.PlanParts(2 << 30)
+	if err != nil || plan.PartCount > upload.MaxParts || plan.PartSize%(1<<20) != 0 {
+		t.Errorf("unexpected plan for 2GB: %+v %v", plan, err)
+	}
+
+	if _, err := upload.PlanParts(0); err != upload.ErrInvalidSize {
+		t.Errorf("expected ErrInvalidSize, got %v", err)
+	}
+}
+
+func TestUploadVerify(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 1000)...)
+	sum := sha256.Sum256(png)
+	expected := upload.Expected{Size: int64(len(png)), SHA256: hex.EncodeToString(sum[:]), ContentType: "image/png"}
+
+	result, err := upload.Inspect(bytes.NewReader(png))
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	if result.ContentType != "image/png" {
+		t.Errorf("expected sniffed image/png, got %s", result.ContentType)
+	}
+	if err := upload.Verify(result, expected); err != nil {
+		t.Errorf("expected valid upload, got %v", err)
+	}
+
+	tampered := expected
+	tampered.SHA256 = strings.Repeat("0", 64)
+	if err := upload.Verify(result, tampered); err != upload.ErrChecksumMismatch {
+		t.Errorf("expected ErrChecksumMismatch, got %v", err)
+	}
+	tampered = expected
+	tampered.Size++
+	if err := upload.Verify(result, tampered); err != upload.ErrSizeMismatch {
+		t.Errorf("expected ErrSizeMismatch, got %v", err)
+	}
+	tampered = expected
+	tampered.ContentType = "application/pdf"
+	if err := upload.Verify(result, tampered); err != upload.ErrTypeMismatch {
+		t.Errorf("expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestUploadTypeMatches(t *testing.T) {
+	cases := []struct {
+		declared, sniffed string
+		want              bool
+	}{
+		{"image/jpeg", "image/jpeg", true},
+		{"image/webp", "image/png", true},
+		{"text/markdown", "text/plain; charset=utf-8", true},
+		{"application/pdf", "application/pdf", true},
+		{"application/pdf", "application/zip", false},
+		{"video/mp4", "application/octet-stream", false},
+		{"image/png", "text/html; charset=utf-8", false},
+		{"text/plain", "text/html; charset=utf-8", false},
+	}
+	for _, c := range cases {
+		if got := upload.TypeMatches(c.declared, c.sniffed); got != c.want {
+			t.Errorf("TypeMatches(%q, %q) = %v, want %v", c.declared, c.sniffed, got, c.want)
+		}
+	}
+}
+
+func TestUploadPolicy(t *testing.T) {
+	policy := upload.Policy{AllowedTypes: []string{"image/png", "application/pdf"}, MaxSize: 10 << 20}
+	if err := policy.Check("image/png", 1<<20); err != nil {
+		t.Errorf("expected png to be allowed, got %v", err)
+	}
+	if err := policy.Check("video/mp4", 1<<20); err != upload.ErrTypeNotAllowed {
+		t.Errorf("expected ErrTypeNotAllowed, got %v", err)
+	}
+	if err := policy.Check("image/png", 11<<20); err != upload.ErrTooLarge {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+
+	// 任务只能在全局限制内收紧
+	narrowed := policy.Narrow([]string{"application/pdf", "video/mp4"}, 20<<20)
+	if len(narrowed.AllowedTypes) != 1 || narrowed.AllowedTypes[0] != "application/pdf" || narrowed.MaxSize != 10<<20 {
+		t.Errorf("unexpected narrowed policy %+v", narrowed)
+	}
+	if unchanged := policy.Narrow(nil, 0); len(unchanged.AllowedTypes) != 2 || unchanged.MaxSize != 10<<20 {
+		t.Errorf("empty task limits should keep the global policy, got %+v", unchanged)
+	}
+
+	types, err := upload.ParseTypes(" image/PNG , application/pdf; charset=binary,")
+	if err != nil || len(types) != 2 || types[0] != "image/png" || types[1] != "application/pdf" {
+		t.Errorf("unexpected parsed types %v %v", types, err)
+	}
+	if _, err := upload.ParseTypes("png"); err == nil {
+		t.Errorf("types without subtype should be rejected")
+	}
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	cases := []struct {
+		name, contentType, want string
+	}{
+		{"report.pdf", "application/pdf", "report.pdf"},
+		{"C:\\Users\\me\\作业 1.PDF", "application/pdf", "作业_1.pdf"},
+		{"../../etc/passwd", "text/plain", "passwd.txt"},
+		{"shell.php.png", "image/png", "shell_php.png"},
+		{"page.html", "text/plain", "page_html.txt"},
+		{".hidden", "image/jpeg", "hidden.jpg"},
+		{"photo.jpeg", "image/jpeg", "photo.jpeg"},
+		{"", "video/mp4", "file.mp4"},
+		{"a\x00b<script>.md", "text/markdown", "a_b_script.md"},
+	}
+	for _, c := range cases {
+		if got := upload.SanitizeFileName(c.name, c.contentType); got != c.want {
+			t.Errorf("SanitizeFileName(%q, %q) = %q, want %q", c.name, c.contentType, got, c.want)
+		}
+	}
+
+	long := upload.SanitizeFileName(strings.Repeat("a", 300)+".png", "image/png")
+	if len(long) != 84 {
+		t.Errorf("long names should be truncated, got %d bytes", len(long))
+	}
+}
+
+func TestUploadTypeByExtension(t *testing.T) {
+	cases := map[string]string{
+		"lessons/intro.MD":  "text/markdown",
+		"assets/photo.JPEG": "image/jpeg",
+		"video.m4v":         "video/mp4",
+		"notes.txt":         "text/plain",
+		"README":            "",
+		"archive.unknownx":  "",
+	}
+	for name, want := range cases {
+		if got := upload.TypeByExtension(name); got != want {
+			t.Errorf("TypeByExtension(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"online-learning-platform/internal/video"
+)
+
+func TestVideoParseProbe(t *testing.T) {
+	data := []byte(`{
+		"streams": [
+			{"codec_type": "video", "width": 1280, "height": 720, "duration": "95.400000"},
+			{"codec_type": "audio", "duration": "95.380000"}
+		],
+		"format": {"duration": "95.433000"}
+	}`)
+	probe, err := video.ParseProbe(data)
+	if err != nil {
+		t.Fatalf("ParseProbe: %v", err)
+	}
+	if probe.Width != 1280 || probe.Height != 720 || !probe.HasAudio || probe.Duration != 95.433 {
+		t.Errorf("ParseProbe = %+v", probe)
+	}
+
+	if _, err := video.ParseProbe([]byte(`{"streams":[{"codec_type":"audio"}],"format":{"duration":"3"}}`)); err == nil {
+		t.Error("ParseProbe without video stream: want error")
+	}
+	if _, err := video.ParseProbe([]byte(`{"streams":[{"codec_type":"video","width":640,"height":360}],"format":{}}`)); err == nil {
+		t.Error("ParseProbe without duration: want error")
+	}
+}
+
+func TestVideoSelectRenditions(t *testing.T) {
+	names := func(rs []video.Rendition) string {
+		var s []string
+		for _, r := range rs {
+			s = append(s, r.Name)
+		}
+		return strings.Join(s, ",")
+	}
+	tests := []struct {
+		height int
+		want   string
+	}{
+		{2160, "1080p,720p,480p,360p"},
+		{720, "720p,480p,360p"},
+		{600, "480p,360p"},
+		{241, "240p"},
+	}
+	for _, tt := range tests {
+		if got := names(video.SelectRenditions(video.DefaultLadder, tt.height)); got != tt.want {
+			t.Errorf("SelectRenditions(%d) = %s, want %s", tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestVideoMasterPlaylist(t *testing.T) {
+	probe := video.Probe{Duration: 60, Width: 1920, Height: 1080, HasAudio: true}
+	got := video.MasterPlaylist(video.SelectRenditions(video.DefaultLadder, 720), probe)
+	want := "#EXTM3U\n#EXT-X-VERSION:3\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=2928000,RESOLUTION=1280x720\n720p/index.m3u8\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=1528000,RESOLUTION=854x480\n480p/index.m3u8\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=896000,RESOLUTION=640x360\n360p/index.m3u8\n"
+	if got != want {
+		t.Errorf("MasterPlaylist =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestVideoRewriteSegments(t *testing.T) {
+	playlist := "#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXTINF:6.000000,\nseg_000.ts\n#EXTINF:2.500000,\nseg_001.ts\n#EXT-X-ENDLIST\n"
+	got, err := video.RewriteSegments(playlist, func(uri string) (string, error) {
+		return "https://cdn.example.com/720p/" + uri + "?sig=x", nil
+	})
+	if err != nil {
+		t.Fatalf("RewriteSegments: %v", err)
+	}
+	want := "#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXTINF:6.000000,\nhttps://cdn.example.com/720p/seg_000.ts?sig=x\n" +
+		"#EXTINF:2.500000,\nhttps://cdn.example.com/720p/seg_001.ts?sig=x\n#EXT-X-ENDLIST\n"
+	if got != want {
+		t.Errorf("RewriteSegments =\n%s\nwant\n%s", got, want)
+	}
+
+	// 主播放列表中的子播放列表保持相对路径
+	master := "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=896000\n360p/index.m3u8\n"
+	if got, _ := video.RewriteSegments(master, func(uri string) (string, error) { return "x", nil }); got != master {
+		t.Errorf("RewriteSegments(master) = %q", got)
+	}
+}
+
+func TestVideoKeys(t *testing.T) {
+	prefix := video.DerivedPrefix("courses/1/chapters/2/lessons/3_intro.mp4")
+	if prefix != "courses/1/chapters/2/lessons/3_intro_hls/" {
+		t.Errorf("DerivedPrefix = %s", prefix)
+	}
+	if !video.IsMasterPlaylistKey(prefix + video.MasterPlaylistName) {
+		t.Error("IsMasterPlaylistKey(master) = false")
+	}
+	if video.IsMasterPlaylistKey("courses/1/master.m3u8") {
+		t.Error("IsMasterPlaylistKey(other) = true")
+	}
+
+	for name, want := range map[string]bool{
+		"master.m3u8":        true,
+		"720p/index.m3u8":    true,
+		"../index.m3u8":      false,
+		"720p/seg_000.ts":    false,
+		"720p/../index.m3u8": false,
+		"thumbnail.jpg":      false,
+	} {
+		if got := video.ValidPlaylistName(name); got != want {
+			t.Errorf("ValidPlaylistName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}